@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/employee"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/leave"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
 	"github.com/Caknoooo/go-gin-clean-starter/providers"
 	"github.com/Caknoooo/go-gin-clean-starter/script"
 	"github.com/samber/do"
@@ -67,7 +77,23 @@ func main() {
 	user.RegisterRoutes(server, injector)
 	auth.RegisterRoutes(server, injector)
 	employee.RegisterRoutes(server, injector)
+	master.RegisterRoutes(server, injector)
 	attendance.RegisterRoutes(server, injector)
+	shift.RegisterRoutes(server, injector)
+	leave.RegisterRoutes(server, injector)
+	overtime.RegisterRoutes(server, injector)
+	notification.RegisterRoutes(server, injector)
+	device.RegisterRoutes(server, injector)
+	visit.RegisterRoutes(server, injector)
+
+	// Register background jobs
+	if scheduler.Enabled() {
+		jobs := scheduler.New(helpers.LoadTimezone(""))
+		if err := attendance.RegisterJobs(jobs, injector); err != nil {
+			log.Fatalf("error registering jobs: %v", err)
+		}
+		jobs.Start(context.Background())
+	}
 
 	run(server)
 }
@@ -32,7 +32,8 @@ func SetUpDatabaseConnection() *gorm.DB {
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{
-		Logger: SetupLogger(),
+		Logger:         SetupLogger(),
+		TranslateError: true,
 	})
 	if err != nil {
 		panic(err)
@@ -55,7 +56,7 @@ func SetUpTestDatabaseConnection() *gorm.DB {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
-	}), &gorm.Config{})
+	}), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic(err)
 	}
@@ -66,7 +67,7 @@ func SetUpTestDatabaseConnection() *gorm.DB {
 }
 
 func SetUpInMemoryDatabase() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic(err)
 	}
@@ -74,7 +75,7 @@ func SetUpInMemoryDatabase() *gorm.DB {
 }
 
 func SetUpTestSQLiteDatabase() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttendanceAnomaly is a punch the anomaly rules found suspicious. It waits in
+// the supervisor's review queue until confirmed or dismissed; the attendance
+// itself is never changed by a flag.
+type AttendanceAnomaly struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID   uuid.UUID `gorm:"type:uuid;not null" json:"employee_id"`
+	AttendanceID uuid.UUID `gorm:"type:uuid;not null" json:"attendance_id"`
+	PunchID      uuid.UUID `gorm:"type:uuid;not null" json:"punch_id"`
+	WorkDate     time.Time `gorm:"type:date;not null" json:"work_date"`
+	Rule         string    `gorm:"type:varchar(30);not null" json:"rule"`
+	Detail       string    `gorm:"type:text" json:"detail"`
+
+	// The other employee of a buddy punching pair
+	RelatedEmployeeID *uuid.UUID `gorm:"type:uuid" json:"related_employee_id,omitempty"`
+
+	Status     string     `gorm:"type:varchar(20);not null;default:'open'" json:"status"`
+	ReviewerID *uuid.UUID `gorm:"type:uuid" json:"reviewer_id"`
+	ReviewedAt *time.Time `gorm:"type:timestamptz" json:"reviewed_at"`
+	ReviewNote string     `gorm:"type:text" json:"review_note"`
+
+	Employee        Employee         `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+	RelatedEmployee *Employee        `gorm:"foreignKey:RelatedEmployeeID;references:ID" json:"related_employee,omitempty"`
+	Punch           *AttendancePunch `gorm:"foreignKey:PunchID;references:ID" json:"punch,omitempty"`
+
+	Timestamp
+}
+
+func (AttendanceAnomaly) TableName() string {
+	return "attendance_anomalies"
+}
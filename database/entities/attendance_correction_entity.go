@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttendanceCorrection is an employee's request to fix a missed or wrong punch.
+// It only touches the attendance row once approved.
+type AttendanceCorrection struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID   uuid.UUID  `gorm:"type:uuid;not null" json:"employee_id"`
+	AttendanceID *uuid.UUID `gorm:"type:uuid" json:"attendance_id"`
+	LocationID   uuid.UUID  `gorm:"type:uuid;not null" json:"location_id"`
+	WorkDate     time.Time  `gorm:"type:date;not null" json:"work_date"`
+
+	ProposedCheckIn  *time.Time `gorm:"type:timestamptz" json:"proposed_check_in"`
+	ProposedCheckOut *time.Time `gorm:"type:timestamptz" json:"proposed_check_out"`
+	Reason           string     `gorm:"type:text;not null" json:"reason"`
+
+	Status     string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ApproverID *uuid.UUID `gorm:"type:uuid" json:"approver_id"`
+	ReviewedAt *time.Time `gorm:"type:timestamptz" json:"reviewed_at"`
+	ReviewNote string     `gorm:"type:text" json:"review_note"`
+
+	// Values of the attendance row right before the correction was applied
+	OriginalCheckIn  *time.Time `gorm:"type:timestamptz" json:"original_check_in"`
+	OriginalCheckOut *time.Time `gorm:"type:timestamptz" json:"original_check_out"`
+	OriginalStatus   string     `gorm:"type:varchar" json:"original_status"`
+
+	Employee   Employee    `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+	Attendance *Attendance `gorm:"foreignKey:AttendanceID;references:ID" json:"attendance,omitempty"`
+
+	Timestamp
+}
+
+func (AttendanceCorrection) TableName() string {
+	return "attendance_corrections"
+}
@@ -9,14 +9,55 @@ import (
 type Attendance struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	EmployeeID   uuid.UUID  `gorm:"type:uuid" json:"employee_id"`
-	LocationID   uuid.UUID  `gorm:"type:uuid" json:"location_id"`
-	CheckInTime  time.Time  `gorm:"type:timestamptz" json:"check_in_time"`
+	LocationID   *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+	CheckInTime  *time.Time `gorm:"type:timestamptz" json:"check_in_time"`
 	CheckOutTime *time.Time `gorm:"type:timestamptz" json:"check_out_time"`
 	Status       string     `gorm:"type:varchar" json:"status"`
-	CreatedAt    time.Time  `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
+
+	// Day the attendance counts towards in the location timezone; an overnight
+	// shift keeps the date it started on
+	WorkDate time.Time `gorm:"type:date" json:"work_date"`
+
+	// Device position captured at punch time, distance is measured to the location center
+	CheckInLatitude   *float64 `gorm:"type:decimal" json:"check_in_latitude"`
+	CheckInLongitude  *float64 `gorm:"type:decimal" json:"check_in_longitude"`
+	CheckInAccuracy   *float64 `gorm:"type:decimal" json:"check_in_accuracy"`
+	CheckInDistance   *float64 `gorm:"type:decimal" json:"check_in_distance"`
+	CheckOutLatitude  *float64 `gorm:"type:decimal" json:"check_out_latitude"`
+	CheckOutLongitude *float64 `gorm:"type:decimal" json:"check_out_longitude"`
+	CheckOutAccuracy  *float64 `gorm:"type:decimal" json:"check_out_accuracy"`
+	CheckOutDistance  *float64 `gorm:"type:decimal" json:"check_out_distance"`
+
+	// Selfies taken at punch time, stored under the assets directory
+	CheckInPhoto  string `gorm:"type:varchar" json:"check_in_photo"`
+	CheckOutPhoto string `gorm:"type:varchar" json:"check_out_photo"`
+
+	// Shift snapshot taken at check-in, minutes are computed against it
+	ShiftID           *uuid.UUID `gorm:"type:uuid" json:"shift_id"`
+	ScheduledStart    *time.Time `gorm:"type:timestamptz" json:"scheduled_start"`
+	ScheduledEnd      *time.Time `gorm:"type:timestamptz" json:"scheduled_end"`
+	LateMinutes       int        `gorm:"type:int;default:0" json:"late_minutes"`
+	EarlyLeaveMinutes int        `gorm:"type:int;default:0" json:"early_leave_minutes"`
+	WorkedMinutes     int        `gorm:"type:int;default:0" json:"worked_minutes"`
+
+	// Time spent between break-start and break-end punches
+	BreakMinutes int `gorm:"type:int;default:0" json:"break_minutes"`
+
+	// Set when the check-out was filled in by the system for a forgotten punch
+	AutoCheckout bool `gorm:"default:false" json:"auto_checkout"`
+
+	// Onsite, or remote when checked in at the remote location under an
+	// approved remote work request
+	WorkMode            string     `gorm:"type:varchar(20);default:'onsite'" json:"work_mode"`
+	RemoteWorkRequestID *uuid.UUID `gorm:"type:uuid" json:"remote_work_request_id"`
+
+	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
 
 	Employee Employee `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
 	Location Location `gorm:"foreignKey:LocationID;references:ID" json:"location"`
+	Shift    *Shift   `gorm:"foreignKey:ShiftID;references:ID" json:"shift,omitempty"`
+
+	Punches []AttendancePunch `gorm:"foreignKey:AttendanceID;references:ID" json:"punches,omitempty"`
 }
 
 func (Attendance) TableName() string {
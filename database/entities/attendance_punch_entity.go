@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttendancePunch is a single clock event of an attendance day. The attendance
+// row keeps the first check-in, the last check-out and the totals derived from
+// its punches.
+type AttendancePunch struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	AttendanceID uuid.UUID  `gorm:"type:uuid;not null" json:"attendance_id"`
+	Type         string     `gorm:"type:varchar(20);not null" json:"type"`
+	PunchTime    time.Time  `gorm:"type:timestamptz;not null" json:"punch_time"`
+	LocationID   *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+
+	Latitude  *float64 `gorm:"type:decimal" json:"latitude"`
+	Longitude *float64 `gorm:"type:decimal" json:"longitude"`
+	Accuracy  *float64 `gorm:"type:decimal" json:"accuracy"`
+	Distance  *float64 `gorm:"type:decimal" json:"distance"`
+	Photo     string   `gorm:"type:varchar" json:"photo"`
+
+	// Punches queued offline keep the id the device gave them, so a retried
+	// upload is recognised, along with the device and when they reached us.
+	ClientID *uuid.UUID `gorm:"type:uuid" json:"client_id,omitempty"`
+	DeviceID string     `gorm:"type:varchar(100)" json:"device_id,omitempty"`
+	SyncedAt *time.Time `gorm:"type:timestamptz" json:"synced_at,omitempty"`
+
+	// State key pressed on a fingerprint terminal, for punches imported from
+	// its attendance log; nil for any other punch.
+	TerminalStatus *int `gorm:"type:smallint" json:"terminal_status,omitempty"`
+
+	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
+}
+
+func (AttendancePunch) TableName() string {
+	return "attendance_punches"
+}
@@ -6,4 +6,11 @@ type Department struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	Name        string    `gorm:"type:varchar;unique;not null" json:"name"`
 	Description string    `gorm:"type:varchar" json:"description"`
+
+	// Employee who approves the longer leave of the department
+	HeadID *uuid.UUID `gorm:"type:uuid" json:"head_id"`
+
+	// Most employees of the department who may be off on the same day, pending
+	// requests included; no cap without one
+	MaxOnLeave *int `gorm:"type:int" json:"max_on_leave"`
 }
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device is a fingerprint terminal pushing its scans over the ZKTeco ADMS
+// protocol. A terminal that calls in before it was registered is kept pending,
+// inactive and without a location, until an admin assigns it one.
+type Device struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	SerialNumber string     `gorm:"type:varchar(50);unique;not null" json:"serial_number"`
+	Name         string     `gorm:"type:varchar" json:"name"`
+	LocationID   *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+	IsActive     bool       `gorm:"default:false" json:"is_active"`
+
+	// Firmware details and the stamp of the last attendance log upload the
+	// terminal reported, handed back on its next handshake so it resumes there.
+	PushVersion string     `gorm:"type:varchar(20)" json:"push_version"`
+	AttlogStamp string     `gorm:"type:varchar(50)" json:"attlog_stamp"`
+	LastSeenAt  *time.Time `gorm:"type:timestamptz" json:"last_seen_at"`
+
+	Location *Location `gorm:"foreignKey:LocationID;references:ID" json:"location,omitempty"`
+
+	Timestamp
+}
+
+func (Device) TableName() string {
+	return "devices"
+}
+
+// DeviceCommand is a command queued for a terminal, handed out on its next
+// poll and closed when the terminal reports the result. The protocol refers
+// to it by CommandNo.
+type DeviceCommand struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DeviceID    uuid.UUID  `gorm:"type:uuid;not null" json:"device_id"`
+	CommandNo   int64      `gorm:"autoIncrement;not null" json:"command_no"`
+	Command     string     `gorm:"type:text;not null" json:"command"`
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"`
+	ReturnCode  *int       `gorm:"type:int" json:"return_code"`
+	SentAt      *time.Time `gorm:"type:timestamptz" json:"sent_at"`
+	CompletedAt *time.Time `gorm:"type:timestamptz" json:"completed_at"`
+
+	Timestamp
+}
+
+func (DeviceCommand) TableName() string {
+	return "device_commands"
+}
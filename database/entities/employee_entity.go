@@ -19,6 +19,10 @@ type Employee struct {
 	EmploymentStatus string    `gorm:"type:varchar" json:"employment_status"`
 	ProbationEndDate time.Time `gorm:"type:date" json:"probation_end_date"`
 
+	// The location the employee is based at; its regional holidays are days
+	// off for them on top of the company-wide ones
+	LocationID *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+
 	User       User       `gorm:"foreignKey:UserID;references:ID" json:"user"`
 	Supervisor *Employee  `gorm:"foreignKey:SupervisorID;references:ID" json:"supervisor"`
 	Department Department `gorm:"foreignKey:DepartmentID;references:ID" json:"department"`
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Holiday struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Date        time.Time `gorm:"type:date;not null" json:"date"`
+	Name        string    `gorm:"type:varchar;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Type        string    `gorm:"type:varchar(20);not null;default:'public'" json:"type"`
+
+	// A regional holiday is only a day off for employees based at the
+	// location; without one it applies company-wide
+	LocationID *uuid.UUID `gorm:"type:uuid" json:"location_id"`
+
+	Location *Location `gorm:"foreignKey:LocationID;references:ID" json:"location,omitempty"`
+
+	Timestamp
+}
+
+func (Holiday) TableName() string {
+	return "holidays"
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveApproval is one step of a leave's approval chain. Steps are decided in
+// Level order; ApproverID names the employee who must act, and is empty for
+// the HR step, which any holder of manage_leave may take.
+type LeaveApproval struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LeaveID    uuid.UUID  `gorm:"type:uuid;not null" json:"leave_id"`
+	Level      int        `gorm:"type:int;not null" json:"level"`
+	Role       string     `gorm:"type:varchar(20);not null" json:"role"`
+	ApproverID *uuid.UUID `gorm:"type:uuid" json:"approver_id"`
+
+	Status      string     `gorm:"type:varchar(20);not null;default:'waiting'" json:"status"`
+	DecidedByID *uuid.UUID `gorm:"type:uuid" json:"decided_by_id"`
+	DecidedAt   *time.Time `gorm:"type:timestamptz" json:"decided_at"`
+	Comment     string     `gorm:"type:text" json:"comment"`
+
+	Approver  *Employee `gorm:"foreignKey:ApproverID;references:ID" json:"approver,omitempty"`
+	DecidedBy *Employee `gorm:"foreignKey:DecidedByID;references:ID" json:"decided_by,omitempty"`
+
+	Timestamp
+}
+
+func (LeaveApproval) TableName() string {
+	return "leave_approvals"
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveCancellation asks to give back the days of an approved leave from
+// FromDate to its end, ToDate when it was requested. A cancellation from the
+// first day cancels the leave; a later one shortens it to the days already
+// taken. ApproverID is the approver of the leave's last step, and empty when
+// HR approved it, so any holder of manage_leave may decide.
+type LeaveCancellation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LeaveID    uuid.UUID  `gorm:"type:uuid;not null" json:"leave_id"`
+	FromDate   time.Time  `gorm:"type:date;not null" json:"from_date"`
+	ToDate     time.Time  `gorm:"type:date;not null" json:"to_date"`
+	Days       float64    `gorm:"type:decimal(6,2);not null;default:0" json:"days"`
+	Reason     string     `gorm:"type:text" json:"reason"`
+	ApproverID *uuid.UUID `gorm:"type:uuid" json:"approver_id"`
+
+	Status      string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	DecidedByID *uuid.UUID `gorm:"type:uuid" json:"decided_by_id"`
+	DecidedAt   *time.Time `gorm:"type:timestamptz" json:"decided_at"`
+	Comment     string     `gorm:"type:text" json:"comment"`
+
+	Leave     *Leave    `gorm:"foreignKey:LeaveID;references:ID" json:"leave,omitempty"`
+	Approver  *Employee `gorm:"foreignKey:ApproverID;references:ID" json:"approver,omitempty"`
+	DecidedBy *Employee `gorm:"foreignKey:DecidedByID;references:ID" json:"decided_by,omitempty"`
+
+	Timestamp
+}
+
+func (LeaveCancellation) TableName() string {
+	return "leave_cancellations"
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"time"
 
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/google/uuid"
 )
 
@@ -13,11 +14,32 @@ type Leave struct {
     EndDate    time.Time `gorm:"type:date" json:"end_date"`
     Reason     string    `gorm:"type:text" json:"reason"`
     Status     string    `gorm:"type:varchar" json:"status"`
+
+    // Days the leave takes, working days unless the type counts calendar days
+    LeaveTypeID *uuid.UUID `gorm:"type:uuid" json:"leave_type_id"`
+    Days        float64    `gorm:"type:decimal(6,2);default:0" json:"days"`
+
+    // Part of the day taken: a morning or afternoon session of a half day, or
+    // StartTime to EndTime ("HH:MM") of an hourly leave, on a single date
+    Unit      string  `gorm:"type:varchar(20);not null;default:'full_day'" json:"unit"`
+    Session   *string `gorm:"type:varchar(20)" json:"session,omitempty"`
+    StartTime *string `gorm:"type:varchar(5)" json:"start_time,omitempty"`
+    EndTime   *string `gorm:"type:varchar(5)" json:"end_time,omitempty"`
+
     CreatedAt  time.Time `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
 
-    Employee Employee `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+    Employee      Employee            `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+    LeaveType     *LeaveType          `gorm:"foreignKey:LeaveTypeID;references:ID" json:"leave_type,omitempty"`
+    Approvals     []LeaveApproval     `gorm:"foreignKey:LeaveID;references:ID" json:"approvals,omitempty"`
+    Cancellations []LeaveCancellation `gorm:"foreignKey:LeaveID;references:ID" json:"cancellations,omitempty"`
 }
 
 func (Leave) TableName() string {
     return "leaves"
 }
+
+// Partial reports whether the leave takes only part of its date, a half-day
+// session or a few hours.
+func (l Leave) Partial() bool {
+    return l.Unit == constants.ENUM_LEAVE_UNIT_HALF_DAY || l.Unit == constants.ENUM_LEAVE_UNIT_HOURLY
+}
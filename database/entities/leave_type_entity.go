@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+// LeaveType is a kind of leave and its entitlement. Types with an accrual
+// policy keep a yearly balance per employee; the others are only capped per
+// request by MaxDaysPerRequest, when set.
+type LeaveType struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Code        string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Paid        bool      `gorm:"not null" json:"paid"`
+
+	// Counts every calendar day of a request instead of the working days,
+	// e.g. maternity leave
+	CalendarDays bool `gorm:"default:false" json:"calendar_days"`
+
+	// Balance built up per year; nothing accrues before MinServiceMonths of
+	// service from the join date, and ProRate grants a yearly type only the
+	// months left in the year it becomes due
+	AccrualPolicy    string  `gorm:"type:varchar(20);not null;default:'none'" json:"accrual_policy"`
+	DaysPerYear      float64 `gorm:"type:decimal(5,1);default:0" json:"days_per_year"`
+	MinServiceMonths int     `gorm:"type:int;default:0" json:"min_service_months"`
+	ProRate          bool    `gorm:"default:false" json:"pro_rate"`
+
+	MaxDaysPerRequest *float64 `gorm:"type:decimal(5,1)" json:"max_days_per_request"`
+
+	// Whether the type may be taken for a morning or afternoon session, or
+	// for a few hours of a working day
+	AllowHalfDay bool `gorm:"default:false" json:"allow_half_day"`
+	AllowHourly  bool `gorm:"default:false" json:"allow_hourly"`
+
+	// Requests of at least this many days also go to the department head and
+	// then HR after the supervisor; unset skips the step, 0 always takes it
+	HeadApprovalFromDays *float64 `gorm:"type:decimal(5,1)" json:"head_approval_from_days"`
+	HRApprovalFromDays   *float64 `gorm:"type:decimal(5,1)" json:"hr_approval_from_days"`
+
+	IsActive bool `gorm:"default:true" json:"is_active"`
+
+	Timestamp
+}
+
+func (LeaveType) TableName() string {
+	return "leave_types"
+}
+
+// LeaveBalance is what an employee has of a leave type in one year. Entitled
+// follows the accrual policy, Adjustment is set by HR, e.g. for days carried
+// over, and Used grows as leave is approved.
+type LeaveBalance struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID  uuid.UUID `gorm:"type:uuid;not null" json:"employee_id"`
+	LeaveTypeID uuid.UUID `gorm:"type:uuid;not null" json:"leave_type_id"`
+	Year        int       `gorm:"type:int;not null" json:"year"`
+	Entitled    float64   `gorm:"type:decimal(6,2);default:0" json:"entitled"`
+	Adjustment  float64   `gorm:"type:decimal(6,2);default:0" json:"adjustment"`
+	Used        float64   `gorm:"type:decimal(6,2);default:0" json:"used"`
+
+	LeaveType LeaveType `gorm:"foreignKey:LeaveTypeID;references:ID" json:"leave_type"`
+
+	Timestamp
+}
+
+func (LeaveBalance) TableName() string {
+	return "leave_balances"
+}
+
+// Remaining is the days of the balance not used yet.
+func (b LeaveBalance) Remaining() float64 {
+	return b.Entitled + b.Adjustment - b.Used
+}
@@ -15,5 +15,19 @@ type Location struct {
 	RadiusMeters int            `gorm:"type:int" json:"radius_meters"`
 	Polygon      datatypes.JSON `gorm:"type:jsonb" json:"polygon"`
 	IsActive     bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time      `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
+	Timezone     string         `gorm:"type:varchar(64);default:'Asia/Jakarta'" json:"timezone"`
+
+	// Check-in and check-out at this location must carry a selfie
+	RequireSelfie bool `gorm:"default:false" json:"require_selfie"`
+
+	// Kiosk mode: a tablet on site shows a rotating code derived from the
+	// secret, and check-ins here must carry a currently valid one
+	KioskEnabled bool   `gorm:"default:false" json:"kiosk_enabled"`
+	KioskSecret  string `gorm:"type:varchar(64)" json:"-"`
+
+	// The virtual location approved remote work days are checked in at; it
+	// has no area and punches there are not checked against one
+	IsRemote bool `gorm:"default:false" json:"is_remote"`
+
+	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
 }
@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type Notification struct {
+	ID     uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	Type   string         `gorm:"type:varchar(50);not null" json:"type"`
+	Title  string         `gorm:"type:varchar;not null" json:"title"`
+	Body   string         `gorm:"type:text" json:"body"`
+	Data   datatypes.JSON `gorm:"type:jsonb" json:"data"`
+	ReadAt *time.Time     `gorm:"type:timestamptz" json:"read_at"`
+
+	Timestamp
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
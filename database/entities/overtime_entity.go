@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OvertimeRequest struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID   uuid.UUID  `gorm:"type:uuid;not null" json:"employee_id"`
+	WorkDate     time.Time  `gorm:"type:date;not null" json:"work_date"`
+	PlannedStart time.Time  `gorm:"type:timestamptz;not null" json:"planned_start"`
+	PlannedEnd   time.Time  `gorm:"type:timestamptz;not null" json:"planned_end"`
+	DayType      string     `gorm:"type:varchar(20);not null" json:"day_type"`
+	Reason       string     `gorm:"type:text" json:"reason"`
+	Status       string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ApproverID   *uuid.UUID `gorm:"type:uuid" json:"approver_id"`
+	ReviewedAt   *time.Time `gorm:"type:timestamptz" json:"reviewed_at"`
+	ReviewNote   string     `gorm:"type:text" json:"review_note"`
+
+	// Filled once the approved window is matched against the attendance record
+	AttendanceID  *uuid.UUID `gorm:"type:uuid" json:"attendance_id"`
+	ActualMinutes int        `gorm:"type:int;default:0" json:"actual_minutes"`
+	PayableHours  float64    `gorm:"type:numeric(6,2);default:0" json:"payable_hours"`
+	ReconciledAt  *time.Time `gorm:"type:timestamptz" json:"reconciled_at"`
+
+	Employee Employee  `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+	Approver *Employee `gorm:"foreignKey:ApproverID;references:ID" json:"approver,omitempty"`
+
+	Timestamp
+}
+
+func (OvertimeRequest) TableName() string {
+	return "overtime_requests"
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RemoteWorkRequest asks the supervisor to let an employee work from home or
+// travel for business on the dates from StartDate to EndDate. Once approved,
+// the employee checks in at the remote location on those dates.
+type RemoteWorkRequest struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID uuid.UUID `gorm:"type:uuid;not null" json:"employee_id"`
+	Type       string    `gorm:"type:varchar(20);not null" json:"type"`
+	StartDate  time.Time `gorm:"type:date;not null" json:"start_date"`
+	EndDate    time.Time `gorm:"type:date;not null" json:"end_date"`
+	Reason     string    `gorm:"type:text;not null" json:"reason"`
+
+	Status     string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ApproverID *uuid.UUID `gorm:"type:uuid" json:"approver_id"`
+	ReviewedAt *time.Time `gorm:"type:timestamptz" json:"reviewed_at"`
+	ReviewNote string     `gorm:"type:text" json:"review_note"`
+
+	Employee Employee `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+
+	Timestamp
+}
+
+func (RemoteWorkRequest) TableName() string {
+	return "remote_work_requests"
+}
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shift times are wall-clock "HH:MM" values; an end before the start means the
+// shift runs past midnight into the next day.
+type Shift struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Code               string    `gorm:"type:varchar;unique;not null" json:"code"`
+	Name               string    `gorm:"type:varchar;not null" json:"name"`
+	StartTime          string    `gorm:"type:varchar(5);not null" json:"start_time"`
+	EndTime            string    `gorm:"type:varchar(5);not null" json:"end_time"`
+	GracePeriodMinutes int       `gorm:"type:int;default:0" json:"grace_period_minutes"`
+	BreakMinutes       int       `gorm:"type:int;default:0" json:"break_minutes"`
+	IsActive           bool      `gorm:"default:true" json:"is_active"`
+
+	Timestamp
+}
+
+// ShiftRotation is a repeating weekly pattern. CycleWeeks > 1 alternates
+// between week patterns, e.g. a morning week followed by a night week.
+type ShiftRotation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string    `gorm:"type:varchar;not null" json:"name"`
+	Description string    `gorm:"type:varchar" json:"description"`
+	CycleWeeks  int       `gorm:"type:int;not null;default:1" json:"cycle_weeks"`
+
+	Days []ShiftRotationDay `gorm:"foreignKey:RotationID;references:ID" json:"days"`
+
+	Timestamp
+}
+
+// ShiftRotationDay has a nil ShiftID for a day off.
+type ShiftRotationDay struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RotationID uuid.UUID  `gorm:"type:uuid;not null" json:"rotation_id"`
+	WeekIndex  int        `gorm:"type:int;not null;default:0" json:"week_index"`
+	DayOfWeek  int        `gorm:"type:int;not null" json:"day_of_week"`
+	ShiftID    *uuid.UUID `gorm:"type:uuid" json:"shift_id"`
+
+	Shift *Shift `gorm:"foreignKey:ShiftID;references:ID" json:"shift,omitempty"`
+}
+
+// EmployeeShift assigns either a fixed shift (worked on regular work days) or a
+// rotation to an employee for a date range. A nil EffectiveTo is open-ended.
+type EmployeeShift struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID    uuid.UUID  `gorm:"type:uuid;not null" json:"employee_id"`
+	ShiftID       *uuid.UUID `gorm:"type:uuid" json:"shift_id"`
+	RotationID    *uuid.UUID `gorm:"type:uuid" json:"rotation_id"`
+	EffectiveFrom time.Time  `gorm:"type:date;not null" json:"effective_from"`
+	EffectiveTo   *time.Time `gorm:"type:date" json:"effective_to"`
+
+	Employee Employee       `gorm:"foreignKey:EmployeeID;references:ID" json:"-"`
+	Shift    *Shift         `gorm:"foreignKey:ShiftID;references:ID" json:"shift,omitempty"`
+	Rotation *ShiftRotation `gorm:"foreignKey:RotationID;references:ID" json:"rotation,omitempty"`
+
+	Timestamp
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Visit is a field worker's call on a customer during a duty day, the
+// attendance record they checked in on.
+type Visit struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID   uuid.UUID `gorm:"type:uuid;not null" json:"employee_id"`
+	AttendanceID uuid.UUID `gorm:"type:uuid;not null" json:"attendance_id"`
+	CustomerName string    `gorm:"type:varchar(150);not null" json:"customer_name"`
+
+	StartedAt      time.Time `gorm:"type:timestamptz;not null" json:"started_at"`
+	StartLatitude  float64   `gorm:"type:decimal;not null" json:"start_latitude"`
+	StartLongitude float64   `gorm:"type:decimal;not null" json:"start_longitude"`
+	StartAccuracy  *float64  `gorm:"type:decimal" json:"start_accuracy"`
+
+	EndedAt      *time.Time `gorm:"type:timestamptz" json:"ended_at"`
+	EndLatitude  *float64   `gorm:"type:decimal" json:"end_latitude"`
+	EndLongitude *float64   `gorm:"type:decimal" json:"end_longitude"`
+	EndAccuracy  *float64   `gorm:"type:decimal" json:"end_accuracy"`
+	Notes        string     `gorm:"type:text" json:"notes"`
+
+	// Photo taken when ending the visit, stored under the assets directory
+	Photo string `gorm:"type:varchar" json:"photo"`
+
+	Employee Employee `gorm:"foreignKey:EmployeeID;references:ID" json:"employee"`
+
+	Timestamp
+}
+
+func (Visit) TableName() string {
+	return "visits"
+}
+
+// LocationPing is a position a field worker's device reported while on duty,
+// tied to the visit in progress at the time, if any.
+type LocationPing struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EmployeeID   uuid.UUID  `gorm:"type:uuid;not null" json:"employee_id"`
+	AttendanceID uuid.UUID  `gorm:"type:uuid;not null" json:"attendance_id"`
+	VisitID      *uuid.UUID `gorm:"type:uuid" json:"visit_id"`
+	Latitude     float64    `gorm:"type:decimal;not null" json:"latitude"`
+	Longitude    float64    `gorm:"type:decimal;not null" json:"longitude"`
+	Accuracy     *float64   `gorm:"type:decimal" json:"accuracy"`
+
+	// Device time of the fix; pings are uploaded in batches, often late
+	RecordedAt time.Time `gorm:"type:timestamptz;not null" json:"recorded_at"`
+
+	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:now()" json:"created_at"`
+}
+
+func (LocationPing) TableName() string {
+	return "location_pings"
+}
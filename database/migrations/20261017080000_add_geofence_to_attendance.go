@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017080000_add_geofence_to_attendance",
+		Up20261017080000AddGeofenceToAttendance,
+		Down20261017080000AddGeofenceToAttendance,
+	)
+}
+
+func Up20261017080000AddGeofenceToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance
+		ADD COLUMN IF NOT EXISTS check_in_latitude decimal,
+		ADD COLUMN IF NOT EXISTS check_in_longitude decimal,
+		ADD COLUMN IF NOT EXISTS check_in_accuracy decimal,
+		ADD COLUMN IF NOT EXISTS check_in_distance decimal,
+		ADD COLUMN IF NOT EXISTS check_out_latitude decimal,
+		ADD COLUMN IF NOT EXISTS check_out_longitude decimal,
+		ADD COLUMN IF NOT EXISTS check_out_accuracy decimal,
+		ADD COLUMN IF NOT EXISTS check_out_distance decimal;`).Error
+}
+
+func Down20261017080000AddGeofenceToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance
+		DROP COLUMN IF EXISTS check_in_latitude,
+		DROP COLUMN IF EXISTS check_in_longitude,
+		DROP COLUMN IF EXISTS check_in_accuracy,
+		DROP COLUMN IF EXISTS check_in_distance,
+		DROP COLUMN IF EXISTS check_out_latitude,
+		DROP COLUMN IF EXISTS check_out_longitude,
+		DROP COLUMN IF EXISTS check_out_accuracy,
+		DROP COLUMN IF EXISTS check_out_distance;`).Error
+}
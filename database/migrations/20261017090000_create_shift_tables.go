@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017090000_create_shift_tables",
+		Up20261017090000CreateShiftTables,
+		Down20261017090000CreateShiftTables,
+	)
+}
+
+func Up20261017090000CreateShiftTables(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+		CREATE TABLE shifts (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			code varchar UNIQUE NOT NULL,
+			name varchar NOT NULL,
+			start_time varchar(5) NOT NULL,
+			end_time varchar(5) NOT NULL,
+			grace_period_minutes int DEFAULT 0,
+			break_minutes int DEFAULT 0,
+			is_active boolean DEFAULT true,
+			created_at timestamptz DEFAULT now(),
+			updated_at timestamptz DEFAULT now()
+		);`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+		CREATE TABLE shift_rotations (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name varchar NOT NULL,
+			description varchar,
+			cycle_weeks int NOT NULL DEFAULT 1,
+			created_at timestamptz DEFAULT now(),
+			updated_at timestamptz DEFAULT now()
+		);`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+		CREATE TABLE shift_rotation_days (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			rotation_id uuid NOT NULL REFERENCES shift_rotations(id) ON DELETE CASCADE,
+			week_index int NOT NULL DEFAULT 0,
+			day_of_week int NOT NULL,
+			shift_id uuid REFERENCES shifts(id),
+			UNIQUE(rotation_id, week_index, day_of_week)
+		);`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+		CREATE TABLE employee_shifts (
+			id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+			employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+			shift_id uuid REFERENCES shifts(id),
+			rotation_id uuid REFERENCES shift_rotations(id),
+			effective_from date NOT NULL,
+			effective_to date,
+			created_at timestamptz DEFAULT now(),
+			updated_at timestamptz DEFAULT now(),
+			CHECK ((shift_id IS NULL) <> (rotation_id IS NULL))
+		);`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`CREATE INDEX idx_employee_shifts_employee ON employee_shifts (employee_id, effective_from);`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+		ALTER TABLE attendance
+			ADD COLUMN IF NOT EXISTS shift_id uuid REFERENCES shifts(id),
+			ADD COLUMN IF NOT EXISTS scheduled_start timestamptz,
+			ADD COLUMN IF NOT EXISTS scheduled_end timestamptz,
+			ADD COLUMN IF NOT EXISTS late_minutes int DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS early_leave_minutes int DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS worked_minutes int DEFAULT 0;`).Error
+	})
+}
+
+func Down20261017090000CreateShiftTables(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+		ALTER TABLE attendance
+			DROP COLUMN IF EXISTS shift_id,
+			DROP COLUMN IF EXISTS scheduled_start,
+			DROP COLUMN IF EXISTS scheduled_end,
+			DROP COLUMN IF EXISTS late_minutes,
+			DROP COLUMN IF EXISTS early_leave_minutes,
+			DROP COLUMN IF EXISTS worked_minutes;`).Error; err != nil {
+			return err
+		}
+
+		tables := []string{
+			"employee_shifts",
+			"shift_rotation_days",
+			"shift_rotations",
+			"shifts",
+		}
+
+		for _, table := range tables {
+			if err := tx.Exec(`DROP TABLE IF EXISTS ` + table + ` CASCADE;`).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017100000_add_work_date_to_attendance",
+		Up20261017100000AddWorkDateToAttendance,
+		Down20261017100000AddWorkDateToAttendance,
+	)
+}
+
+func Up20261017100000AddWorkDateToAttendance(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+		ALTER TABLE locations
+			ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT 'Asia/Jakarta';`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+		ALTER TABLE attendance
+			ADD COLUMN IF NOT EXISTS work_date date;`).Error; err != nil {
+			return err
+		}
+
+		// Existing rows take the calendar day of the check-in at their location
+		if err := tx.Exec(`
+		UPDATE attendance a
+		SET work_date = (a.check_in_time AT TIME ZONE COALESCE(l.timezone, 'Asia/Jakarta'))::date
+		FROM locations l
+		WHERE l.id = a.location_id AND a.work_date IS NULL;`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_attendance_employee_work_date ON attendance (employee_id, work_date);`).Error
+	})
+}
+
+func Down20261017100000AddWorkDateToAttendance(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+		DROP INDEX IF EXISTS idx_attendance_employee_work_date;
+		ALTER TABLE attendance DROP COLUMN IF EXISTS work_date;`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`ALTER TABLE locations DROP COLUMN IF EXISTS timezone;`).Error
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017110000_create_overtime_requests_table",
+		Up20261017110000CreateOvertimeRequestsTable,
+		Down20261017110000CreateOvertimeRequestsTable,
+	)
+}
+
+func Up20261017110000CreateOvertimeRequestsTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS overtime_requests (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		work_date date NOT NULL,
+		planned_start timestamptz NOT NULL,
+		planned_end timestamptz NOT NULL,
+		day_type varchar(20) NOT NULL,
+		reason text,
+		status varchar(20) NOT NULL DEFAULT 'pending',
+		approver_id uuid REFERENCES employees(id),
+		reviewed_at timestamptz,
+		review_note text,
+		attendance_id uuid REFERENCES attendance(id) ON DELETE SET NULL,
+		actual_minutes int NOT NULL DEFAULT 0,
+		payable_hours numeric(6,2) NOT NULL DEFAULT 0,
+		reconciled_at timestamptz,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (planned_end > planned_start)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_overtime_requests_employee_date ON overtime_requests (employee_id, work_date);
+	CREATE INDEX IF NOT EXISTS idx_overtime_requests_status ON overtime_requests (status);`).Error
+}
+
+func Down20261017110000CreateOvertimeRequestsTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS overtime_requests;`).Error
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017120000_create_attendance_corrections_table",
+		Up20261017120000CreateAttendanceCorrectionsTable,
+		Down20261017120000CreateAttendanceCorrectionsTable,
+	)
+}
+
+func Up20261017120000CreateAttendanceCorrectionsTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS attendance_corrections (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		attendance_id uuid REFERENCES attendance(id) ON DELETE SET NULL,
+		location_id uuid NOT NULL REFERENCES locations(id),
+		work_date date NOT NULL,
+		proposed_check_in timestamptz,
+		proposed_check_out timestamptz,
+		reason text NOT NULL,
+		status varchar(20) NOT NULL DEFAULT 'pending',
+		approver_id uuid REFERENCES employees(id),
+		reviewed_at timestamptz,
+		review_note text,
+		original_check_in timestamptz,
+		original_check_out timestamptz,
+		original_status varchar,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (proposed_check_in IS NOT NULL OR proposed_check_out IS NOT NULL)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attendance_corrections_employee_date ON attendance_corrections (employee_id, work_date);
+	CREATE INDEX IF NOT EXISTS idx_attendance_corrections_status ON attendance_corrections (status);`).Error
+}
+
+func Down20261017120000CreateAttendanceCorrectionsTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS attendance_corrections;`).Error
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017130000_create_leaves_table",
+		Up20261017130000CreateLeavesTable,
+		Down20261017130000CreateLeavesTable,
+	)
+}
+
+func Up20261017130000CreateLeavesTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS leaves (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		start_date date NOT NULL,
+		end_date date NOT NULL,
+		reason text,
+		status varchar NOT NULL DEFAULT 'pending',
+		created_at timestamptz DEFAULT now(),
+		CHECK (end_date >= start_date)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_leaves_employee_dates ON leaves (employee_id, start_date, end_date);
+	CREATE INDEX IF NOT EXISTS idx_leaves_status ON leaves (status);`).Error
+}
+
+func Down20261017130000CreateLeavesTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS leaves;`).Error
+}
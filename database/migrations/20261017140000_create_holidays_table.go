@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017140000_create_holidays_table",
+		Up20261017140000CreateHolidaysTable,
+		Down20261017140000CreateHolidaysTable,
+	)
+}
+
+func Up20261017140000CreateHolidaysTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS holidays (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		date date NOT NULL UNIQUE,
+		name varchar NOT NULL,
+		description text,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);`).Error
+}
+
+func Down20261017140000CreateHolidaysTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS holidays;`).Error
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017150000_create_notifications_table",
+		Up20261017150000CreateNotificationsTable,
+		Down20261017150000CreateNotificationsTable,
+	)
+}
+
+func Up20261017150000CreateNotificationsTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS notifications (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		type varchar(50) NOT NULL,
+		title varchar NOT NULL,
+		body text,
+		data jsonb,
+		read_at timestamptz,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications (user_id, read_at);`).Error
+}
+
+func Down20261017150000CreateNotificationsTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS notifications;`).Error
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017160000_add_auto_checkout_to_attendance",
+		Up20261017160000AddAutoCheckoutToAttendance,
+		Down20261017160000AddAutoCheckoutToAttendance,
+	)
+}
+
+func Up20261017160000AddAutoCheckoutToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance
+		ADD COLUMN IF NOT EXISTS auto_checkout boolean NOT NULL DEFAULT false;
+
+	CREATE INDEX IF NOT EXISTS idx_attendance_open ON attendance (work_date)
+		WHERE check_in_time IS NOT NULL AND check_out_time IS NULL;`).Error
+}
+
+func Down20261017160000AddAutoCheckoutToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	DROP INDEX IF EXISTS idx_attendance_open;
+
+	ALTER TABLE attendance
+		DROP COLUMN IF EXISTS auto_checkout;`).Error
+}
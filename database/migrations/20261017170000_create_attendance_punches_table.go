@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017170000_create_attendance_punches_table",
+		Up20261017170000CreateAttendancePunchesTable,
+		Down20261017170000CreateAttendancePunchesTable,
+	)
+}
+
+func Up20261017170000CreateAttendancePunchesTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS attendance_punches (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		attendance_id uuid NOT NULL REFERENCES attendance(id) ON DELETE CASCADE,
+		type varchar(20) NOT NULL,
+		punch_time timestamptz NOT NULL,
+		location_id uuid REFERENCES locations(id),
+		latitude decimal,
+		longitude decimal,
+		accuracy decimal,
+		distance decimal,
+		created_at timestamptz DEFAULT now(),
+		CHECK (type IN ('in', 'out', 'break_start', 'break_end'))
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attendance_punches_attendance ON attendance_punches (attendance_id, punch_time);
+
+	ALTER TABLE attendance
+		ADD COLUMN IF NOT EXISTS break_minutes int NOT NULL DEFAULT 0;
+
+	-- Existing records become a single in/out segment
+	INSERT INTO attendance_punches (attendance_id, type, punch_time, location_id, latitude, longitude, accuracy, distance)
+	SELECT a.id, 'in', a.check_in_time, a.location_id, a.check_in_latitude, a.check_in_longitude, a.check_in_accuracy, a.check_in_distance
+	FROM attendance a
+	WHERE a.check_in_time IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM attendance_punches p WHERE p.attendance_id = a.id);
+
+	INSERT INTO attendance_punches (attendance_id, type, punch_time, location_id, latitude, longitude, accuracy, distance)
+	SELECT a.id, 'out', a.check_out_time, a.location_id, a.check_out_latitude, a.check_out_longitude, a.check_out_accuracy, a.check_out_distance
+	FROM attendance a
+	WHERE a.check_out_time IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM attendance_punches p WHERE p.attendance_id = a.id AND p.type = 'out');`).Error
+}
+
+func Down20261017170000CreateAttendancePunchesTable(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance
+		DROP COLUMN IF EXISTS break_minutes;
+
+	DROP TABLE IF EXISTS attendance_punches;`).Error
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017180000_add_selfie_to_attendance",
+		Up20261017180000AddSelfieToAttendance,
+		Down20261017180000AddSelfieToAttendance,
+	)
+}
+
+func Up20261017180000AddSelfieToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE locations
+		ADD COLUMN IF NOT EXISTS require_selfie boolean NOT NULL DEFAULT false;
+
+	ALTER TABLE attendance
+		ADD COLUMN IF NOT EXISTS check_in_photo varchar,
+		ADD COLUMN IF NOT EXISTS check_out_photo varchar;
+
+	ALTER TABLE attendance_punches
+		ADD COLUMN IF NOT EXISTS photo varchar;`).Error
+}
+
+func Down20261017180000AddSelfieToAttendance(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance_punches
+		DROP COLUMN IF EXISTS photo;
+
+	ALTER TABLE attendance
+		DROP COLUMN IF EXISTS check_in_photo,
+		DROP COLUMN IF EXISTS check_out_photo;
+
+	ALTER TABLE locations
+		DROP COLUMN IF EXISTS require_selfie;`).Error
+}
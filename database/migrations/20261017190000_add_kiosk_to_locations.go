@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017190000_add_kiosk_to_locations",
+		Up20261017190000AddKioskToLocations,
+		Down20261017190000AddKioskToLocations,
+	)
+}
+
+func Up20261017190000AddKioskToLocations(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE locations
+		ADD COLUMN IF NOT EXISTS kiosk_enabled boolean NOT NULL DEFAULT false,
+		ADD COLUMN IF NOT EXISTS kiosk_secret varchar(64);`).Error
+}
+
+func Down20261017190000AddKioskToLocations(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE locations
+		DROP COLUMN IF EXISTS kiosk_enabled,
+		DROP COLUMN IF EXISTS kiosk_secret;`).Error
+}
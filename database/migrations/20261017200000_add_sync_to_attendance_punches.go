@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017200000_add_sync_to_attendance_punches",
+		Up20261017200000AddSyncToAttendancePunches,
+		Down20261017200000AddSyncToAttendancePunches,
+	)
+}
+
+func Up20261017200000AddSyncToAttendancePunches(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance_punches
+		ADD COLUMN IF NOT EXISTS client_id uuid,
+		ADD COLUMN IF NOT EXISTS device_id varchar(100),
+		ADD COLUMN IF NOT EXISTS synced_at timestamptz;
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_punches_client_id
+		ON attendance_punches (client_id)
+		WHERE client_id IS NOT NULL;`).Error
+}
+
+func Down20261017200000AddSyncToAttendancePunches(db *gorm.DB) error {
+	return db.Exec(`
+	DROP INDEX IF EXISTS idx_attendance_punches_client_id;
+
+	ALTER TABLE attendance_punches
+		DROP COLUMN IF EXISTS client_id,
+		DROP COLUMN IF EXISTS device_id,
+		DROP COLUMN IF EXISTS synced_at;`).Error
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017210000_add_terminal_status_to_attendance_punches",
+		Up20261017210000AddTerminalStatusToAttendancePunches,
+		Down20261017210000AddTerminalStatusToAttendancePunches,
+	)
+}
+
+func Up20261017210000AddTerminalStatusToAttendancePunches(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance_punches
+		ADD COLUMN IF NOT EXISTS terminal_status smallint;`).Error
+}
+
+func Down20261017210000AddTerminalStatusToAttendancePunches(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance_punches
+		DROP COLUMN IF EXISTS terminal_status;`).Error
+}
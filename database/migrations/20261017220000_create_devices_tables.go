@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017220000_create_devices_tables",
+		Up20261017220000CreateDevicesTables,
+		Down20261017220000CreateDevicesTables,
+	)
+}
+
+func Up20261017220000CreateDevicesTables(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS devices (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		serial_number varchar(50) NOT NULL UNIQUE,
+		name varchar,
+		location_id uuid REFERENCES locations(id),
+		is_active boolean NOT NULL DEFAULT false,
+		push_version varchar(20),
+		attlog_stamp varchar(50),
+		last_seen_at timestamptz,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	CREATE TABLE IF NOT EXISTS device_commands (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		device_id uuid NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+		command_no bigserial NOT NULL UNIQUE,
+		command text NOT NULL,
+		status varchar(20) NOT NULL,
+		return_code int,
+		sent_at timestamptz,
+		completed_at timestamptz,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (status IN ('pending', 'sent', 'done', 'failed'))
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_device_commands_pending ON device_commands (device_id, command_no)
+		WHERE status = 'pending';`).Error
+}
+
+func Down20261017220000CreateDevicesTables(db *gorm.DB) error {
+	return db.Exec(`
+	DROP TABLE IF EXISTS device_commands;
+
+	DROP TABLE IF EXISTS devices;`).Error
+}
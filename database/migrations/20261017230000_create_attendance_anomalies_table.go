@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017230000_create_attendance_anomalies_table",
+		Up20261017230000CreateAttendanceAnomaliesTable,
+		Down20261017230000CreateAttendanceAnomaliesTable,
+	)
+}
+
+func Up20261017230000CreateAttendanceAnomaliesTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS attendance_anomalies (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		attendance_id uuid NOT NULL REFERENCES attendance(id) ON DELETE CASCADE,
+		punch_id uuid NOT NULL REFERENCES attendance_punches(id) ON DELETE CASCADE,
+		work_date date NOT NULL,
+		rule varchar(30) NOT NULL,
+		detail text,
+		related_employee_id uuid REFERENCES employees(id) ON DELETE SET NULL,
+		status varchar(20) NOT NULL DEFAULT 'open',
+		reviewer_id uuid REFERENCES employees(id),
+		reviewed_at timestamptz,
+		review_note text,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	-- A punch is flagged once per rule, so detection can run over the same days again
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_attendance_anomalies_rule_punch ON attendance_anomalies (rule, punch_id);
+	CREATE INDEX IF NOT EXISTS idx_attendance_anomalies_employee_date ON attendance_anomalies (employee_id, work_date);
+	CREATE INDEX IF NOT EXISTS idx_attendance_anomalies_status ON attendance_anomalies (status);`).Error
+}
+
+func Down20261017230000CreateAttendanceAnomaliesTable(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS attendance_anomalies;`).Error
+}
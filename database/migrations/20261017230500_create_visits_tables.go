@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017230500_create_visits_tables",
+		Up20261017230500CreateVisitsTables,
+		Down20261017230500CreateVisitsTables,
+	)
+}
+
+func Up20261017230500CreateVisitsTables(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS visits (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		attendance_id uuid NOT NULL REFERENCES attendance(id) ON DELETE CASCADE,
+		customer_name varchar(150) NOT NULL,
+		started_at timestamptz NOT NULL,
+		start_latitude decimal NOT NULL,
+		start_longitude decimal NOT NULL,
+		start_accuracy decimal,
+		ended_at timestamptz,
+		end_latitude decimal,
+		end_longitude decimal,
+		end_accuracy decimal,
+		notes text,
+		photo varchar,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (ended_at IS NULL OR ended_at >= started_at)
+	);
+
+	-- One visit in progress per employee
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_visits_employee_open ON visits (employee_id) WHERE ended_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_visits_attendance ON visits (attendance_id);
+
+	CREATE TABLE IF NOT EXISTS location_pings (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		attendance_id uuid NOT NULL REFERENCES attendance(id) ON DELETE CASCADE,
+		visit_id uuid REFERENCES visits(id) ON DELETE SET NULL,
+		latitude decimal NOT NULL,
+		longitude decimal NOT NULL,
+		accuracy decimal,
+		recorded_at timestamptz NOT NULL,
+		created_at timestamptz DEFAULT now()
+	);
+
+	-- A retried upload does not store the same fix twice
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_location_pings_employee_recorded ON location_pings (employee_id, recorded_at);
+	CREATE INDEX IF NOT EXISTS idx_location_pings_attendance ON location_pings (attendance_id, recorded_at);`).Error
+}
+
+func Down20261017230500CreateVisitsTables(db *gorm.DB) error {
+	return db.Exec(`
+	DROP TABLE IF EXISTS location_pings;
+	DROP TABLE IF EXISTS visits;`).Error
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017231000_create_remote_work_requests_table",
+		Up20261017231000CreateRemoteWorkRequestsTable,
+		Down20261017231000CreateRemoteWorkRequestsTable,
+	)
+}
+
+func Up20261017231000CreateRemoteWorkRequestsTable(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS remote_work_requests (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		type varchar(20) NOT NULL,
+		start_date date NOT NULL,
+		end_date date NOT NULL,
+		reason text NOT NULL,
+		status varchar(20) NOT NULL DEFAULT 'pending',
+		approver_id uuid REFERENCES employees(id),
+		reviewed_at timestamptz,
+		review_note text,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (end_date >= start_date)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_remote_work_requests_employee_dates ON remote_work_requests (employee_id, start_date, end_date);
+	CREATE INDEX IF NOT EXISTS idx_remote_work_requests_status ON remote_work_requests (status);
+
+	-- The virtual location remote work days are checked in at
+	ALTER TABLE locations ADD COLUMN IF NOT EXISTS is_remote boolean NOT NULL DEFAULT false;
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_locations_remote ON locations (is_remote) WHERE is_remote;
+	INSERT INTO locations (name, is_remote)
+	SELECT 'Remote', true
+	WHERE NOT EXISTS (SELECT 1 FROM locations WHERE is_remote);
+
+	ALTER TABLE attendance
+		ADD COLUMN IF NOT EXISTS work_mode varchar(20) NOT NULL DEFAULT 'onsite',
+		ADD COLUMN IF NOT EXISTS remote_work_request_id uuid REFERENCES remote_work_requests(id) ON DELETE SET NULL;`).Error
+}
+
+func Down20261017231000CreateRemoteWorkRequestsTable(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE attendance
+		DROP COLUMN IF EXISTS remote_work_request_id,
+		DROP COLUMN IF EXISTS work_mode;
+	DELETE FROM locations l WHERE l.is_remote
+		AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.location_id = l.id)
+		AND NOT EXISTS (SELECT 1 FROM attendance_punches p WHERE p.location_id = l.id);
+	ALTER TABLE locations DROP COLUMN IF EXISTS is_remote;
+	DROP TABLE IF EXISTS remote_work_requests;`).Error
+}
@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017231500_add_unique_attendance_work_date",
+		Up20261017231500AddUniqueAttendanceWorkDate,
+		Down20261017231500AddUniqueAttendanceWorkDate,
+	)
+}
+
+func Up20261017231500AddUniqueAttendanceWorkDate(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Records checked in without a location take the day in the company zone
+		if err := tx.Exec(`
+		UPDATE attendance
+		SET work_date = (check_in_time AT TIME ZONE ?)::date
+		WHERE work_date IS NULL AND check_in_time IS NOT NULL;`, helpers.LoadTimezone("").String()).Error; err != nil {
+			return err
+		}
+
+		// Duplicates left by concurrent check-ins fold into the earliest
+		// check-in of the day, which takes over their punches and references
+		if err := tx.Exec(`
+		CREATE TEMPORARY TABLE attendance_duplicates ON COMMIT DROP AS
+		SELECT id, keep_id FROM (
+			SELECT id, first_value(id) OVER (
+				PARTITION BY employee_id, work_date
+				ORDER BY check_in_time NULLS LAST, created_at, id
+			) AS keep_id
+			FROM attendance
+			WHERE work_date IS NOT NULL
+		) ranked
+		WHERE id <> keep_id;`).Error; err != nil {
+			return err
+		}
+
+		for _, table := range []string{
+			"attendance_punches",
+			"attendance_anomalies",
+			"attendance_corrections",
+			"overtime_requests",
+			"visits",
+			"location_pings",
+		} {
+			if err := tx.Exec(`
+			UPDATE ` + table + ` t
+			SET attendance_id = d.keep_id
+			FROM attendance_duplicates d
+			WHERE t.attendance_id = d.id;`).Error; err != nil {
+				return err
+			}
+		}
+
+		// The duplicate checked out last carries the check-out of the day. Its
+		// worked minutes start at its own check-in, so the moments between the
+		// two check-ins are added back.
+		if err := tx.Exec(`
+		UPDATE attendance s
+		SET check_out_time = c.check_out_time,
+			check_out_latitude = c.check_out_latitude,
+			check_out_longitude = c.check_out_longitude,
+			check_out_accuracy = c.check_out_accuracy,
+			check_out_distance = c.check_out_distance,
+			check_out_photo = c.check_out_photo,
+			auto_checkout = c.auto_checkout,
+			break_minutes = c.break_minutes,
+			early_leave_minutes = c.early_leave_minutes,
+			worked_minutes = c.worked_minutes + GREATEST(COALESCE(FLOOR(EXTRACT(EPOCH FROM c.check_in_time - s.check_in_time) / 60), 0), 0)::int,
+			status = CASE WHEN s.status = 'on_time' AND c.early_leave_minutes > 0 THEN 'early_leave' ELSE s.status END
+		FROM (
+			SELECT DISTINCT ON (d.keep_id) d.keep_id, a.*
+			FROM attendance_duplicates d
+			JOIN attendance a ON a.id = d.id
+			WHERE a.check_out_time IS NOT NULL
+			ORDER BY d.keep_id, a.check_out_time DESC
+		) c
+		WHERE s.id = c.keep_id
+			AND (s.check_out_time IS NULL OR c.check_out_time > s.check_out_time);`).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+		DELETE FROM attendance a
+		USING attendance_duplicates d
+		WHERE a.id = d.id;`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+		DROP INDEX IF EXISTS idx_attendance_employee_work_date;
+		CREATE UNIQUE INDEX IF NOT EXISTS uq_attendance_employee_work_date ON attendance (employee_id, work_date);`).Error
+	})
+}
+
+func Down20261017231500AddUniqueAttendanceWorkDate(db *gorm.DB) error {
+	return db.Exec(`
+	DROP INDEX IF EXISTS uq_attendance_employee_work_date;
+	CREATE INDEX IF NOT EXISTS idx_attendance_employee_work_date ON attendance (employee_id, work_date);`).Error
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017232000_create_leave_types_and_balances",
+		Up20261017232000CreateLeaveTypesAndBalances,
+		Down20261017232000CreateLeaveTypesAndBalances,
+	)
+}
+
+func Up20261017232000CreateLeaveTypesAndBalances(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS leave_types (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		code varchar(50) NOT NULL UNIQUE,
+		name varchar(100) NOT NULL,
+		description text,
+		paid boolean NOT NULL DEFAULT true,
+		calendar_days boolean NOT NULL DEFAULT false,
+		accrual_policy varchar(20) NOT NULL DEFAULT 'none',
+		days_per_year decimal(5,1) NOT NULL DEFAULT 0,
+		min_service_months int NOT NULL DEFAULT 0,
+		pro_rate boolean NOT NULL DEFAULT false,
+		max_days_per_request decimal(5,1),
+		is_active boolean NOT NULL DEFAULT true,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	-- Statutory leave of UU 13/2003 as amended by UU 6/2023, paid unless noted
+	INSERT INTO leave_types (code, name, description, paid, calendar_days, accrual_policy, days_per_year, min_service_months, pro_rate, max_days_per_request) VALUES
+		('annual', 'Annual Leave', '12 working days a year after 12 months of continuous service (art. 79)', true, false, 'yearly', 12, 12, true, NULL),
+		('sick', 'Sick Leave', 'Absence due to illness with a doctor''s note (art. 93)', true, false, 'none', 0, 0, false, NULL),
+		('maternity', 'Maternity Leave', '1.5 months before and 1.5 months after giving birth (art. 82)', true, true, 'none', 0, 0, false, 90),
+		('miscarriage', 'Miscarriage Leave', '1.5 months of rest after a miscarriage (art. 82)', true, true, 'none', 0, 0, false, 45),
+		('menstrual', 'Menstrual Leave', 'The first and second day of menstruation (art. 81)', true, false, 'none', 0, 0, false, 2),
+		('marriage', 'Marriage Leave', 'The employee''s own marriage (art. 93)', true, false, 'none', 0, 0, false, 3),
+		('child_marriage', 'Child''s Marriage Leave', 'Marriage of the employee''s child (art. 93)', true, false, 'none', 0, 0, false, 2),
+		('child_circumcision', 'Child''s Circumcision or Baptism Leave', 'Circumcision or baptism of the employee''s child (art. 93)', true, false, 'none', 0, 0, false, 2),
+		('paternity', 'Paternity Leave', 'The employee''s wife gives birth or miscarries (art. 93)', true, false, 'none', 0, 0, false, 2),
+		('bereavement', 'Bereavement Leave', 'Death of a spouse, parent, parent-in-law, child or child-in-law (art. 93)', true, false, 'none', 0, 0, false, 2),
+		('household_bereavement', 'Household Bereavement Leave', 'Death of a member of the household (art. 93)', true, false, 'none', 0, 0, false, 1),
+		('pilgrimage', 'Religious Pilgrimage Leave', 'Performing an obligation of the employee''s religion (art. 93)', true, true, 'none', 0, 0, false, NULL),
+		('unpaid', 'Unpaid Leave', 'Leave without pay agreed with the company', false, false, 'none', 0, 0, false, NULL)
+	ON CONFLICT (code) DO NOTHING;
+
+	CREATE TABLE IF NOT EXISTS leave_balances (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		employee_id uuid NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+		leave_type_id uuid NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+		year int NOT NULL,
+		entitled decimal(6,2) NOT NULL DEFAULT 0,
+		adjustment decimal(6,2) NOT NULL DEFAULT 0,
+		used decimal(6,2) NOT NULL DEFAULT 0,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_leave_balances_employee_type_year ON leave_balances (employee_id, leave_type_id, year);
+
+	ALTER TABLE leaves
+		ADD COLUMN IF NOT EXISTS leave_type_id uuid REFERENCES leave_types(id),
+		ADD COLUMN IF NOT EXISTS days decimal(6,2) NOT NULL DEFAULT 0;
+
+	-- Leaves taken before types existed keep no type and count calendar days
+	UPDATE leaves SET days = end_date - start_date + 1 WHERE days = 0;
+
+	CREATE INDEX IF NOT EXISTS idx_leaves_employee_type ON leaves (employee_id, leave_type_id, start_date);`).Error
+}
+
+func Down20261017232000CreateLeaveTypesAndBalances(db *gorm.DB) error {
+	return db.Exec(`
+	DROP INDEX IF EXISTS idx_leaves_employee_type;
+	ALTER TABLE leaves
+		DROP COLUMN IF EXISTS days,
+		DROP COLUMN IF EXISTS leave_type_id;
+	DROP TABLE IF EXISTS leave_balances;
+	DROP TABLE IF EXISTS leave_types;`).Error
+}
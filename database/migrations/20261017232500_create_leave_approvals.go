@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017232500_create_leave_approvals",
+		Up20261017232500CreateLeaveApprovals,
+		Down20261017232500CreateLeaveApprovals,
+	)
+}
+
+func Up20261017232500CreateLeaveApprovals(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE departments
+		ADD COLUMN IF NOT EXISTS head_id uuid REFERENCES employees(id) ON DELETE SET NULL;
+
+	ALTER TABLE leave_types
+		ADD COLUMN IF NOT EXISTS head_approval_from_days decimal(5,1),
+		ADD COLUMN IF NOT EXISTS hr_approval_from_days decimal(5,1);
+
+	-- Longer annual leave needs the department head; leave HR has to plan for
+	-- or that is not paid always goes to HR
+	UPDATE leave_types SET head_approval_from_days = 5 WHERE code = 'annual';
+	UPDATE leave_types SET hr_approval_from_days = 0 WHERE code IN ('maternity', 'miscarriage', 'pilgrimage', 'unpaid');
+
+	CREATE TABLE IF NOT EXISTS leave_approvals (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		leave_id uuid NOT NULL REFERENCES leaves(id) ON DELETE CASCADE,
+		level int NOT NULL,
+		role varchar(20) NOT NULL,
+		approver_id uuid REFERENCES employees(id) ON DELETE SET NULL,
+		status varchar(20) NOT NULL DEFAULT 'waiting',
+		decided_by_id uuid REFERENCES employees(id) ON DELETE SET NULL,
+		decided_at timestamptz,
+		comment text,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now()
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_leave_approvals_leave_level ON leave_approvals (leave_id, level);
+
+	-- Leave still pending goes to the supervisor, or to HR without one
+	INSERT INTO leave_approvals (leave_id, level, role, approver_id, status)
+	SELECT l.id, 1, CASE WHEN e.supervisor_id IS NULL THEN 'hr' ELSE 'supervisor' END, e.supervisor_id, 'pending'
+	FROM leaves l
+	JOIN employees e ON e.id = l.employee_id
+	WHERE l.status = 'pending'
+	ON CONFLICT (leave_id, level) DO NOTHING;
+	CREATE INDEX IF NOT EXISTS idx_leave_approvals_pending ON leave_approvals (approver_id) WHERE status = 'pending';`).Error
+}
+
+func Down20261017232500CreateLeaveApprovals(db *gorm.DB) error {
+	return db.Exec(`
+	DROP TABLE IF EXISTS leave_approvals;
+	ALTER TABLE leave_types
+		DROP COLUMN IF EXISTS hr_approval_from_days,
+		DROP COLUMN IF EXISTS head_approval_from_days;
+	ALTER TABLE departments DROP COLUMN IF EXISTS head_id;`).Error
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017233000_add_holiday_types_and_locations",
+		Up20261017233000AddHolidayTypesAndLocations,
+		Down20261017233000AddHolidayTypesAndLocations,
+	)
+}
+
+func Up20261017233000AddHolidayTypesAndLocations(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE holidays
+		ADD COLUMN IF NOT EXISTS type varchar(20) NOT NULL DEFAULT 'public',
+		ADD COLUMN IF NOT EXISTS location_id uuid REFERENCES locations(id) ON DELETE CASCADE;
+
+	-- A date is on the calendar once company-wide and once per location
+	ALTER TABLE holidays DROP CONSTRAINT IF EXISTS holidays_date_key;
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_holidays_date_company ON holidays (date) WHERE location_id IS NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_holidays_date_location ON holidays (date, location_id) WHERE location_id IS NOT NULL;
+
+	ALTER TABLE employees
+		ADD COLUMN IF NOT EXISTS location_id uuid REFERENCES locations(id) ON DELETE SET NULL;`).Error
+}
+
+func Down20261017233000AddHolidayTypesAndLocations(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE employees DROP COLUMN IF EXISTS location_id;
+	DROP INDEX IF EXISTS uq_holidays_date_location;
+	DROP INDEX IF EXISTS uq_holidays_date_company;
+	DELETE FROM holidays WHERE location_id IS NOT NULL;
+	ALTER TABLE holidays
+		DROP COLUMN IF EXISTS location_id,
+		DROP COLUMN IF EXISTS type,
+		ADD CONSTRAINT holidays_date_key UNIQUE (date);`).Error
+}
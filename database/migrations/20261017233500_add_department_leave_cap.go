@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017233500_add_department_leave_cap",
+		Up20261017233500AddDepartmentLeaveCap,
+		Down20261017233500AddDepartmentLeaveCap,
+	)
+}
+
+func Up20261017233500AddDepartmentLeaveCap(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE departments
+		ADD COLUMN IF NOT EXISTS max_on_leave int CHECK (max_on_leave > 0);
+
+	-- Overlap checks look up the pending and approved leaves around a range
+	CREATE INDEX IF NOT EXISTS idx_leaves_employee_dates ON leaves (employee_id, start_date, end_date)
+		WHERE status IN ('pending', 'approved');`).Error
+}
+
+func Down20261017233500AddDepartmentLeaveCap(db *gorm.DB) error {
+	return db.Exec(`
+	DROP INDEX IF EXISTS idx_leaves_employee_dates;
+	ALTER TABLE departments DROP COLUMN IF EXISTS max_on_leave;`).Error
+}
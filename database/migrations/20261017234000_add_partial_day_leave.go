@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017234000_add_partial_day_leave",
+		Up20261017234000AddPartialDayLeave,
+		Down20261017234000AddPartialDayLeave,
+	)
+}
+
+func Up20261017234000AddPartialDayLeave(db *gorm.DB) error {
+	return db.Exec(`
+	ALTER TABLE leave_types
+		ADD COLUMN IF NOT EXISTS allow_half_day boolean NOT NULL DEFAULT false,
+		ADD COLUMN IF NOT EXISTS allow_hourly boolean NOT NULL DEFAULT false;
+
+	ALTER TABLE leaves
+		ADD COLUMN IF NOT EXISTS unit varchar(20) NOT NULL DEFAULT 'full_day',
+		ADD COLUMN IF NOT EXISTS session varchar(20),
+		ADD COLUMN IF NOT EXISTS start_time varchar(5),
+		ADD COLUMN IF NOT EXISTS end_time varchar(5);
+
+	UPDATE leave_types SET allow_half_day = true WHERE code IN ('annual', 'unpaid');
+	UPDATE leave_types SET allow_half_day = true, allow_hourly = true WHERE code = 'sick';
+
+	-- Izin: a few hours off within a working day, paid and kept off the balance
+	INSERT INTO leave_types (code, name, description, paid, calendar_days, accrual_policy, days_per_year, min_service_months, pro_rate, max_days_per_request, allow_half_day, allow_hourly) VALUES
+		('permission', 'Permission (Izin)', 'A few hours off for a doctor''s visit or another personal errand', true, false, 'none', 0, 0, false, 1, false, true)
+	ON CONFLICT (code) DO NOTHING;`).Error
+}
+
+func Down20261017234000AddPartialDayLeave(db *gorm.DB) error {
+	return db.Exec(`
+	DELETE FROM leave_types WHERE code = 'permission' AND NOT EXISTS (SELECT 1 FROM leaves WHERE leaves.leave_type_id = leave_types.id);
+	ALTER TABLE leaves
+		DROP COLUMN IF EXISTS end_time,
+		DROP COLUMN IF EXISTS start_time,
+		DROP COLUMN IF EXISTS session,
+		DROP COLUMN IF EXISTS unit;
+	ALTER TABLE leave_types
+		DROP COLUMN IF EXISTS allow_hourly,
+		DROP COLUMN IF EXISTS allow_half_day;`).Error
+}
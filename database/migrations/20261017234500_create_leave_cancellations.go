@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration(
+		"20261017234500_create_leave_cancellations",
+		Up20261017234500CreateLeaveCancellations,
+		Down20261017234500CreateLeaveCancellations,
+	)
+}
+
+func Up20261017234500CreateLeaveCancellations(db *gorm.DB) error {
+	return db.Exec(`
+	CREATE TABLE IF NOT EXISTS leave_cancellations (
+		id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+		leave_id uuid NOT NULL REFERENCES leaves(id) ON DELETE CASCADE,
+		from_date date NOT NULL,
+		to_date date NOT NULL,
+		days decimal(6,2) NOT NULL DEFAULT 0,
+		reason text,
+		approver_id uuid REFERENCES employees(id) ON DELETE SET NULL,
+		status varchar(20) NOT NULL DEFAULT 'pending',
+		decided_by_id uuid REFERENCES employees(id) ON DELETE SET NULL,
+		decided_at timestamptz,
+		comment text,
+		created_at timestamptz DEFAULT now(),
+		updated_at timestamptz DEFAULT now(),
+		CHECK (to_date >= from_date)
+	);
+
+	-- A leave has at most one cancellation waiting for a decision
+	CREATE UNIQUE INDEX IF NOT EXISTS uq_leave_cancellations_pending ON leave_cancellations (leave_id) WHERE status = 'pending';
+	CREATE INDEX IF NOT EXISTS idx_leave_cancellations_approver ON leave_cancellations (approver_id) WHERE status = 'pending';`).Error
+}
+
+func Down20261017234500CreateLeaveCancellations(db *gorm.DB) error {
+	return db.Exec(`DROP TABLE IF EXISTS leave_cancellations;`).Error
+}
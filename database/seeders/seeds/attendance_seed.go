@@ -29,8 +29,8 @@ func AttendanceSeeder(db *gorm.DB) error {
 
 	for _, data := range listData {
 		var existingData entities.Attendance
-		// Use ID for idempotency check as attendance records might not have other unique keys
-		err := db.First(&existingData, "id = ?", data.ID).Error
+		// An employee has one attendance per work date
+		err := db.First(&existingData, "employee_id = ? AND work_date = ?", data.EmployeeID, data.WorkDate.Format("2006-01-02")).Error
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
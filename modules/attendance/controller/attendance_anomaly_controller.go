@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	AttendanceAnomalyController interface {
+		Scan(ctx *gin.Context)
+		GetAll(ctx *gin.Context)
+		GetPendingReviews(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		Confirm(ctx *gin.Context)
+		Dismiss(ctx *gin.Context)
+	}
+
+	attendanceAnomalyController struct {
+		service service.AttendanceAnomalyService
+	}
+)
+
+func NewAttendanceAnomalyController(s service.AttendanceAnomalyService) AttendanceAnomalyController {
+	return &attendanceAnomalyController{
+		service: s,
+	}
+}
+
+// Scan godoc
+// @Summary Run attendance anomaly detection
+// @Description Flags impossible travel, repeated coordinates, unusual check-in hours and buddy punching on the given work dates
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param body body dto.AnomalyScanDTO true "Scan DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/anomalies/scan [post]
+func (c *attendanceAnomalyController) Scan(ctx *gin.Context) {
+	var req dto.AnomalyScanDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Scan(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed detect anomalies", err.Error(), nil)
+		ctx.JSON(anomalyErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("anomaly detection finished", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *attendanceAnomalyController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindAll(ctx.Request.Context(), &filter, ctx.Query("status"))
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get anomalies", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetPendingReviews godoc
+// @Summary Anomaly review queue
+// @Description Open anomaly flags of the employees the logged-in user supervises
+// @Tags attendances
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /attendances/anomalies/reviews [get]
+func (c *attendanceAnomalyController) GetPendingReviews(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindPendingReviews(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get anomalies", err.Error(), nil)
+		ctx.JSON(anomalyErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *attendanceAnomalyController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		res := utils.BuildResponseFailed("not found", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Confirm godoc
+// @Summary Confirm an attendance anomaly
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Anomaly ID"
+// @Param body body dto.AnomalyReviewDTO false "Review DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/anomalies/{id}/confirm [post]
+func (c *attendanceAnomalyController) Confirm(ctx *gin.Context) {
+	c.review(ctx, c.service.Confirm, "anomaly confirmed")
+}
+
+// Dismiss godoc
+// @Summary Dismiss an attendance anomaly as a false alarm
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Anomaly ID"
+// @Param body body dto.AnomalyReviewDTO false "Review DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/anomalies/{id}/dismiss [post]
+func (c *attendanceAnomalyController) Dismiss(ctx *gin.Context) {
+	c.review(ctx, c.service.Dismiss, "anomaly dismissed")
+}
+
+func (c *attendanceAnomalyController) review(ctx *gin.Context, review func(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceAnomaly, error), message string) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.AnomalyReviewDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := review(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed review anomaly", err.Error(), nil)
+		ctx.JSON(anomalyErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess(message, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func anomalyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrNotSupervisor):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrAnomalyNotOpen):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrReportDate),
+		errors.Is(err, dto.ErrAnomalyRange):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrEmployeeNotLinked):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,8 +1,14 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
 	"net/http"
+	"reflect"
+	"strings"
 
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/validation"
@@ -10,6 +16,8 @@ import (
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
 	"github.com/samber/do"
 	"gorm.io/gorm"
 )
@@ -19,11 +27,24 @@ type (
 		GetAll(ctx *gin.Context)
 		GetByID(ctx *gin.Context)
 		GetByEmployeeID(ctx *gin.Context)
+		GetAutoCheckouts(ctx *gin.Context)
 		CheckIn(ctx *gin.Context)
 		CheckOut(ctx *gin.Context)
-		Update(ctx *gin.Context)
-		Approve(ctx *gin.Context)
+		GetPhoto(ctx *gin.Context)
+		StartBreak(ctx *gin.Context)
+		EndBreak(ctx *gin.Context)
+		Sync(ctx *gin.Context)
+		ImportAttlog(ctx *gin.Context)
 		Delete(ctx *gin.Context)
+
+		// Self-service, for the employee linked to the login
+		MyCheckIn(ctx *gin.Context)
+		MyCheckOut(ctx *gin.Context)
+		MyStartBreak(ctx *gin.Context)
+		MyEndBreak(ctx *gin.Context)
+		MySync(ctx *gin.Context)
+		MyToday(ctx *gin.Context)
+		MyHistory(ctx *gin.Context)
 	}
 
 	attendanceController struct {
@@ -105,22 +126,59 @@ func (c *attendanceController) GetByEmployeeID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// GetAutoCheckouts godoc
+// @Summary Get automatically closed attendances
+// @Description Get attendances whose check-out was filled in by the system, for review
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} utils.Response
+// @Router /attendances/auto-checkouts [get]
+func (c *attendanceController) GetAutoCheckouts(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindAutoCheckouts(ctx.Request.Context(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get attendances", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
 // CheckIn godoc
 // @Summary Check in
-// @Description Check in
+// @Description Check in, optionally with a selfie sent as a multipart form
 // @Tags attendances
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Param body body dto.CheckInDTO true "CheckIn DTO"
+// @Param photo formData file false "Selfie, required at locations with require_selfie"
 // @Success 201 {object} utils.Response
 // @Router /attendances/check-in [post]
 func (c *attendanceController) CheckIn(ctx *gin.Context) {
-	var req dto.CheckInDTO
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	c.checkIn(ctx, uuid.Nil)
+}
+
+// checkIn punches for employeeID, or for the employee named in the body when
+// employeeID is nil.
+func (c *attendanceController) checkIn(ctx *gin.Context, employeeID uuid.UUID) {
+	req := dto.CheckInDTO{EmployeeID: employeeID}
+	photo, err := bindPunch(ctx, &req)
+	if err != nil {
 		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
+	req.Photo = photo
+	if employeeID != uuid.Nil {
+		req.EmployeeID = employeeID
+	}
 
 	if err := c.validation.CheckIn(req); err != nil {
 		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
@@ -131,7 +189,7 @@ func (c *attendanceController) CheckIn(ctx *gin.Context) {
 	result, err := c.service.CheckIn(req)
 	if err != nil {
 		res := utils.BuildResponseFailed("failed check-in", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.JSON(punchErrorStatus(err), res)
 		return
 	}
 	res := utils.BuildResponseSuccess("check-in successful", result)
@@ -140,20 +198,30 @@ func (c *attendanceController) CheckIn(ctx *gin.Context) {
 
 // CheckOut godoc
 // @Summary Check out
-// @Description Check out
+// @Description Check out, optionally with a selfie sent as a multipart form
 // @Tags attendances
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Param body body dto.CheckOutDTO true "CheckOut DTO"
+// @Param photo formData file false "Selfie, required at locations with require_selfie"
 // @Success 200 {object} utils.Response
 // @Router /attendances/check-out [put]
 func (c *attendanceController) CheckOut(ctx *gin.Context) {
-	var req dto.CheckOutDTO
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	c.checkOut(ctx, uuid.Nil)
+}
+
+func (c *attendanceController) checkOut(ctx *gin.Context, employeeID uuid.UUID) {
+	req := dto.CheckOutDTO{EmployeeID: employeeID}
+	photo, err := bindPunch(ctx, &req)
+	if err != nil {
 		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
+	req.Photo = photo
+	if employeeID != uuid.Nil {
+		req.EmployeeID = employeeID
+	}
 
 	if err := c.validation.CheckOut(req); err != nil {
 		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
@@ -164,80 +232,167 @@ func (c *attendanceController) CheckOut(ctx *gin.Context) {
 	result, err := c.service.CheckOut(req)
 	if err != nil {
 		res := utils.BuildResponseFailed("failed check-out", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.JSON(punchErrorStatus(err), res)
 		return
 	}
 	res := utils.BuildResponseSuccess("check-out successful", result)
 	ctx.JSON(http.StatusOK, res)
 }
 
-// Update godoc
-// @Summary Update attendance
-// @Description Update attendance
+// GetPhoto godoc
+// @Summary Get a punch selfie
+// @Description Get the selfie taken at the check-in or check-out of an attendance
+// @Tags attendances
+// @Produce image/jpeg,image/png
+// @Param id path string true "Attendance ID"
+// @Param punch path string true "check-in or check-out"
+// @Success 200 {file} file
+// @Router /attendances/{id}/photos/{punch} [get]
+func (c *attendanceController) GetPhoto(ctx *gin.Context) {
+	file, err := c.service.PhotoFile(ctx.Param("id"), ctx.Param("punch"))
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get photo", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+	ctx.File(file)
+}
+
+// StartBreak godoc
+// @Summary Start a break
+// @Description Start a break on the open attendance
 // @Tags attendances
 // @Accept json
 // @Produce json
-// @Param id path string true "Attendance ID"
-// @Param body body dto.UpdateAttendanceDTO true "UpdateAttendance DTO"
+// @Param body body dto.BreakDTO true "Break DTO"
 // @Success 200 {object} utils.Response
-// @Router /attendances/{id} [put]
-func (c *attendanceController) Update(ctx *gin.Context) {
-	id := ctx.Param("id")
-	var req dto.UpdateAttendanceDTO
+// @Router /attendances/break-start [post]
+func (c *attendanceController) StartBreak(ctx *gin.Context) {
+	c.punchBreak(ctx, uuid.Nil, c.service.StartBreak, "failed start break", "break started")
+}
+
+// EndBreak godoc
+// @Summary End a break
+// @Description End the break in progress on the open attendance
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param body body dto.BreakDTO true "Break DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/break-end [post]
+func (c *attendanceController) EndBreak(ctx *gin.Context) {
+	c.punchBreak(ctx, uuid.Nil, c.service.EndBreak, "failed end break", "break ended")
+}
+
+func (c *attendanceController) punchBreak(ctx *gin.Context, employeeID uuid.UUID, punch func(dto.BreakDTO) (*entities.Attendance, error), failed, success string) {
+	req := dto.BreakDTO{EmployeeID: employeeID}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
+	if employeeID != uuid.Nil {
+		req.EmployeeID = employeeID
+	}
 
-	if err := c.validation.UpdateAttendance(req); err != nil {
+	if err := c.validation.Break(req); err != nil {
 		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	result, err := c.service.Update(id, req)
+	result, err := punch(req)
 	if err != nil {
-		res := utils.BuildResponseFailed("failed update attendance", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+		res := utils.BuildResponseFailed(failed, err.Error(), nil)
+		ctx.JSON(punchErrorStatus(err), res)
 		return
 	}
-	res := utils.BuildResponseSuccess("update successful", result)
+	res := utils.BuildResponseSuccess(success, result)
 	ctx.JSON(http.StatusOK, res)
 }
 
-// Approve godoc
-// @Summary Approve attendance
-// @Description Approve attendance
+// Sync godoc
+// @Summary Sync offline punches
+// @Description Upload punches a device queued while offline; each punch gets its own result
 // @Tags attendances
 // @Accept json
 // @Produce json
-// @Param id path string true "Attendance ID"
-// @Param body body dto.ApproveAttendanceDTO true "ApproveAttendance DTO"
+// @Param body body dto.SyncDTO true "Sync DTO"
 // @Success 200 {object} utils.Response
-// @Router /attendances/{id}/approve [put]
-func (c *attendanceController) Approve(ctx *gin.Context) {
-	id := ctx.Param("id")
-	var req dto.ApproveAttendanceDTO
+// @Router /attendances/sync [post]
+func (c *attendanceController) Sync(ctx *gin.Context) {
+	c.sync(ctx, uuid.Nil)
+}
+
+func (c *attendanceController) sync(ctx *gin.Context, employeeID uuid.UUID) {
+	var req dto.SyncDTO
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
+	if employeeID != uuid.Nil {
+		for i := range req.Punches {
+			req.Punches[i].EmployeeID = employeeID
+		}
+	}
 
-	if err := c.validation.ApproveAttendance(req); err != nil {
+	if err := c.validation.Sync(req); err != nil {
 		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	result, err := c.service.Approve(id, req)
+	result, err := c.service.Sync(ctx.Request.Context(), req)
 	if err != nil {
-		res := utils.BuildResponseFailed("failed approval", err.Error(), nil)
+		res := utils.BuildResponseFailed("failed sync punches", err.Error(), nil)
 		ctx.JSON(http.StatusInternalServerError, res)
 		return
 	}
-	res := utils.BuildResponseSuccess("approval successful", result)
+	res := utils.BuildResponseSuccess("sync processed", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ImportAttlog godoc
+// @Summary Import a fingerprint terminal log
+// @Description Import the attlog.dat of a ZKTeco terminal; PINs are matched against employee codes
+// @Tags attendances
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "attlog.dat exported from the terminal"
+// @Param location_id formData string true "Location the terminal stands at"
+// @Param device_id formData string false "Serial number of the terminal"
+// @Success 200 {object} utils.Response
+// @Router /attendances/imports/attlog [post]
+func (c *attendanceController) ImportAttlog(ctx *gin.Context) {
+	var req dto.AttlogImportDTO
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", dto.ErrAttlogFile.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		res := utils.BuildResponseFailed("failed import attendance log", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	defer file.Close()
+
+	result, err := c.service.ImportAttlog(ctx.Request.Context(), uuid.MustParse(req.LocationID), req.DeviceID, file)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed import attendance log", err.Error(), nil)
+		ctx.JSON(punchErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("attendance log imported", result)
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -261,3 +416,207 @@ func (c *attendanceController) Delete(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess("delete successful", nil)
 	ctx.JSON(http.StatusOK, res)
 }
+
+// MyCheckIn godoc
+// @Summary Check in as the logged-in employee
+// @Description Check in for the employee linked to the login; employee_id in the body is ignored
+// @Tags attendances
+// @Accept json,mpfd
+// @Produce json
+// @Param body body dto.CheckInDTO true "CheckIn DTO"
+// @Param photo formData file false "Selfie, required at locations with require_selfie"
+// @Success 201 {object} utils.Response
+// @Router /attendances/me/check-in [post]
+func (c *attendanceController) MyCheckIn(ctx *gin.Context) {
+	if employeeID, ok := c.currentEmployee(ctx); ok {
+		c.checkIn(ctx, employeeID)
+	}
+}
+
+// MyCheckOut godoc
+// @Summary Check out as the logged-in employee
+// @Description Check out for the employee linked to the login; employee_id in the body is ignored
+// @Tags attendances
+// @Accept json,mpfd
+// @Produce json
+// @Param body body dto.CheckOutDTO true "CheckOut DTO"
+// @Param photo formData file false "Selfie, required at locations with require_selfie"
+// @Success 200 {object} utils.Response
+// @Router /attendances/me/check-out [put]
+func (c *attendanceController) MyCheckOut(ctx *gin.Context) {
+	if employeeID, ok := c.currentEmployee(ctx); ok {
+		c.checkOut(ctx, employeeID)
+	}
+}
+
+func (c *attendanceController) MyStartBreak(ctx *gin.Context) {
+	if employeeID, ok := c.currentEmployee(ctx); ok {
+		c.punchBreak(ctx, employeeID, c.service.StartBreak, "failed start break", "break started")
+	}
+}
+
+func (c *attendanceController) MyEndBreak(ctx *gin.Context) {
+	if employeeID, ok := c.currentEmployee(ctx); ok {
+		c.punchBreak(ctx, employeeID, c.service.EndBreak, "failed end break", "break ended")
+	}
+}
+
+func (c *attendanceController) MySync(ctx *gin.Context) {
+	if employeeID, ok := c.currentEmployee(ctx); ok {
+		c.sync(ctx, employeeID)
+	}
+}
+
+// MyToday godoc
+// @Summary Get today's attendance of the logged-in employee
+// @Description Get the open attendance, or else today's; data is null before the first check-in
+// @Tags attendances
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /attendances/me/today [get]
+func (c *attendanceController) MyToday(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.service.Today(ctx.Request.Context(), employeeID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get attendance", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// MyHistory godoc
+// @Summary Get the attendance history of the logged-in employee
+// @Tags attendances
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} utils.Response
+// @Router /attendances/me/history [get]
+func (c *attendanceController) MyHistory(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindByEmployeeID(ctx.Request.Context(), employeeID.String(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get attendances", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// currentEmployee resolves the employee linked to the login and writes the
+// error response when there is none.
+func (c *attendanceController) currentEmployee(ctx *gin.Context) (uuid.UUID, bool) {
+	userID := ctx.MustGet("user_id").(string)
+
+	employeeID, err := c.service.EmployeeIDByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, dto.ErrEmployeeNotLinked) {
+			status = http.StatusForbidden
+		}
+		res := utils.BuildResponseFailed("failed resolve employee", err.Error(), nil)
+		ctx.JSON(status, res)
+		return uuid.Nil, false
+	}
+	return employeeID, true
+}
+
+// bindPunch binds a punch sent as JSON, or as a multipart form carrying the same
+// fields next to an optional "photo" file. Form values are decoded as JSON when
+// they parse as such, except for string fields, so numbers and IDs land in the
+// same DTO fields.
+func bindPunch(ctx *gin.Context, req any) (*multipart.FileHeader, error) {
+	if ctx.ContentType() != binding.MIMEMultipartPOSTForm {
+		return nil, ctx.ShouldBindJSON(req)
+	}
+
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	textFields := stringFields(req)
+	fields := make(map[string]json.RawMessage, len(form.Value))
+	for key, values := range form.Value {
+		if len(values) == 0 {
+			continue
+		}
+		value := []byte(values[0])
+		if textFields[key] || !json.Valid(value) {
+			value, _ = json.Marshal(values[0])
+		}
+		fields[key] = value
+	}
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := binding.JSON.BindBody(body, req); err != nil {
+		return nil, err
+	}
+
+	if files := form.File["photo"]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, nil
+}
+
+// stringFields lists the JSON names of the string fields of the struct req
+// points to.
+func stringFields(req any) map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(req).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		kind := field.Type.Kind()
+		if kind == reflect.Pointer {
+			kind = field.Type.Elem().Kind()
+		}
+		if kind == reflect.String {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// punchErrorStatus maps check-in/check-out rejections to client errors so the
+// app can tell a refused punch apart from a server failure.
+func punchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrOutsideGeofence),
+		errors.Is(err, dto.ErrGPSAccuracyTooLow),
+		errors.Is(err, dto.ErrLocationInactive),
+		errors.Is(err, dto.ErrSelfieRequired),
+		errors.Is(err, dto.ErrSelfieInvalid),
+		errors.Is(err, dto.ErrKioskCodeRequired),
+		errors.Is(err, dto.ErrKioskCodeInvalid),
+		errors.Is(err, dto.ErrRemoteWorkNotApproved):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, dto.ErrAlreadyOnBreak),
+		errors.Is(err, dto.ErrNotOnBreak),
+		errors.Is(err, dto.ErrPunchOutOfOrder),
+		errors.Is(err, dto.ErrAlreadyCheckedIn),
+		errors.Is(err, dto.ErrPunchConflict):
+		return http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	AttendanceCorrectionController interface {
+		Submit(ctx *gin.Context)
+		GetAll(ctx *gin.Context)
+		GetMine(ctx *gin.Context)
+		GetPendingApprovals(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		Approve(ctx *gin.Context)
+		Reject(ctx *gin.Context)
+	}
+
+	attendanceCorrectionController struct {
+		service    service.AttendanceCorrectionService
+		validation *validation.AttendanceValidation
+	}
+)
+
+func NewAttendanceCorrectionController(s service.AttendanceCorrectionService) AttendanceCorrectionController {
+	return &attendanceCorrectionController{
+		service:    s,
+		validation: validation.NewAttendanceValidation(),
+	}
+}
+
+// Submit godoc
+// @Summary Request an attendance correction
+// @Description Proposes check-in/check-out times for a missed or wrong punch, pending supervisor approval
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param body body dto.CorrectionCreateDTO true "Correction DTO"
+// @Success 201 {object} utils.Response
+// @Router /attendances/corrections [post]
+func (c *attendanceCorrectionController) Submit(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var req dto.CorrectionCreateDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.validation.CreateCorrection(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Submit(ctx.Request.Context(), userID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed submit correction", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("correction submitted", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *attendanceCorrectionController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindAll(ctx.Request.Context(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get corrections", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *attendanceCorrectionController) GetMine(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindMine(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get corrections", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *attendanceCorrectionController) GetPendingApprovals(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindPendingApprovals(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get pending corrections", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *attendanceCorrectionController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+	result, err := c.service.GetByID(ctx.Request.Context(), id, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get correction", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Approve godoc
+// @Summary Approve an attendance correction
+// @Description Applies the proposed times to the attendance row, keeping the original values for audit
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Correction ID"
+// @Param body body dto.CorrectionReviewDTO false "Review DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/corrections/{id}/approve [post]
+func (c *attendanceCorrectionController) Approve(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.CorrectionReviewDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Approve(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed approve correction", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("correction approved", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Reject godoc
+// @Summary Reject an attendance correction
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Correction ID"
+// @Param body body dto.CorrectionRejectDTO true "Reject DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/corrections/{id}/reject [post]
+func (c *attendanceCorrectionController) Reject(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.CorrectionRejectDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Reject(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reject correction", err.Error(), nil)
+		ctx.JSON(correctionErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("correction rejected", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func correctionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrNotSupervisor),
+		errors.Is(err, dto.ErrNotAttendanceOwner),
+		errors.Is(err, dto.ErrNotRequestViewer):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrCorrectionPending),
+		errors.Is(err, dto.ErrCorrectionNotPending),
+		errors.Is(err, dto.ErrAttendanceAlreadyExist):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrEmployeeNotLinked),
+		errors.Is(err, dto.ErrCorrectionRange),
+		errors.Is(err, dto.ErrCorrectionOutsideDate),
+		errors.Is(err, dto.ErrCorrectionInFuture):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type (
+	AttendanceReportController interface {
+		Summary(ctx *gin.Context)
+	}
+
+	attendanceReportController struct {
+		service service.AttendanceReportService
+	}
+)
+
+func NewAttendanceReportController(s service.AttendanceReportService) AttendanceReportController {
+	return &attendanceReportController{
+		service: s,
+	}
+}
+
+// reportHeader names the columns of an exported attendance report.
+var reportHeader = []any{
+	"Employee Code", "Name", "Department", "Days Present", "Remote Days", "Late Count", "Late Minutes",
+	"Early Leave Count", "Absences", "Leave Days", "Overtime Hours", "Average Worked Hours",
+}
+
+// Summary godoc
+// @Summary Attendance summary report
+// @Description Per employee attendance, leave and overtime totals over a date range, as JSON, CSV or XLSX
+// @Tags attendances
+// @Produce json
+// @Param from query string true "First work date, YYYY-MM-DD"
+// @Param to query string true "Last work date, YYYY-MM-DD"
+// @Param employee_id query string false "Employee ID"
+// @Param department_id query string false "Department ID"
+// @Param location_id query string false "Location ID"
+// @Param format query string false "json, csv or xlsx"
+// @Success 200 {object} utils.Response
+// @Router /attendances/reports/summary [get]
+func (c *attendanceReportController) Summary(ctx *gin.Context) {
+	var req dto.ReportDTO
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from query", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	report, err := c.service.Summary(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed build attendance report", err.Error(), nil)
+		ctx.JSON(reportErrorStatus(err), res)
+		return
+	}
+
+	filename := fmt.Sprintf("attendance-report-%s-%s", report.From, report.To)
+	switch req.Format {
+	case "csv":
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		writer := csv.NewWriter(ctx.Writer)
+		for _, row := range reportTable(report) {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = fmt.Sprint(value)
+			}
+			if err := writer.Write(record); err != nil {
+				_ = ctx.Error(err)
+				return
+			}
+		}
+		writer.Flush()
+	case "xlsx":
+		ctx.Header("Content-Disposition", `attachment; filename="`+filename+`.xlsx"`)
+		ctx.Header("Content-Type", helpers.XLSXContentType)
+		ctx.Status(http.StatusOK)
+		if err := helpers.WriteXLSX(ctx.Writer, "Attendance "+report.From[:7], reportTable(report)); err != nil {
+			_ = ctx.Error(err)
+		}
+	default:
+		res := utils.BuildResponseSuccess("success", report)
+		ctx.JSON(http.StatusOK, res)
+	}
+}
+
+// reportTable lays a report out as rows under reportHeader.
+func reportTable(report dto.AttendanceReport) [][]any {
+	table := [][]any{reportHeader}
+	for _, row := range report.Rows {
+		table = append(table, []any{
+			row.EmployeeCode,
+			row.Name,
+			row.Department,
+			row.DaysPresent,
+			row.RemoteDays,
+			row.LateCount,
+			row.LateMinutes,
+			row.EarlyLeaveCount,
+			row.Absences,
+			row.LeaveDays,
+			row.OvertimeHours,
+			row.AverageWorkedHours,
+		})
+	}
+	return table
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrReportDate),
+		errors.Is(err, dto.ErrReportRange),
+		errors.Is(err, dto.ErrReportScope):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	RemoteWorkController interface {
+		Submit(ctx *gin.Context)
+		GetAll(ctx *gin.Context)
+		GetMine(ctx *gin.Context)
+		GetPendingApprovals(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		Approve(ctx *gin.Context)
+		Reject(ctx *gin.Context)
+	}
+
+	remoteWorkController struct {
+		service    service.RemoteWorkService
+		validation *validation.AttendanceValidation
+	}
+)
+
+func NewRemoteWorkController(s service.RemoteWorkService) RemoteWorkController {
+	return &remoteWorkController{
+		service:    s,
+		validation: validation.NewAttendanceValidation(),
+	}
+}
+
+// Submit godoc
+// @Summary Request remote work
+// @Description Asks to work from home or travel for business on a range of dates, pending supervisor approval
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param body body dto.RemoteWorkCreateDTO true "Remote work DTO"
+// @Success 201 {object} utils.Response
+// @Router /attendances/remote-work [post]
+func (c *remoteWorkController) Submit(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var req dto.RemoteWorkCreateDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.validation.CreateRemoteWork(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Submit(ctx.Request.Context(), userID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed submit remote work request", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("remote work request submitted", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *remoteWorkController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindAll(ctx.Request.Context(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get remote work requests", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *remoteWorkController) GetMine(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindMine(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get remote work requests", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *remoteWorkController) GetPendingApprovals(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.service.FindPendingApprovals(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get pending remote work requests", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *remoteWorkController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+	result, err := c.service.GetByID(ctx.Request.Context(), id, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get remote work request", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Approve godoc
+// @Summary Approve a remote work request
+// @Description Lets the employee check in at the remote location on the requested dates
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Remote work request ID"
+// @Param body body dto.RemoteWorkReviewDTO false "Review DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/remote-work/{id}/approve [post]
+func (c *remoteWorkController) Approve(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.RemoteWorkReviewDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Approve(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed approve remote work request", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("remote work request approved", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Reject godoc
+// @Summary Reject a remote work request
+// @Tags attendances
+// @Accept json
+// @Produce json
+// @Param id path string true "Remote work request ID"
+// @Param body body dto.RemoteWorkRejectDTO true "Reject DTO"
+// @Success 200 {object} utils.Response
+// @Router /attendances/remote-work/{id}/reject [post]
+func (c *remoteWorkController) Reject(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.RemoteWorkRejectDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Reject(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reject remote work request", err.Error(), nil)
+		ctx.JSON(remoteWorkErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("remote work request rejected", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func remoteWorkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrNotSupervisor),
+		errors.Is(err, dto.ErrNotRequestViewer):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrRemoteWorkOverlap),
+		errors.Is(err, dto.ErrRemoteWorkNotPending):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrRemoteWorkDate):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrEmployeeNotLinked),
+		errors.Is(err, dto.ErrRemoteWorkRange),
+		errors.Is(err, dto.ErrRemoteWorkInPast):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
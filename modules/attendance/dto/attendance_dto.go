@@ -1,20 +1,277 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"mime/multipart"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLocationInactive  = errors.New("location is inactive")
+	ErrLocationPolygon   = errors.New("location polygon is invalid")
+	ErrOutsideGeofence   = errors.New("device position is outside the location area")
+	ErrGPSAccuracyTooLow = errors.New("device position accuracy is too low")
+	ErrAlreadyOnBreak    = errors.New("a break is already in progress")
+	ErrNotOnBreak        = errors.New("no break is in progress")
+	ErrSelfieRequired    = errors.New("a selfie is required at this location")
+	ErrSelfieInvalid     = errors.New("selfie must be a jpg or png image of at most 5 MB")
+	ErrPhotoNotFound     = errors.New("attendance has no photo for this punch")
+	ErrKioskCodeRequired = errors.New("scan the kiosk code to punch at this location")
+	ErrKioskCodeInvalid  = errors.New("kiosk code is expired or not valid for this location")
+	ErrPunchOutOfOrder   = errors.New("punch is earlier than the last punch of the attendance")
+	ErrNoOpenAttendance  = errors.New("no open check-in record found")
+	ErrAlreadyCheckedIn  = errors.New("already checked in for this work date")
+	ErrPunchConflict     = errors.New("attendance was changed by another punch, reload and try again")
+	ErrSyncTooOld        = errors.New("punch is too old to sync")
+	ErrSyncInFuture      = errors.New("punch time is ahead of the server clock")
+	ErrSyncLocation      = errors.New("location_id or remote is required for an in punch")
+	ErrSyncEmployee      = errors.New("employee_id is required")
+	ErrAttlogFile        = errors.New("an attendance log file is required")
+
+	ErrEmployeeNotLinked      = errors.New("no employee record is linked to this user")
+	ErrNotSupervisor          = errors.New("only the employee's supervisor can review this request")
+	ErrNotAttendanceOwner     = errors.New("attendance belongs to another employee")
+	ErrNotRequestViewer       = errors.New("only the employee, their supervisor or HR can view this request")
+	ErrCorrectionEmpty        = errors.New("at least one of check_in_time or check_out_time is required")
+	ErrCorrectionTarget       = errors.New("work_date, location_id and check_in_time are required without attendance_id")
+	ErrCorrectionAbsence      = errors.New("location_id and check_in_time are required to correct an absence")
+	ErrCorrectionRange        = errors.New("check_out_time must be after check_in_time")
+	ErrCorrectionOutsideDate  = errors.New("check_in_time must fall on the work date or the night after")
+	ErrCorrectionInFuture     = errors.New("corrected times must not be in the future")
+	ErrCorrectionPending      = errors.New("a correction for this work date is already pending")
+	ErrCorrectionNotPending   = errors.New("correction request is no longer pending")
+	ErrAttendanceAlreadyExist = errors.New("attendance exists for this work date, correct it by attendance_id")
+
+	ErrReportDate  = errors.New("from and to must be dates in YYYY-MM-DD format")
+	ErrReportRange = errors.New("to must not be before from, and a report covers at most 92 days")
+	ErrReportScope = errors.New("give at most one of employee_id, department_id and location_id")
+
+	ErrAnomalyRange   = errors.New("to must not be before from, and a scan covers at most 31 days")
+	ErrAnomalyNotOpen = errors.New("anomaly flag is already reviewed")
+
+	ErrRemoteWorkNotApproved = errors.New("no approved remote work request covers this work date")
+	ErrRemoteWorkDate        = errors.New("start_date and end_date must be dates in YYYY-MM-DD format")
+	ErrRemoteWorkRange       = errors.New("end_date must not be before start_date, and a request covers at most 31 days")
+	ErrRemoteWorkInPast      = errors.New("remote work cannot be requested for past dates")
+	ErrRemoteWorkOverlap     = errors.New("another pending or approved remote work request covers some of these dates")
+	ErrRemoteWorkNotPending  = errors.New("remote work request is no longer pending")
+)
+
+// CheckInDTO arrives as JSON, or as a multipart form with the same fields and
+// an optional "photo" selfie. KioskCode comes from the QR code of a kiosk
+// location and stands in for the geofence check there. Remote checks in at the
+// remote location instead, on a date an approved remote work request covers.
+// DeviceID identifies the phone or tablet the punch is made on.
 type CheckInDTO struct {
-	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
-	LocationID uuid.UUID `json:"location_id" binding:"required"`
+	EmployeeID uuid.UUID             `json:"employee_id" binding:"required"`
+	LocationID uuid.UUID             `json:"location_id" binding:"required_without=Remote"`
+	Remote     bool                  `json:"remote"`
+	Latitude   *float64              `json:"latitude" binding:"required,latitude"`
+	Longitude  *float64              `json:"longitude" binding:"required,longitude"`
+	Accuracy   float64               `json:"accuracy" binding:"gte=0"`
+	KioskCode  string                `json:"kiosk_code"`
+	DeviceID   string                `json:"device_id" binding:"max=100"`
+	Photo      *multipart.FileHeader `json:"-"`
 }
 
+// CheckOutDTO arrives like CheckInDTO.
 type CheckOutDTO struct {
+	EmployeeID uuid.UUID             `json:"employee_id" binding:"required"`
+	Latitude   *float64              `json:"latitude" binding:"required,latitude"`
+	Longitude  *float64              `json:"longitude" binding:"required,longitude"`
+	Accuracy   float64               `json:"accuracy" binding:"gte=0"`
+	KioskCode  string                `json:"kiosk_code"`
+	DeviceID   string                `json:"device_id" binding:"max=100"`
+	Photo      *multipart.FileHeader `json:"-"`
+}
+
+// BreakDTO starts or ends a break on the open attendance of the employee.
+type BreakDTO struct {
 	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
+	Latitude   *float64  `json:"latitude" binding:"required,latitude"`
+	Longitude  *float64  `json:"longitude" binding:"required,longitude"`
+	Accuracy   float64   `json:"accuracy" binding:"gte=0"`
+	DeviceID   string    `json:"device_id" binding:"max=100"`
+}
+
+// SyncDTO uploads the punches a device queued while offline.
+type SyncDTO struct {
+	DeviceID string         `json:"device_id" binding:"required,max=100"`
+	Punches  []SyncPunchDTO `json:"punches" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncPunchDTO is one queued punch. ClientID is generated on the device and
+// identifies the punch across retried uploads; PunchedAt is the device clock at
+// the time of the punch. EmployeeID is filled in from the login on /me/sync.
+type SyncPunchDTO struct {
+	ClientID   uuid.UUID  `json:"client_id" binding:"required"`
+	Type       string     `json:"type" binding:"required,oneof=in out break_start break_end"`
+	EmployeeID uuid.UUID  `json:"employee_id"`
+	LocationID *uuid.UUID `json:"location_id"`
+	Remote     bool       `json:"remote"`
+	PunchedAt  time.Time  `json:"punched_at" binding:"required"`
+	Latitude   *float64   `json:"latitude" binding:"required,latitude"`
+	Longitude  *float64   `json:"longitude" binding:"required,longitude"`
+	Accuracy   float64    `json:"accuracy" binding:"gte=0"`
+	KioskCode  string     `json:"kiosk_code"`
+}
+
+// SyncItemResult reports what became of one uploaded punch.
+type SyncItemResult struct {
+	ClientID     uuid.UUID  `json:"client_id"`
+	Status       string     `json:"status"`
+	AttendanceID *uuid.UUID `json:"attendance_id,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// SyncResult lists the outcome of every punch in the order they were uploaded.
+type SyncResult struct {
+	ReceivedAt time.Time        `json:"received_at"`
+	Accepted   int              `json:"accepted"`
+	Duplicates int              `json:"duplicates"`
+	Rejected   int              `json:"rejected"`
+	Items      []SyncItemResult `json:"items"`
+}
+
+// AttlogImportDTO names the location of the terminal an attendance log comes
+// from, and optionally the terminal itself.
+type AttlogImportDTO struct {
+	LocationID string `form:"location_id" binding:"required,uuid"`
+	DeviceID   string `form:"device_id" binding:"max=100"`
+}
+
+// AttlogImportResult reports one attendance log import. Duplicates are scans
+// already imported, repeated in the file, or double scans of the same finger.
+type AttlogImportResult struct {
+	Lines       int                  `json:"lines"`
+	Imported    int                  `json:"imported"`
+	Duplicates  int                  `json:"duplicates"`
+	Attendances int                  `json:"attendances"`
+	Unmatched   []AttlogUnmatchedPIN `json:"unmatched"`
+	Invalid     []AttlogInvalidLine  `json:"invalid"`
+}
+
+// AttlogUnmatchedPIN is a terminal user PIN no employee code matches.
+type AttlogUnmatchedPIN struct {
+	PIN     string `json:"pin"`
+	Punches int    `json:"punches"`
+}
+
+type AttlogInvalidLine struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// CorrectionCreateDTO fixes an existing record by attendance_id, or fills in a
+// missed day when work_date, location_id and check_in_time are given instead.
+type CorrectionCreateDTO struct {
+	AttendanceID *uuid.UUID `json:"attendance_id"`
+	WorkDate     string     `json:"work_date"`
+	LocationID   *uuid.UUID `json:"location_id"`
+	CheckInTime  *time.Time `json:"check_in_time"`
+	CheckOutTime *time.Time `json:"check_out_time"`
+	Reason       string     `json:"reason" binding:"required"`
+}
+
+type CorrectionReviewDTO struct {
+	Note string `json:"note"`
+}
+
+type CorrectionRejectDTO struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// RemoteWorkCreateDTO asks to work remotely on the dates from start_date to
+// end_date, inclusive.
+type RemoteWorkCreateDTO struct {
+	Type      string `json:"type" binding:"required,oneof=wfh business_trip"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+type RemoteWorkReviewDTO struct {
+	Note string `json:"note"`
+}
+
+type RemoteWorkRejectDTO struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// AbsenceDetectionResult summarises one absence detection pass over a work date.
+type AbsenceDetectionResult struct {
+	WorkDate string `json:"work_date"`
+	Holiday  bool   `json:"holiday"`
+	Marked   int    `json:"marked"`
+	Pending  int    `json:"pending"`
+}
+
+// AutoCheckoutResult summarises one pass closing forgotten check-outs.
+type AutoCheckoutResult struct {
+	Open   int `json:"open"`
+	Closed int `json:"closed"`
+}
+
+// ReportDTO picks the dates and the employees of an attendance report. At most
+// one of employee_id, department_id and location_id narrows it down; format
+// is json unless csv or xlsx is asked for.
+type ReportDTO struct {
+	From         string `form:"from" binding:"required"`
+	To           string `form:"to" binding:"required"`
+	EmployeeID   string `form:"employee_id" binding:"omitempty,uuid"`
+	DepartmentID string `form:"department_id" binding:"omitempty,uuid"`
+	LocationID   string `form:"location_id" binding:"omitempty,uuid"`
+	Format       string `form:"format" binding:"omitempty,oneof=json csv xlsx"`
+}
+
+// AttendanceReport is the attendance summary of a date range, one row per
+// employee, that a month is closed from before payroll.
+type AttendanceReport struct {
+	From string                `json:"from"`
+	To   string                `json:"to"`
+	Rows []AttendanceReportRow `json:"rows"`
+}
+
+// AttendanceReportRow sums up one employee. Remote days are the days present
+// that were worked remotely. Leave days count the scheduled working days an
+// approved leave covers, half-day and hourly leave by their share of the day;
+// overtime hours are those worked within approved requests.
+type AttendanceReportRow struct {
+	EmployeeID         uuid.UUID `json:"employee_id"`
+	EmployeeCode       string    `json:"employee_code"`
+	Name               string    `json:"name"`
+	Department         string    `json:"department"`
+	DaysPresent        int       `json:"days_present"`
+	RemoteDays         int       `json:"remote_days"`
+	LateCount          int       `json:"late_count"`
+	LateMinutes        int       `json:"late_minutes"`
+	EarlyLeaveCount    int       `json:"early_leave_count"`
+	Absences           int       `json:"absences"`
+	LeaveDays          float64   `json:"leave_days"`
+	OvertimeHours      float64   `json:"overtime_hours"`
+	AverageWorkedHours float64   `json:"average_worked_hours"`
+}
+
+// AnomalyScanDTO runs anomaly detection over the work dates from..to.
+type AnomalyScanDTO struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
 }
 
-type UpdateAttendanceDTO struct {
-	Status string `json:"status" binding:"required"`
+type AnomalyReviewDTO struct {
+	Note string `json:"note"`
 }
 
-type ApproveAttendanceDTO struct {
-	Status string `json:"status" binding:"required,oneof=approved rejected"`
+// AnomalyDetectionResult summarises one anomaly detection pass. Found counts
+// the flags raised on the work dates, Created those not stored by an earlier
+// pass.
+type AnomalyDetectionResult struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Punches int            `json:"punches"`
+	Found   int            `json:"found"`
+	Created int64          `json:"created"`
+	Rules   map[string]int `json:"rules"`
 }
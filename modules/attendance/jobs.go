@@ -0,0 +1,81 @@
+package attendance
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
+	"github.com/samber/do"
+)
+
+func RegisterJobs(jobs *scheduler.Scheduler, injector *do.Injector) error {
+	absenceService := do.MustInvoke[service.AbsenceService](injector)
+	autoCheckoutService := do.MustInvoke[service.AutoCheckoutService](injector)
+	anomalyService := do.MustInvoke[service.AttendanceAnomalyService](injector)
+
+	absenceAt := os.Getenv("ABSENCE_DETECTION_AT")
+	if absenceAt == "" {
+		absenceAt = constants.DEFAULT_ABSENCE_DETECTION_AT
+	}
+
+	err := jobs.Register(scheduler.Job{
+		Name: "absence_detection",
+		At:   absenceAt,
+		Run: func(ctx context.Context) error {
+			today := time.Now().In(helpers.LoadTimezone(""))
+			for back := constants.ABSENCE_DETECTION_LOOKBACK_DAYS; back >= 1; back-- {
+				result, err := absenceService.DetectAbsences(ctx, today.AddDate(0, 0, -back))
+				if err != nil {
+					return err
+				}
+				log.Printf("absence detection %s: %d marked absent, %d pending, holiday=%t", result.WorkDate, result.Marked, result.Pending, result.Holiday)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = jobs.Register(scheduler.Job{
+		Name:  "auto_checkout",
+		Every: constants.AUTO_CHECKOUT_INTERVAL_MINUTES * time.Minute,
+		Run: func(ctx context.Context) error {
+			result, err := autoCheckoutService.CloseStale(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if result.Closed > 0 {
+				log.Printf("auto checkout: closed %d of %d open records", result.Closed, result.Open)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	anomalyAt := os.Getenv("ANOMALY_DETECTION_AT")
+	if anomalyAt == "" {
+		anomalyAt = constants.DEFAULT_ANOMALY_DETECTION_AT
+	}
+
+	return jobs.Register(scheduler.Job{
+		Name: "anomaly_detection",
+		At:   anomalyAt,
+		Run: func(ctx context.Context) error {
+			today := time.Now().In(helpers.LoadTimezone(""))
+			result, err := anomalyService.Detect(ctx, today.AddDate(0, 0, -constants.ANOMALY_DETECTION_LOOKBACK_DAYS), today.AddDate(0, 0, -1))
+			if err != nil {
+				return err
+			}
+			log.Printf("anomaly detection %s..%s: %d punches, %d flagged, %d new", result.From, result.To, result.Punches, result.Found, result.Created)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttendanceAnomalyRepository interface {
+	CreateMany(ctx context.Context, tx *gorm.DB, anomalies []entities.AttendanceAnomaly) (int64, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter, status string) (*pagination.Page[entities.AttendanceAnomaly], error)
+	FindOpenBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.AttendanceAnomaly], error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceAnomaly, error)
+	Update(ctx context.Context, tx *gorm.DB, anomaly entities.AttendanceAnomaly) (entities.AttendanceAnomaly, error)
+}
+
+type attendanceAnomalyRepository struct {
+	db *gorm.DB
+}
+
+func NewAttendanceAnomalyRepository(db *gorm.DB) AttendanceAnomalyRepository {
+	return &attendanceAnomalyRepository{
+		db: db,
+	}
+}
+
+// CreateMany stores new flags and skips those whose punch is already flagged
+// for the same rule, whatever their review status. It returns how many were
+// stored.
+func (r *attendanceAnomalyRepository) CreateMany(ctx context.Context, tx *gorm.DB, anomalies []entities.AttendanceAnomaly) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if len(anomalies) == 0 {
+		return 0, nil
+	}
+	result := tx.WithContext(ctx).Omit(clause.Associations).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "rule"}, {Name: "punch_id"}}, DoNothing: true}).
+		Create(&anomalies)
+	return result.RowsAffected, result.Error
+}
+
+func (r *attendanceAnomalyRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter, status string) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	if db == nil {
+		db = r.db
+	}
+	query := db.WithContext(ctx).Model(&entities.AttendanceAnomaly{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return r.paginate(query, filter)
+}
+
+func (r *attendanceAnomalyRepository) FindOpenBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	if db == nil {
+		db = r.db
+	}
+	subordinates := db.Model(&entities.Employee{}).Select("id").Where("supervisor_id = ?", supervisorID)
+	query := db.WithContext(ctx).Model(&entities.AttendanceAnomaly{}).
+		Where("status = ?", constants.ENUM_ANOMALY_STATUS_OPEN).
+		Where("employee_id IN (?)", subordinates)
+	return r.paginate(query, filter)
+}
+
+func (r *attendanceAnomalyRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceAnomaly, error) {
+	if db == nil {
+		db = r.db
+	}
+	var anomaly entities.AttendanceAnomaly
+	if err := db.WithContext(ctx).Preload("Employee").Preload("RelatedEmployee").Preload("Punch").Where("id = ?", id).First(&anomaly).Error; err != nil {
+		return entities.AttendanceAnomaly{}, err
+	}
+	return anomaly, nil
+}
+
+func (r *attendanceAnomalyRepository) Update(ctx context.Context, tx *gorm.DB, anomaly entities.AttendanceAnomaly) (entities.AttendanceAnomaly, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Save(&anomaly).Error; err != nil {
+		return entities.AttendanceAnomaly{}, err
+	}
+	return anomaly, nil
+}
+
+func (r *attendanceAnomalyRepository) paginate(query *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	var anomalies []entities.AttendanceAnomaly
+	var page pagination.Page[entities.AttendanceAnomaly]
+
+	paginator, err := pagination.NewPaginator(query.Preload("Employee").Preload("RelatedEmployee").Preload("Punch").Order("work_date DESC, created_at DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&anomalies).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(anomalies, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttendanceCorrectionRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, correction entities.AttendanceCorrection) (entities.AttendanceCorrection, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error)
+	FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.AttendanceCorrection], error)
+	FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.AttendanceCorrection], error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceCorrection, error)
+	HasPending(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (bool, error)
+	Update(ctx context.Context, tx *gorm.DB, correction entities.AttendanceCorrection) (entities.AttendanceCorrection, error)
+	Apply(ctx context.Context, correction *entities.AttendanceCorrection, attendance *entities.Attendance, audit entities.AuditLog) error
+}
+
+type attendanceCorrectionRepository struct {
+	db *gorm.DB
+}
+
+func NewAttendanceCorrectionRepository(db *gorm.DB) AttendanceCorrectionRepository {
+	return &attendanceCorrectionRepository{
+		db: db,
+	}
+}
+
+func (r *attendanceCorrectionRepository) Create(ctx context.Context, tx *gorm.DB, correction entities.AttendanceCorrection) (entities.AttendanceCorrection, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&correction).Error; err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	return correction, nil
+}
+
+func (r *attendanceCorrectionRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.AttendanceCorrection{}), filter)
+}
+
+func (r *attendanceCorrectionRepository) FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.AttendanceCorrection], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.AttendanceCorrection{}).Where("employee_id = ?", employeeID), filter)
+}
+
+func (r *attendanceCorrectionRepository) FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.AttendanceCorrection], error) {
+	if db == nil {
+		db = r.db
+	}
+	subordinates := db.Model(&entities.Employee{}).Select("id").Where("supervisor_id = ?", supervisorID)
+	query := db.WithContext(ctx).Model(&entities.AttendanceCorrection{}).
+		Where("status = ?", constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING).
+		Where("employee_id IN (?)", subordinates)
+	return r.paginate(query, filter)
+}
+
+func (r *attendanceCorrectionRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceCorrection, error) {
+	if db == nil {
+		db = r.db
+	}
+	var correction entities.AttendanceCorrection
+	if err := db.WithContext(ctx).Preload("Employee").Preload("Attendance").Where("id = ?", id).First(&correction).Error; err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	return correction, nil
+}
+
+func (r *attendanceCorrectionRepository) HasPending(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (bool, error) {
+	if db == nil {
+		db = r.db
+	}
+	var count int64
+	if err := db.WithContext(ctx).Model(&entities.AttendanceCorrection{}).
+		Where("employee_id = ? AND work_date = ? AND status = ?", employeeID, workDate.Format("2006-01-02"), constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *attendanceCorrectionRepository) Update(ctx context.Context, tx *gorm.DB, correction entities.AttendanceCorrection) (entities.AttendanceCorrection, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Save(&correction).Error; err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	return correction, nil
+}
+
+// Apply writes the corrected attendance with its punches, the approved
+// correction and its audit entry in one transaction. An attendance without an
+// ID is created.
+func (r *attendanceCorrectionRepository) Apply(ctx context.Context, correction *entities.AttendanceCorrection, attendance *entities.Attendance, audit entities.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if attendance.ID == uuid.Nil {
+			if err := tx.Omit(clause.Associations).Create(attendance).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Omit(clause.Associations).Save(attendance).Error; err != nil {
+			return err
+		}
+
+		for i := range attendance.Punches {
+			attendance.Punches[i].AttendanceID = attendance.ID
+			if err := tx.Save(&attendance.Punches[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		correction.AttendanceID = &attendance.ID
+		if err := tx.Omit(clause.Associations).Save(correction).Error; err != nil {
+			return err
+		}
+
+		audit.EntityID = attendance.ID
+		return tx.Create(&audit).Error
+	})
+}
+
+func (r *attendanceCorrectionRepository) paginate(query *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error) {
+	var corrections []entities.AttendanceCorrection
+	var page pagination.Page[entities.AttendanceCorrection]
+
+	paginator, err := pagination.NewPaginator(query.Preload("Employee").Order("created_at DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&corrections).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(corrections, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
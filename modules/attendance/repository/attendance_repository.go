@@ -2,21 +2,37 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrAttendanceChanged is returned by RecordPunch when another punch reached the
+// record after it was read.
+var ErrAttendanceChanged = errors.New("attendance was changed by another punch")
+
 type AttendanceRepository interface {
 	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error)
 	FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.Attendance], error)
+	FindAutoCheckouts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error)
 	FindByID(id uuid.UUID) (*entities.Attendance, error)
-	FindTodayByEmployeeID(employeeID uuid.UUID) (*entities.Attendance, error)
+	FindByEmployeeAndWorkDate(employeeID uuid.UUID, workDate time.Time) (*entities.Attendance, error)
+	FindOpenByEmployeeID(employeeID uuid.UUID, since time.Time) (*entities.Attendance, error)
+	FindEmployeeIDsByWorkDate(ctx context.Context, db *gorm.DB, workDate time.Time) ([]uuid.UUID, error)
+	FindInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time, locationID *uuid.UUID) ([]entities.Attendance, error)
+	FindPunchedInRange(ctx context.Context, db *gorm.DB, start, end time.Time) ([]entities.Attendance, error)
+	FindOpenUntil(ctx context.Context, db *gorm.DB, workDate time.Time) ([]entities.Attendance, error)
+	CloseAutomatically(ctx context.Context, tx *gorm.DB, attendance entities.Attendance, punch entities.AttendancePunch) (bool, error)
 	Create(attendance *entities.Attendance) (*entities.Attendance, error)
 	Update(attendance *entities.Attendance) (*entities.Attendance, error)
+	RecordPunch(ctx context.Context, attendance *entities.Attendance, punch entities.AttendancePunch) (*entities.Attendance, error)
+	FindPunchByClientID(ctx context.Context, db *gorm.DB, clientID uuid.UUID) (entities.AttendancePunch, error)
+	SaveWithPunches(ctx context.Context, attendance *entities.Attendance) error
 	Delete(id uuid.UUID) error
 }
 
@@ -30,22 +46,38 @@ func NewAttendanceRepository(db *gorm.DB) AttendanceRepository {
 	}
 }
 
+// punchesByTime preloads the punches of a record in the order they happened.
+func punchesByTime(db *gorm.DB) *gorm.DB {
+	return db.Order("punch_time")
+}
+
 func (r *attendanceRepository) FindByID(id uuid.UUID) (*entities.Attendance, error) {
 	var attendance entities.Attendance
-	if err := r.db.Preload("Employee").Preload("Location").Where("id = ?", id).First(&attendance).Error; err != nil {
+	if err := r.db.Preload("Employee").Preload("Location").Preload("Shift").Preload("Punches", punchesByTime).Where("id = ?", id).First(&attendance).Error; err != nil {
 		return nil, err
 	}
 	return &attendance, nil
 }
 
-func (r *attendanceRepository) FindTodayByEmployeeID(employeeID uuid.UUID) (*entities.Attendance, error) {
+func (r *attendanceRepository) FindByEmployeeAndWorkDate(employeeID uuid.UUID, workDate time.Time) (*entities.Attendance, error) {
 	var attendance entities.Attendance
-	today := time.Now().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
+	err := r.db.Preload("Employee").Preload("Location").Preload("Shift").Preload("Punches", punchesByTime).
+		Where("employee_id = ? AND work_date = ?", employeeID, workDate.Format("2006-01-02")).
+		First(&attendance).Error
 
-	err := r.db.Preload("Employee").Preload("Location").
-		Where("employee_id = ?", employeeID).
-		Where("check_in_time >= ? AND check_in_time < ?", today, tomorrow).
+	if err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
+
+// FindOpenByEmployeeID returns the most recent checked-in record without a
+// check-out whose work date is on or after since.
+func (r *attendanceRepository) FindOpenByEmployeeID(employeeID uuid.UUID, since time.Time) (*entities.Attendance, error) {
+	var attendance entities.Attendance
+	err := r.db.Preload("Employee").Preload("Location").Preload("Shift").Preload("Punches", punchesByTime).
+		Where("employee_id = ? AND check_in_time IS NOT NULL AND check_out_time IS NULL AND work_date >= ?", employeeID, since.Format("2006-01-02")).
+		Order("check_in_time DESC").
 		First(&attendance).Error
 
 	if err != nil {
@@ -54,6 +86,116 @@ func (r *attendanceRepository) FindTodayByEmployeeID(employeeID uuid.UUID) (*ent
 	return &attendance, nil
 }
 
+// FindEmployeeIDsByWorkDate lists the employees that already have a record for
+// workDate, whatever its status.
+func (r *attendanceRepository) FindEmployeeIDsByWorkDate(ctx context.Context, db *gorm.DB, workDate time.Time) ([]uuid.UUID, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var ids []uuid.UUID
+	if err := db.WithContext(ctx).Model(&entities.Attendance{}).
+		Where("work_date = ?", workDate.Format("2006-01-02")).
+		Distinct().Pluck("employee_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindInRange lists the records of the employees with a work date within
+// [start, end], optionally only those taken at one location.
+func (r *attendanceRepository) FindInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time, locationID *uuid.UUID) ([]entities.Attendance, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var attendances []entities.Attendance
+	if len(employeeIDs) == 0 {
+		return attendances, nil
+	}
+	query := db.WithContext(ctx).
+		Where("employee_id IN ?", employeeIDs).
+		Where("work_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if locationID != nil {
+		query = query.Where("location_id = ?", *locationID)
+	}
+	if err := query.Order("work_date").Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
+
+// FindPunchedInRange lists the checked-in records of every employee with a
+// work date within [start, end], with their location and punches loaded.
+func (r *attendanceRepository) FindPunchedInRange(ctx context.Context, db *gorm.DB, start, end time.Time) ([]entities.Attendance, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var attendances []entities.Attendance
+	if err := db.WithContext(ctx).Preload("Location").Preload("Punches", punchesByTime).
+		Where("check_in_time IS NOT NULL").
+		Where("work_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("work_date").Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
+
+// FindOpenUntil lists every checked-in record without a check-out whose work
+// date is on or before workDate, with the employee user loaded for notifications.
+func (r *attendanceRepository) FindOpenUntil(ctx context.Context, db *gorm.DB, workDate time.Time) ([]entities.Attendance, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var attendances []entities.Attendance
+	if err := db.WithContext(ctx).Preload("Employee.User").Preload("Location").Preload("Shift").Preload("Punches", punchesByTime).
+		Where("check_in_time IS NOT NULL AND check_out_time IS NULL AND work_date <= ?", workDate.Format("2006-01-02")).
+		Order("work_date").
+		Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+	return attendances, nil
+}
+
+// CloseAutomatically writes a system check-out and its out punch. It only
+// touches a record that is still open, so a punch that lands in the meantime
+// wins; the result reports whether the record was closed.
+func (r *attendanceRepository) CloseAutomatically(ctx context.Context, tx *gorm.DB, attendance entities.Attendance, punch entities.AttendancePunch) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	closed := false
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Attendance{}).
+			Where("id = ? AND check_out_time IS NULL", attendance.ID).
+			Updates(map[string]any{
+				"check_out_time":      attendance.CheckOutTime,
+				"status":              attendance.Status,
+				"early_leave_minutes": attendance.EarlyLeaveMinutes,
+				"worked_minutes":      attendance.WorkedMinutes,
+				"break_minutes":       attendance.BreakMinutes,
+				"auto_checkout":       true,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		closed = true
+
+		punch.AttendanceID = attendance.ID
+		return tx.Create(&punch).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return closed, nil
+}
+
 func (r *attendanceRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error) {
 	if db == nil {
 		db = r.db
@@ -96,28 +238,127 @@ func (r *attendanceRepository) FindByEmployeeID(ctx context.Context, db *gorm.DB
 	return &page, nil
 }
 
+func (r *attendanceRepository) FindAutoCheckouts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var attendances []entities.Attendance
+	var page pagination.Page[entities.Attendance]
+
+	paginator, err := pagination.NewPaginator(db.WithContext(ctx).Model(&entities.Attendance{}).Where("auto_checkout = ?", true).Preload("Employee").Preload("Location").Order("work_date DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&attendances).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(attendances, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
 func (r *attendanceRepository) Create(attendance *entities.Attendance) (*entities.Attendance, error) {
 	if err := r.db.Create(attendance).Error; err != nil {
 		return nil, err
 	}
-	// Preload the associated Employee and Location after creation
-	if err := r.db.Preload("Employee").Preload("Location").First(&attendance, "id = ?", attendance.ID).Error; err != nil {
+	// Preload the associated Employee, Location and punches after creation
+	if err := r.db.Preload("Employee").Preload("Location").Preload("Punches", punchesByTime).First(&attendance, "id = ?", attendance.ID).Error; err != nil {
 		return nil, err
 	}
 	return attendance, nil
 }
 
 func (r *attendanceRepository) Update(attendance *entities.Attendance) (*entities.Attendance, error) {
-	if err := r.db.Save(attendance).Error; err != nil {
+	if err := r.db.Omit("Punches").Save(attendance).Error; err != nil {
 		return nil, err
 	}
 	// Preload the associated Employee and Location after update
-	if err := r.db.Preload("Employee").Preload("Location").First(&attendance, "id = ?", attendance.ID).Error; err != nil {
+	if err := r.db.Preload("Employee").Preload("Location").Preload("Punches", punchesByTime).First(&attendance, "id = ?", attendance.ID).Error; err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// RecordPunch stores a new punch together with the attendance totals it
+// changes, then reloads the record. The record is locked first and must still
+// hold the punches it was read with, so of two punches racing on the same
+// record only the first lands; the other gets ErrAttendanceChanged.
+func (r *attendanceRepository) RecordPunch(ctx context.Context, attendance *entities.Attendance, punch entities.AttendancePunch) (*entities.Attendance, error) {
+	var known int64
+	for _, p := range attendance.Punches {
+		if p.ID != uuid.Nil {
+			known++
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked entities.Attendance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&locked, "id = ?", attendance.ID).Error; err != nil {
+			return err
+		}
+
+		var stored int64
+		if err := tx.Model(&entities.AttendancePunch{}).Where("attendance_id = ?", attendance.ID).Count(&stored).Error; err != nil {
+			return err
+		}
+		if stored != known {
+			return ErrAttendanceChanged
+		}
+
+		if err := tx.Omit(clause.Associations).Save(attendance).Error; err != nil {
+			return err
+		}
+		punch.AttendanceID = attendance.ID
+		return tx.Create(&punch).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Employee").Preload("Location").Preload("Punches", punchesByTime).First(&attendance, "id = ?", attendance.ID).Error; err != nil {
 		return nil, err
 	}
 	return attendance, nil
 }
 
+// SaveWithPunches creates or updates the attendance together with every punch
+// it holds, new or changed.
+func (r *attendanceRepository) SaveWithPunches(ctx context.Context, attendance *entities.Attendance) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if attendance.ID == uuid.Nil {
+			if err := tx.Omit(clause.Associations).Create(attendance).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Omit(clause.Associations).Save(attendance).Error; err != nil {
+			return err
+		}
+
+		for i := range attendance.Punches {
+			attendance.Punches[i].AttendanceID = attendance.ID
+			if err := tx.Save(&attendance.Punches[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindPunchByClientID returns the punch a device uploaded under clientID.
+func (r *attendanceRepository) FindPunchByClientID(ctx context.Context, db *gorm.DB, clientID uuid.UUID) (entities.AttendancePunch, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var punch entities.AttendancePunch
+	if err := db.WithContext(ctx).Where("client_id = ?", clientID).First(&punch).Error; err != nil {
+		return entities.AttendancePunch{}, err
+	}
+	return punch, nil
+}
+
 func (r *attendanceRepository) Delete(id uuid.UUID) error {
 	if err := r.db.Delete(&entities.Attendance{}, "id = ?", id).Error; err != nil {
 		return err
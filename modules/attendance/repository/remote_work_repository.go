@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RemoteWorkRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error)
+	FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.RemoteWorkRequest], error)
+	FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.RemoteWorkRequest], error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.RemoteWorkRequest, error)
+	HasOverlap(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) (bool, error)
+	FindApprovedOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (entities.RemoteWorkRequest, error)
+	Update(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error)
+}
+
+type remoteWorkRepository struct {
+	db *gorm.DB
+}
+
+func NewRemoteWorkRepository(db *gorm.DB) RemoteWorkRepository {
+	return &remoteWorkRepository{
+		db: db,
+	}
+}
+
+func (r *remoteWorkRepository) Create(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Create(&request).Error; err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	return request, nil
+}
+
+func (r *remoteWorkRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.RemoteWorkRequest{}), filter)
+}
+
+func (r *remoteWorkRepository) FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.RemoteWorkRequest{}).Where("employee_id = ?", employeeID), filter)
+}
+
+func (r *remoteWorkRepository) FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	subordinates := db.Model(&entities.Employee{}).Select("id").Where("supervisor_id = ?", supervisorID)
+	query := db.WithContext(ctx).Model(&entities.RemoteWorkRequest{}).
+		Where("status = ?", constants.ENUM_REMOTE_WORK_STATUS_PENDING).
+		Where("employee_id IN (?)", subordinates)
+	return r.paginate(query, filter)
+}
+
+func (r *remoteWorkRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.RemoteWorkRequest, error) {
+	if db == nil {
+		db = r.db
+	}
+	var request entities.RemoteWorkRequest
+	if err := db.WithContext(ctx).Preload("Employee").Where("id = ?", id).First(&request).Error; err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	return request, nil
+}
+
+// HasOverlap reports whether a pending or approved request of the employee
+// covers any date from start to end.
+func (r *remoteWorkRepository) HasOverlap(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) (bool, error) {
+	if db == nil {
+		db = r.db
+	}
+	var count int64
+	if err := db.WithContext(ctx).Model(&entities.RemoteWorkRequest{}).
+		Where("employee_id = ? AND status IN ?", employeeID, []string{constants.ENUM_REMOTE_WORK_STATUS_PENDING, constants.ENUM_REMOTE_WORK_STATUS_APPROVED}).
+		Where("start_date <= ? AND end_date >= ?", end.Format("2006-01-02"), start.Format("2006-01-02")).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindApprovedOn returns the approved request of the employee that covers the
+// work date.
+func (r *remoteWorkRepository) FindApprovedOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (entities.RemoteWorkRequest, error) {
+	if db == nil {
+		db = r.db
+	}
+	var request entities.RemoteWorkRequest
+	date := workDate.Format("2006-01-02")
+	if err := db.WithContext(ctx).
+		Where("employee_id = ? AND status = ?", employeeID, constants.ENUM_REMOTE_WORK_STATUS_APPROVED).
+		Where("start_date <= ? AND end_date >= ?", date, date).
+		First(&request).Error; err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	return request, nil
+}
+
+func (r *remoteWorkRepository) Update(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Save(&request).Error; err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	return request, nil
+}
+
+func (r *remoteWorkRepository) paginate(query *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	var requests []entities.RemoteWorkRequest
+	var page pagination.Page[entities.RemoteWorkRequest]
+
+	paginator, err := pagination.NewPaginator(query.Preload("Employee").Order("created_at DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(requests, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/controller"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do"
@@ -11,17 +12,56 @@ import (
 
 func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 	attendanceController := do.MustInvoke[controller.AttendanceController](injector)
+	correctionController := do.MustInvoke[controller.AttendanceCorrectionController](injector)
+	reportController := do.MustInvoke[controller.AttendanceReportController](injector)
+	anomalyController := do.MustInvoke[controller.AttendanceAnomalyController](injector)
+	remoteWorkController := do.MustInvoke[controller.RemoteWorkController](injector)
 	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
 
 	attendanceRoutes := server.Group("/api/attendances")
 	attendanceRoutes.Use(middlewares.Authenticate(jwtService))
 	{
 		attendanceRoutes.GET("", middlewares.Authenticate(jwtService), attendanceController.GetAll)
+		attendanceRoutes.GET("/auto-checkouts", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_REPORT), attendanceController.GetAutoCheckouts)
+		attendanceRoutes.GET("/reports/summary", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_REPORT), reportController.Summary)
+		attendanceRoutes.GET("/anomalies", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_ANOMALIES), anomalyController.GetAll)
+		attendanceRoutes.POST("/anomalies/scan", middlewares.Authorize(rbacService, constants.PERMISSION_SCAN_ATTENDANCE_ANOMALIES), anomalyController.Scan)
+		attendanceRoutes.GET("/anomalies/reviews", anomalyController.GetPendingReviews)
+		attendanceRoutes.GET("/anomalies/:id", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_ANOMALIES), anomalyController.GetByID)
+		attendanceRoutes.POST("/anomalies/:id/confirm", anomalyController.Confirm)
+		attendanceRoutes.POST("/anomalies/:id/dismiss", anomalyController.Dismiss)
+		attendanceRoutes.GET("/corrections", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_CORRECTIONS), correctionController.GetAll)
+		attendanceRoutes.GET("/corrections/me", correctionController.GetMine)
+		attendanceRoutes.GET("/corrections/approvals", correctionController.GetPendingApprovals)
+		attendanceRoutes.GET("/corrections/:id", correctionController.GetByID)
+		attendanceRoutes.POST("/corrections", correctionController.Submit)
+		attendanceRoutes.POST("/corrections/:id/approve", correctionController.Approve)
+		attendanceRoutes.POST("/corrections/:id/reject", correctionController.Reject)
+		attendanceRoutes.GET("/remote-work", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_REMOTE_WORK), remoteWorkController.GetAll)
+		attendanceRoutes.GET("/remote-work/me", remoteWorkController.GetMine)
+		attendanceRoutes.GET("/remote-work/approvals", remoteWorkController.GetPendingApprovals)
+		attendanceRoutes.GET("/remote-work/:id", remoteWorkController.GetByID)
+		attendanceRoutes.POST("/remote-work", remoteWorkController.Submit)
+		attendanceRoutes.POST("/remote-work/:id/approve", remoteWorkController.Approve)
+		attendanceRoutes.POST("/remote-work/:id/reject", remoteWorkController.Reject)
 		attendanceRoutes.GET("/:id", middlewares.Authenticate(jwtService), attendanceController.GetByID)
-		attendanceRoutes.POST("/check-in", middlewares.Authenticate(jwtService), attendanceController.CheckIn)
-		attendanceRoutes.PUT("/check-out", middlewares.Authenticate(jwtService), attendanceController.CheckOut)
-		attendanceRoutes.PUT("/:id", middlewares.Authenticate(jwtService), attendanceController.Update)
-		attendanceRoutes.PUT("/:id/approve", middlewares.Authenticate(jwtService), attendanceController.Approve)
+		attendanceRoutes.GET("/:id/photos/:punch", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_ATTENDANCE_PHOTOS), attendanceController.GetPhoto)
+		attendanceRoutes.POST("/check-in", middlewares.Authorize(rbacService, constants.PERMISSION_PUNCH_FOR_OTHERS), attendanceController.CheckIn)
+		attendanceRoutes.PUT("/check-out", middlewares.Authorize(rbacService, constants.PERMISSION_PUNCH_FOR_OTHERS), attendanceController.CheckOut)
+		attendanceRoutes.POST("/break-start", middlewares.Authorize(rbacService, constants.PERMISSION_PUNCH_FOR_OTHERS), attendanceController.StartBreak)
+		attendanceRoutes.POST("/break-end", middlewares.Authorize(rbacService, constants.PERMISSION_PUNCH_FOR_OTHERS), attendanceController.EndBreak)
+		attendanceRoutes.POST("/sync", middlewares.Authorize(rbacService, constants.PERMISSION_PUNCH_FOR_OTHERS), attendanceController.Sync)
+		attendanceRoutes.POST("/imports/attlog", middlewares.Authorize(rbacService, constants.PERMISSION_IMPORT_ATTENDANCE), attendanceController.ImportAttlog)
+
+		// Self-service, the employee comes from the login
+		attendanceRoutes.POST("/me/check-in", attendanceController.MyCheckIn)
+		attendanceRoutes.PUT("/me/check-out", attendanceController.MyCheckOut)
+		attendanceRoutes.POST("/me/break-start", attendanceController.MyStartBreak)
+		attendanceRoutes.POST("/me/break-end", attendanceController.MyEndBreak)
+		attendanceRoutes.POST("/me/sync", attendanceController.MySync)
+		attendanceRoutes.GET("/me/today", attendanceController.MyToday)
+		attendanceRoutes.GET("/me/history", attendanceController.MyHistory)
 		attendanceRoutes.DELETE("/:id", middlewares.Authenticate(jwtService), attendanceController.Delete)
 		attendanceRoutes.GET("/employee/:employee_id", middlewares.Authenticate(jwtService), attendanceController.GetByEmployeeID)
 	}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AbsenceService interface {
+	DetectAbsences(ctx context.Context, workDate time.Time) (dto.AbsenceDetectionResult, error)
+}
+
+type absenceService struct {
+	attendanceRepository repository.AttendanceRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	leaveRepository      leaveRepository.LeaveRepository
+	masterRepository     masterRepository.MasterRepository
+	shiftService         shiftService.ShiftService
+	notificationService  notificationService.NotificationService
+	db                   *gorm.DB
+}
+
+func NewAbsenceService(
+	attendanceRepo repository.AttendanceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	leaveRepo leaveRepository.LeaveRepository,
+	masterRepo masterRepository.MasterRepository,
+	shiftSvc shiftService.ShiftService,
+	notificationSvc notificationService.NotificationService,
+	db *gorm.DB,
+) AbsenceService {
+	return &absenceService{
+		attendanceRepository: attendanceRepo,
+		employeeRepository:   employeeRepo,
+		leaveRepository:      leaveRepo,
+		masterRepository:     masterRepo,
+		shiftService:         shiftSvc,
+		notificationService:  notificationSvc,
+		db:                   db,
+	}
+}
+
+// DetectAbsences records an "absent" attendance for every active employee who
+// was expected at work on workDate but has no record and no approved leave.
+// Company holidays and the regional ones of an employee's location are days
+// off. Employees whose shift has not ended yet are left for a later run, and
+// existing records are never touched, so the pass can be repeated.
+func (s *absenceService) DetectAbsences(ctx context.Context, workDate time.Time) (dto.AbsenceDetectionResult, error) {
+	y, m, d := workDate.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, helpers.LoadTimezone(""))
+	date := helpers.DateOf(day)
+	result := dto.AbsenceDetectionResult{WorkDate: date.Format("2006-01-02")}
+
+	holiday, err := s.masterRepository.IsHoliday(ctx, nil, date, nil)
+	if err != nil {
+		return result, err
+	}
+	if holiday {
+		result.Holiday = true
+		return result, nil
+	}
+
+	employees, err := s.employeeRepository.FindActive(ctx, nil, date)
+	if err != nil {
+		return result, err
+	}
+
+	skip := map[uuid.UUID]bool{}
+	onLeave, err := s.leaveRepository.FindEmployeeIDsOnLeave(ctx, nil, date)
+	if err != nil {
+		return result, err
+	}
+	recorded, err := s.attendanceRepository.FindEmployeeIDsByWorkDate(ctx, nil, date)
+	if err != nil {
+		return result, err
+	}
+	for _, id := range append(onLeave, recorded...) {
+		skip[id] = true
+	}
+
+	now := time.Now()
+	absentBySupervisor := map[uuid.UUID][]entities.Employee{}
+	supervisors := map[uuid.UUID]*entities.Employee{}
+	regionalHolidays := map[uuid.UUID]bool{}
+	for _, employee := range employees {
+		if skip[employee.ID] {
+			continue
+		}
+
+		if employee.LocationID != nil {
+			regional, ok := regionalHolidays[*employee.LocationID]
+			if !ok {
+				if regional, err = s.masterRepository.IsHoliday(ctx, nil, date, employee.LocationID); err != nil {
+					return result, err
+				}
+				regionalHolidays[*employee.LocationID] = regional
+			}
+			if regional {
+				continue
+			}
+		}
+
+		working, shift, err := s.shiftService.ScheduleOn(ctx, employee.ID, day)
+		if err != nil {
+			return result, err
+		}
+		if !working {
+			continue
+		}
+
+		absence := &entities.Attendance{
+			EmployeeID: employee.ID,
+			WorkDate:   date,
+			Status:     constants.ENUM_ATTENDANCE_STATUS_ABSENT,
+		}
+		dayEnd := day.AddDate(0, 0, 1)
+		if shift != nil {
+			start, end, err := shiftService.ShiftWindow(*shift, day)
+			if err != nil {
+				return result, err
+			}
+			absence.ShiftID = &shift.ID
+			absence.ScheduledStart = &start
+			absence.ScheduledEnd = &end
+			dayEnd = end
+		}
+		if now.Before(dayEnd) {
+			result.Pending++
+			continue
+		}
+
+		if _, err := s.attendanceRepository.Create(absence); errors.Is(err, gorm.ErrDuplicatedKey) {
+			// Checked in while the day was being closed
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		result.Marked++
+
+		if employee.Supervisor != nil {
+			absentBySupervisor[employee.Supervisor.ID] = append(absentBySupervisor[employee.Supervisor.ID], employee)
+			supervisors[employee.Supervisor.ID] = employee.Supervisor
+		}
+	}
+
+	for supervisorID, absentees := range absentBySupervisor {
+		s.notifySupervisor(ctx, *supervisors[supervisorID], result.WorkDate, absentees)
+	}
+
+	return result, nil
+}
+
+// notifySupervisor sends one digest per supervisor. The absences are already
+// stored, so a failed notification is logged rather than failing the run.
+func (s *absenceService) notifySupervisor(ctx context.Context, supervisor entities.Employee, workDate string, absentees []entities.Employee) {
+	names := make([]string, 0, len(absentees))
+	ids := make([]uuid.UUID, 0, len(absentees))
+	for _, employee := range absentees {
+		name := employee.User.Name
+		if name == "" {
+			name = employee.EmployeeCode
+		}
+		names = append(names, name)
+		ids = append(ids, employee.ID)
+	}
+
+	title := fmt.Sprintf("Absences on %s", workDate)
+	body := fmt.Sprintf("%d of your team members did not record attendance on %s: %s.", len(absentees), workDate, strings.Join(names, ", "))
+	data := map[string]any{"work_date": workDate, "employee_ids": ids}
+
+	if _, err := s.notificationService.Notify(ctx, supervisor.User, constants.ENUM_NOTIFICATION_TYPE_ABSENCE, title, body, data); err != nil {
+		log.Printf("absence detection %s: failed to notify supervisor %s: %v", workDate, supervisor.ID, err)
+	}
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttendanceAnomalyService interface {
+	Detect(ctx context.Context, from, to time.Time) (dto.AnomalyDetectionResult, error)
+	Scan(ctx context.Context, req dto.AnomalyScanDTO) (dto.AnomalyDetectionResult, error)
+	FindAll(ctx context.Context, filter *pagination.Filter, status string) (*pagination.Page[entities.AttendanceAnomaly], error)
+	FindPendingReviews(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceAnomaly], error)
+	GetByID(ctx context.Context, id uuid.UUID) (entities.AttendanceAnomaly, error)
+	Confirm(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceAnomaly, error)
+	Dismiss(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceAnomaly, error)
+}
+
+type attendanceAnomalyService struct {
+	anomalyRepository    repository.AttendanceAnomalyRepository
+	attendanceRepository repository.AttendanceRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	masterRepository     masterRepository.MasterRepository
+	db                   *gorm.DB
+}
+
+func NewAttendanceAnomalyService(
+	anomalyRepo repository.AttendanceAnomalyRepository,
+	attendanceRepo repository.AttendanceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	masterRepo masterRepository.MasterRepository,
+	db *gorm.DB,
+) AttendanceAnomalyService {
+	return &attendanceAnomalyService{
+		anomalyRepository:    anomalyRepo,
+		attendanceRepository: attendanceRepo,
+		employeeRepository:   employeeRepo,
+		masterRepository:     masterRepo,
+		db:                   db,
+	}
+}
+
+// punchTrace is a punch with what the anomaly rules need from its record.
+// position is the device fix when one was sent, otherwise the centre of the
+// location punched at; gps tells the two apart.
+type punchTrace struct {
+	punch          entities.AttendancePunch
+	employeeID     uuid.UUID
+	attendanceID   uuid.UUID
+	workDate       time.Time
+	position       *helpers.GeoPoint
+	gps            bool
+	loc            *time.Location
+	checkIn        bool
+	scheduledStart *time.Time
+}
+
+// Detect runs the anomaly rules over the punches of the work dates from..to
+// and stores a flag for every suspicious one. Patterns and typical hours are
+// read from the history before from as well, but only punches within the
+// range are flagged. Punches flagged by an earlier pass are left alone, so a
+// range can be scanned again.
+func (s *attendanceAnomalyService) Detect(ctx context.Context, from, to time.Time) (dto.AnomalyDetectionResult, error) {
+	from, to = helpers.DateOf(from), helpers.DateOf(to)
+	result := dto.AnomalyDetectionResult{
+		From:  from.Format("2006-01-02"),
+		To:    to.Format("2006-01-02"),
+		Rules: map[string]int{},
+	}
+
+	attendances, err := s.attendanceRepository.FindPunchedInRange(ctx, nil, from.AddDate(0, 0, -constants.ANOMALY_HISTORY_DAYS), to)
+	if err != nil {
+		return result, err
+	}
+	traces, err := s.tracePunches(ctx, attendances)
+	if err != nil {
+		return result, err
+	}
+
+	var found []entities.AttendanceAnomaly
+	found = append(found, impossibleTravel(traces)...)
+	found = append(found, repeatedCoordinates(traces)...)
+	found = append(found, unusualHours(traces)...)
+	found = append(found, buddyPunching(traces)...)
+
+	for _, trace := range traces {
+		if !trace.workDate.Before(from) {
+			result.Punches++
+		}
+	}
+
+	var anomalies []entities.AttendanceAnomaly
+	seen := map[string]bool{}
+	for _, anomaly := range found {
+		key := anomaly.Rule + "|" + anomaly.PunchID.String()
+		if anomaly.WorkDate.Before(from) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		anomaly.Status = constants.ENUM_ANOMALY_STATUS_OPEN
+		anomalies = append(anomalies, anomaly)
+		result.Rules[anomaly.Rule]++
+	}
+	result.Found = len(anomalies)
+
+	result.Created, err = s.anomalyRepository.CreateMany(ctx, nil, anomalies)
+	return result, err
+}
+
+func (s *attendanceAnomalyService) Scan(ctx context.Context, req dto.AnomalyScanDTO) (dto.AnomalyDetectionResult, error) {
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return dto.AnomalyDetectionResult{}, dto.ErrReportDate
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		return dto.AnomalyDetectionResult{}, dto.ErrReportDate
+	}
+	if to.Before(from) || to.Sub(from) >= constants.ANOMALY_SCAN_MAX_DAYS*24*time.Hour {
+		return dto.AnomalyDetectionResult{}, dto.ErrAnomalyRange
+	}
+	return s.Detect(ctx, from, to)
+}
+
+// tracePunches flattens the records into their punches, ordered by employee
+// and time.
+func (s *attendanceAnomalyService) tracePunches(ctx context.Context, attendances []entities.Attendance) ([]punchTrace, error) {
+	locations := map[uuid.UUID]entities.Location{}
+	for _, attendance := range attendances {
+		if attendance.LocationID != nil {
+			locations[*attendance.LocationID] = attendance.Location
+		}
+	}
+
+	var traces []punchTrace
+	for _, attendance := range attendances {
+		checkIn := true
+		for _, punch := range attendance.Punches {
+			trace := punchTrace{
+				punch:          punch,
+				employeeID:     attendance.EmployeeID,
+				attendanceID:   attendance.ID,
+				workDate:       helpers.DateOf(attendance.WorkDate),
+				loc:            helpers.LoadTimezone(attendance.Location.Timezone),
+				scheduledStart: attendance.ScheduledStart,
+			}
+			if checkIn && punch.Type == constants.ENUM_PUNCH_TYPE_IN {
+				trace.checkIn = true
+				checkIn = false
+			}
+
+			if punch.Latitude != nil && punch.Longitude != nil {
+				trace.position = &helpers.GeoPoint{Latitude: *punch.Latitude, Longitude: *punch.Longitude}
+				trace.gps = true
+			} else if locationID := punch.LocationID; locationID != nil || attendance.LocationID != nil {
+				if locationID == nil {
+					locationID = attendance.LocationID
+				}
+				location, ok := locations[*locationID]
+				if !ok {
+					var err error
+					location, err = s.masterRepository.GetLocationByID(ctx, nil, *locationID)
+					if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, err
+					}
+					locations[*locationID] = location
+				}
+				if location.ID != uuid.Nil {
+					trace.position = &helpers.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude}
+				}
+			}
+			traces = append(traces, trace)
+		}
+	}
+
+	sort.SliceStable(traces, func(i, j int) bool {
+		if traces[i].employeeID != traces[j].employeeID {
+			return traces[i].employeeID.String() < traces[j].employeeID.String()
+		}
+		return traces[i].punch.PunchTime.Before(traces[j].punch.PunchTime)
+	})
+	return traces, nil
+}
+
+func anomalyOn(trace punchTrace, rule string, detail string) entities.AttendanceAnomaly {
+	return entities.AttendanceAnomaly{
+		EmployeeID:   trace.employeeID,
+		AttendanceID: trace.attendanceID,
+		PunchID:      trace.punch.ID,
+		WorkDate:     trace.workDate,
+		Rule:         rule,
+		Detail:       detail,
+	}
+}
+
+// impossibleTravel flags a punch made further from the employee's previous
+// punch than can be covered in the time between them.
+func impossibleTravel(traces []punchTrace) []entities.AttendanceAnomaly {
+	var anomalies []entities.AttendanceAnomaly
+	var previous *punchTrace
+	for i := range traces {
+		trace := &traces[i]
+		if trace.position == nil {
+			continue
+		}
+		if previous == nil || previous.employeeID != trace.employeeID {
+			previous = trace
+			continue
+		}
+
+		meters := helpers.HaversineDistance(*previous.position, *trace.position)
+		if meters >= constants.ANOMALY_MIN_TRAVEL_METERS {
+			elapsed := trace.punch.PunchTime.Sub(previous.punch.PunchTime)
+			speed := math.Inf(1)
+			if elapsed > 0 {
+				speed = meters / 1000 / elapsed.Hours()
+			}
+			if speed > constants.ANOMALY_MAX_TRAVEL_SPEED_KMH {
+				anomalies = append(anomalies, anomalyOn(*trace, constants.ENUM_ANOMALY_RULE_IMPOSSIBLE_TRAVEL, fmt.Sprintf(
+					"%.1f km from the punch at %s, %s earlier",
+					meters/1000, previous.punch.PunchTime.In(previous.loc).Format("2006-01-02 15:04"), elapsed.Round(time.Minute),
+				)))
+			}
+		}
+		previous = trace
+	}
+	return anomalies
+}
+
+// repeatedCoordinates flags device fixes that repeat to the last digit on
+// several work dates, which real GPS fixes do not.
+func repeatedCoordinates(traces []punchTrace) []entities.AttendanceAnomaly {
+	type position struct {
+		employeeID uuid.UUID
+		key        string
+	}
+	days := map[position]map[time.Time]bool{}
+	for _, trace := range traces {
+		if !trace.gps {
+			continue
+		}
+		at := position{trace.employeeID, coordinateKey(*trace.position)}
+		if days[at] == nil {
+			days[at] = map[time.Time]bool{}
+		}
+		days[at][trace.workDate] = true
+	}
+
+	var anomalies []entities.AttendanceAnomaly
+	for _, trace := range traces {
+		if !trace.gps {
+			continue
+		}
+		repeats := len(days[position{trace.employeeID, coordinateKey(*trace.position)}])
+		if repeats >= constants.ANOMALY_REPEATED_COORDINATE_DAYS {
+			anomalies = append(anomalies, anomalyOn(trace, constants.ENUM_ANOMALY_RULE_REPEATED_COORDINATES, fmt.Sprintf(
+				"position %s reported on %d work dates",
+				coordinateKey(*trace.position), repeats,
+			)))
+		}
+	}
+	return anomalies
+}
+
+func coordinateKey(p helpers.GeoPoint) string {
+	return strconv.FormatFloat(p.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(p.Longitude, 'f', -1, 64)
+}
+
+// unusualHours flags a check-in far from when the employee starts work: the
+// shift start when the record has one, otherwise the employee's median
+// check-in over the history before the work date.
+func unusualHours(traces []punchTrace) []entities.AttendanceAnomaly {
+	var anomalies []entities.AttendanceAnomaly
+	for start := 0; start < len(traces); {
+		end := start + 1
+		for end < len(traces) && traces[end].employeeID == traces[start].employeeID {
+			end++
+		}
+		anomalies = append(anomalies, unusualCheckIns(traces[start:end])...)
+		start = end
+	}
+	return anomalies
+}
+
+// unusualCheckIns applies unusualHours to the time ordered punches of one
+// employee.
+func unusualCheckIns(traces []punchTrace) []entities.AttendanceAnomaly {
+	var anomalies []entities.AttendanceAnomaly
+	for _, trace := range traces {
+		if !trace.checkIn {
+			continue
+		}
+
+		checkIn := minuteOfDay(trace.punch.PunchTime.In(trace.loc))
+		var usual int
+		var reference string
+		if trace.scheduledStart != nil {
+			usual = minuteOfDay(trace.scheduledStart.In(trace.loc))
+			reference = "shift starts"
+		} else {
+			var samples []int
+			since := trace.workDate.AddDate(0, 0, -constants.ANOMALY_HISTORY_DAYS)
+			for _, past := range traces {
+				if past.checkIn && past.workDate.Before(trace.workDate) && !past.workDate.Before(since) {
+					samples = append(samples, minuteOfDay(past.punch.PunchTime.In(past.loc)))
+				}
+			}
+			if len(samples) < constants.ANOMALY_TYPICAL_HOURS_MIN_SAMPLES {
+				continue
+			}
+			sort.Ints(samples)
+			usual = samples[len(samples)/2]
+			reference = "usual check-in"
+		}
+
+		gap := checkIn - usual
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > 12*60 {
+			gap = 24*60 - gap
+		}
+		if gap > constants.ANOMALY_UNUSUAL_HOURS_MINUTES {
+			anomalies = append(anomalies, anomalyOn(trace, constants.ENUM_ANOMALY_RULE_UNUSUAL_HOURS, fmt.Sprintf(
+				"checked in at %s, %s %02d:%02d",
+				trace.punch.PunchTime.In(trace.loc).Format("15:04"), reference, usual/60, usual%60,
+			)))
+		}
+	}
+	return anomalies
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// buddyPunching flags punches two employees keep making on the same device
+// moments apart, as when one punches for both. Fingerprint terminal scans are
+// left out, a finger cannot be lent.
+func buddyPunching(traces []punchTrace) []entities.AttendanceAnomaly {
+	byDevice := map[string][]punchTrace{}
+	for _, trace := range traces {
+		if trace.punch.DeviceID != "" && trace.punch.TerminalStatus == nil {
+			byDevice[trace.punch.DeviceID] = append(byDevice[trace.punch.DeviceID], trace)
+		}
+	}
+
+	type pair struct {
+		first, second uuid.UUID
+	}
+	type match struct {
+		trace, other punchTrace
+	}
+	days := map[pair]map[time.Time]bool{}
+	matches := map[pair][]match{}
+	var order []pair
+
+	window := constants.ANOMALY_BUDDY_WINDOW_SECONDS * time.Second
+	for _, punches := range byDevice {
+		sort.SliceStable(punches, func(i, j int) bool {
+			return punches[i].punch.PunchTime.Before(punches[j].punch.PunchTime)
+		})
+		for i := range punches {
+			for j := i + 1; j < len(punches) && punches[j].punch.PunchTime.Sub(punches[i].punch.PunchTime) <= window; j++ {
+				a, b := punches[i], punches[j]
+				if a.employeeID == b.employeeID {
+					continue
+				}
+				key := pair{a.employeeID, b.employeeID}
+				if key.first.String() > key.second.String() {
+					key = pair{b.employeeID, a.employeeID}
+				}
+				if days[key] == nil {
+					days[key] = map[time.Time]bool{}
+					order = append(order, key)
+				}
+				days[key][a.workDate] = true
+				matches[key] = append(matches[key], match{a, b}, match{b, a})
+			}
+		}
+	}
+
+	var anomalies []entities.AttendanceAnomaly
+	for _, key := range order {
+		if len(days[key]) < constants.ANOMALY_BUDDY_MIN_DAYS {
+			continue
+		}
+		for _, m := range matches[key] {
+			anomaly := anomalyOn(m.trace, constants.ENUM_ANOMALY_RULE_BUDDY_PUNCHING, fmt.Sprintf(
+				"punched on device %s within %s of the same colleague on %d work dates",
+				m.trace.punch.DeviceID, window, len(days[key]),
+			))
+			related := m.other.employeeID
+			anomaly.RelatedEmployeeID = &related
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies
+}
+
+func (s *attendanceAnomalyService) FindAll(ctx context.Context, filter *pagination.Filter, status string) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	return s.anomalyRepository.FindAll(ctx, nil, filter, status)
+}
+
+func (s *attendanceAnomalyService) FindPendingReviews(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	supervisor, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.anomalyRepository.FindOpenBySupervisorID(ctx, nil, filter, supervisor.ID)
+}
+
+func (s *attendanceAnomalyService) GetByID(ctx context.Context, id uuid.UUID) (entities.AttendanceAnomaly, error) {
+	return s.anomalyRepository.GetByID(ctx, nil, id)
+}
+
+// Confirm marks the flag as a real irregularity, to be followed up outside
+// the attendance record.
+func (s *attendanceAnomalyService) Confirm(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceAnomaly, error) {
+	return s.review(ctx, id, userID, note, constants.ENUM_ANOMALY_STATUS_CONFIRMED)
+}
+
+// Dismiss closes the flag as a false alarm.
+func (s *attendanceAnomalyService) Dismiss(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceAnomaly, error) {
+	return s.review(ctx, id, userID, note, constants.ENUM_ANOMALY_STATUS_DISMISSED)
+}
+
+// review closes an open flag for userID, who must be the supervisor of the
+// flagged employee. The reviewer is checked before the status so nobody else
+// learns whether a flag is still open.
+func (s *attendanceAnomalyService) review(ctx context.Context, id uuid.UUID, userID string, note string, status string) (entities.AttendanceAnomaly, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.AttendanceAnomaly{}, err
+	}
+
+	anomaly, err := s.anomalyRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.AttendanceAnomaly{}, err
+	}
+	if anomaly.Employee.SupervisorID == nil || *anomaly.Employee.SupervisorID != reviewer.ID {
+		return entities.AttendanceAnomaly{}, dto.ErrNotSupervisor
+	}
+	if anomaly.Status != constants.ENUM_ANOMALY_STATUS_OPEN {
+		return entities.AttendanceAnomaly{}, dto.ErrAnomalyNotOpen
+	}
+
+	now := time.Now()
+	anomaly.Status = status
+	anomaly.ReviewerID = &reviewer.ID
+	anomaly.ReviewedAt = &now
+	anomaly.ReviewNote = note
+
+	return s.anomalyRepository.Update(ctx, nil, anomaly)
+}
+
+func (s *attendanceAnomalyService) employeeByUserID(ctx context.Context, userID string) (entities.Employee, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+	return employee, err
+}
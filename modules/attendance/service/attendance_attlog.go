@@ -0,0 +1,326 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// attlogNamespace derives the client id of a terminal scan from the location,
+// PIN and scan time, so the same scan imported twice, from a USB export or
+// pushed by the terminal, is stored once.
+var attlogNamespace = uuid.MustParse("5d0f6a3c-8e21-4b7a-9c54-2f1e8d7b6a90")
+
+// Columns of a ZKTeco attlog line exported to USB: PIN, scan time, machine
+// number, state key, verify mode and work code. Pushed lines leave out the
+// machine number, which moves the state key one column left.
+const (
+	attlogPINField        = 0
+	attlogTimeField       = 1
+	attlogStatusField     = 3
+	attlogPushStatusField = 2
+)
+
+// attlogRecord is one scan read from a terminal log.
+type attlogRecord struct {
+	line   int
+	pin    string
+	at     string
+	status int
+}
+
+// parseAttlog reads the tab separated scans of a terminal log. statusField is
+// the column of the state key, which differs between USB exports and pushes;
+// lines without it count as check-in key scans.
+func parseAttlog(r io.Reader, statusField int) ([]attlogRecord, []dto.AttlogInvalidLine, int, error) {
+	var records []attlogRecord
+	var invalid []dto.AttlogInvalidLine
+
+	scanner := bufio.NewScanner(r)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) <= attlogTimeField || fields[attlogPINField] == "" {
+			invalid = append(invalid, dto.AttlogInvalidLine{Line: lines, Error: "expected a PIN and a scan time separated by a tab"})
+			continue
+		}
+		if _, err := time.Parse(time.DateTime, fields[attlogTimeField]); err != nil {
+			invalid = append(invalid, dto.AttlogInvalidLine{Line: lines, Error: fmt.Sprintf("scan time %q is not YYYY-MM-DD HH:MM:SS", fields[attlogTimeField])})
+			continue
+		}
+
+		record := attlogRecord{line: lines, pin: fields[attlogPINField], at: fields[attlogTimeField]}
+		if len(fields) > statusField {
+			if status, err := strconv.Atoi(fields[statusField]); err == nil {
+				record.status = status
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, lines, err
+	}
+	return records, invalid, lines, nil
+}
+
+// ImportAttlog folds the scans of a ZKTeco attendance log into attendance
+// records of the location the terminal stands at. PINs are matched against
+// employee codes; scans already stored are skipped, so a file can be imported
+// again after it grew.
+func (s *attendanceService) ImportAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, file io.Reader) (dto.AttlogImportResult, error) {
+	return s.importAttlog(ctx, locationID, deviceID, file, attlogStatusField)
+}
+
+// ImportPushedAttlog folds the ATTLOG lines a terminal pushed over ADMS, the
+// same way as an exported log.
+func (s *attendanceService) ImportPushedAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, body io.Reader) (dto.AttlogImportResult, error) {
+	return s.importAttlog(ctx, locationID, deviceID, body, attlogPushStatusField)
+}
+
+func (s *attendanceService) importAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, file io.Reader, statusField int) (dto.AttlogImportResult, error) {
+	location, err := s.masterRepository.GetLocationByID(ctx, nil, locationID)
+	if err != nil {
+		return dto.AttlogImportResult{}, err
+	}
+
+	records, invalid, lines, err := parseAttlog(file, statusField)
+	if err != nil {
+		return dto.AttlogImportResult{}, err
+	}
+
+	result, err := s.importTerminalScans(ctx, location, deviceID, records)
+	if err != nil {
+		return dto.AttlogImportResult{}, err
+	}
+	result.Lines = lines
+	result.Invalid = append(result.Invalid, invalid...)
+	return result, nil
+}
+
+// terminalDay collects the scans of one employee on one work date.
+type terminalDay struct {
+	attendance *entities.Attendance
+	added      int
+}
+
+func (s *attendanceService) importTerminalScans(ctx context.Context, location entities.Location, deviceID string, records []attlogRecord) (dto.AttlogImportResult, error) {
+	result := dto.AttlogImportResult{Unmatched: []dto.AttlogUnmatchedPIN{}, Invalid: []dto.AttlogInvalidLine{}}
+
+	codes := make([]string, 0, len(records))
+	for _, record := range records {
+		codes = append(codes, record.pin)
+	}
+	employees, err := s.employeeRepository.FindByEmployeeCodes(ctx, nil, codes)
+	if err != nil {
+		return result, err
+	}
+	byCode := make(map[string]uuid.UUID, len(employees))
+	for _, employee := range employees {
+		byCode[employee.EmployeeCode] = employee.ID
+	}
+
+	loc := helpers.LoadTimezone(location.Timezone)
+	unmatched := map[string]int{}
+	type matchedScan struct {
+		attlogRecord
+		employeeID uuid.UUID
+		time       time.Time
+	}
+	var scans []matchedScan
+	for _, record := range records {
+		employeeID, ok := byCode[record.pin]
+		if !ok {
+			unmatched[record.pin]++
+			continue
+		}
+		at, _ := time.ParseInLocation(time.DateTime, record.at, loc)
+		scans = append(scans, matchedScan{attlogRecord: record, employeeID: employeeID, time: at})
+	}
+	sort.SliceStable(scans, func(i, j int) bool {
+		if scans[i].employeeID != scans[j].employeeID {
+			return scans[i].employeeID.String() < scans[j].employeeID.String()
+		}
+		return scans[i].time.Before(scans[j].time)
+	})
+
+	days := map[string]*terminalDay{}
+	var order []*terminalDay
+	seen := map[uuid.UUID]bool{}
+	for _, scan := range scans {
+		clientID := uuid.NewSHA1(attlogNamespace, []byte(location.ID.String()+"|"+scan.pin+"|"+scan.at))
+		if seen[clientID] {
+			result.Duplicates++
+			continue
+		}
+		seen[clientID] = true
+
+		if _, err := s.attendanceRepository.FindPunchByClientID(ctx, nil, clientID); err == nil {
+			result.Duplicates++
+			continue
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, err
+		}
+
+		workDay, shift, err := s.resolveWorkDay(scan.employeeID, scan.time)
+		if err != nil {
+			return result, err
+		}
+		key := scan.employeeID.String() + "|" + workDay.Format(time.DateOnly)
+		day, ok := days[key]
+		if !ok {
+			attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(scan.employeeID, helpers.DateOf(workDay))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				attendance = &entities.Attendance{
+					EmployeeID: scan.employeeID,
+					LocationID: &location.ID,
+					Location:   location,
+					WorkDate:   helpers.DateOf(workDay),
+					Shift:      shift,
+				}
+			} else if err != nil {
+				return result, err
+			}
+			day = &terminalDay{attendance: attendance}
+			days[key] = day
+			order = append(order, day)
+		}
+
+		if doubleScan(day.attendance, scan.time) {
+			result.Duplicates++
+			continue
+		}
+
+		status := scan.status
+		day.attendance.Punches = append(day.attendance.Punches, entities.AttendancePunch{
+			PunchTime:      scan.time,
+			LocationID:     &location.ID,
+			ClientID:       &clientID,
+			DeviceID:       deviceID,
+			TerminalStatus: &status,
+		})
+		day.added++
+	}
+
+	for _, day := range order {
+		if day.added == 0 {
+			continue
+		}
+		foldTerminalPunches(day.attendance)
+		leaves, err := s.leaveRepository.FindApprovedPartialOn(ctx, nil, day.attendance.EmployeeID, day.attendance.WorkDate)
+		if err != nil {
+			return result, err
+		}
+		if err := recomputeAttendance(day.attendance, leaves); err != nil {
+			return result, err
+		}
+		if err := s.attendanceRepository.SaveWithPunches(ctx, day.attendance); err != nil {
+			return result, err
+		}
+		result.Imported += day.added
+		result.Attendances++
+	}
+
+	for pin, punches := range unmatched {
+		result.Unmatched = append(result.Unmatched, dto.AttlogUnmatchedPIN{PIN: pin, Punches: punches})
+	}
+	sort.Slice(result.Unmatched, func(i, j int) bool {
+		return result.Unmatched[i].PIN < result.Unmatched[j].PIN
+	})
+	return result, nil
+}
+
+// doubleScan reports whether the record already holds a punch within the
+// double scan window of at.
+func doubleScan(attendance *entities.Attendance, at time.Time) bool {
+	window := constants.ATTLOG_DOUBLE_SCAN_SECONDS * time.Second
+	for _, punch := range attendance.Punches {
+		if gap := at.Sub(punch.PunchTime); gap < window && gap > -window {
+			return true
+		}
+	}
+	return false
+}
+
+// foldTerminalPunches types the terminal scans of a record by replaying its
+// punches in time order, then takes the check-in and check-out from them. A
+// break key is kept when it fits; any other scan is an in while the employee is
+// out and an out otherwise. Punches from other sources keep their type.
+func foldTerminalPunches(attendance *entities.Attendance) {
+	punches := attendance.Punches
+	sort.SliceStable(punches, func(i, j int) bool {
+		return punches[i].PunchTime.Before(punches[j].PunchTime)
+	})
+
+	state := punchedOut
+	for i := range punches {
+		punch := &punches[i]
+		if punch.TerminalStatus != nil {
+			switch {
+			case *punch.TerminalStatus == constants.ZKTECO_STATUS_BREAK_OUT && state == working:
+				punch.Type = constants.ENUM_PUNCH_TYPE_BREAK_START
+			case *punch.TerminalStatus == constants.ZKTECO_STATUS_BREAK_IN && state == onBreak:
+				punch.Type = constants.ENUM_PUNCH_TYPE_BREAK_END
+			case state == punchedOut:
+				punch.Type = constants.ENUM_PUNCH_TYPE_IN
+			default:
+				punch.Type = constants.ENUM_PUNCH_TYPE_OUT
+			}
+		}
+
+		switch punch.Type {
+		case constants.ENUM_PUNCH_TYPE_IN:
+			if state == punchedOut {
+				state = working
+			}
+		case constants.ENUM_PUNCH_TYPE_BREAK_START:
+			if state == working {
+				state = onBreak
+			}
+		case constants.ENUM_PUNCH_TYPE_BREAK_END:
+			if state == onBreak {
+				state = working
+			}
+		case constants.ENUM_PUNCH_TYPE_OUT:
+			state = punchedOut
+		}
+	}
+
+	attendance.CheckInTime, attendance.CheckOutTime = nil, nil
+	for _, punch := range punches {
+		if punch.Type == constants.ENUM_PUNCH_TYPE_IN {
+			checkIn := punch.PunchTime
+			attendance.CheckInTime = &checkIn
+			break
+		}
+	}
+	if last := len(punches) - 1; last >= 0 && state == punchedOut && punches[last].Type == constants.ENUM_PUNCH_TYPE_OUT {
+		checkOut := punches[last].PunchTime
+		attendance.CheckOutTime = &checkOut
+		if punches[last].TerminalStatus != nil {
+			attendance.AutoCheckout = false
+		}
+	}
+	attendance.Punches = punches
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttendanceCorrectionService interface {
+	Submit(ctx context.Context, userID string, req dto.CorrectionCreateDTO) (entities.AttendanceCorrection, error)
+	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error)
+	FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error)
+	FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error)
+	GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.AttendanceCorrection, error)
+	Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceCorrection, error)
+	Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceCorrection, error)
+}
+
+type attendanceCorrectionService struct {
+	correctionRepository repository.AttendanceCorrectionRepository
+	attendanceRepository repository.AttendanceRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	masterRepository     masterRepository.MasterRepository
+	leaveRepository      leaveRepository.LeaveRepository
+	shiftService         shiftService.ShiftService
+	rbacService          rbacService.RbacService
+	db                   *gorm.DB
+}
+
+func NewAttendanceCorrectionService(
+	correctionRepo repository.AttendanceCorrectionRepository,
+	attendanceRepo repository.AttendanceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	masterRepo masterRepository.MasterRepository,
+	leaveRepo leaveRepository.LeaveRepository,
+	shiftSvc shiftService.ShiftService,
+	rbacSvc rbacService.RbacService,
+	db *gorm.DB,
+) AttendanceCorrectionService {
+	return &attendanceCorrectionService{
+		correctionRepository: correctionRepo,
+		attendanceRepository: attendanceRepo,
+		employeeRepository:   employeeRepo,
+		masterRepository:     masterRepo,
+		leaveRepository:      leaveRepo,
+		shiftService:         shiftSvc,
+		rbacService:          rbacSvc,
+		db:                   db,
+	}
+}
+
+func (s *attendanceCorrectionService) Submit(ctx context.Context, userID string, req dto.CorrectionCreateDTO) (entities.AttendanceCorrection, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+
+	correction := entities.AttendanceCorrection{
+		EmployeeID:       employee.ID,
+		AttendanceID:     req.AttendanceID,
+		ProposedCheckIn:  req.CheckInTime,
+		ProposedCheckOut: req.CheckOutTime,
+		Reason:           req.Reason,
+		Status:           constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING,
+	}
+
+	var location entities.Location
+	checkIn, checkOut := req.CheckInTime, req.CheckOutTime
+	if req.AttendanceID != nil {
+		attendance, err := s.attendanceRepository.FindByID(*req.AttendanceID)
+		if err != nil {
+			return entities.AttendanceCorrection{}, err
+		}
+		if attendance.EmployeeID != employee.ID {
+			return entities.AttendanceCorrection{}, dto.ErrNotAttendanceOwner
+		}
+		location = attendance.Location
+		if attendance.LocationID == nil {
+			// An absence has no punch to correct; the employee supplies both.
+			if req.LocationID == nil || req.CheckInTime == nil {
+				return entities.AttendanceCorrection{}, dto.ErrCorrectionAbsence
+			}
+			location, err = s.masterRepository.GetLocationByID(ctx, nil, *req.LocationID)
+			if err != nil {
+				return entities.AttendanceCorrection{}, err
+			}
+		}
+		correction.LocationID = location.ID
+		correction.WorkDate = attendance.WorkDate
+		if checkIn == nil {
+			checkIn = attendance.CheckInTime
+		}
+		if checkOut == nil {
+			checkOut = attendance.CheckOutTime
+		}
+	} else {
+		location, err = s.masterRepository.GetLocationByID(ctx, nil, *req.LocationID)
+		if err != nil {
+			return entities.AttendanceCorrection{}, err
+		}
+		workDay, err := time.ParseInLocation("2006-01-02", req.WorkDate, helpers.LoadTimezone(location.Timezone))
+		if err != nil {
+			return entities.AttendanceCorrection{}, err
+		}
+		_, err = s.attendanceRepository.FindByEmployeeAndWorkDate(employee.ID, helpers.DateOf(workDay))
+		if err == nil {
+			return entities.AttendanceCorrection{}, dto.ErrAttendanceAlreadyExist
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.AttendanceCorrection{}, err
+		}
+		correction.LocationID = location.ID
+		correction.WorkDate = helpers.DateOf(workDay)
+	}
+
+	if err := validateCorrectedTimes(correction.WorkDate, location, checkIn, checkOut); err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+
+	pending, err := s.correctionRepository.HasPending(ctx, nil, employee.ID, correction.WorkDate)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	if pending {
+		return entities.AttendanceCorrection{}, dto.ErrCorrectionPending
+	}
+
+	return s.correctionRepository.Create(ctx, nil, correction)
+}
+
+func (s *attendanceCorrectionService) FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error) {
+	return s.correctionRepository.FindAll(ctx, nil, filter)
+}
+
+func (s *attendanceCorrectionService) FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.correctionRepository.FindByEmployeeID(ctx, nil, filter, employee.ID)
+}
+
+func (s *attendanceCorrectionService) FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.AttendanceCorrection], error) {
+	supervisor, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.correctionRepository.FindPendingBySupervisorID(ctx, nil, filter, supervisor.ID)
+}
+
+// GetByID shows a correction to the employee who filed it, their supervisor
+// and holders of view_attendance_corrections.
+func (s *attendanceCorrectionService) GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.AttendanceCorrection, error) {
+	correction, err := s.correctionRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	allowed, err := mayViewRequest(ctx, s.employeeRepository, s.rbacService, userID, correction.Employee, constants.PERMISSION_VIEW_ATTENDANCE_CORRECTIONS)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	if !allowed {
+		return entities.AttendanceCorrection{}, dto.ErrNotRequestViewer
+	}
+	return correction, nil
+}
+
+// Approve applies the correction to the attendance row, creating it for a
+// missed day, and recomputes the shift figures from the corrected times.
+func (s *attendanceCorrectionService) Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceCorrection, error) {
+	reviewer, correction, err := s.reviewable(ctx, id, userID)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+
+	attendance, err := s.correctedAttendance(ctx, &correction)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+
+	oldValues, _ := json.Marshal(map[string]interface{}{
+		"check_in_time":  correction.OriginalCheckIn,
+		"check_out_time": correction.OriginalCheckOut,
+		"status":         correction.OriginalStatus,
+	})
+	newValues, _ := json.Marshal(map[string]interface{}{
+		"check_in_time":  attendance.CheckInTime,
+		"check_out_time": attendance.CheckOutTime,
+		"status":         attendance.Status,
+	})
+	audit := entities.AuditLog{
+		UserID:    reviewer.UserID,
+		Action:    "attendance_correction_approved",
+		Entity:    attendance.TableName(),
+		OldValues: oldValues,
+		NewValues: newValues,
+		Source:    correction.TableName(),
+		Severity:  "info",
+	}
+
+	now := time.Now()
+	correction.Status = constants.ENUM_ATTENDANCE_CORRECTION_STATUS_APPROVED
+	correction.ApproverID = &reviewer.ID
+	correction.ReviewedAt = &now
+	correction.ReviewNote = note
+
+	if err := s.correctionRepository.Apply(ctx, &correction, attendance, audit); err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+	correction.Attendance = attendance
+	return correction, nil
+}
+
+func (s *attendanceCorrectionService) Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.AttendanceCorrection, error) {
+	reviewer, correction, err := s.reviewable(ctx, id, userID)
+	if err != nil {
+		return entities.AttendanceCorrection{}, err
+	}
+
+	now := time.Now()
+	correction.Status = constants.ENUM_ATTENDANCE_CORRECTION_STATUS_REJECTED
+	correction.ApproverID = &reviewer.ID
+	correction.ReviewedAt = &now
+	correction.ReviewNote = note
+
+	return s.correctionRepository.Update(ctx, nil, correction)
+}
+
+// reviewable loads a pending correction and checks that userID is the
+// supervisor of the employee who filed it.
+func (s *attendanceCorrectionService) reviewable(ctx context.Context, id uuid.UUID, userID string) (entities.Employee, entities.AttendanceCorrection, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.Employee{}, entities.AttendanceCorrection{}, err
+	}
+
+	correction, err := s.correctionRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.Employee{}, entities.AttendanceCorrection{}, err
+	}
+	if correction.Status != constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING {
+		return entities.Employee{}, entities.AttendanceCorrection{}, dto.ErrCorrectionNotPending
+	}
+	if correction.Employee.SupervisorID == nil || *correction.Employee.SupervisorID != reviewer.ID {
+		return entities.Employee{}, entities.AttendanceCorrection{}, dto.ErrNotSupervisor
+	}
+	return reviewer, correction, nil
+}
+
+// correctedAttendance builds the attendance row as it should look after the
+// correction and snapshots the values it replaces onto the correction.
+func (s *attendanceCorrectionService) correctedAttendance(ctx context.Context, correction *entities.AttendanceCorrection) (*entities.Attendance, error) {
+	var attendance *entities.Attendance
+	if correction.AttendanceID != nil {
+		existing, err := s.attendanceRepository.FindByID(*correction.AttendanceID)
+		if err != nil {
+			return nil, err
+		}
+		attendance = existing
+
+		correction.OriginalCheckIn = attendance.CheckInTime
+		correction.OriginalCheckOut = attendance.CheckOutTime
+		correction.OriginalStatus = attendance.Status
+
+		if attendance.LocationID == nil {
+			location, err := s.masterRepository.GetLocationByID(ctx, nil, correction.LocationID)
+			if err != nil {
+				return nil, err
+			}
+			attendance.LocationID = &location.ID
+			attendance.Location = location
+		}
+	} else {
+		location, err := s.masterRepository.GetLocationByID(ctx, nil, correction.LocationID)
+		if err != nil {
+			return nil, err
+		}
+		attendance = &entities.Attendance{
+			EmployeeID: correction.EmployeeID,
+			LocationID: &correction.LocationID,
+			WorkDate:   correction.WorkDate,
+			Location:   location,
+		}
+
+		shift, err := s.shiftService.ResolveShift(ctx, correction.EmployeeID, localWorkDay(attendance))
+		if err != nil {
+			return nil, err
+		}
+		if shift != nil {
+			attendance.ShiftID = &shift.ID
+			attendance.Shift = shift
+		}
+	}
+
+	if correction.ProposedCheckIn != nil {
+		checkIn := *correction.ProposedCheckIn
+		attendance.CheckInTime = &checkIn
+	}
+	if correction.ProposedCheckOut != nil {
+		checkOut := *correction.ProposedCheckOut
+		attendance.CheckOutTime = &checkOut
+		attendance.AutoCheckout = false
+	}
+	alignPunches(attendance)
+
+	leaves, err := s.leaveRepository.FindApprovedPartialOn(ctx, nil, attendance.EmployeeID, attendance.WorkDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := recomputeAttendance(attendance, leaves); err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// mayViewRequest reports whether the user signed in as userID may see a
+// request filed by employee: the employee, their supervisor, or a holder of
+// permission.
+func mayViewRequest(ctx context.Context, employeeRepo employeeRepository.EmployeeRepository, rbacSvc rbacService.RbacService, userID string, employee entities.Employee, permission string) (bool, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return false, nil
+	}
+	viewer, err := employeeRepo.FindByUserID(ctx, nil, uid)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if err == nil && (viewer.ID == employee.ID || (employee.SupervisorID != nil && *employee.SupervisorID == viewer.ID)) {
+		return true, nil
+	}
+	return rbacSvc.HasPermission(ctx, uid, permission)
+}
+
+func (s *attendanceCorrectionService) employeeByUserID(ctx context.Context, userID string) (entities.Employee, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+	return employee, err
+}
+
+// validateCorrectedTimes checks the times the attendance would end up with. A
+// check-in may fall on the work date or the night after, for overnight shifts.
+func validateCorrectedTimes(workDate time.Time, location entities.Location, checkIn, checkOut *time.Time) error {
+	if checkIn != nil && checkOut != nil && !checkOut.After(*checkIn) {
+		return dto.ErrCorrectionRange
+	}
+
+	now := time.Now()
+	if checkIn != nil {
+		dayStart := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 0, 0, 0, 0, helpers.LoadTimezone(location.Timezone))
+		if checkIn.Before(dayStart) || !checkIn.Before(dayStart.AddDate(0, 0, 2)) {
+			return dto.ErrCorrectionOutsideDate
+		}
+		if checkIn.After(now) {
+			return dto.ErrCorrectionInFuture
+		}
+	}
+	if checkOut != nil && checkOut.After(now) {
+		return dto.ErrCorrectionInFuture
+	}
+	return nil
+}
+
+// localWorkDay is midnight of the attendance work date in its location timezone.
+func localWorkDay(attendance *entities.Attendance) time.Time {
+	wd := attendance.WorkDate
+	return time.Date(wd.Year(), wd.Month(), wd.Day(), 0, 0, 0, 0, helpers.LoadTimezone(attendance.Location.Timezone))
+}
+
+// recomputeAttendance resets the derived figures and classifies the record again
+// against its shift less the partial leaves of the day, for times that did not
+// come from a live punch.
+func recomputeAttendance(attendance *entities.Attendance, leaves []entities.Leave) error {
+	attendance.Status = constants.ENUM_ATTENDANCE_STATUS_PRESENT
+	attendance.LateMinutes = 0
+	attendance.EarlyLeaveMinutes = 0
+	attendance.WorkedMinutes = 0
+
+	if attendance.Shift != nil {
+		if err := applyShiftOnCheckIn(attendance, attendance.Shift, localWorkDay(attendance), leaves); err != nil {
+			return err
+		}
+	}
+	applyShiftOnCheckOut(attendance)
+	return nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+)
+
+// States of an attendance day while its punches are replayed
+const (
+	punchedOut = iota
+	working
+	onBreak
+)
+
+// summarizePunches replays the punches in time order and returns the minutes
+// spent working and on break. A break lasts until its break-end or the next out
+// punch; punches that do not fit the current state are ignored. ok reports
+// whether at least one work segment was closed by an out punch.
+func summarizePunches(punches []entities.AttendancePunch) (worked, breaks int, ok bool) {
+	sorted := append([]entities.AttendancePunch(nil), punches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PunchTime.Before(sorted[j].PunchTime)
+	})
+
+	var workedTime, breakTime time.Duration
+	var since time.Time
+	state := punchedOut
+	for _, punch := range sorted {
+		switch punch.Type {
+		case constants.ENUM_PUNCH_TYPE_IN:
+			if state == punchedOut {
+				state, since = working, punch.PunchTime
+			}
+		case constants.ENUM_PUNCH_TYPE_BREAK_START:
+			if state == working {
+				workedTime += punch.PunchTime.Sub(since)
+				state, since = onBreak, punch.PunchTime
+			}
+		case constants.ENUM_PUNCH_TYPE_BREAK_END:
+			if state == onBreak {
+				breakTime += punch.PunchTime.Sub(since)
+				state, since = working, punch.PunchTime
+			}
+		case constants.ENUM_PUNCH_TYPE_OUT:
+			switch state {
+			case working:
+				workedTime += punch.PunchTime.Sub(since)
+				ok = true
+			case onBreak:
+				breakTime += punch.PunchTime.Sub(since)
+				ok = true
+			}
+			state = punchedOut
+		}
+	}
+
+	return int(workedTime.Minutes()), int(breakTime.Minutes()), ok
+}
+
+// lastPunch returns the latest punch of the record, nil without punches.
+func lastPunch(attendance *entities.Attendance) *entities.AttendancePunch {
+	var last *entities.AttendancePunch
+	for i := range attendance.Punches {
+		if last == nil || !attendance.Punches[i].PunchTime.Before(last.PunchTime) {
+			last = &attendance.Punches[i]
+		}
+	}
+	return last
+}
+
+// segmentLocationID is the location of the current work segment, the one the
+// latest in punch was made at. It falls back to the location of the record.
+func segmentLocationID(attendance *entities.Attendance) *uuid.UUID {
+	var latest *entities.AttendancePunch
+	for i := range attendance.Punches {
+		punch := &attendance.Punches[i]
+		if punch.Type != constants.ENUM_PUNCH_TYPE_IN || punch.LocationID == nil {
+			continue
+		}
+		if latest == nil || !punch.PunchTime.Before(latest.PunchTime) {
+			latest = punch
+		}
+	}
+	if latest == nil {
+		return attendance.LocationID
+	}
+	return latest.LocationID
+}
+
+// alignPunches moves the first in punch and the last out punch onto the
+// check-in and check-out of the record, adding them when missing, so totals
+// derived from the punches follow times that did not come from a live punch.
+func alignPunches(attendance *entities.Attendance) {
+	first, last := -1, -1
+	for i, punch := range attendance.Punches {
+		switch punch.Type {
+		case constants.ENUM_PUNCH_TYPE_IN:
+			if first < 0 || punch.PunchTime.Before(attendance.Punches[first].PunchTime) {
+				first = i
+			}
+		case constants.ENUM_PUNCH_TYPE_OUT:
+			if last < 0 || !punch.PunchTime.Before(attendance.Punches[last].PunchTime) {
+				last = i
+			}
+		}
+	}
+
+	if attendance.CheckInTime != nil {
+		if first >= 0 {
+			attendance.Punches[first].PunchTime = *attendance.CheckInTime
+		} else {
+			attendance.Punches = append(attendance.Punches, entities.AttendancePunch{
+				Type:       constants.ENUM_PUNCH_TYPE_IN,
+				PunchTime:  *attendance.CheckInTime,
+				LocationID: attendance.LocationID,
+			})
+		}
+	}
+	if attendance.CheckOutTime != nil {
+		if last >= 0 {
+			attendance.Punches[last].PunchTime = *attendance.CheckOutTime
+		} else {
+			attendance.Punches = append(attendance.Punches, entities.AttendancePunch{
+				Type:       constants.ENUM_PUNCH_TYPE_OUT,
+				PunchTime:  *attendance.CheckOutTime,
+				LocationID: segmentLocationID(attendance),
+			})
+		}
+	}
+}
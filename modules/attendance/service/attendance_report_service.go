@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	overtimeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/overtime/repository"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttendanceReportService interface {
+	Summary(ctx context.Context, req dto.ReportDTO) (dto.AttendanceReport, error)
+}
+
+type attendanceReportService struct {
+	attendanceRepository repository.AttendanceRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	leaveRepository      leaveRepository.LeaveRepository
+	overtimeRepository   overtimeRepository.OvertimeRepository
+	masterRepository     masterRepository.MasterRepository
+	shiftService         shiftService.ShiftService
+	db                   *gorm.DB
+}
+
+func NewAttendanceReportService(
+	attendanceRepo repository.AttendanceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	leaveRepo leaveRepository.LeaveRepository,
+	overtimeRepo overtimeRepository.OvertimeRepository,
+	masterRepo masterRepository.MasterRepository,
+	shiftSvc shiftService.ShiftService,
+	db *gorm.DB,
+) AttendanceReportService {
+	return &attendanceReportService{
+		attendanceRepository: attendanceRepo,
+		employeeRepository:   employeeRepo,
+		leaveRepository:      leaveRepo,
+		overtimeRepository:   overtimeRepo,
+		masterRepository:     masterRepo,
+		shiftService:         shiftSvc,
+		db:                   db,
+	}
+}
+
+// reportTally collects the figures of one employee while records are read.
+type reportTally struct {
+	row           dto.AttendanceReportRow
+	workedMinutes int
+	workedDays    int
+	overtime      int
+	leaveDates    map[time.Time]float64
+	locationID    *uuid.UUID
+}
+
+// Summary sums up attendance, leave and overtime per employee over a date
+// range. For a location only the records taken there count, and only the
+// employees who have one are listed.
+func (s *attendanceReportService) Summary(ctx context.Context, req dto.ReportDTO) (dto.AttendanceReport, error) {
+	from, err := time.Parse(time.DateOnly, req.From)
+	if err != nil {
+		return dto.AttendanceReport{}, dto.ErrReportDate
+	}
+	to, err := time.Parse(time.DateOnly, req.To)
+	if err != nil {
+		return dto.AttendanceReport{}, dto.ErrReportDate
+	}
+	if to.Before(from) || to.Sub(from) >= constants.ATTENDANCE_REPORT_MAX_DAYS*24*time.Hour {
+		return dto.AttendanceReport{}, dto.ErrReportRange
+	}
+
+	scopes := 0
+	for _, scope := range []string{req.EmployeeID, req.DepartmentID, req.LocationID} {
+		if scope != "" {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		return dto.AttendanceReport{}, dto.ErrReportScope
+	}
+
+	var employees []entities.Employee
+	switch {
+	case req.EmployeeID != "":
+		employee, err := s.employeeRepository.FindByID(ctx, nil, uuid.MustParse(req.EmployeeID))
+		if err != nil {
+			return dto.AttendanceReport{}, err
+		}
+		employees = []entities.Employee{employee}
+	case req.DepartmentID != "":
+		departmentID := uuid.MustParse(req.DepartmentID)
+		employees, err = s.employeeRepository.FindForReport(ctx, nil, from, to, &departmentID)
+	default:
+		employees, err = s.employeeRepository.FindForReport(ctx, nil, from, to, nil)
+	}
+	if err != nil {
+		return dto.AttendanceReport{}, err
+	}
+
+	var locationID *uuid.UUID
+	if req.LocationID != "" {
+		id := uuid.MustParse(req.LocationID)
+		locationID = &id
+	}
+
+	ids := make([]uuid.UUID, 0, len(employees))
+	tallies := make(map[uuid.UUID]*reportTally, len(employees))
+	for _, employee := range employees {
+		ids = append(ids, employee.ID)
+		tallies[employee.ID] = &reportTally{
+			row: dto.AttendanceReportRow{
+				EmployeeID:   employee.ID,
+				EmployeeCode: employee.EmployeeCode,
+				Name:         employee.User.Name,
+				Department:   employee.Department.Name,
+			},
+			leaveDates: map[time.Time]float64{},
+			locationID: employee.LocationID,
+		}
+	}
+
+	attendances, err := s.attendanceRepository.FindInRange(ctx, nil, ids, from, to, locationID)
+	if err != nil {
+		return dto.AttendanceReport{}, err
+	}
+	attended := map[uuid.UUID]bool{}
+	for _, attendance := range attendances {
+		tally, ok := tallies[attendance.EmployeeID]
+		if !ok {
+			continue
+		}
+		attended[attendance.EmployeeID] = true
+		tallyAttendance(tally, attendance)
+	}
+
+	if err := s.tallyLeaves(ctx, tallies, ids, from, to); err != nil {
+		return dto.AttendanceReport{}, err
+	}
+
+	overtimes, err := s.overtimeRepository.FindApprovedForEmployees(ctx, nil, ids, from, to)
+	if err != nil {
+		return dto.AttendanceReport{}, err
+	}
+	for _, overtime := range overtimes {
+		if tally, ok := tallies[overtime.EmployeeID]; ok {
+			tally.overtime += overtime.ActualMinutes
+		}
+	}
+
+	report := dto.AttendanceReport{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Rows: make([]dto.AttendanceReportRow, 0, len(employees)),
+	}
+	for _, employee := range employees {
+		if locationID != nil && !attended[employee.ID] {
+			continue
+		}
+		tally := tallies[employee.ID]
+		row := tally.row
+		for _, share := range tally.leaveDates {
+			row.LeaveDays += share
+		}
+		row.LeaveDays = math.Round(row.LeaveDays*100) / 100
+		row.OvertimeHours = roundHours(float64(tally.overtime))
+		if tally.workedDays > 0 {
+			row.AverageWorkedHours = roundHours(float64(tally.workedMinutes) / float64(tally.workedDays))
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report, nil
+}
+
+func tallyAttendance(tally *reportTally, attendance entities.Attendance) {
+	if attendance.Status == constants.ENUM_ATTENDANCE_STATUS_ABSENT {
+		tally.row.Absences++
+		return
+	}
+	if attendance.CheckInTime == nil {
+		return
+	}
+
+	tally.row.DaysPresent++
+	if attendance.WorkMode == constants.ENUM_WORK_MODE_REMOTE {
+		tally.row.RemoteDays++
+	}
+	if attendance.LateMinutes > 0 {
+		tally.row.LateCount++
+		tally.row.LateMinutes += attendance.LateMinutes
+	}
+	if attendance.EarlyLeaveMinutes > 0 {
+		tally.row.EarlyLeaveCount++
+	}
+	if attendance.CheckOutTime != nil {
+		tally.workedMinutes += attendance.WorkedMinutes
+		tally.workedDays++
+	}
+}
+
+// tallyLeaves counts the days within the range an approved leave covers on
+// which the employee was scheduled to work and that were no holiday where they
+// are based. A half-day or hourly leave counts its share of the day, and
+// overlapping leaves count a day once.
+func (s *attendanceReportService) tallyLeaves(ctx context.Context, tallies map[uuid.UUID]*reportTally, ids []uuid.UUID, from, to time.Time) error {
+	leaves, err := s.leaveRepository.FindApprovedInRange(ctx, nil, ids, from, to)
+	if err != nil {
+		return err
+	}
+
+	// Company-wide days are keyed by the nil location
+	type holidayKey struct {
+		day      time.Time
+		location uuid.UUID
+	}
+	holidays := map[holidayKey]bool{}
+	for _, leave := range leaves {
+		tally, ok := tallies[leave.EmployeeID]
+		if !ok {
+			continue
+		}
+
+		share := 1.0
+		if leave.Partial() {
+			share = leave.Days
+		}
+		start, end := helpers.DateOf(leave.StartDate), helpers.DateOf(leave.EndDate)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if tally.leaveDates[day] >= 1 {
+				continue
+			}
+			key := holidayKey{day: day}
+			if tally.locationID != nil {
+				key.location = *tally.locationID
+			}
+			holiday, ok := holidays[key]
+			if !ok {
+				if holiday, err = s.masterRepository.IsHoliday(ctx, nil, day, tally.locationID); err != nil {
+					return err
+				}
+				holidays[key] = holiday
+			}
+			if holiday {
+				continue
+			}
+			working, _, err := s.shiftService.ScheduleOn(ctx, leave.EmployeeID, day)
+			if err != nil {
+				return err
+			}
+			if working {
+				tally.leaveDates[day] = math.Min(tally.leaveDates[day]+share, 1)
+			}
+		}
+	}
+	return nil
+}
+
+// roundHours converts minutes to hours rounded to two decimals.
+func roundHours(minutes float64) float64 {
+	return math.Round(minutes/60*100) / 100
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// checkSelfie rejects a punch at a location that requires a selfie when none
+// was sent, and a photo that is not a small enough jpg or png.
+func checkSelfie(location entities.Location, photo *multipart.FileHeader) error {
+	if photo == nil {
+		if location.RequireSelfie {
+			return dto.ErrSelfieRequired
+		}
+		return nil
+	}
+	_, err := selfieExtension(photo)
+	return err
+}
+
+// storeSelfie writes a checked selfie through utils.UploadFile under a random
+// name and returns its path relative to utils.PATH, empty without a photo.
+func storeSelfie(photo *multipart.FileHeader) (string, error) {
+	if photo == nil {
+		return "", nil
+	}
+
+	ext, err := selfieExtension(photo)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/%s.%s", constants.ATTENDANCE_SELFIE_DIR, uuid.New(), ext)
+	if err := utils.UploadFile(photo, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// discardSelfie removes a stored selfie whose punch could not be saved.
+func discardSelfie(path string) {
+	if path != "" {
+		_ = os.Remove(selfieFile(path))
+	}
+}
+
+// selfieFile is the file on disk for a path returned by storeSelfie.
+func selfieFile(path string) string {
+	return filepath.Join(utils.PATH, filepath.FromSlash(path))
+}
+
+// selfieExtension sniffs the photo content, the client file name is not trusted.
+func selfieExtension(photo *multipart.FileHeader) (string, error) {
+	if photo.Size > constants.ATTENDANCE_SELFIE_MAX_BYTES {
+		return "", dto.ErrSelfieInvalid
+	}
+
+	file, err := photo.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", dto.ErrSelfieInvalid
+	}
+
+	switch http.DetectContentType(head[:n]) {
+	case "image/jpeg":
+		return "jpg", nil
+	case "image/png":
+		return "png", nil
+	default:
+		return "", dto.ErrSelfieInvalid
+	}
+}
@@ -3,11 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -17,24 +24,48 @@ type AttendanceService interface {
 	GetByID(id string) (*entities.Attendance, error)
 	CheckIn(req dto.CheckInDTO) (*entities.Attendance, error)
 	CheckOut(req dto.CheckOutDTO) (*entities.Attendance, error)
-	Update(id string, req dto.UpdateAttendanceDTO) (*entities.Attendance, error)
-	Approve(id string, req dto.ApproveAttendanceDTO) (*entities.Attendance, error)
+	StartBreak(req dto.BreakDTO) (*entities.Attendance, error)
+	EndBreak(req dto.BreakDTO) (*entities.Attendance, error)
+	Sync(ctx context.Context, req dto.SyncDTO) (dto.SyncResult, error)
+	EmployeeIDByUserID(ctx context.Context, userID string) (uuid.UUID, error)
+	Today(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error)
+	OpenAttendance(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error)
+	ImportAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, file io.Reader) (dto.AttlogImportResult, error)
+	ImportPushedAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, body io.Reader) (dto.AttlogImportResult, error)
+	PhotoFile(id string, punch string) (string, error)
 	Delete(id string) error
+	Reclassify(ctx context.Context, employeeID uuid.UUID, start, end time.Time) error
 	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error)
 	FindByEmployeeID(ctx context.Context, employeeID string, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error)
+	FindAutoCheckouts(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error)
 }
 
 type attendanceService struct {
 	attendanceRepository repository.AttendanceRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	masterRepository     masterRepository.MasterRepository
+	remoteWorkRepository repository.RemoteWorkRepository
+	leaveRepository      leaveRepository.LeaveRepository
+	shiftService         shiftService.ShiftService
 	db                   *gorm.DB
 }
 
 func NewAttendanceService(
 	attendanceRepo repository.AttendanceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	masterRepo masterRepository.MasterRepository,
+	remoteWorkRepo repository.RemoteWorkRepository,
+	leaveRepo leaveRepository.LeaveRepository,
+	shiftSvc shiftService.ShiftService,
 	db *gorm.DB,
 ) AttendanceService {
 	return &attendanceService{
 		attendanceRepository: attendanceRepo,
+		employeeRepository:   employeeRepo,
+		masterRepository:     masterRepo,
+		remoteWorkRepository: remoteWorkRepo,
+		leaveRepository:      leaveRepo,
+		shiftService:         shiftSvc,
 		db:                   db,
 	}
 }
@@ -61,6 +92,11 @@ func (s *attendanceService) FindAll(ctx context.Context, filter *pagination.Filt
 	return page, nil
 }
 
+// FindAutoCheckouts lists the records closed by the system for review.
+func (s *attendanceService) FindAutoCheckouts(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error) {
+	return s.attendanceRepository.FindAutoCheckouts(ctx, s.db, filter)
+}
+
 func (s *attendanceService) FindByEmployeeID(ctx context.Context, employeeID string, filter *pagination.Filter) (*pagination.Page[entities.Attendance], error) {
 	uid, err := uuid.Parse(employeeID)
 	if err != nil {
@@ -73,77 +109,368 @@ func (s *attendanceService) FindByEmployeeID(ctx context.Context, employeeID str
 	return page, nil
 }
 
+// EmployeeIDByUserID resolves the employee record linked to a login, for the
+// self-service endpoints.
+func (s *attendanceService) EmployeeIDByUserID(ctx context.Context, userID string) (uuid.UUID, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, dto.ErrEmployeeNotLinked
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return employee.ID, nil
+}
+
+// Today returns the record the employee is currently checked in on, or else the
+// record of today's work date. It returns nil when there is neither.
+func (s *attendanceService) Today(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error) {
+	now := time.Now()
+	if attendance, err := s.openAttendance(employeeID, now); err == nil {
+		return attendance, nil
+	}
+
+	attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(employeeID, helpers.DateOf(now.In(helpers.LoadTimezone(""))))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// OpenAttendance returns the record the employee is checked in on right now,
+// or dto.ErrNoOpenAttendance when they are off duty.
+func (s *attendanceService) OpenAttendance(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error) {
+	return s.openAttendance(employeeID, time.Now())
+}
+
+// CheckIn opens the attendance of the work date, or another work segment on a
+// record that was checked out, e.g. for a split shift or after a visit to a
+// client site.
 func (s *attendanceService) CheckIn(req dto.CheckInDTO) (*entities.Attendance, error) {
-	// Check if already checked in today
-	_, err := s.attendanceRepository.FindTodayByEmployeeID(req.EmployeeID)
-	if err == nil {
-		return nil, errors.New("already checked in today")
+	return s.checkIn(req, livePunch(req.DeviceID))
+}
+
+func (s *attendanceService) checkIn(req dto.CheckInDTO, origin punchOrigin) (*entities.Attendance, error) {
+	var location entities.Location
+	var err error
+	if req.Remote {
+		location, err = s.masterRepository.GetRemoteLocation(context.Background(), nil)
+	} else {
+		location, err = s.masterRepository.GetLocationByID(context.Background(), nil, req.LocationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	distance, err := verifyPunchPosition(location, *req.Latitude, *req.Longitude, req.Accuracy, req.KioskCode, origin.at)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSelfie(location, req.Photo); err != nil {
+		return nil, err
+	}
+
+	now := origin.at.In(helpers.LoadTimezone(location.Timezone))
+	workDay, shift, err := s.resolveWorkDay(req.EmployeeID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	workMode := constants.ENUM_WORK_MODE_ONSITE
+	var remoteWorkRequestID *uuid.UUID
+	if location.IsRemote {
+		request, err := s.remoteWorkRepository.FindApprovedOn(context.Background(), nil, req.EmployeeID, helpers.DateOf(workDay))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrRemoteWorkNotApproved
+		}
+		if err != nil {
+			return nil, err
+		}
+		workMode = constants.ENUM_WORK_MODE_REMOTE
+		remoteWorkRequestID = &request.ID
+	}
+
+	// Check if already checked in for this work date
+	attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(req.EmployeeID, helpers.DateOf(workDay))
+	reopened := err == nil
+	if reopened {
+		if attendance.CheckInTime == nil || attendance.CheckOutTime == nil || attendance.AutoCheckout {
+			return nil, dto.ErrAlreadyCheckedIn
+		}
+		if now.Before(*attendance.CheckOutTime) {
+			return nil, dto.ErrPunchOutOfOrder
+		}
+		reopenAttendance(attendance)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		leaves, err := s.leaveRepository.FindApprovedPartialOn(context.Background(), nil, req.EmployeeID, helpers.DateOf(workDay))
+		if err != nil {
+			return nil, err
+		}
+		attendance = &entities.Attendance{
+			EmployeeID:          req.EmployeeID,
+			LocationID:          &location.ID,
+			CheckInTime:         &now,
+			Status:              constants.ENUM_ATTENDANCE_STATUS_PRESENT,
+			WorkDate:            helpers.DateOf(workDay),
+			CheckInLatitude:     req.Latitude,
+			CheckInLongitude:    req.Longitude,
+			CheckInAccuracy:     &req.Accuracy,
+			CheckInDistance:     distance,
+			WorkMode:            workMode,
+			RemoteWorkRequestID: remoteWorkRequestID,
+		}
+		if err := applyShiftOnCheckIn(attendance, shift, workDay, leaves); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
 	}
 
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
+	photo, err := storeSelfie(req.Photo)
+	if err != nil {
 		return nil, err
 	}
 
-	newAttendance := &entities.Attendance{
-		EmployeeID:  req.EmployeeID,
-		LocationID:  req.LocationID,
-		CheckInTime: time.Now(),
-		Status:      "present",
+	punch := entities.AttendancePunch{
+		Type:       constants.ENUM_PUNCH_TYPE_IN,
+		PunchTime:  now,
+		LocationID: &location.ID,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		Accuracy:   &req.Accuracy,
+		Distance:   distance,
+		Photo:      photo,
 	}
+	origin.stamp(&punch)
+	attendance.Punches = append(attendance.Punches, punch)
 
-	return s.attendanceRepository.Create(newAttendance)
+	var result *entities.Attendance
+	if reopened {
+		result, err = s.attendanceRepository.RecordPunch(context.Background(), attendance, punch)
+	} else {
+		attendance.CheckInPhoto = photo
+		result, err = s.attendanceRepository.Create(attendance)
+	}
+	if err != nil {
+		discardSelfie(photo)
+		return nil, punchConflict(err)
+	}
+	return result, nil
+}
+
+// reopenAttendance clears the check-out of a record that gets another work
+// segment, along with the figures derived from it, until the next check-out.
+func reopenAttendance(attendance *entities.Attendance) {
+	attendance.CheckOutTime = nil
+	attendance.CheckOutLatitude = nil
+	attendance.CheckOutLongitude = nil
+	attendance.CheckOutAccuracy = nil
+	attendance.CheckOutDistance = nil
+	attendance.CheckOutPhoto = ""
+	attendance.EarlyLeaveMinutes = 0
+	if attendance.Status == constants.ENUM_ATTENDANCE_STATUS_EARLY_LEAVE {
+		attendance.Status = constants.ENUM_ATTENDANCE_STATUS_ON_TIME
+	}
 }
 
+// CheckOut closes the current work segment. The position is checked against the
+// location the segment was checked in at; a break still running ends with it.
 func (s *attendanceService) CheckOut(req dto.CheckOutDTO) (*entities.Attendance, error) {
-	// Find today's attendance
-	attendance, err := s.attendanceRepository.FindTodayByEmployeeID(req.EmployeeID)
+	return s.checkOut(req, livePunch(req.DeviceID))
+}
+
+func (s *attendanceService) checkOut(req dto.CheckOutDTO, origin punchOrigin) (*entities.Attendance, error) {
+	attendance, err := s.openAttendance(req.EmployeeID, origin.at)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("no check-in record found for today")
-		}
 		return nil, err
 	}
 
-	if attendance.CheckOutTime != nil {
-		return nil, errors.New("already checked out today")
+	location, err := s.segmentLocation(attendance)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
+	distance, err := verifyPunchPosition(location, *req.Latitude, *req.Longitude, req.Accuracy, req.KioskCode, origin.at)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSelfie(location, req.Photo); err != nil {
+		return nil, err
+	}
+
+	photo, err := storeSelfie(req.Photo)
+	if err != nil {
+		return nil, err
+	}
+
+	now := origin.at.In(helpers.LoadTimezone(attendance.Location.Timezone))
+	punch := entities.AttendancePunch{
+		Type:       constants.ENUM_PUNCH_TYPE_OUT,
+		PunchTime:  now,
+		LocationID: &location.ID,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		Accuracy:   &req.Accuracy,
+		Distance:   distance,
+		Photo:      photo,
+	}
+	origin.stamp(&punch)
+
 	attendance.CheckOutTime = &now
+	attendance.CheckOutLatitude = req.Latitude
+	attendance.CheckOutLongitude = req.Longitude
+	attendance.CheckOutAccuracy = &req.Accuracy
+	attendance.CheckOutDistance = distance
+	attendance.CheckOutPhoto = photo
+	attendance.Punches = append(attendance.Punches, punch)
+	applyShiftOnCheckOut(attendance)
 
-	return s.attendanceRepository.Update(attendance)
+	result, err := s.attendanceRepository.RecordPunch(context.Background(), attendance, punch)
+	if err != nil {
+		discardSelfie(photo)
+		return nil, punchConflict(err)
+	}
+	return result, nil
 }
 
-func (s *attendanceService) Update(id string, req dto.UpdateAttendanceDTO) (*entities.Attendance, error) {
-	uid, err := uuid.Parse(id)
+// PhotoFile returns the file of the selfie taken at the "check-in" or
+// "check-out" of an attendance.
+func (s *attendanceService) PhotoFile(id string, punch string) (string, error) {
+	attendance, err := s.GetByID(id)
 	if err != nil {
-		return nil, errors.New("invalid id")
+		return "", err
 	}
 
-	attendance, err := s.attendanceRepository.FindByID(uid)
+	var photo string
+	switch punch {
+	case "check-in":
+		photo = attendance.CheckInPhoto
+	case "check-out":
+		photo = attendance.CheckOutPhoto
+	}
+	if photo == "" {
+		return "", dto.ErrPhotoNotFound
+	}
+	return selfieFile(photo), nil
+}
+
+func (s *attendanceService) StartBreak(req dto.BreakDTO) (*entities.Attendance, error) {
+	return s.punchBreak(req, constants.ENUM_PUNCH_TYPE_BREAK_START, livePunch(req.DeviceID))
+}
+
+func (s *attendanceService) EndBreak(req dto.BreakDTO) (*entities.Attendance, error) {
+	return s.punchBreak(req, constants.ENUM_PUNCH_TYPE_BREAK_END, livePunch(req.DeviceID))
+}
+
+// punchBreak records a break-start or break-end on the open record. Breaks may
+// be taken off site, so the position is kept with its distance to the location
+// but not checked against the geofence.
+func (s *attendanceService) punchBreak(req dto.BreakDTO, kind string, origin punchOrigin) (*entities.Attendance, error) {
+	attendance, err := s.openAttendance(req.EmployeeID, origin.at)
 	if err != nil {
 		return nil, err
 	}
 
-	attendance.Status = req.Status
+	last := lastPunch(attendance)
+	onBreak := last != nil && last.Type == constants.ENUM_PUNCH_TYPE_BREAK_START
+	if kind == constants.ENUM_PUNCH_TYPE_BREAK_START && onBreak {
+		return nil, dto.ErrAlreadyOnBreak
+	}
+	if kind == constants.ENUM_PUNCH_TYPE_BREAK_END && !onBreak {
+		return nil, dto.ErrNotOnBreak
+	}
 
-	return s.attendanceRepository.Update(attendance)
-}
+	location, err := s.segmentLocation(attendance)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *attendanceService) Approve(id string, req dto.ApproveAttendanceDTO) (*entities.Attendance, error) {
-	uid, err := uuid.Parse(id)
+	now := origin.at.In(helpers.LoadTimezone(attendance.Location.Timezone))
+	var distance *float64
+	if !location.IsRemote {
+		d := helpers.HaversineDistance(
+			helpers.GeoPoint{Latitude: *req.Latitude, Longitude: *req.Longitude},
+			helpers.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude},
+		)
+		distance = &d
+	}
+	punch := entities.AttendancePunch{
+		Type:       kind,
+		PunchTime:  now,
+		LocationID: &location.ID,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		Accuracy:   &req.Accuracy,
+		Distance:   distance,
+	}
+	origin.stamp(&punch)
+
+	attendance.Punches = append(attendance.Punches, punch)
+	_, attendance.BreakMinutes, _ = summarizePunches(attendance.Punches)
+
+	result, err := s.attendanceRepository.RecordPunch(context.Background(), attendance, punch)
 	if err != nil {
-		return nil, errors.New("invalid id")
+		return nil, punchConflict(err)
 	}
+	return result, nil
+}
 
-	attendance, err := s.attendanceRepository.FindByID(uid)
+// punchConflict reports a punch that lost a race with another punch of the
+// same employee: a second record for the work date, refused by the unique
+// index, or a record changed after it was read.
+func punchConflict(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return dto.ErrAlreadyCheckedIn
+	case errors.Is(err, repository.ErrAttendanceChanged):
+		return dto.ErrPunchConflict
+	default:
+		return err
+	}
+}
+
+// openAttendance finds the record the employee is checked in on and that still
+// accepted punches at the time of the punch. A punch earlier than the last one
+// on the record is refused.
+func (s *attendanceService) openAttendance(employeeID uuid.UUID, at time.Time) (*entities.Attendance, error) {
+	// Two days back covers an overnight shift at a location ahead of the company zone
+	since := helpers.DateOf(at.In(helpers.LoadTimezone("")).AddDate(0, 0, -2))
+	attendance, err := s.attendanceRepository.FindOpenByEmployeeID(employeeID, since)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrNoOpenAttendance
+		}
 		return nil, err
 	}
 
-	attendance.Status = req.Status
+	loc := helpers.LoadTimezone(attendance.Location.Timezone)
+	if at.In(loc).After(checkOutDeadline(attendance, loc)) {
+		return nil, dto.ErrNoOpenAttendance
+	}
+	if last := lastPunch(attendance); last != nil && at.Before(last.PunchTime) {
+		return nil, dto.ErrPunchOutOfOrder
+	}
+	return attendance, nil
+}
 
-	return s.attendanceRepository.Update(attendance)
+// segmentLocation loads the location of the current work segment when it is not
+// the one the record was first checked in at.
+func (s *attendanceService) segmentLocation(attendance *entities.Attendance) (entities.Location, error) {
+	locationID := segmentLocationID(attendance)
+	if locationID == nil || (attendance.LocationID != nil && *locationID == *attendance.LocationID) {
+		return attendance.Location, nil
+	}
+	return s.masterRepository.GetLocationByID(context.Background(), nil, *locationID)
 }
 
 func (s *attendanceService) Delete(id string) error {
@@ -159,3 +486,245 @@ func (s *attendanceService) Delete(id string) error {
 
 	return s.attendanceRepository.Delete(uid)
 }
+
+// Reclassify classifies the checked-in records of an employee from start to
+// end again against the half-day and hourly leaves still approved on each day,
+// e.g. once a leave that excused part of a shift was cancelled.
+func (s *attendanceService) Reclassify(ctx context.Context, employeeID uuid.UUID, start, end time.Time) error {
+	for day := helpers.DateOf(start); !day.After(helpers.DateOf(end)); day = day.AddDate(0, 0, 1) {
+		attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(employeeID, day)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if attendance.CheckInTime == nil {
+			continue
+		}
+
+		leaves, err := s.leaveRepository.FindApprovedPartialOn(ctx, nil, employeeID, day)
+		if err != nil {
+			return err
+		}
+		if err := recomputeAttendance(attendance, leaves); err != nil {
+			return err
+		}
+		if err := s.attendanceRepository.SaveWithPunches(ctx, attendance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyPunchPosition checks that a punch was made at the location and returns
+// its distance to it. The remote location has no position to check against or
+// measure from; staff working away from a location punch there with an
+// approved remote-work request.
+func verifyPunchPosition(location entities.Location, latitude, longitude, accuracy float64, kioskCode string, now time.Time) (*float64, error) {
+	if location.IsRemote {
+		if !location.IsActive {
+			return nil, dto.ErrLocationInactive
+		}
+		return nil, nil
+	}
+
+	distance, err := verifyPresence(location, latitude, longitude, accuracy, kioskCode, now)
+	if err != nil {
+		return nil, err
+	}
+	return &distance, nil
+}
+
+// verifyPresence checks that the punch was made at the location: with a valid
+// kiosk code at a kiosk location, where indoor GPS is not trusted, and against
+// the geofence anywhere else. It returns the distance of the device fix to the
+// location center in meters.
+func verifyPresence(location entities.Location, latitude, longitude, accuracy float64, kioskCode string, now time.Time) (float64, error) {
+	if !location.KioskEnabled && kioskCode == "" {
+		return verifyGeofence(location, latitude, longitude, accuracy)
+	}
+
+	if !location.IsActive {
+		return 0, dto.ErrLocationInactive
+	}
+	if kioskCode == "" {
+		return 0, dto.ErrKioskCodeRequired
+	}
+
+	period := constants.KIOSK_CODE_PERIOD_SECONDS * time.Second
+	if !location.KioskEnabled || !helpers.VerifyTOTP(location.KioskSecret, kioskCode, now, period, constants.KIOSK_CODE_DIGITS, constants.KIOSK_CODE_SKEW_PERIODS) {
+		return 0, dto.ErrKioskCodeInvalid
+	}
+
+	return helpers.HaversineDistance(
+		helpers.GeoPoint{Latitude: latitude, Longitude: longitude},
+		helpers.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude},
+	), nil
+}
+
+// verifyGeofence checks a device fix against the location area and returns its
+// distance to the location center in meters. A polygon, when configured, takes
+// precedence over the radius; a location with neither accepts any position.
+func verifyGeofence(location entities.Location, latitude, longitude, accuracy float64) (float64, error) {
+	if !location.IsActive {
+		return 0, dto.ErrLocationInactive
+	}
+
+	if accuracy > constants.ATTENDANCE_MAX_GPS_ACCURACY_METERS {
+		return 0, dto.ErrGPSAccuracyTooLow
+	}
+
+	point := helpers.GeoPoint{Latitude: latitude, Longitude: longitude}
+	distance := helpers.HaversineDistance(point, helpers.GeoPoint{
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+	})
+
+	polygon, err := helpers.ParsePolygon(location.Polygon)
+	if err != nil {
+		return 0, dto.ErrLocationPolygon
+	}
+
+	if polygon != nil {
+		if !helpers.PointInPolygon(point, polygon) {
+			return 0, dto.ErrOutsideGeofence
+		}
+		return distance, nil
+	}
+
+	if location.RadiusMeters > 0 && distance > float64(location.RadiusMeters) {
+		return 0, dto.ErrOutsideGeofence
+	}
+
+	return distance, nil
+}
+
+// resolveWorkDay picks the work date a punch at now belongs to, as midnight in
+// now's location. A punch still inside yesterday's overnight shift counts towards
+// yesterday; anything else counts towards today.
+func (s *attendanceService) resolveWorkDay(employeeID uuid.UUID, now time.Time) (time.Time, *entities.Shift, error) {
+	today := helpers.StartOfDay(now)
+	yesterday := today.AddDate(0, 0, -1)
+
+	shift, err := s.shiftService.ResolveShift(context.Background(), employeeID, yesterday)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if shift != nil {
+		_, end, err := shiftService.ShiftWindow(*shift, yesterday)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		if now.Before(end) {
+			return yesterday, shift, nil
+		}
+	}
+
+	shift, err = s.shiftService.ResolveShift(context.Background(), employeeID, today)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return today, shift, nil
+}
+
+// checkOutDeadline is the last moment an open record accepts a check-out: the end
+// of its work date, or a grace period after the scheduled end when that is later.
+func checkOutDeadline(attendance *entities.Attendance, loc *time.Location) time.Time {
+	wd := attendance.WorkDate
+	deadline := time.Date(wd.Year(), wd.Month(), wd.Day()+1, 0, 0, 0, 0, loc)
+	if attendance.ScheduledEnd != nil {
+		grace := attendance.ScheduledEnd.Add(constants.ATTENDANCE_CHECK_OUT_GRACE_HOURS * time.Hour)
+		if grace.After(deadline) {
+			deadline = grace
+		}
+	}
+	return deadline
+}
+
+// applyShiftOnCheckIn snapshots the scheduled window of shift onto the record and
+// classifies the check-in. Without a shift the record stays "present". The
+// window left by the approved half-day and hourly leaves of the day is what is
+// snapshotted.
+func applyShiftOnCheckIn(attendance *entities.Attendance, shift *entities.Shift, workDate time.Time, leaves []entities.Leave) error {
+	if shift == nil || attendance.CheckInTime == nil {
+		return nil
+	}
+
+	start, end, err := shiftService.ShiftWindow(*shift, workDate)
+	if err != nil {
+		return err
+	}
+	start, end, err = excuseLeaves(start, end, *shift, workDate, leaves)
+	if err != nil {
+		return err
+	}
+
+	attendance.ShiftID = &shift.ID
+	attendance.ScheduledStart = &start
+	attendance.ScheduledEnd = &end
+	attendance.Status = constants.ENUM_ATTENDANCE_STATUS_ON_TIME
+
+	deadline := start.Add(time.Duration(shift.GracePeriodMinutes) * time.Minute)
+	if attendance.CheckInTime.After(deadline) {
+		attendance.Status = constants.ENUM_ATTENDANCE_STATUS_LATE
+		attendance.LateMinutes = int(attendance.CheckInTime.Sub(start).Minutes())
+	}
+
+	return nil
+}
+
+// excuseLeaves narrows a scheduled window by partial leaves. Leave taken from
+// the start moves the start to its end and leave taken up to the end moves the
+// end to its start, so a morning half day is not late and an afternoon one is
+// no early leave. Hours off in the middle of the shift change neither.
+func excuseLeaves(start, end time.Time, shift entities.Shift, workDate time.Time, leaves []entities.Leave) (time.Time, time.Time, error) {
+	for narrowed := true; narrowed; {
+		narrowed = false
+		for _, leave := range leaves {
+			from, to, err := shiftService.LeaveWindow(leave, &shift, workDate)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			if !from.After(start) && to.After(start) && to.Before(end) {
+				start, narrowed = to, true
+			}
+			if !to.Before(end) && from.Before(end) && from.After(start) {
+				end, narrowed = from, true
+			}
+		}
+	}
+	return start, end, nil
+}
+
+// applyShiftOnCheckOut computes worked and break minutes from the punches and
+// flags an early leave. Without punched breaks the shift break is deducted
+// instead. A late arrival keeps its "late" status.
+func applyShiftOnCheckOut(attendance *entities.Attendance) {
+	if attendance.CheckInTime == nil || attendance.CheckOutTime == nil {
+		return
+	}
+
+	worked, breaks, ok := summarizePunches(attendance.Punches)
+	if !ok {
+		worked = int(attendance.CheckOutTime.Sub(*attendance.CheckInTime).Minutes())
+		breaks = 0
+	}
+	attendance.BreakMinutes = breaks
+	if breaks == 0 && attendance.Shift != nil && worked > attendance.Shift.BreakMinutes {
+		worked -= attendance.Shift.BreakMinutes
+	}
+	if worked < 0 {
+		worked = 0
+	}
+	attendance.WorkedMinutes = worked
+
+	if attendance.ScheduledEnd == nil || !attendance.CheckOutTime.Before(*attendance.ScheduledEnd) {
+		return
+	}
+
+	attendance.EarlyLeaveMinutes = int(attendance.ScheduledEnd.Sub(*attendance.CheckOutTime).Minutes())
+	if attendance.Status == constants.ENUM_ATTENDANCE_STATUS_ON_TIME {
+		attendance.Status = constants.ENUM_ATTENDANCE_STATUS_EARLY_LEAVE
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// punchOrigin is when a punch was made and, for punches queued on a device
+// while offline, which device and upload it came from.
+type punchOrigin struct {
+	at       time.Time
+	clientID *uuid.UUID
+	deviceID string
+	syncedAt *time.Time
+}
+
+// livePunch is a punch made against the server right now on deviceID.
+func livePunch(deviceID string) punchOrigin {
+	return punchOrigin{at: time.Now(), deviceID: deviceID}
+}
+
+func (o punchOrigin) stamp(punch *entities.AttendancePunch) {
+	punch.ClientID = o.clientID
+	punch.DeviceID = o.deviceID
+	punch.SyncedAt = o.syncedAt
+}
+
+// Sync replays the punches a device queued while offline at the time the
+// device recorded them. They are applied oldest first, each on its own, so one
+// refused punch does not hold back the rest. A punch whose client id is
+// already stored is reported as a duplicate, which makes retried uploads safe.
+func (s *attendanceService) Sync(ctx context.Context, req dto.SyncDTO) (dto.SyncResult, error) {
+	receivedAt := time.Now()
+	result := dto.SyncResult{
+		ReceivedAt: receivedAt,
+		Items:      make([]dto.SyncItemResult, len(req.Punches)),
+	}
+
+	order := make([]int, len(req.Punches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Punches[order[a]].PunchedAt.Before(req.Punches[order[b]].PunchedAt)
+	})
+
+	seen := make(map[uuid.UUID]bool, len(req.Punches))
+	for _, i := range order {
+		item := req.Punches[i]
+		if seen[item.ClientID] {
+			result.Items[i] = dto.SyncItemResult{ClientID: item.ClientID, Status: constants.ENUM_SYNC_STATUS_DUPLICATE}
+			continue
+		}
+		seen[item.ClientID] = true
+
+		clientID := item.ClientID
+		origin := punchOrigin{at: item.PunchedAt, clientID: &clientID, deviceID: req.DeviceID, syncedAt: &receivedAt}
+		result.Items[i] = s.syncPunch(ctx, item, origin)
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case constants.ENUM_SYNC_STATUS_ACCEPTED:
+			result.Accepted++
+		case constants.ENUM_SYNC_STATUS_DUPLICATE:
+			result.Duplicates++
+		default:
+			result.Rejected++
+		}
+	}
+	return result, nil
+}
+
+func (s *attendanceService) syncPunch(ctx context.Context, item dto.SyncPunchDTO, origin punchOrigin) dto.SyncItemResult {
+	result := dto.SyncItemResult{ClientID: item.ClientID}
+
+	if punch, err := s.attendanceRepository.FindPunchByClientID(ctx, nil, item.ClientID); err == nil {
+		result.Status = constants.ENUM_SYNC_STATUS_DUPLICATE
+		result.AttendanceID = &punch.AttendanceID
+		return result
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		result.Status = constants.ENUM_SYNC_STATUS_REJECTED
+		result.Error = err.Error()
+		return result
+	}
+
+	attendance, err := s.applySyncedPunch(item, origin)
+	if err != nil {
+		// A concurrent upload of the same punch loses on the unique client id
+		if punch, findErr := s.attendanceRepository.FindPunchByClientID(ctx, nil, item.ClientID); findErr == nil {
+			result.Status = constants.ENUM_SYNC_STATUS_DUPLICATE
+			result.AttendanceID = &punch.AttendanceID
+			return result
+		}
+		result.Status = constants.ENUM_SYNC_STATUS_REJECTED
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = constants.ENUM_SYNC_STATUS_ACCEPTED
+	result.AttendanceID = &attendance.ID
+	return result
+}
+
+// applySyncedPunch checks the device clock against the time the upload was
+// received, then routes the punch like its live counterpart.
+func (s *attendanceService) applySyncedPunch(item dto.SyncPunchDTO, origin punchOrigin) (*entities.Attendance, error) {
+	receivedAt := *origin.syncedAt
+	if item.PunchedAt.Before(receivedAt.Add(-constants.ATTENDANCE_SYNC_MAX_AGE_HOURS * time.Hour)) {
+		return nil, dto.ErrSyncTooOld
+	}
+	if item.PunchedAt.After(receivedAt.Add(constants.ATTENDANCE_SYNC_MAX_AHEAD_MINUTES * time.Minute)) {
+		return nil, dto.ErrSyncInFuture
+	}
+
+	if item.EmployeeID == uuid.Nil {
+		return nil, dto.ErrSyncEmployee
+	}
+
+	switch item.Type {
+	case constants.ENUM_PUNCH_TYPE_IN:
+		if item.LocationID == nil && !item.Remote {
+			return nil, dto.ErrSyncLocation
+		}
+		req := dto.CheckInDTO{
+			EmployeeID: item.EmployeeID,
+			Remote:     item.Remote,
+			Latitude:   item.Latitude,
+			Longitude:  item.Longitude,
+			Accuracy:   item.Accuracy,
+			KioskCode:  item.KioskCode,
+		}
+		if item.LocationID != nil {
+			req.LocationID = *item.LocationID
+		}
+		return s.checkIn(req, origin)
+	case constants.ENUM_PUNCH_TYPE_OUT:
+		return s.checkOut(dto.CheckOutDTO{
+			EmployeeID: item.EmployeeID,
+			Latitude:   item.Latitude,
+			Longitude:  item.Longitude,
+			Accuracy:   item.Accuracy,
+			KioskCode:  item.KioskCode,
+		}, origin)
+	default:
+		return s.punchBreak(dto.BreakDTO{
+			EmployeeID: item.EmployeeID,
+			Latitude:   item.Latitude,
+			Longitude:  item.Longitude,
+			Accuracy:   item.Accuracy,
+		}, item.Type, origin)
+	}
+}
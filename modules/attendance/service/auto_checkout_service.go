@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type AutoCheckoutService interface {
+	CloseStale(ctx context.Context, now time.Time) (dto.AutoCheckoutResult, error)
+}
+
+type autoCheckoutService struct {
+	attendanceRepository repository.AttendanceRepository
+	notificationService  notificationService.NotificationService
+	db                   *gorm.DB
+}
+
+func NewAutoCheckoutService(
+	attendanceRepo repository.AttendanceRepository,
+	notificationSvc notificationService.NotificationService,
+	db *gorm.DB,
+) AutoCheckoutService {
+	return &autoCheckoutService{
+		attendanceRepository: attendanceRepo,
+		notificationService:  notificationSvc,
+		db:                   db,
+	}
+}
+
+// CloseStale closes every open record whose check-out deadline has passed by
+// now. The check-out is set to the scheduled shift end, or to the configured
+// cut-off on the work date for records without a shift, and flagged as
+// automatic so it can be reviewed. The employee is asked to file a correction.
+func (s *autoCheckoutService) CloseStale(ctx context.Context, now time.Time) (dto.AutoCheckoutResult, error) {
+	var result dto.AutoCheckoutResult
+
+	today := helpers.DateOf(now.In(helpers.LoadTimezone("")))
+	open, err := s.attendanceRepository.FindOpenUntil(ctx, nil, today)
+	if err != nil {
+		return result, err
+	}
+	result.Open = len(open)
+
+	for i := range open {
+		attendance := &open[i]
+		loc := helpers.LoadTimezone(attendance.Location.Timezone)
+		if !now.After(autoCheckoutDeadline(attendance, loc)) {
+			continue
+		}
+
+		checkOut := autoCheckoutTime(attendance, loc)
+		punch := entities.AttendancePunch{
+			Type:       constants.ENUM_PUNCH_TYPE_OUT,
+			PunchTime:  checkOut,
+			LocationID: segmentLocationID(attendance),
+		}
+		attendance.CheckOutTime = &checkOut
+		attendance.Punches = append(attendance.Punches, punch)
+		applyShiftOnCheckOut(attendance)
+
+		closed, err := s.attendanceRepository.CloseAutomatically(ctx, nil, *attendance, punch)
+		if err != nil {
+			return result, err
+		}
+		if !closed {
+			continue
+		}
+		attendance.AutoCheckout = true
+		result.Closed++
+
+		s.notifyEmployee(ctx, *attendance, loc)
+	}
+
+	return result, nil
+}
+
+func (s *autoCheckoutService) notifyEmployee(ctx context.Context, attendance entities.Attendance, loc *time.Location) {
+	workDate := attendance.WorkDate.Format("2006-01-02")
+	title := fmt.Sprintf("Missing check-out on %s", workDate)
+	body := fmt.Sprintf("Your attendance on %s had no check-out and was closed automatically at %s. Please file a correction with the time you actually left.",
+		workDate, attendance.CheckOutTime.In(loc).Format("15:04"))
+	data := map[string]any{"attendance_id": attendance.ID, "work_date": workDate}
+
+	if _, err := s.notificationService.Notify(ctx, attendance.Employee.User, constants.ENUM_NOTIFICATION_TYPE_AUTO_CHECKOUT, title, body, data); err != nil {
+		log.Printf("auto checkout %s: failed to notify employee %s: %v", attendance.ID, attendance.EmployeeID, err)
+	}
+}
+
+// autoCheckoutDeadline is the moment CloseStale closes an open record. A record
+// without a shift is closed at the cut-off on its work date, unless it was
+// punched after the cut-off; everything else waits for checkOutDeadline.
+func autoCheckoutDeadline(attendance *entities.Attendance, loc *time.Location) time.Time {
+	if attendance.ScheduledEnd == nil {
+		cutOff := autoCheckoutCutOff(attendance.WorkDate, loc)
+		last := attendance.CheckInTime
+		if punch := lastPunch(attendance); punch != nil {
+			last = &punch.PunchTime
+		}
+		if last == nil || last.Before(cutOff) {
+			return cutOff
+		}
+	}
+	return checkOutDeadline(attendance, loc)
+}
+
+// autoCheckoutCutOff is the ATTENDANCE_AUTO_CHECKOUT_AT clock on workDate,
+// falling back to DEFAULT_AUTO_CHECKOUT_AT.
+func autoCheckoutCutOff(workDate time.Time, loc *time.Location) time.Time {
+	hour, minute, err := shiftService.ParseClock(os.Getenv("ATTENDANCE_AUTO_CHECKOUT_AT"))
+	if err != nil {
+		hour, minute, _ = shiftService.ParseClock(constants.DEFAULT_AUTO_CHECKOUT_AT)
+	}
+	return time.Date(workDate.Year(), workDate.Month(), workDate.Day(), hour, minute, 0, 0, loc)
+}
+
+// autoCheckoutTime is the check-out written for a forgotten punch: the scheduled
+// shift end, otherwise the ATTENDANCE_AUTO_CHECKOUT_AT clock on the work date.
+// It never precedes the check-in or the latest punch.
+func autoCheckoutTime(attendance *entities.Attendance, loc *time.Location) time.Time {
+	var checkOut time.Time
+	if attendance.ScheduledEnd != nil {
+		checkOut = *attendance.ScheduledEnd
+	} else {
+		checkOut = autoCheckoutCutOff(attendance.WorkDate, loc)
+	}
+
+	if attendance.CheckInTime != nil && checkOut.Before(*attendance.CheckInTime) {
+		checkOut = *attendance.CheckInTime
+	}
+	if last := lastPunch(attendance); last != nil && checkOut.Before(last.PunchTime) {
+		checkOut = last.PunchTime
+	}
+	return checkOut
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RemoteWorkService interface {
+	Submit(ctx context.Context, userID string, req dto.RemoteWorkCreateDTO) (entities.RemoteWorkRequest, error)
+	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error)
+	FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error)
+	FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error)
+	GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.RemoteWorkRequest, error)
+	Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.RemoteWorkRequest, error)
+	Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.RemoteWorkRequest, error)
+}
+
+type remoteWorkService struct {
+	remoteWorkRepository repository.RemoteWorkRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	rbacService          rbacService.RbacService
+	db                   *gorm.DB
+}
+
+func NewRemoteWorkService(
+	remoteWorkRepo repository.RemoteWorkRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	rbacSvc rbacService.RbacService,
+	db *gorm.DB,
+) RemoteWorkService {
+	return &remoteWorkService{
+		remoteWorkRepository: remoteWorkRepo,
+		employeeRepository:   employeeRepo,
+		rbacService:          rbacSvc,
+		db:                   db,
+	}
+}
+
+// Submit files a remote work request of the logged-in employee for their
+// supervisor. Dates are company calendar dates from today on, and may not
+// overlap another request that is pending or approved.
+func (s *remoteWorkService) Submit(ctx context.Context, userID string, req dto.RemoteWorkCreateDTO) (entities.RemoteWorkRequest, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+
+	start, err := time.Parse(time.DateOnly, req.StartDate)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkDate
+	}
+	end, err := time.Parse(time.DateOnly, req.EndDate)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkDate
+	}
+	if end.Before(start) || end.Sub(start) >= constants.REMOTE_WORK_MAX_DAYS*24*time.Hour {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkRange
+	}
+	if start.Before(helpers.DateOf(time.Now().In(helpers.LoadTimezone("")))) {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkInPast
+	}
+
+	overlap, err := s.remoteWorkRepository.HasOverlap(ctx, nil, employee.ID, start, end)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	if overlap {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkOverlap
+	}
+
+	return s.remoteWorkRepository.Create(ctx, nil, entities.RemoteWorkRequest{
+		EmployeeID: employee.ID,
+		Type:       req.Type,
+		StartDate:  start,
+		EndDate:    end,
+		Reason:     req.Reason,
+		Status:     constants.ENUM_REMOTE_WORK_STATUS_PENDING,
+	})
+}
+
+func (s *remoteWorkService) FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	return s.remoteWorkRepository.FindAll(ctx, nil, filter)
+}
+
+func (s *remoteWorkService) FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.remoteWorkRepository.FindByEmployeeID(ctx, nil, filter, employee.ID)
+}
+
+func (s *remoteWorkService) FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.RemoteWorkRequest], error) {
+	supervisor, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.remoteWorkRepository.FindPendingBySupervisorID(ctx, nil, filter, supervisor.ID)
+}
+
+// GetByID shows a request to the employee who filed it, their supervisor and
+// holders of view_remote_work.
+func (s *remoteWorkService) GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.RemoteWorkRequest, error) {
+	request, err := s.remoteWorkRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	allowed, err := mayViewRequest(ctx, s.employeeRepository, s.rbacService, userID, request.Employee, constants.PERMISSION_VIEW_REMOTE_WORK)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	if !allowed {
+		return entities.RemoteWorkRequest{}, dto.ErrNotRequestViewer
+	}
+	return request, nil
+}
+
+// Approve lets the employee check in at the remote location on the requested dates.
+func (s *remoteWorkService) Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.RemoteWorkRequest, error) {
+	return s.review(ctx, id, userID, note, constants.ENUM_REMOTE_WORK_STATUS_APPROVED)
+}
+
+func (s *remoteWorkService) Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.RemoteWorkRequest, error) {
+	return s.review(ctx, id, userID, note, constants.ENUM_REMOTE_WORK_STATUS_REJECTED)
+}
+
+// review settles a pending request; only the supervisor of the employee who
+// filed it may.
+func (s *remoteWorkService) review(ctx context.Context, id uuid.UUID, userID string, note string, status string) (entities.RemoteWorkRequest, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+
+	request, err := s.remoteWorkRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.RemoteWorkRequest{}, err
+	}
+	if request.Status != constants.ENUM_REMOTE_WORK_STATUS_PENDING {
+		return entities.RemoteWorkRequest{}, dto.ErrRemoteWorkNotPending
+	}
+	if request.Employee.SupervisorID == nil || *request.Employee.SupervisorID != reviewer.ID {
+		return entities.RemoteWorkRequest{}, dto.ErrNotSupervisor
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.ApproverID = &reviewer.ID
+	request.ReviewedAt = &now
+	request.ReviewNote = note
+	return s.remoteWorkRepository.Update(ctx, nil, request)
+}
+
+func (s *remoteWorkService) employeeByUserID(ctx context.Context, userID string) (entities.Employee, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+	return employee, err
+}
@@ -1,10 +1,157 @@
 package tests
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestAttendanceucontroller (t *testing.T) {
 	assert.True(t, true)
 }
+
+type fakeAttendanceService struct {
+	service.AttendanceService
+	employees map[string]uuid.UUID
+	checkedIn *dto.CheckInDTO
+}
+
+func (s *fakeAttendanceService) EmployeeIDByUserID(ctx context.Context, userID string) (uuid.UUID, error) {
+	employeeID, ok := s.employees[userID]
+	if !ok {
+		return uuid.Nil, dto.ErrEmployeeNotLinked
+	}
+	return employeeID, nil
+}
+
+func (s *fakeAttendanceService) CheckIn(req dto.CheckInDTO) (*entities.Attendance, error) {
+	s.checkedIn = &req
+	return &entities.Attendance{EmployeeID: req.EmployeeID}, nil
+}
+
+// loggedInAs serves the attendance routes for userID, as Authenticate would.
+func loggedInAs(svc service.AttendanceService, userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	injector := do.New()
+	do.ProvideNamedValue(injector, constants.DB, (*gorm.DB)(nil))
+	attendanceController := controller.NewAttendanceController(injector, svc)
+
+	server := gin.New()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", userID)
+	})
+	server.POST("/api/attendances/me/check-in", attendanceController.MyCheckIn)
+	return server
+}
+
+func TestAttendanceController_MyCheckIn_UsesLinkedEmployee(t *testing.T) {
+	userID, employeeID := uuid.NewString(), uuid.New()
+	svc := &fakeAttendanceService{employees: map[string]uuid.UUID{userID: employeeID}}
+	body := `{"employee_id": "` + uuid.NewString() + `", "location_id": "` + uuid.NewString() + `", "latitude": -6.2088, "longitude": 106.8456}`
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/attendances/me/check-in", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	loggedInAs(svc, userID).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, employeeID, svc.checkedIn.EmployeeID, "the body cannot pick another employee")
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/api/attendances/me/check-in", strings.NewReader(`{"location_id": "`+uuid.NewString()+`", "latitude": -6.2088, "longitude": 106.8456}`))
+	request.Header.Set("Content-Type", "application/json")
+	loggedInAs(svc, userID).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code, "employee_id may be left out")
+}
+
+func TestAttendanceController_MyCheckIn_RequiresLinkedEmployee(t *testing.T) {
+	svc := &fakeAttendanceService{}
+	body := `{"employee_id": "` + uuid.NewString() + `", "location_id": "` + uuid.NewString() + `", "latitude": -6.2088, "longitude": 106.8456}`
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/attendances/me/check-in", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	loggedInAs(svc, uuid.NewString()).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Nil(t, svc.checkedIn)
+}
+
+type fakeAttendanceReportService struct {
+	report dto.AttendanceReport
+}
+
+func (s *fakeAttendanceReportService) Summary(ctx context.Context, req dto.ReportDTO) (dto.AttendanceReport, error) {
+	return s.report, nil
+}
+
+func serveReport(path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeAttendanceReportService{report: dto.AttendanceReport{From: "2026-09-01", To: "2026-09-30", Rows: []dto.AttendanceReportRow{
+		{EmployeeCode: "7", Name: "Budi & Sons", DaysPresent: 20, LateMinutes: 35, OvertimeHours: 2.25},
+	}}}
+	server := gin.New()
+	server.GET("/api/attendances/reports/summary", controller.NewAttendanceReportController(svc).Summary)
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func TestAttendanceReportController_Summary_ExportsCSV(t *testing.T) {
+	recorder := serveReport("/api/attendances/reports/summary?from=2026-09-01&to=2026-09-30&format=csv")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), "attendance-report-2026-09-01-2026-09-30.csv")
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "Employee Code,Name,Department,Days Present"))
+	assert.Equal(t, "7,Budi & Sons,,20,0,0,35,0,0,0,2.25,0", lines[1])
+}
+
+func TestAttendanceReportController_Summary_ExportsXLSX(t *testing.T) {
+	recorder := serveReport("/api/attendances/reports/summary?from=2026-09-01&to=2026-09-30&format=xlsx")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, helpers.XLSXContentType, recorder.Header().Get("Content-Type"))
+
+	body := recorder.Body.Bytes()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	assert.NoError(t, err)
+	var sheet string
+	for _, file := range archive.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			content, err := file.Open()
+			assert.NoError(t, err)
+			raw, _ := io.ReadAll(content)
+			sheet = string(raw)
+		}
+	}
+	assert.Contains(t, sheet, "Employee Code")
+	assert.Contains(t, sheet, "Budi &amp; Sons", "text is escaped")
+	assert.Contains(t, sheet, "<v>2.25</v>", "numbers are stored as numbers")
+}
+
+func TestAttendanceReportController_Summary_RejectsUnknownFormat(t *testing.T) {
+	recorder := serveReport("/api/attendances/reports/summary?from=2026-09-01&to=2026-09-30&format=pdf")
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -1,10 +1,162 @@
 package tests
 
 import (
+	"context"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestAttendanceurepository (t *testing.T) {
 	assert.True(t, true)
 }
+
+// sqliteUUID generates ids in the hyphenated form uuid.UUID writes.
+const sqliteUUID = `(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-' || lower(hex(randomblob(2))) || '-' || lower(hex(randomblob(2))) || '-' || lower(hex(randomblob(6))))`
+
+// newAttendanceDB opens a SQLite file with the attendance tables and the
+// unique work date index of the migrations. Writers queue up behind each other
+// like concurrent requests on Postgres.
+func newAttendanceDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "attendance.db") + "?_busy_timeout=10000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ddl := range []string{
+		`CREATE TABLE employees (id text PRIMARY KEY)`,
+		`CREATE TABLE locations (id text PRIMARY KEY)`,
+		`CREATE TABLE attendance (
+			id text PRIMARY KEY DEFAULT ` + sqliteUUID + `,
+			employee_id text, location_id text, check_in_time datetime, check_out_time datetime, status text,
+			work_date date,
+			check_in_latitude real, check_in_longitude real, check_in_accuracy real, check_in_distance real,
+			check_out_latitude real, check_out_longitude real, check_out_accuracy real, check_out_distance real,
+			check_in_photo text, check_out_photo text,
+			shift_id text, scheduled_start datetime, scheduled_end datetime,
+			late_minutes integer DEFAULT 0, early_leave_minutes integer DEFAULT 0, worked_minutes integer DEFAULT 0,
+			break_minutes integer DEFAULT 0, auto_checkout boolean DEFAULT false,
+			work_mode text DEFAULT 'onsite', remote_work_request_id text,
+			created_at datetime DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE UNIQUE INDEX uq_attendance_employee_work_date ON attendance (employee_id, work_date)`,
+		`CREATE TABLE attendance_punches (
+			id text PRIMARY KEY DEFAULT ` + sqliteUUID + `,
+			attendance_id text NOT NULL REFERENCES attendance(id), type text NOT NULL, punch_time datetime NOT NULL, location_id text,
+			latitude real, longitude real, accuracy real, distance real, photo text,
+			client_id text, device_id text, synced_at datetime, terminal_status integer,
+			created_at datetime DEFAULT CURRENT_TIMESTAMP
+		)`,
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func checkedInOn(employeeID uuid.UUID, workDate time.Time) *entities.Attendance {
+	checkIn := workDate.Add(8 * time.Hour)
+	return &entities.Attendance{
+		EmployeeID:  employeeID,
+		CheckInTime: &checkIn,
+		Status:      constants.ENUM_ATTENDANCE_STATUS_PRESENT,
+		WorkDate:    workDate,
+		Punches:     []entities.AttendancePunch{{Type: constants.ENUM_PUNCH_TYPE_IN, PunchTime: checkIn}},
+	}
+}
+
+func TestAttendanceRepository_Create_OneRecordPerWorkDate(t *testing.T) {
+	db := newAttendanceDB(t)
+	repo := repository.NewAttendanceRepository(db)
+	employeeID := uuid.New()
+	workDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	const taps = 8
+	var wg sync.WaitGroup
+	errs := make([]error, taps)
+	for i := 0; i < taps; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = repo.Create(checkedInOn(employeeID, workDate))
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	}
+	assert.Equal(t, 1, created, "concurrent check-ins create a single record")
+
+	var rows, punches int64
+	db.Model(&entities.Attendance{}).Count(&rows)
+	db.Model(&entities.AttendancePunch{}).Count(&punches)
+	assert.Equal(t, int64(1), rows)
+	assert.Equal(t, int64(1), punches, "a refused record leaves no punch behind")
+
+	_, err := repo.Create(checkedInOn(employeeID, workDate.AddDate(0, 0, 1)))
+	assert.NoError(t, err, "the next work date is a new record")
+}
+
+func TestAttendanceRepository_RecordPunch_RefusesStaleRecord(t *testing.T) {
+	db := newAttendanceDB(t)
+	repo := repository.NewAttendanceRepository(db)
+	workDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	attendance, err := repo.Create(checkedInOn(uuid.New(), workDate))
+	assert.NoError(t, err)
+
+	// Check-outs from repeated taps read the open record before any is written
+	const taps = 4
+	reads := make([]*entities.Attendance, taps)
+	for i := range reads {
+		reads[i], err = repo.FindByID(attendance.ID)
+		assert.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, taps)
+	for i := range reads {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkOut := workDate.Add(17 * time.Hour).Add(time.Duration(i) * time.Second)
+			punch := entities.AttendancePunch{Type: constants.ENUM_PUNCH_TYPE_OUT, PunchTime: checkOut}
+			reads[i].CheckOutTime = &checkOut
+			reads[i].Punches = append(reads[i].Punches, punch)
+			_, errs[i] = repo.RecordPunch(context.Background(), reads[i], punch)
+		}(i)
+	}
+	wg.Wait()
+
+	recorded := 0
+	for _, err := range errs {
+		if err == nil {
+			recorded++
+			continue
+		}
+		assert.ErrorIs(t, err, repository.ErrAttendanceChanged)
+	}
+	assert.Equal(t, 1, recorded, "only the first of racing punches lands")
+
+	stored, err := repo.FindByID(attendance.ID)
+	assert.NoError(t, err)
+	assert.Len(t, stored.Punches, 2)
+}
@@ -1,10 +1,1978 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	overtimeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/overtime/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 func TestAttendanceuservice (t *testing.T) {
 	assert.True(t, true)
 }
+
+type fakeAttendanceRepository struct {
+	repository.AttendanceRepository
+	today    *entities.Attendance
+	created  []*entities.Attendance
+	recorded []uuid.UUID
+	open     []entities.Attendance
+	closed   []entities.Attendance
+	// location is what the real repository preloads on a created record
+	location entities.Location
+	saved    int
+	inRange  []entities.Attendance
+	// conflict is what the database answers a write that lost a race
+	conflict error
+}
+
+func (r *fakeAttendanceRepository) FindByEmployeeAndWorkDate(employeeID uuid.UUID, workDate time.Time) (*entities.Attendance, error) {
+	if r.today == nil || !r.today.WorkDate.Equal(workDate) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.today, nil
+}
+
+func (r *fakeAttendanceRepository) FindOpenByEmployeeID(employeeID uuid.UUID, since time.Time) (*entities.Attendance, error) {
+	if r.today == nil || r.today.CheckOutTime != nil || r.today.WorkDate.Before(since) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.today, nil
+}
+
+func (r *fakeAttendanceRepository) Create(attendance *entities.Attendance) (*entities.Attendance, error) {
+	if r.conflict != nil {
+		return nil, r.conflict
+	}
+	attendance.Location = r.location
+	r.today = attendance
+	r.created = append(r.created, attendance)
+	return attendance, nil
+}
+
+func (r *fakeAttendanceRepository) FindOpenUntil(ctx context.Context, db *gorm.DB, workDate time.Time) ([]entities.Attendance, error) {
+	return r.open, nil
+}
+
+func (r *fakeAttendanceRepository) CloseAutomatically(ctx context.Context, tx *gorm.DB, attendance entities.Attendance, punch entities.AttendancePunch) (bool, error) {
+	r.closed = append(r.closed, attendance)
+	return true, nil
+}
+
+func (r *fakeAttendanceRepository) FindEmployeeIDsByWorkDate(ctx context.Context, db *gorm.DB, workDate time.Time) ([]uuid.UUID, error) {
+	return r.recorded, nil
+}
+
+func (r *fakeAttendanceRepository) Update(attendance *entities.Attendance) (*entities.Attendance, error) {
+	r.today = attendance
+	return attendance, nil
+}
+
+func (r *fakeAttendanceRepository) RecordPunch(ctx context.Context, attendance *entities.Attendance, punch entities.AttendancePunch) (*entities.Attendance, error) {
+	if r.conflict != nil {
+		return nil, r.conflict
+	}
+	r.today = attendance
+	return attendance, nil
+}
+
+func (r *fakeAttendanceRepository) SaveWithPunches(ctx context.Context, attendance *entities.Attendance) error {
+	r.today = attendance
+	r.saved++
+	return nil
+}
+
+func (r *fakeAttendanceRepository) FindPunchByClientID(ctx context.Context, db *gorm.DB, clientID uuid.UUID) (entities.AttendancePunch, error) {
+	if r.today != nil {
+		for _, punch := range r.today.Punches {
+			if punch.ClientID != nil && *punch.ClientID == clientID {
+				return punch, nil
+			}
+		}
+	}
+	return entities.AttendancePunch{}, gorm.ErrRecordNotFound
+}
+
+type fakeMasterRepository struct {
+	masterRepository.MasterRepository
+	location entities.Location
+	holiday  bool
+}
+
+func (r *fakeMasterRepository) GetLocationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Location, error) {
+	return r.location, nil
+}
+
+func (r *fakeMasterRepository) IsHoliday(ctx context.Context, db *gorm.DB, date time.Time, locationID *uuid.UUID) (bool, error) {
+	return r.holiday, nil
+}
+
+func (r *fakeMasterRepository) GetRemoteLocation(ctx context.Context, db *gorm.DB) (entities.Location, error) {
+	return r.location, nil
+}
+
+type fakeRemoteWorkRepository struct {
+	repository.RemoteWorkRepository
+	requests []entities.RemoteWorkRequest
+	updated  *entities.RemoteWorkRequest
+}
+
+func (r *fakeRemoteWorkRepository) FindApprovedOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (entities.RemoteWorkRequest, error) {
+	for _, request := range r.requests {
+		if request.EmployeeID == employeeID && request.Status == constants.ENUM_REMOTE_WORK_STATUS_APPROVED &&
+			!workDate.Before(request.StartDate) && !workDate.After(request.EndDate) {
+			return request, nil
+		}
+	}
+	return entities.RemoteWorkRequest{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRemoteWorkRepository) HasOverlap(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) (bool, error) {
+	for _, request := range r.requests {
+		if request.EmployeeID == employeeID && request.Status != constants.ENUM_REMOTE_WORK_STATUS_REJECTED &&
+			!start.After(request.EndDate) && !end.Before(request.StartDate) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRemoteWorkRepository) Create(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error) {
+	request.ID = uuid.New()
+	r.requests = append(r.requests, request)
+	return request, nil
+}
+
+func (r *fakeRemoteWorkRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.RemoteWorkRequest, error) {
+	for _, request := range r.requests {
+		if request.ID == id {
+			return request, nil
+		}
+	}
+	return entities.RemoteWorkRequest{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRemoteWorkRepository) Update(ctx context.Context, tx *gorm.DB, request entities.RemoteWorkRequest) (entities.RemoteWorkRequest, error) {
+	r.updated = &request
+	return request, nil
+}
+
+type fakeShiftService struct {
+	shiftService.ShiftService
+	shift    *entities.Shift
+	off      bool
+	restDays map[time.Weekday]bool
+}
+
+func (s *fakeShiftService) ResolveShift(ctx context.Context, employeeID uuid.UUID, date time.Time) (*entities.Shift, error) {
+	return s.shift, nil
+}
+
+func (s *fakeShiftService) ScheduleOn(ctx context.Context, employeeID uuid.UUID, date time.Time) (bool, *entities.Shift, error) {
+	return !s.off && !s.restDays[date.Weekday()], s.shift, nil
+}
+
+func newGeofenceService(location entities.Location) (service.AttendanceService, *fakeAttendanceRepository) {
+	attendanceRepo := &fakeAttendanceRepository{location: location}
+	return service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, nil), attendanceRepo
+}
+
+func newShiftService(shift *entities.Shift) (service.AttendanceService, *fakeAttendanceRepository) {
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	attendanceRepo := &fakeAttendanceRepository{location: location}
+	return service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{shift: shift}, nil), attendanceRepo
+}
+
+// shiftAround builds a shift whose start is offset from now, so the test does
+// not depend on the wall clock.
+func shiftAround(startOffset, length time.Duration, grace int) *entities.Shift {
+	start := time.Now().In(helpers.LoadTimezone("")).Add(startOffset)
+	end := start.Add(length)
+	return &entities.Shift{
+		ID:                 uuid.New(),
+		StartTime:          start.Format("15:04"),
+		EndTime:            end.Format("15:04"),
+		GracePeriodMinutes: grace,
+		BreakMinutes:       60,
+	}
+}
+
+// companyToday is the current work date in the company timezone.
+func companyToday() time.Time {
+	return helpers.DateOf(time.Now().In(helpers.LoadTimezone("")))
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestAttendanceService_CheckIn_InsideRadius(t *testing.T) {
+	svc, _ := newGeofenceService(entities.Location{
+		ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+	})
+
+	result, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2090),
+		Longitude:  float(106.8457),
+		Accuracy:   10,
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result.CheckInDistance)
+	assert.Less(t, *result.CheckInDistance, 100.0)
+	assert.Equal(t, -6.2090, *result.CheckInLatitude)
+}
+
+func TestAttendanceService_CheckIn_OutsideRadius(t *testing.T) {
+	svc, _ := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+	})
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2200),
+		Longitude:  float(106.8456),
+		Accuracy:   10,
+	})
+
+	assert.ErrorIs(t, err, dto.ErrOutsideGeofence)
+}
+
+func TestAttendanceService_CheckIn_Polygon(t *testing.T) {
+	polygon := datatypes.JSON(`[
+		{"latitude": -6.2080, "longitude": 106.8450},
+		{"latitude": -6.2080, "longitude": 106.8460},
+		{"latitude": -6.2095, "longitude": 106.8460},
+		{"latitude": -6.2095, "longitude": 106.8450}
+	]`)
+	svc, _ := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 5000, Polygon: polygon, IsActive: true,
+	})
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8470),
+		Accuracy:   5,
+	})
+	assert.ErrorIs(t, err, dto.ErrOutsideGeofence, "polygon takes precedence over the radius")
+
+	_, err = svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8455),
+		Accuracy:   5,
+	})
+	assert.NoError(t, err)
+}
+
+func TestAttendanceService_CheckIn_InactiveLocation(t *testing.T) {
+	svc, _ := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: false,
+	})
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+	})
+
+	assert.ErrorIs(t, err, dto.ErrLocationInactive)
+}
+
+func TestAttendanceService_CheckIn_PoorAccuracy(t *testing.T) {
+	svc, _ := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+	})
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   500,
+	})
+
+	assert.ErrorIs(t, err, dto.ErrGPSAccuracyTooLow)
+}
+
+func TestAttendanceService_CheckOut_OutsideRadius(t *testing.T) {
+	location := entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+	}
+	svc, attendanceRepo := newGeofenceService(location)
+	attendanceRepo.today = &entities.Attendance{Location: location, WorkDate: companyToday()}
+
+	_, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.3000),
+		Longitude:  float(106.8456),
+		Accuracy:   10,
+	})
+
+	assert.ErrorIs(t, err, dto.ErrOutsideGeofence)
+	assert.Nil(t, attendanceRepo.today.CheckOutTime)
+}
+
+func checkInAtOffice(svc service.AttendanceService) (*entities.Attendance, error) {
+	return svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+}
+
+func TestAttendanceService_CheckIn_WithoutShiftIsPresent(t *testing.T) {
+	svc, _ := newShiftService(nil)
+
+	result, err := checkInAtOffice(svc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_PRESENT, result.Status)
+	assert.Nil(t, result.ShiftID)
+}
+
+func TestAttendanceService_CheckIn_OnTimeWithinGrace(t *testing.T) {
+	if hour := time.Now().In(helpers.LoadTimezone("")).Hour(); hour == 0 || hour == 23 {
+		t.Skip("shift offsets would cross midnight")
+	}
+	svc, _ := newShiftService(shiftAround(-10*time.Minute, 8*time.Hour, 15))
+
+	result, err := checkInAtOffice(svc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_ON_TIME, result.Status)
+	assert.Equal(t, 0, result.LateMinutes)
+	assert.NotNil(t, result.ScheduledStart)
+}
+
+func TestAttendanceService_CheckIn_Late(t *testing.T) {
+	if hour := time.Now().In(helpers.LoadTimezone("")).Hour(); hour == 0 || hour == 23 {
+		t.Skip("shift offsets would cross midnight")
+	}
+	svc, _ := newShiftService(shiftAround(-45*time.Minute, 8*time.Hour, 15))
+
+	result, err := checkInAtOffice(svc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_LATE, result.Status)
+	assert.InDelta(t, 45, result.LateMinutes, 1)
+}
+
+func TestAttendanceService_CheckIn_PartialLeaveMovesTheStart(t *testing.T) {
+	if hour := time.Now().In(helpers.LoadTimezone("")).Hour(); hour < 4 || hour >= 19 {
+		t.Skip("shift offsets would cross midnight")
+	}
+	shift := shiftAround(-3*time.Hour, 8*time.Hour, 15)
+	start, err := time.Parse("15:04", shift.StartTime)
+	assert.NoError(t, err)
+	morning := constants.ENUM_LEAVE_SESSION_MORNING
+	doctorFrom, doctorTo := shift.StartTime, start.Add(2*time.Hour).Format("15:04")
+
+	cases := []struct {
+		name   string
+		leave  entities.Leave
+		status string
+		late   float64
+	}{
+		{"a morning off is not late", entities.Leave{Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Session: &morning}, constants.ENUM_ATTENDANCE_STATUS_ON_TIME, 0},
+		{"only the hours after the leave count", entities.Leave{Unit: constants.ENUM_LEAVE_UNIT_HOURLY, StartTime: &doctorFrom, EndTime: &doctorTo}, constants.ENUM_ATTENDANCE_STATUS_LATE, 60},
+	}
+	for _, c := range cases {
+		location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+		attendanceRepo := &fakeAttendanceRepository{location: location}
+		leaveRepo := &fakeLeaveRepository{partial: []entities.Leave{c.leave}}
+		svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, leaveRepo, &fakeShiftService{shift: shift}, nil)
+
+		result, err := checkInAtOffice(svc)
+
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.status, result.Status, c.name)
+		assert.InDelta(t, c.late, result.LateMinutes, 1, c.name)
+		assert.Equal(t, shift.EndTime, result.ScheduledEnd.Format("15:04"), c.name)
+	}
+}
+
+func TestAttendanceService_Reclassify_DropsACancelledLeave(t *testing.T) {
+	workDate := time.Date(2030, time.March, 4, 0, 0, 0, 0, time.UTC)
+	checkIn := time.Date(2030, time.March, 4, 10, 0, 0, 0, helpers.LoadTimezone(""))
+	shift := &entities.Shift{ID: uuid.New(), StartTime: "08:00", EndTime: "16:00"}
+	attendanceRepo := &fakeAttendanceRepository{today: &entities.Attendance{
+		WorkDate:    workDate,
+		CheckInTime: &checkIn,
+		Status:      constants.ENUM_ATTENDANCE_STATUS_ON_TIME,
+		Shift:       shift,
+	}}
+	// The morning off that excused the check-in was cancelled
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, nil)
+
+	err := svc.Reclassify(context.Background(), uuid.New(), workDate.AddDate(0, 0, -1), workDate.AddDate(0, 0, 1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attendanceRepo.saved, "only the day with a record is saved")
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_LATE, attendanceRepo.today.Status)
+	assert.Equal(t, 120, attendanceRepo.today.LateMinutes)
+}
+
+func TestAttendanceService_Reclassify_ExcusesAnApprovedHalfDay(t *testing.T) {
+	workDate := time.Date(2030, time.March, 4, 0, 0, 0, 0, time.UTC)
+	checkIn := time.Date(2030, time.March, 4, 11, 55, 0, 0, helpers.LoadTimezone(""))
+	shift := &entities.Shift{ID: uuid.New(), StartTime: "08:00", EndTime: "16:00"}
+	attendanceRepo := &fakeAttendanceRepository{today: &entities.Attendance{
+		WorkDate:    workDate,
+		CheckInTime: &checkIn,
+		Status:      constants.ENUM_ATTENDANCE_STATUS_LATE,
+		LateMinutes: 235,
+		Shift:       shift,
+	}}
+	// The morning off was approved after the check-in
+	morning := constants.ENUM_LEAVE_SESSION_MORNING
+	leaveRepo := &fakeLeaveRepository{partial: []entities.Leave{{Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Session: &morning}}}
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{}, &fakeRemoteWorkRepository{}, leaveRepo, &fakeShiftService{}, nil)
+
+	err := svc.Reclassify(context.Background(), uuid.New(), workDate, workDate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_ON_TIME, attendanceRepo.today.Status)
+	assert.Zero(t, attendanceRepo.today.LateMinutes)
+}
+
+func TestAttendanceService_CheckOut_EarlyLeave(t *testing.T) {
+	now := time.Now()
+	shift := &entities.Shift{BreakMinutes: 60}
+	scheduledEnd := now.Add(2 * time.Hour)
+	checkIn := now.Add(-6 * time.Hour)
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime:  &checkIn,
+		WorkDate:     companyToday(),
+		ScheduledEnd: &scheduledEnd,
+		Status:       constants.ENUM_ATTENDANCE_STATUS_ON_TIME,
+		Shift:        shift,
+		Location:     location,
+	}
+
+	result, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_EARLY_LEAVE, result.Status)
+	assert.InDelta(t, 120, result.EarlyLeaveMinutes, 1)
+	assert.InDelta(t, 300, result.WorkedMinutes, 1)
+}
+
+func TestAttendanceService_CheckIn_OvernightShiftKeepsStartDate(t *testing.T) {
+	now := time.Now().In(helpers.LoadTimezone(""))
+	if now.Hour() >= 21 {
+		t.Skip("shift clocks would cross midnight")
+	}
+	// Ends an hour from now and starts two hours from now, so yesterday's
+	// occurrence is still running
+	shift := &entities.Shift{
+		ID:        uuid.New(),
+		StartTime: now.Add(2 * time.Hour).Format("15:04"),
+		EndTime:   now.Add(time.Hour).Format("15:04"),
+	}
+	svc, _ := newShiftService(shift)
+
+	result, err := checkInAtOffice(svc)
+
+	assert.NoError(t, err)
+	assert.Equal(t, companyToday().AddDate(0, 0, -1), result.WorkDate)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_LATE, result.Status)
+}
+
+func TestAttendanceService_CheckOut_AfterMidnightForOvernightShift(t *testing.T) {
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	scheduledEnd := time.Now().Add(time.Hour)
+	checkIn := time.Now().Add(-7 * time.Hour)
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime:  &checkIn,
+		WorkDate:     companyToday().AddDate(0, 0, -1),
+		ScheduledEnd: &scheduledEnd,
+		Status:       constants.ENUM_ATTENDANCE_STATUS_ON_TIME,
+		Location:     location,
+	}
+
+	result, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result.CheckOutTime)
+}
+
+func TestAttendanceService_CheckOut_StaleRecordWithoutShift(t *testing.T) {
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	checkIn := time.Now().Add(-30 * time.Hour)
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime: &checkIn,
+		WorkDate:    companyToday().AddDate(0, 0, -1),
+		Location:    location,
+	}
+
+	_, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+
+	assert.Error(t, err)
+}
+
+func (r *fakeAttendanceRepository) FindByID(id uuid.UUID) (*entities.Attendance, error) {
+	if r.today == nil || r.today.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.today, nil
+}
+
+func punchAt(kind string, at time.Time) entities.AttendancePunch {
+	return entities.AttendancePunch{Type: kind, PunchTime: at}
+}
+
+func TestAttendanceService_CheckOut_DeductsPunchedBreak(t *testing.T) {
+	now := time.Now()
+	checkIn := now.Add(-6 * time.Hour)
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime: &checkIn,
+		WorkDate:    companyToday(),
+		Shift:       &entities.Shift{BreakMinutes: 60},
+		Location:    location,
+		Punches: []entities.AttendancePunch{
+			punchAt(constants.ENUM_PUNCH_TYPE_IN, checkIn),
+			punchAt(constants.ENUM_PUNCH_TYPE_BREAK_START, now.Add(-4*time.Hour)),
+			punchAt(constants.ENUM_PUNCH_TYPE_BREAK_END, now.Add(-210*time.Minute)),
+		},
+	}
+
+	result, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30, result.BreakMinutes)
+	assert.InDelta(t, 330, result.WorkedMinutes, 1, "the punched break replaces the shift break")
+	assert.Len(t, result.Punches, 4)
+	assert.Equal(t, constants.ENUM_PUNCH_TYPE_OUT, result.Punches[3].Type)
+}
+
+func TestAttendanceService_Break_RejectsOutOfOrderPunches(t *testing.T) {
+	checkIn := time.Now().Add(-2 * time.Hour)
+	location := entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime: &checkIn,
+		WorkDate:    companyToday(),
+		Location:    location,
+		Punches:     []entities.AttendancePunch{punchAt(constants.ENUM_PUNCH_TYPE_IN, checkIn)},
+	}
+	req := dto.BreakDTO{EmployeeID: uuid.New(), Latitude: float(-6.2100), Longitude: float(106.8456), Accuracy: 5}
+
+	_, err := svc.EndBreak(req)
+	assert.ErrorIs(t, err, dto.ErrNotOnBreak)
+
+	result, err := svc.StartBreak(req)
+	assert.NoError(t, err, "a break may be started outside the geofence")
+	assert.Equal(t, constants.ENUM_PUNCH_TYPE_BREAK_START, result.Punches[1].Type)
+	assert.Greater(t, *result.Punches[1].Distance, 100.0)
+
+	_, err = svc.StartBreak(req)
+	assert.ErrorIs(t, err, dto.ErrAlreadyOnBreak)
+}
+
+func TestAttendanceService_CheckIn_ResumesCheckedOutDay(t *testing.T) {
+	now := time.Now()
+	checkIn := now.Add(-5 * time.Hour)
+	checkOut := now.Add(-3 * time.Hour)
+	svc, attendanceRepo := newShiftService(nil)
+	attendanceRepo.today = &entities.Attendance{
+		CheckInTime:  &checkIn,
+		CheckOutTime: &checkOut,
+		WorkDate:     companyToday(),
+		Status:       constants.ENUM_ATTENDANCE_STATUS_PRESENT,
+		Location:     entities.Location{Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true},
+		Punches: []entities.AttendancePunch{
+			punchAt(constants.ENUM_PUNCH_TYPE_IN, checkIn),
+			punchAt(constants.ENUM_PUNCH_TYPE_OUT, checkOut),
+		},
+	}
+
+	resumed, err := checkInAtOffice(svc)
+
+	assert.NoError(t, err)
+	assert.Nil(t, resumed.CheckOutTime)
+	assert.Equal(t, checkIn, *resumed.CheckInTime, "the first check-in is kept")
+	assert.Len(t, resumed.Punches, 3)
+	assert.Empty(t, attendanceRepo.created)
+
+	// Back-date the second segment so it lasts an hour
+	resumed.Punches[2].PunchTime = now.Add(-time.Hour)
+	result, err := svc.CheckOut(dto.CheckOutDTO{
+		EmployeeID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	})
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 180, result.WorkedMinutes, 1, "both segments count, the gap between them does not")
+}
+
+// selfie builds an uploaded "photo" file the way gin hands it to the service.
+func selfie(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("photo", name)
+	assert.NoError(t, err)
+	_, err = part.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	assert.NoError(t, err)
+	return form.File["photo"][0]
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAttendanceService_CheckIn_SelfieRequiredAtLocation(t *testing.T) {
+	svc, attendanceRepo := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true, RequireSelfie: true,
+	})
+
+	_, err := checkInAtOffice(svc)
+
+	assert.ErrorIs(t, err, dto.ErrSelfieRequired)
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAttendanceService_CheckIn_RejectsNonImageSelfie(t *testing.T) {
+	svc, attendanceRepo := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+	})
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+		Photo:      selfie(t, "selfie.png", []byte("#!/bin/sh\necho not a photo\n")),
+	})
+
+	assert.ErrorIs(t, err, dto.ErrSelfieInvalid, "the content decides, not the file name")
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAttendanceService_CheckIn_StoresSelfie(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	svc, _ := newGeofenceService(entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true, RequireSelfie: true,
+	})
+
+	result, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+		Photo:      selfie(t, "IMG_0001.HEIC", pngHeader),
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.CheckInPhoto, constants.ATTENDANCE_SELFIE_DIR+"/"))
+	assert.True(t, strings.HasSuffix(result.CheckInPhoto, ".png"))
+	assert.Equal(t, result.CheckInPhoto, result.Punches[0].Photo)
+	assert.FileExists(t, filepath.Join(dir, "assets", result.CheckInPhoto))
+}
+
+func kioskLocation(t *testing.T) entities.Location {
+	secret, err := helpers.NewTOTPSecret()
+	assert.NoError(t, err)
+	return entities.Location{
+		Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true,
+		KioskEnabled: true, KioskSecret: secret,
+	}
+}
+
+func kioskCode(t *testing.T, location entities.Location, at time.Time) string {
+	code, err := helpers.TOTPCode(location.KioskSecret, at, constants.KIOSK_CODE_PERIOD_SECONDS*time.Second, constants.KIOSK_CODE_DIGITS)
+	assert.NoError(t, err)
+	return code
+}
+
+func TestAttendanceService_CheckIn_KioskCodeRequired(t *testing.T) {
+	svc, attendanceRepo := newGeofenceService(kioskLocation(t))
+
+	_, err := checkInAtOffice(svc)
+
+	assert.ErrorIs(t, err, dto.ErrKioskCodeRequired)
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAttendanceService_CheckIn_KioskCodeSkipsGeofence(t *testing.T) {
+	location := kioskLocation(t)
+	svc, _ := newGeofenceService(location)
+
+	result, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2200),
+		Longitude:  float(106.8456),
+		Accuracy:   800,
+		KioskCode:  kioskCode(t, location, time.Now()),
+	})
+
+	assert.NoError(t, err, "indoor GPS is not trusted at a kiosk")
+	assert.Greater(t, *result.CheckInDistance, 100.0)
+}
+
+func TestAttendanceService_CheckIn_KioskCodeInvalid(t *testing.T) {
+	location := kioskLocation(t)
+	svc, _ := newGeofenceService(location)
+	period := constants.KIOSK_CODE_PERIOD_SECONDS * time.Second
+
+	for name, code := range map[string]string{
+		"expired":      kioskCode(t, location, time.Now().Add(-3*period)),
+		"other secret": kioskCode(t, kioskLocation(t), time.Now()),
+		"truncated":    kioskCode(t, location, time.Now())[1:],
+	} {
+		_, err := svc.CheckIn(dto.CheckInDTO{
+			EmployeeID: uuid.New(),
+			LocationID: uuid.New(),
+			Latitude:   float(-6.2088),
+			Longitude:  float(106.8456),
+			Accuracy:   5,
+			KioskCode:  code,
+		})
+		assert.ErrorIs(t, err, dto.ErrKioskCodeInvalid, name)
+	}
+
+	location.KioskEnabled = false
+	svc, _ = newGeofenceService(location)
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: uuid.New(),
+		LocationID: uuid.New(),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+		KioskCode:  kioskCode(t, location, time.Now()),
+	})
+	assert.ErrorIs(t, err, dto.ErrKioskCodeInvalid, "a disabled kiosk's codes stop working")
+}
+
+// queuedPunch is a punch the app recorded offline at clock on yesterday's date.
+func queuedPunch(kind string, clock time.Duration) dto.SyncPunchDTO {
+	yesterday := helpers.StartOfDay(time.Now().In(helpers.LoadTimezone(""))).AddDate(0, 0, -1)
+	locationID := uuid.New()
+	return dto.SyncPunchDTO{
+		ClientID:   uuid.New(),
+		Type:       kind,
+		EmployeeID: uuid.New(),
+		LocationID: &locationID,
+		PunchedAt:  yesterday.Add(clock),
+		Latitude:   float(-6.2088),
+		Longitude:  float(106.8456),
+		Accuracy:   5,
+	}
+}
+
+func TestAttendanceService_Sync_ReplaysQueuedPunchesInOrder(t *testing.T) {
+	svc, attendanceRepo := newShiftService(nil)
+	punches := []dto.SyncPunchDTO{
+		queuedPunch(constants.ENUM_PUNCH_TYPE_OUT, 17*time.Hour),
+		queuedPunch(constants.ENUM_PUNCH_TYPE_BREAK_END, 12*time.Hour+30*time.Minute),
+		queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 9*time.Hour),
+		queuedPunch(constants.ENUM_PUNCH_TYPE_BREAK_START, 12*time.Hour),
+	}
+
+	result, err := svc.Sync(context.Background(), dto.SyncDTO{DeviceID: "android-7f3a", Punches: punches})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Accepted)
+	for i, item := range result.Items {
+		assert.Equal(t, punches[i].ClientID, item.ClientID, "results follow the upload order")
+		assert.Equal(t, constants.ENUM_SYNC_STATUS_ACCEPTED, item.Status, item.Error)
+	}
+
+	attendance := attendanceRepo.today
+	assert.Equal(t, punches[2].PunchedAt, *attendance.CheckInTime, "the device clock is kept")
+	assert.Equal(t, punches[0].PunchedAt, *attendance.CheckOutTime)
+	assert.Equal(t, 30, attendance.BreakMinutes)
+	assert.Equal(t, 450, attendance.WorkedMinutes)
+	assert.Equal(t, "android-7f3a", attendance.Punches[0].DeviceID)
+	assert.Equal(t, punches[2].ClientID, *attendance.Punches[0].ClientID)
+	assert.Equal(t, result.ReceivedAt, *attendance.Punches[0].SyncedAt)
+}
+
+func TestAttendanceService_Sync_ReportsDuplicates(t *testing.T) {
+	svc, attendanceRepo := newShiftService(nil)
+	in := queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 9*time.Hour)
+	req := dto.SyncDTO{DeviceID: "android-7f3a", Punches: []dto.SyncPunchDTO{in, in}}
+
+	first, err := svc.Sync(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.Accepted)
+	assert.Equal(t, 1, first.Duplicates, "a punch queued twice is stored once")
+
+	retry, err := svc.Sync(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, retry.Duplicates, "a retried upload changes nothing")
+	assert.Len(t, attendanceRepo.created, 1)
+	assert.Len(t, attendanceRepo.today.Punches, 1)
+}
+
+func TestAttendanceService_Sync_RejectsClockSkew(t *testing.T) {
+	svc, _ := newShiftService(nil)
+	stale := queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 0)
+	stale.PunchedAt = time.Now().Add(-(constants.ATTENDANCE_SYNC_MAX_AGE_HOURS + 1) * time.Hour)
+	ahead := queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 0)
+	ahead.PunchedAt = time.Now().Add(time.Hour)
+	in := queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 9*time.Hour)
+
+	result, err := svc.Sync(context.Background(), dto.SyncDTO{
+		DeviceID: "android-7f3a",
+		Punches:  []dto.SyncPunchDTO{stale, ahead, in},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	assert.Equal(t, dto.ErrSyncTooOld.Error(), result.Items[0].Error)
+	assert.Equal(t, dto.ErrSyncInFuture.Error(), result.Items[1].Error)
+	assert.Equal(t, constants.ENUM_SYNC_STATUS_ACCEPTED, result.Items[2].Status)
+}
+
+func TestAttendanceService_Sync_RejectsPunchBeforeLast(t *testing.T) {
+	svc, _ := newShiftService(nil)
+	in := queuedPunch(constants.ENUM_PUNCH_TYPE_IN, 9*time.Hour)
+	_, err := svc.Sync(context.Background(), dto.SyncDTO{DeviceID: "android-7f3a", Punches: []dto.SyncPunchDTO{in}})
+	assert.NoError(t, err)
+
+	result, err := svc.Sync(context.Background(), dto.SyncDTO{
+		DeviceID: "android-9c21",
+		Punches:  []dto.SyncPunchDTO{queuedPunch(constants.ENUM_PUNCH_TYPE_BREAK_START, 8*time.Hour)},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_SYNC_STATUS_REJECTED, result.Items[0].Status)
+	assert.Equal(t, dto.ErrPunchOutOfOrder.Error(), result.Items[0].Error)
+}
+
+type fakeCorrectionRepository struct {
+	repository.AttendanceCorrectionRepository
+	correction entities.AttendanceCorrection
+	applied    *entities.Attendance
+	audit      *entities.AuditLog
+}
+
+func (r *fakeCorrectionRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceCorrection, error) {
+	return r.correction, nil
+}
+
+func (r *fakeCorrectionRepository) HasPending(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, workDate time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeCorrectionRepository) Create(ctx context.Context, tx *gorm.DB, correction entities.AttendanceCorrection) (entities.AttendanceCorrection, error) {
+	r.correction = correction
+	return correction, nil
+}
+
+func (r *fakeCorrectionRepository) Apply(ctx context.Context, correction *entities.AttendanceCorrection, attendance *entities.Attendance, audit entities.AuditLog) error {
+	r.correction = *correction
+	r.applied = attendance
+	r.audit = &audit
+	return nil
+}
+
+type fakeEmployeeRepository struct {
+	employeeRepository.EmployeeRepository
+	byUserID map[uuid.UUID]entities.Employee
+	active   []entities.Employee
+}
+
+func (r *fakeEmployeeRepository) FindByUserID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (entities.Employee, error) {
+	employee, ok := r.byUserID[userID]
+	if !ok {
+		return entities.Employee{}, gorm.ErrRecordNotFound
+	}
+	return employee, nil
+}
+
+func (r *fakeEmployeeRepository) FindByEmployeeCodes(ctx context.Context, db *gorm.DB, codes []string) ([]entities.Employee, error) {
+	var employees []entities.Employee
+	for _, employee := range r.active {
+		for _, code := range codes {
+			if employee.EmployeeCode == code {
+				employees = append(employees, employee)
+				break
+			}
+		}
+	}
+	return employees, nil
+}
+
+func TestAttendanceService_ImportAttlog_FoldsScans(t *testing.T) {
+	location := entities.Location{ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, IsActive: true}
+	employee := entities.Employee{ID: uuid.New(), EmployeeCode: "7"}
+	attendanceRepo := &fakeAttendanceRepository{}
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{active: []entities.Employee{employee}}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, nil)
+
+	day := companyToday().AddDate(0, 0, -1).Format(time.DateOnly)
+	attlog := strings.Join([]string{
+		"     7\t" + day + " 08:01:10\t1\t0\t1\t0",
+		"     7\t" + day + " 08:01:40\t1\t0\t1\t0",
+		"     7\t" + day + " 12:00:00\t1\t2\t1\t0",
+		"     7\t" + day + " 12:45:00\t1\t3\t1\t0",
+		"     7\t" + day + " 17:05:00\t1\t0\t1\t0",
+		"    99\t" + day + " 08:00:00\t1\t0\t1\t0",
+		"not an attlog line",
+	}, "\r\n")
+
+	result, err := svc.ImportAttlog(context.Background(), location.ID, "CQZ7231560", strings.NewReader(attlog))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, result.Lines)
+	assert.Equal(t, 4, result.Imported)
+	assert.Equal(t, 1, result.Duplicates, "a double scan is one punch")
+	assert.Equal(t, 1, result.Attendances)
+	assert.Equal(t, []dto.AttlogUnmatchedPIN{{PIN: "99", Punches: 1}}, result.Unmatched)
+	assert.Len(t, result.Invalid, 1)
+	assert.Equal(t, 7, result.Invalid[0].Line)
+
+	attendance := attendanceRepo.today
+	var types []string
+	for _, punch := range attendance.Punches {
+		types = append(types, punch.Type)
+	}
+	assert.Equal(t, []string{
+		constants.ENUM_PUNCH_TYPE_IN,
+		constants.ENUM_PUNCH_TYPE_BREAK_START,
+		constants.ENUM_PUNCH_TYPE_BREAK_END,
+		constants.ENUM_PUNCH_TYPE_OUT,
+	}, types, "a scan with the check-in key closes the day when the employee is in")
+	assert.Equal(t, employee.ID, attendance.EmployeeID)
+	assert.Equal(t, "08:01", attendance.CheckInTime.Format("15:04"))
+	assert.Equal(t, "17:05", attendance.CheckOutTime.Format("15:04"))
+	assert.Equal(t, 45, attendance.BreakMinutes)
+	assert.Equal(t, 498, attendance.WorkedMinutes)
+	assert.Equal(t, "CQZ7231560", attendance.Punches[0].DeviceID)
+
+	again, err := svc.ImportAttlog(context.Background(), location.ID, "CQZ7231560", strings.NewReader(attlog))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, again.Imported, "importing the same file twice changes nothing")
+	assert.Equal(t, 5, again.Duplicates)
+	assert.Equal(t, 1, attendanceRepo.saved)
+}
+
+func TestAttendanceService_ImportPushedAttlog_ReadsPushColumns(t *testing.T) {
+	location := entities.Location{ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, IsActive: true}
+	employee := entities.Employee{ID: uuid.New(), EmployeeCode: "7"}
+	attendanceRepo := &fakeAttendanceRepository{}
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{active: []entities.Employee{employee}}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, nil)
+
+	day := companyToday().AddDate(0, 0, -1).Format(time.DateOnly)
+	attlog := strings.Join([]string{
+		"7\t" + day + " 08:00:00\t0\t1\t0\t0\t0",
+		"7\t" + day + " 12:00:00\t2\t1\t0\t0\t0",
+		"7\t" + day + " 12:30:00\t3\t1\t0\t0\t0",
+		"7\t" + day + " 17:00:00\t1\t1\t0\t0\t0",
+	}, "\n")
+
+	result, err := svc.ImportPushedAttlog(context.Background(), location.ID, "CQZ7231560", strings.NewReader(attlog))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Imported)
+	var types []string
+	for _, punch := range attendanceRepo.today.Punches {
+		types = append(types, punch.Type)
+	}
+	assert.Equal(t, []string{
+		constants.ENUM_PUNCH_TYPE_IN,
+		constants.ENUM_PUNCH_TYPE_BREAK_START,
+		constants.ENUM_PUNCH_TYPE_BREAK_END,
+		constants.ENUM_PUNCH_TYPE_OUT,
+	}, types, "the state key is the third column of a pushed line")
+	assert.Equal(t, 30, attendanceRepo.today.BreakMinutes)
+}
+
+func TestCorrectionService_ApproveAppliesAndKeepsOriginal(t *testing.T) {
+	supervisor := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	employee := entities.Employee{ID: uuid.New(), SupervisorID: &supervisor.ID}
+	checkIn := time.Now().Add(-10 * time.Hour)
+	locationID := uuid.New()
+	attendanceRepo := &fakeAttendanceRepository{today: &entities.Attendance{
+		ID:          uuid.New(),
+		EmployeeID:  employee.ID,
+		LocationID:  &locationID,
+		CheckInTime: &checkIn,
+		WorkDate:    companyToday(),
+		Status:      constants.ENUM_ATTENDANCE_STATUS_PRESENT,
+	}}
+	proposedCheckOut := checkIn.Add(9 * time.Hour)
+	correctionRepo := &fakeCorrectionRepository{correction: entities.AttendanceCorrection{
+		ID:               uuid.New(),
+		EmployeeID:       employee.ID,
+		AttendanceID:     &attendanceRepo.today.ID,
+		ProposedCheckOut: &proposedCheckOut,
+		Status:           constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING,
+		Employee:         employee,
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{supervisor.UserID: supervisor}}
+	svc := service.NewAttendanceCorrectionService(correctionRepo, attendanceRepo, employeeRepo, &fakeMasterRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, &fakeRbacService{}, nil)
+
+	result, err := svc.Approve(context.Background(), correctionRepo.correction.ID, supervisor.UserID.String(), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_CORRECTION_STATUS_APPROVED, result.Status)
+	assert.Nil(t, result.OriginalCheckOut)
+	assert.Equal(t, checkIn, *result.OriginalCheckIn)
+	assert.Equal(t, proposedCheckOut, *correctionRepo.applied.CheckOutTime)
+	assert.Equal(t, 540, correctionRepo.applied.WorkedMinutes)
+	assert.Equal(t, supervisor.UserID, correctionRepo.audit.UserID)
+}
+
+func TestCorrectionService_ApproveRequiresSupervisor(t *testing.T) {
+	supervisorID := uuid.New()
+	stranger := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	correctionRepo := &fakeCorrectionRepository{correction: entities.AttendanceCorrection{
+		Status:   constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING,
+		Employee: entities.Employee{SupervisorID: &supervisorID},
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{stranger.UserID: stranger}}
+	svc := service.NewAttendanceCorrectionService(correctionRepo, &fakeAttendanceRepository{}, employeeRepo, &fakeMasterRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, &fakeRbacService{}, nil)
+
+	_, err := svc.Approve(context.Background(), uuid.New(), stranger.UserID.String(), "")
+
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor)
+	assert.Nil(t, correctionRepo.applied)
+}
+
+func TestCorrectionService_SubmitForAnotherEmployee(t *testing.T) {
+	requester := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	attendanceRepo := &fakeAttendanceRepository{today: &entities.Attendance{
+		ID:         uuid.New(),
+		EmployeeID: uuid.New(),
+		WorkDate:   companyToday(),
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{requester.UserID: requester}}
+	svc := service.NewAttendanceCorrectionService(&fakeCorrectionRepository{}, attendanceRepo, employeeRepo, &fakeMasterRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, &fakeRbacService{}, nil)
+
+	checkOut := time.Now().Add(-time.Hour)
+	_, err := svc.Submit(context.Background(), requester.UserID.String(), dto.CorrectionCreateDTO{
+		AttendanceID: &attendanceRepo.today.ID,
+		CheckOutTime: &checkOut,
+		Reason:       "forgot",
+	})
+
+	assert.ErrorIs(t, err, dto.ErrNotAttendanceOwner)
+}
+
+func TestCorrectionService_SubmitCheckOutBeforeCheckIn(t *testing.T) {
+	requester := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	checkIn := time.Now().Add(-time.Hour)
+	locationID := uuid.New()
+	attendanceRepo := &fakeAttendanceRepository{today: &entities.Attendance{
+		ID:          uuid.New(),
+		EmployeeID:  requester.ID,
+		LocationID:  &locationID,
+		CheckInTime: &checkIn,
+		WorkDate:    companyToday(),
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{requester.UserID: requester}}
+	svc := service.NewAttendanceCorrectionService(&fakeCorrectionRepository{}, attendanceRepo, employeeRepo, &fakeMasterRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, &fakeRbacService{}, nil)
+
+	checkOut := time.Now().Add(-2 * time.Hour)
+	_, err := svc.Submit(context.Background(), requester.UserID.String(), dto.CorrectionCreateDTO{
+		AttendanceID: &attendanceRepo.today.ID,
+		CheckOutTime: &checkOut,
+		Reason:       "forgot",
+	})
+
+	assert.ErrorIs(t, err, dto.ErrCorrectionRange)
+}
+
+type fakeRbacService struct {
+	rbacService.RbacService
+	granted map[uuid.UUID]string
+}
+
+func (f *fakeRbacService) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	return f.granted[userID] == permission, nil
+}
+
+func TestCorrectionService_GetByID_ScopedToRequesterSupervisorAndHR(t *testing.T) {
+	supervisor := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	requester := entities.Employee{ID: uuid.New(), UserID: uuid.New(), SupervisorID: &supervisor.ID}
+	colleague := entities.Employee{ID: uuid.New(), UserID: uuid.New()}
+	hrUserID := uuid.New()
+	correctionRepo := &fakeCorrectionRepository{correction: entities.AttendanceCorrection{
+		ID:         uuid.New(),
+		EmployeeID: requester.ID,
+		Status:     constants.ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING,
+		Employee:   requester,
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		supervisor.UserID: supervisor,
+		requester.UserID:  requester,
+		colleague.UserID:  colleague,
+	}}
+	rbac := &fakeRbacService{granted: map[uuid.UUID]string{hrUserID: constants.PERMISSION_VIEW_ATTENDANCE_CORRECTIONS}}
+	svc := service.NewAttendanceCorrectionService(correctionRepo, &fakeAttendanceRepository{}, employeeRepo, &fakeMasterRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, rbac, nil)
+
+	for _, userID := range []uuid.UUID{requester.UserID, supervisor.UserID, hrUserID} {
+		result, err := svc.GetByID(context.Background(), correctionRepo.correction.ID, userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, correctionRepo.correction.ID, result.ID)
+	}
+
+	_, err := svc.GetByID(context.Background(), correctionRepo.correction.ID, colleague.UserID.String())
+
+	assert.ErrorIs(t, err, dto.ErrNotRequestViewer)
+}
+
+func (r *fakeEmployeeRepository) FindActive(ctx context.Context, db *gorm.DB, date time.Time) ([]entities.Employee, error) {
+	return r.active, nil
+}
+
+type fakeLeaveRepository struct {
+	leaveRepository.LeaveRepository
+	onLeave  []uuid.UUID
+	approved []entities.Leave
+	partial  []entities.Leave
+}
+
+func (r *fakeLeaveRepository) FindEmployeeIDsOnLeave(ctx context.Context, db *gorm.DB, date time.Time) ([]uuid.UUID, error) {
+	return r.onLeave, nil
+}
+
+func (r *fakeLeaveRepository) FindApprovedPartialOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) ([]entities.Leave, error) {
+	return r.partial, nil
+}
+
+type fakeNotificationService struct {
+	notificationService.NotificationService
+	sent []entities.Notification
+}
+
+func (s *fakeNotificationService) Notify(ctx context.Context, recipient entities.User, kind, title, body string, data any) (entities.Notification, error) {
+	notification := entities.Notification{UserID: recipient.ID, Type: kind, Title: title, Body: body}
+	s.sent = append(s.sent, notification)
+	return notification, nil
+}
+
+func TestAbsenceService_MarksAbsentAndNotifiesSupervisor(t *testing.T) {
+	supervisor := entities.Employee{ID: uuid.New(), User: entities.User{ID: uuid.New(), Name: "Sari"}}
+	absent := entities.Employee{ID: uuid.New(), User: entities.User{Name: "Budi"}, Supervisor: &supervisor}
+	onLeave := entities.Employee{ID: uuid.New(), Supervisor: &supervisor}
+	checkedIn := entities.Employee{ID: uuid.New(), Supervisor: &supervisor}
+
+	attendanceRepo := &fakeAttendanceRepository{recorded: []uuid.UUID{checkedIn.ID}}
+	employeeRepo := &fakeEmployeeRepository{active: []entities.Employee{absent, onLeave, checkedIn}}
+	notifications := &fakeNotificationService{}
+	svc := service.NewAbsenceService(attendanceRepo, employeeRepo, &fakeLeaveRepository{onLeave: []uuid.UUID{onLeave.ID}}, &fakeMasterRepository{}, &fakeShiftService{}, notifications, nil)
+
+	workDate := companyToday().AddDate(0, 0, -1)
+	result, err := svc.DetectAbsences(context.Background(), workDate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Marked)
+	assert.Len(t, attendanceRepo.created, 1)
+	assert.Equal(t, absent.ID, attendanceRepo.created[0].EmployeeID)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_ABSENT, attendanceRepo.created[0].Status)
+	assert.Equal(t, workDate, attendanceRepo.created[0].WorkDate)
+	assert.Nil(t, attendanceRepo.created[0].CheckInTime)
+	assert.Len(t, notifications.sent, 1)
+	assert.Equal(t, supervisor.User.ID, notifications.sent[0].UserID)
+	assert.Contains(t, notifications.sent[0].Body, "Budi")
+}
+
+func TestAbsenceService_SkipsHoliday(t *testing.T) {
+	attendanceRepo := &fakeAttendanceRepository{}
+	employeeRepo := &fakeEmployeeRepository{active: []entities.Employee{{ID: uuid.New()}}}
+	svc := service.NewAbsenceService(attendanceRepo, employeeRepo, &fakeLeaveRepository{}, &fakeMasterRepository{holiday: true}, &fakeShiftService{}, &fakeNotificationService{}, nil)
+
+	result, err := svc.DetectAbsences(context.Background(), companyToday().AddDate(0, 0, -1))
+
+	assert.NoError(t, err)
+	assert.True(t, result.Holiday)
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAbsenceService_SkipsDayOff(t *testing.T) {
+	attendanceRepo := &fakeAttendanceRepository{}
+	employeeRepo := &fakeEmployeeRepository{active: []entities.Employee{{ID: uuid.New()}}}
+	svc := service.NewAbsenceService(attendanceRepo, employeeRepo, &fakeLeaveRepository{}, &fakeMasterRepository{}, &fakeShiftService{off: true}, &fakeNotificationService{}, nil)
+
+	result, err := svc.DetectAbsences(context.Background(), companyToday().AddDate(0, 0, -1))
+
+	assert.NoError(t, err)
+	assert.Zero(t, result.Marked)
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAbsenceService_WaitsForShiftToEnd(t *testing.T) {
+	shift := shiftAround(-time.Hour, 2*time.Hour, 0)
+	if time.Now().In(helpers.LoadTimezone("")).Add(-time.Hour).Day() != time.Now().In(helpers.LoadTimezone("")).Day() {
+		t.Skip("shift would start on the previous day")
+	}
+	attendanceRepo := &fakeAttendanceRepository{}
+	employeeRepo := &fakeEmployeeRepository{active: []entities.Employee{{ID: uuid.New()}}}
+	svc := service.NewAbsenceService(attendanceRepo, employeeRepo, &fakeLeaveRepository{}, &fakeMasterRepository{}, &fakeShiftService{shift: shift}, &fakeNotificationService{}, nil)
+
+	result, err := svc.DetectAbsences(context.Background(), companyToday())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Pending)
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAutoCheckoutService_ClosesAtShiftEndAndNotifies(t *testing.T) {
+	now := time.Now()
+	checkIn := now.Add(-16 * time.Hour)
+	scheduledEnd := now.Add(-7 * time.Hour)
+	employee := entities.Employee{ID: uuid.New(), User: entities.User{ID: uuid.New()}}
+	attendanceRepo := &fakeAttendanceRepository{open: []entities.Attendance{{
+		ID:           uuid.New(),
+		EmployeeID:   employee.ID,
+		CheckInTime:  &checkIn,
+		WorkDate:     companyToday().AddDate(0, 0, -1),
+		ScheduledEnd: &scheduledEnd,
+		Status:       constants.ENUM_ATTENDANCE_STATUS_ON_TIME,
+		Employee:     employee,
+	}}}
+	notifications := &fakeNotificationService{}
+	svc := service.NewAutoCheckoutService(attendanceRepo, notifications, nil)
+
+	result, err := svc.CloseStale(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Closed)
+	assert.Equal(t, scheduledEnd, *attendanceRepo.closed[0].CheckOutTime)
+	assert.Equal(t, constants.ENUM_ATTENDANCE_STATUS_ON_TIME, attendanceRepo.closed[0].Status)
+	assert.Len(t, notifications.sent, 1)
+	assert.Equal(t, employee.User.ID, notifications.sent[0].UserID)
+	assert.Equal(t, constants.ENUM_NOTIFICATION_TYPE_AUTO_CHECKOUT, notifications.sent[0].Type)
+}
+
+func TestAutoCheckoutService_LeavesRecordWithinDeadline(t *testing.T) {
+	now := time.Now()
+	checkIn := now.Add(-2 * time.Hour)
+	scheduledEnd := now.Add(6 * time.Hour)
+	attendanceRepo := &fakeAttendanceRepository{open: []entities.Attendance{{
+		ID:           uuid.New(),
+		CheckInTime:  &checkIn,
+		WorkDate:     companyToday(),
+		ScheduledEnd: &scheduledEnd,
+	}}}
+	notifications := &fakeNotificationService{}
+	svc := service.NewAutoCheckoutService(attendanceRepo, notifications, nil)
+
+	result, err := svc.CloseStale(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Open)
+	assert.Zero(t, result.Closed)
+	assert.Empty(t, attendanceRepo.closed)
+	assert.Empty(t, notifications.sent)
+}
+
+func TestAutoCheckoutService_UsesCutOffWithoutShift(t *testing.T) {
+	t.Setenv("ATTENDANCE_AUTO_CHECKOUT_AT", "17:30")
+	workDate := companyToday().AddDate(0, 0, -2)
+	loc := helpers.LoadTimezone("")
+	checkIn := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 8, 0, 0, 0, loc)
+	attendanceRepo := &fakeAttendanceRepository{open: []entities.Attendance{{
+		ID:          uuid.New(),
+		CheckInTime: &checkIn,
+		WorkDate:    workDate,
+	}}}
+	svc := service.NewAutoCheckoutService(attendanceRepo, &fakeNotificationService{}, nil)
+
+	_, err := svc.CloseStale(context.Background(), time.Now())
+
+	assert.NoError(t, err)
+	assert.Len(t, attendanceRepo.closed, 1)
+	assert.Equal(t, time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 17, 30, 0, 0, loc), *attendanceRepo.closed[0].CheckOutTime)
+	assert.Equal(t, 570, attendanceRepo.closed[0].WorkedMinutes)
+}
+
+func TestAutoCheckoutService_ClosesUnscheduledAtCutOffSameDay(t *testing.T) {
+	loc := helpers.LoadTimezone("")
+	workDate := companyToday()
+	checkIn := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 8, 0, 0, 0, loc)
+	cutOff := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 17, 30, 0, 0, loc)
+	t.Setenv("ATTENDANCE_AUTO_CHECKOUT_AT", "17:30")
+
+	before := &fakeAttendanceRepository{open: []entities.Attendance{{ID: uuid.New(), CheckInTime: &checkIn, WorkDate: workDate}}}
+	result, err := service.NewAutoCheckoutService(before, &fakeNotificationService{}, nil).CloseStale(context.Background(), cutOff.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Zero(t, result.Closed)
+
+	after := &fakeAttendanceRepository{open: []entities.Attendance{{ID: uuid.New(), CheckInTime: &checkIn, WorkDate: workDate}}}
+	result, err = service.NewAutoCheckoutService(after, &fakeNotificationService{}, nil).CloseStale(context.Background(), cutOff.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Closed)
+	assert.Equal(t, cutOff, *after.closed[0].CheckOutTime)
+}
+
+func TestAutoCheckoutService_LeavesUnscheduledCheckedInAfterCutOff(t *testing.T) {
+	loc := helpers.LoadTimezone("")
+	workDate := companyToday()
+	checkIn := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 19, 0, 0, 0, loc)
+	t.Setenv("ATTENDANCE_AUTO_CHECKOUT_AT", "17:30")
+	attendanceRepo := &fakeAttendanceRepository{open: []entities.Attendance{{ID: uuid.New(), CheckInTime: &checkIn, WorkDate: workDate}}}
+
+	result, err := service.NewAutoCheckoutService(attendanceRepo, &fakeNotificationService{}, nil).CloseStale(context.Background(), checkIn.Add(time.Hour))
+
+	assert.NoError(t, err)
+	assert.Zero(t, result.Closed)
+}
+
+func (r *fakeAttendanceRepository) FindInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time, locationID *uuid.UUID) ([]entities.Attendance, error) {
+	var attendances []entities.Attendance
+	for _, attendance := range r.inRange {
+		if locationID == nil || (attendance.LocationID != nil && *attendance.LocationID == *locationID) {
+			attendances = append(attendances, attendance)
+		}
+	}
+	return attendances, nil
+}
+
+func (r *fakeEmployeeRepository) FindForReport(ctx context.Context, db *gorm.DB, start, end time.Time, departmentID *uuid.UUID) ([]entities.Employee, error) {
+	var employees []entities.Employee
+	for _, employee := range r.active {
+		if departmentID == nil || employee.DepartmentID == *departmentID {
+			employees = append(employees, employee)
+		}
+	}
+	return employees, nil
+}
+
+func (r *fakeLeaveRepository) FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.Leave, error) {
+	return r.approved, nil
+}
+
+type fakeOvertimeRepository struct {
+	overtimeRepository.OvertimeRepository
+	approved []entities.OvertimeRequest
+}
+
+func (r *fakeOvertimeRepository) FindApprovedForEmployees(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error) {
+	return r.approved, nil
+}
+
+func reportDay(day int) time.Time {
+	return time.Date(2026, time.September, day, 0, 0, 0, 0, time.UTC)
+}
+
+func reportAttendance(employeeID uuid.UUID, day int, late, earlyLeave, worked int, closed bool) entities.Attendance {
+	checkIn := reportDay(day).Add(8 * time.Hour)
+	attendance := entities.Attendance{
+		EmployeeID:        employeeID,
+		WorkDate:          reportDay(day),
+		CheckInTime:       &checkIn,
+		Status:            constants.ENUM_ATTENDANCE_STATUS_ON_TIME,
+		LateMinutes:       late,
+		EarlyLeaveMinutes: earlyLeave,
+		WorkedMinutes:     worked,
+	}
+	if closed {
+		checkOut := checkIn.Add(time.Duration(worked) * time.Minute)
+		attendance.CheckOutTime = &checkOut
+	}
+	return attendance
+}
+
+func TestAttendanceReportService_Summary(t *testing.T) {
+	departmentID := uuid.New()
+	budi := entities.Employee{ID: uuid.New(), EmployeeCode: "7", DepartmentID: departmentID, User: entities.User{Name: "Budi"}, Department: entities.Department{Name: "Operations"}}
+	sari := entities.Employee{ID: uuid.New(), EmployeeCode: "8", User: entities.User{Name: "Sari"}}
+
+	attendanceRepo := &fakeAttendanceRepository{inRange: []entities.Attendance{
+		reportAttendance(budi.ID, 1, 0, 0, 480, true),
+		reportAttendance(budi.ID, 2, 15, 0, 465, true),
+		reportAttendance(budi.ID, 8, 5, 30, 0, false),
+		{EmployeeID: budi.ID, WorkDate: reportDay(9), Status: constants.ENUM_ATTENDANCE_STATUS_ABSENT},
+	}}
+	leaveRepo := &fakeLeaveRepository{approved: []entities.Leave{
+		{EmployeeID: budi.ID, StartDate: reportDay(3), EndDate: reportDay(7)},
+		{EmployeeID: budi.ID, StartDate: reportDay(7), EndDate: reportDay(7)},
+		{EmployeeID: budi.ID, StartDate: time.Date(2026, time.August, 30, 0, 0, 0, 0, time.UTC), EndDate: reportDay(1)},
+		{EmployeeID: budi.ID, StartDate: reportDay(7), EndDate: reportDay(7), Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Days: 0.5},
+		{EmployeeID: budi.ID, StartDate: reportDay(8), EndDate: reportDay(8), Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Days: 0.5},
+		{EmployeeID: budi.ID, StartDate: reportDay(8), EndDate: reportDay(8), Unit: constants.ENUM_LEAVE_UNIT_HOURLY, Days: 0.25},
+	}}
+	overtimeRepo := &fakeOvertimeRepository{approved: []entities.OvertimeRequest{
+		{EmployeeID: budi.ID, ActualMinutes: 90},
+		{EmployeeID: budi.ID, ActualMinutes: 45},
+	}}
+	weekend := &fakeShiftService{restDays: map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}}
+	svc := service.NewAttendanceReportService(attendanceRepo, &fakeEmployeeRepository{active: []entities.Employee{budi, sari}}, leaveRepo, overtimeRepo, &fakeMasterRepository{}, weekend, nil)
+
+	report, err := svc.Summary(context.Background(), dto.ReportDTO{From: "2026-09-01", To: "2026-09-30"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-09-01", report.From)
+	assert.Len(t, report.Rows, 2)
+	assert.Equal(t, dto.AttendanceReportRow{
+		EmployeeID:         budi.ID,
+		EmployeeCode:       "7",
+		Name:               "Budi",
+		Department:         "Operations",
+		DaysPresent:        3,
+		LateCount:          2,
+		LateMinutes:        20,
+		EarlyLeaveCount:    1,
+		Absences:           1,
+		LeaveDays:          4.75,
+		OvertimeHours:      2.25,
+		AverageWorkedHours: 7.88,
+	}, report.Rows[0], "leave days skip the weekend, overlaps and days outside the range, and count parts of a day")
+	assert.Equal(t, dto.AttendanceReportRow{EmployeeID: sari.ID, EmployeeCode: "8", Name: "Sari"}, report.Rows[1])
+}
+
+func TestAttendanceReportService_Summary_LocationListsItsStaff(t *testing.T) {
+	office, branch := uuid.New(), uuid.New()
+	budi := entities.Employee{ID: uuid.New(), EmployeeCode: "7"}
+	sari := entities.Employee{ID: uuid.New(), EmployeeCode: "8"}
+	atOffice := reportAttendance(budi.ID, 1, 0, 0, 480, true)
+	atOffice.LocationID = &office
+	atBranch := reportAttendance(sari.ID, 1, 0, 0, 480, true)
+	atBranch.LocationID = &branch
+	attendanceRepo := &fakeAttendanceRepository{inRange: []entities.Attendance{atOffice, atBranch}}
+	svc := service.NewAttendanceReportService(attendanceRepo, &fakeEmployeeRepository{active: []entities.Employee{budi, sari}}, &fakeLeaveRepository{}, &fakeOvertimeRepository{}, &fakeMasterRepository{}, &fakeShiftService{}, nil)
+
+	report, err := svc.Summary(context.Background(), dto.ReportDTO{From: "2026-09-01", To: "2026-09-30", LocationID: office.String()})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Rows, 1)
+	assert.Equal(t, budi.ID, report.Rows[0].EmployeeID)
+}
+
+func TestAttendanceReportService_Summary_RejectsBadRequests(t *testing.T) {
+	svc := service.NewAttendanceReportService(&fakeAttendanceRepository{}, &fakeEmployeeRepository{}, &fakeLeaveRepository{}, &fakeOvertimeRepository{}, &fakeMasterRepository{}, &fakeShiftService{}, nil)
+
+	_, err := svc.Summary(context.Background(), dto.ReportDTO{From: "2026-09-30", To: "2026-09-01"})
+	assert.ErrorIs(t, err, dto.ErrReportRange)
+
+	_, err = svc.Summary(context.Background(), dto.ReportDTO{From: "2026-01-01", To: "2026-12-31"})
+	assert.ErrorIs(t, err, dto.ErrReportRange)
+
+	_, err = svc.Summary(context.Background(), dto.ReportDTO{From: "01/09/2026", To: "2026-09-30"})
+	assert.ErrorIs(t, err, dto.ErrReportDate)
+
+	_, err = svc.Summary(context.Background(), dto.ReportDTO{From: "2026-09-01", To: "2026-09-30", EmployeeID: uuid.NewString(), LocationID: uuid.NewString()})
+	assert.ErrorIs(t, err, dto.ErrReportScope)
+}
+
+func (r *fakeAttendanceRepository) FindPunchedInRange(ctx context.Context, db *gorm.DB, start, end time.Time) ([]entities.Attendance, error) {
+	return r.inRange, nil
+}
+
+type fakeAnomalyRepository struct {
+	repository.AttendanceAnomalyRepository
+	created []entities.AttendanceAnomaly
+	anomaly entities.AttendanceAnomaly
+}
+
+func (r *fakeAnomalyRepository) CreateMany(ctx context.Context, tx *gorm.DB, anomalies []entities.AttendanceAnomaly) (int64, error) {
+	r.created = append(r.created, anomalies...)
+	return int64(len(anomalies)), nil
+}
+
+func (r *fakeAnomalyRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.AttendanceAnomaly, error) {
+	return r.anomaly, nil
+}
+
+func (r *fakeAnomalyRepository) FindOpenBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.AttendanceAnomaly], error) {
+	page := &pagination.Page[entities.AttendanceAnomaly]{}
+	if r.anomaly.Status == constants.ENUM_ANOMALY_STATUS_OPEN && r.anomaly.Employee.SupervisorID != nil && *r.anomaly.Employee.SupervisorID == supervisorID {
+		page.Data = append(page.Data, r.anomaly)
+	}
+	return page, nil
+}
+
+func (r *fakeAnomalyRepository) Update(ctx context.Context, tx *gorm.DB, anomaly entities.AttendanceAnomaly) (entities.AttendanceAnomaly, error) {
+	r.anomaly = anomaly
+	return anomaly, nil
+}
+
+var jakarta = helpers.LoadTimezone("Asia/Jakarta")
+
+func anomalyAt(day, hour, minute, second int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, second, 0, jakarta)
+}
+
+func anomalyPunch(punchType string, at time.Time, position ...float64) entities.AttendancePunch {
+	punch := entities.AttendancePunch{ID: uuid.New(), Type: punchType, PunchTime: at}
+	if len(position) == 2 {
+		punch.Latitude, punch.Longitude = &position[0], &position[1]
+	}
+	return punch
+}
+
+func anomalyDay(employeeID uuid.UUID, punches ...entities.AttendancePunch) entities.Attendance {
+	return entities.Attendance{
+		ID:         uuid.New(),
+		EmployeeID: employeeID,
+		WorkDate:   helpers.DateOf(punches[0].PunchTime),
+		Location:   entities.Location{Timezone: "Asia/Jakarta"},
+		Punches:    punches,
+	}
+}
+
+func detectAnomalies(t *testing.T, attendances []entities.Attendance, from, to int) []entities.AttendanceAnomaly {
+	anomalyRepo := &fakeAnomalyRepository{}
+	svc := service.NewAttendanceAnomalyService(anomalyRepo, &fakeAttendanceRepository{inRange: attendances}, &fakeEmployeeRepository{}, &fakeMasterRepository{}, nil)
+
+	result, err := svc.Detect(context.Background(), anomalyAt(from, 0, 0, 0), anomalyAt(to, 0, 0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, len(anomalyRepo.created), result.Found)
+	return anomalyRepo.created
+}
+
+func TestAttendanceAnomalyService_Detect_ImpossibleTravel(t *testing.T) {
+	employeeID := uuid.New()
+	surabaya := anomalyPunch(constants.ENUM_PUNCH_TYPE_OUT, anomalyAt(10, 9, 0, 0), -7.2575, 112.7521)
+	attendances := []entities.Attendance{
+		anomalyDay(employeeID,
+			anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(9, 8, 0, 0), -6.2088, 106.8456),
+			anomalyPunch(constants.ENUM_PUNCH_TYPE_OUT, anomalyAt(9, 17, 0, 0), -6.2150, 106.8200),
+		),
+		anomalyDay(employeeID,
+			anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(10, 8, 0, 0), -6.2089, 106.8457),
+			surabaya,
+		),
+	}
+
+	anomalies := detectAnomalies(t, attendances, 9, 10)
+
+	assert.Len(t, anomalies, 1, "a 3 km hop over a working day is fine")
+	assert.Equal(t, constants.ENUM_ANOMALY_RULE_IMPOSSIBLE_TRAVEL, anomalies[0].Rule)
+	assert.Equal(t, surabaya.ID, anomalies[0].PunchID)
+	assert.Equal(t, constants.ENUM_ANOMALY_STATUS_OPEN, anomalies[0].Status)
+	assert.Contains(t, anomalies[0].Detail, "2026-10-10 08:00")
+}
+
+func TestAttendanceAnomalyService_Detect_RepeatedCoordinatesFlagsOnlyTheRange(t *testing.T) {
+	employeeID := uuid.New()
+	var attendances []entities.Attendance
+	for day := 6; day <= 8; day++ {
+		attendances = append(attendances, anomalyDay(employeeID,
+			anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 8, 0, 0), -6.2088, 106.8456),
+		))
+	}
+
+	anomalies := detectAnomalies(t, attendances, 8, 8)
+
+	assert.Len(t, anomalies, 1, "earlier days count towards the pattern but are not flagged again")
+	assert.Equal(t, constants.ENUM_ANOMALY_RULE_REPEATED_COORDINATES, anomalies[0].Rule)
+	assert.Equal(t, helpers.DateOf(anomalyAt(8, 0, 0, 0)), anomalies[0].WorkDate)
+	assert.Equal(t, "position -6.2088,106.8456 reported on 3 work dates", anomalies[0].Detail)
+}
+
+func TestAttendanceAnomalyService_Detect_UnusualHours(t *testing.T) {
+	regular, shiftWorker := uuid.New(), uuid.New()
+	var attendances []entities.Attendance
+	for day := 1; day <= 5; day++ {
+		attendances = append(attendances, anomalyDay(regular, anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 8, day, 0))))
+	}
+	night := anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(6, 2, 30, 0))
+	attendances = append(attendances, anomalyDay(regular, night))
+
+	// On a night shift the shift start is what counts, not the day time history
+	for day := 1; day <= 5; day++ {
+		attendances = append(attendances, anomalyDay(shiftWorker, anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 8, 0, 0))))
+	}
+	onShift := anomalyDay(shiftWorker, anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(6, 21, 50, 0)))
+	shiftStart := anomalyAt(6, 22, 0, 0)
+	onShift.ScheduledStart = &shiftStart
+	attendances = append(attendances, onShift)
+
+	anomalies := detectAnomalies(t, attendances, 1, 6)
+
+	assert.Len(t, anomalies, 1, "the first days have no history to go by")
+	assert.Equal(t, constants.ENUM_ANOMALY_RULE_UNUSUAL_HOURS, anomalies[0].Rule)
+	assert.Equal(t, night.ID, anomalies[0].PunchID)
+	assert.Equal(t, "checked in at 02:30, usual check-in 08:03", anomalies[0].Detail)
+}
+
+func TestAttendanceAnomalyService_Detect_BuddyPunching(t *testing.T) {
+	budi, sari, andi := uuid.New(), uuid.New(), uuid.New()
+	shared := func(punch entities.AttendancePunch) entities.AttendancePunch {
+		punch.DeviceID = "TAB-01"
+		return punch
+	}
+	terminal := func(punch entities.AttendancePunch) entities.AttendancePunch {
+		punch.DeviceID = "CQZ7231560"
+		status := 0
+		punch.TerminalStatus = &status
+		return punch
+	}
+
+	var attendances []entities.Attendance
+	for day := 1; day <= 3; day++ {
+		attendances = append(attendances,
+			anomalyDay(budi, shared(anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 7, 58, 0)))),
+			anomalyDay(sari, shared(anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 7, 58, 40)))),
+			anomalyDay(andi, terminal(anomalyPunch(constants.ENUM_PUNCH_TYPE_IN, anomalyAt(day, 7, 58, 20)))),
+		)
+	}
+	// Only two days together on the device: not yet a pattern
+	for day := 1; day <= 2; day++ {
+		attendances = append(attendances, anomalyDay(andi, shared(anomalyPunch(constants.ENUM_PUNCH_TYPE_OUT, anomalyAt(day, 17, 0, 0)))))
+		attendances = append(attendances, anomalyDay(budi, shared(anomalyPunch(constants.ENUM_PUNCH_TYPE_OUT, anomalyAt(day, 17, 0, 30)))))
+	}
+
+	anomalies := detectAnomalies(t, attendances, 1, 3)
+
+	assert.Len(t, anomalies, 6)
+	for _, anomaly := range anomalies {
+		assert.Equal(t, constants.ENUM_ANOMALY_RULE_BUDDY_PUNCHING, anomaly.Rule)
+		assert.NotEqual(t, andi, anomaly.EmployeeID, "fingerprint scans cannot be lent")
+		if anomaly.EmployeeID == budi {
+			assert.Equal(t, sari, *anomaly.RelatedEmployeeID)
+		} else {
+			assert.Equal(t, budi, *anomaly.RelatedEmployeeID)
+		}
+	}
+}
+
+func TestAttendanceAnomalyService_Detect_BuddyPunchingOnLiveCheckIns(t *testing.T) {
+	budi, sari := uuid.New(), uuid.New()
+	var punches []entities.AttendancePunch
+	for _, employeeID := range []uuid.UUID{budi, sari} {
+		svc, attendanceRepo := newShiftService(nil)
+		_, err := svc.CheckIn(dto.CheckInDTO{
+			EmployeeID: employeeID,
+			LocationID: uuid.New(),
+			Latitude:   float(-6.2088),
+			Longitude:  float(106.8456),
+			Accuracy:   5,
+			DeviceID:   "PHONE-7",
+		})
+		assert.NoError(t, err)
+		punch := attendanceRepo.created[0].Punches[0]
+		assert.Equal(t, "PHONE-7", punch.DeviceID)
+		punches = append(punches, punch)
+	}
+
+	// The same two check-ins on three work dates
+	var attendances []entities.Attendance
+	for day := 0; day < constants.ANOMALY_BUDDY_MIN_DAYS; day++ {
+		for i, employeeID := range []uuid.UUID{budi, sari} {
+			punch := punches[i]
+			punch.ID = uuid.New()
+			punch.PunchTime = punch.PunchTime.AddDate(0, 0, -day)
+			attendances = append(attendances, anomalyDay(employeeID, punch))
+		}
+	}
+	anomalyRepo := &fakeAnomalyRepository{}
+	svc := service.NewAttendanceAnomalyService(anomalyRepo, &fakeAttendanceRepository{inRange: attendances}, &fakeEmployeeRepository{}, &fakeMasterRepository{}, nil)
+	now := time.Now()
+	_, err := svc.Detect(context.Background(), now.AddDate(0, 0, -constants.ANOMALY_BUDDY_MIN_DAYS), now.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+
+	var flagged []uuid.UUID
+	for _, anomaly := range anomalyRepo.created {
+		if anomaly.Rule == constants.ENUM_ANOMALY_RULE_BUDDY_PUNCHING {
+			flagged = append(flagged, anomaly.EmployeeID)
+		}
+	}
+	assert.Contains(t, flagged, budi)
+	assert.Contains(t, flagged, sari)
+}
+
+func TestAttendanceAnomalyService_Scan_RejectsLongRange(t *testing.T) {
+	svc := service.NewAttendanceAnomalyService(&fakeAnomalyRepository{}, &fakeAttendanceRepository{}, &fakeEmployeeRepository{}, &fakeMasterRepository{}, nil)
+
+	_, err := svc.Scan(context.Background(), dto.AnomalyScanDTO{From: "2026-09-01", To: "2026-10-02"})
+	assert.ErrorIs(t, err, dto.ErrAnomalyRange)
+
+	_, err = svc.Scan(context.Background(), dto.AnomalyScanDTO{From: "2026-10-02", To: "2026-10-01"})
+	assert.ErrorIs(t, err, dto.ErrAnomalyRange)
+}
+
+func TestAttendanceAnomalyService_Confirm_BySupervisorOnly(t *testing.T) {
+	supervisorUser, otherUser := uuid.New(), uuid.New()
+	supervisor := entities.Employee{ID: uuid.New(), UserID: supervisorUser}
+	other := entities.Employee{ID: uuid.New(), UserID: otherUser}
+	anomalyRepo := &fakeAnomalyRepository{anomaly: entities.AttendanceAnomaly{
+		ID:       uuid.New(),
+		Rule:     constants.ENUM_ANOMALY_RULE_BUDDY_PUNCHING,
+		Status:   constants.ENUM_ANOMALY_STATUS_OPEN,
+		Employee: entities.Employee{ID: uuid.New(), SupervisorID: &supervisor.ID},
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{supervisorUser: supervisor, otherUser: other}}
+	svc := service.NewAttendanceAnomalyService(anomalyRepo, &fakeAttendanceRepository{}, employeeRepo, &fakeMasterRepository{}, nil)
+
+	_, err := svc.Confirm(context.Background(), anomalyRepo.anomaly.ID, otherUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor)
+
+	anomaly, err := svc.Confirm(context.Background(), anomalyRepo.anomaly.ID, supervisorUser.String(), "Same phone, talked to both")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_ANOMALY_STATUS_CONFIRMED, anomaly.Status)
+	assert.Equal(t, supervisor.ID, *anomaly.ReviewerID)
+	assert.Equal(t, "Same phone, talked to both", anomaly.ReviewNote)
+
+	_, err = svc.Dismiss(context.Background(), anomalyRepo.anomaly.ID, supervisorUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrAnomalyNotOpen)
+}
+
+func TestAttendanceAnomalyService_Review_RejectsEveryoneButTheSupervisor(t *testing.T) {
+	supervisorUser, otherUser, unlinkedUser := uuid.New(), uuid.New(), uuid.New()
+	supervisor := entities.Employee{ID: uuid.New(), UserID: supervisorUser}
+	other := entities.Employee{ID: uuid.New(), UserID: otherUser}
+	anomalyRepo := &fakeAnomalyRepository{anomaly: entities.AttendanceAnomaly{
+		ID:       uuid.New(),
+		Rule:     constants.ENUM_ANOMALY_RULE_BUDDY_PUNCHING,
+		Status:   constants.ENUM_ANOMALY_STATUS_OPEN,
+		Employee: entities.Employee{ID: uuid.New(), SupervisorID: &supervisor.ID},
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{supervisorUser: supervisor, otherUser: other}}
+	svc := service.NewAttendanceAnomalyService(anomalyRepo, &fakeAttendanceRepository{}, employeeRepo, &fakeMasterRepository{}, nil)
+
+	reviews, err := svc.FindPendingReviews(context.Background(), otherUser.String(), &pagination.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, reviews.Data, "the inbox only holds flags on the reviewer's own team")
+
+	_, err = svc.FindPendingReviews(context.Background(), unlinkedUser.String(), &pagination.Filter{})
+	assert.ErrorIs(t, err, dto.ErrEmployeeNotLinked)
+
+	_, err = svc.Dismiss(context.Background(), anomalyRepo.anomaly.ID, otherUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor)
+
+	_, err = svc.Dismiss(context.Background(), anomalyRepo.anomaly.ID, unlinkedUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrEmployeeNotLinked)
+	assert.Equal(t, constants.ENUM_ANOMALY_STATUS_OPEN, anomalyRepo.anomaly.Status)
+
+	reviews, err = svc.FindPendingReviews(context.Background(), supervisorUser.String(), &pagination.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, reviews.Data, 1)
+
+	_, err = svc.Dismiss(context.Background(), anomalyRepo.anomaly.ID, supervisorUser.String(), "Shared the office tablet")
+	assert.NoError(t, err)
+
+	_, err = svc.Confirm(context.Background(), anomalyRepo.anomaly.ID, otherUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor, "a closed flag reads the same as an open one to others")
+}
+
+func (r *fakeEmployeeRepository) FindByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Employee, error) {
+	for _, employee := range r.active {
+		if employee.ID == id {
+			return employee, nil
+		}
+	}
+	for _, employee := range r.byUserID {
+		if employee.ID == id {
+			return employee, nil
+		}
+	}
+	return entities.Employee{}, gorm.ErrRecordNotFound
+}
+
+func TestAttendanceService_CheckIn_AwayFromLocationIsRefused(t *testing.T) {
+	location := entities.Location{ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	salesperson := entities.Employee{ID: uuid.New()}
+	attendanceRepo := &fakeAttendanceRepository{location: location}
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{active: []entities.Employee{salesperson}}, &fakeMasterRepository{location: location}, &fakeRemoteWorkRepository{}, &fakeLeaveRepository{}, &fakeShiftService{}, nil)
+
+	_, err := svc.CheckIn(dto.CheckInDTO{
+		EmployeeID: salesperson.ID,
+		LocationID: location.ID,
+		Latitude:   float(-6.9175),
+		Longitude:  float(107.6191),
+		Accuracy:   15,
+	})
+
+	assert.ErrorIs(t, err, dto.ErrOutsideGeofence, "staff away from a location need an approved remote-work request")
+	assert.Empty(t, attendanceRepo.created)
+}
+
+func TestAttendanceService_CheckIn_RemoteNeedsApprovedRequest(t *testing.T) {
+	remote := entities.Location{ID: uuid.New(), Name: "Remote", IsRemote: true, IsActive: true}
+	employeeID := uuid.New()
+	attendanceRepo := &fakeAttendanceRepository{location: remote}
+	remoteWorkRepo := &fakeRemoteWorkRepository{}
+	svc := service.NewAttendanceService(attendanceRepo, &fakeEmployeeRepository{}, &fakeMasterRepository{location: remote}, remoteWorkRepo, &fakeLeaveRepository{}, &fakeShiftService{}, nil)
+
+	_, err := svc.CheckIn(dto.CheckInDTO{EmployeeID: employeeID, Remote: true, Latitude: float(-7.2575), Longitude: float(112.7521)})
+	assert.ErrorIs(t, err, dto.ErrRemoteWorkNotApproved)
+
+	request := entities.RemoteWorkRequest{
+		ID:         uuid.New(),
+		EmployeeID: employeeID,
+		Type:       constants.ENUM_REMOTE_WORK_TYPE_WFH,
+		StartDate:  companyToday().AddDate(0, 0, -1),
+		EndDate:    companyToday().AddDate(0, 0, 1),
+		Status:     constants.ENUM_REMOTE_WORK_STATUS_APPROVED,
+	}
+	remoteWorkRepo.requests = append(remoteWorkRepo.requests, request)
+
+	result, err := svc.CheckIn(dto.CheckInDTO{EmployeeID: employeeID, Remote: true, Latitude: float(-7.2575), Longitude: float(112.7521)})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_WORK_MODE_REMOTE, result.WorkMode)
+	assert.Equal(t, request.ID, *result.RemoteWorkRequestID)
+	assert.Equal(t, remote.ID, *result.LocationID)
+	assert.Nil(t, result.CheckInDistance, "no geofence applies to remote work")
+}
+
+func TestRemoteWorkService_Submit_RejectsOverlap(t *testing.T) {
+	userID, employee := uuid.New(), entities.Employee{ID: uuid.New()}
+	remoteWorkRepo := &fakeRemoteWorkRepository{}
+	svc := service.NewRemoteWorkService(remoteWorkRepo, &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{userID: employee}}, &fakeRbacService{}, nil)
+	start := companyToday().AddDate(0, 0, 1)
+
+	request, err := svc.Submit(context.Background(), userID.String(), dto.RemoteWorkCreateDTO{
+		Type: constants.ENUM_REMOTE_WORK_TYPE_BUSINESS_TRIP, StartDate: start.Format(time.DateOnly), EndDate: start.AddDate(0, 0, 2).Format(time.DateOnly), Reason: "client visit",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_REMOTE_WORK_STATUS_PENDING, request.Status)
+
+	_, err = svc.Submit(context.Background(), userID.String(), dto.RemoteWorkCreateDTO{
+		Type: constants.ENUM_REMOTE_WORK_TYPE_WFH, StartDate: start.AddDate(0, 0, 2).Format(time.DateOnly), EndDate: start.AddDate(0, 0, 3).Format(time.DateOnly), Reason: "home",
+	})
+	assert.ErrorIs(t, err, dto.ErrRemoteWorkOverlap)
+
+	_, err = svc.Submit(context.Background(), userID.String(), dto.RemoteWorkCreateDTO{
+		Type: constants.ENUM_REMOTE_WORK_TYPE_WFH, StartDate: start.AddDate(0, 0, -2).Format(time.DateOnly), EndDate: start.AddDate(0, 0, -2).Format(time.DateOnly), Reason: "home",
+	})
+	assert.ErrorIs(t, err, dto.ErrRemoteWorkInPast)
+}
+
+func TestRemoteWorkService_Approve_OnlySupervisor(t *testing.T) {
+	supervisorUser, otherUser := uuid.New(), uuid.New()
+	supervisor, other := entities.Employee{ID: uuid.New()}, entities.Employee{ID: uuid.New()}
+	request := entities.RemoteWorkRequest{
+		ID:       uuid.New(),
+		Status:   constants.ENUM_REMOTE_WORK_STATUS_PENDING,
+		Employee: entities.Employee{ID: uuid.New(), SupervisorID: &supervisor.ID},
+	}
+	remoteWorkRepo := &fakeRemoteWorkRepository{requests: []entities.RemoteWorkRequest{request}}
+	svc := service.NewRemoteWorkService(remoteWorkRepo, &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		supervisorUser: supervisor,
+		otherUser:      other,
+	}}, &fakeRbacService{}, nil)
+
+	_, err := svc.Approve(context.Background(), request.ID, otherUser.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor)
+
+	approved, err := svc.Approve(context.Background(), request.ID, supervisorUser.String(), "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_REMOTE_WORK_STATUS_APPROVED, approved.Status)
+	assert.Equal(t, supervisor.ID, *approved.ApproverID)
+}
+
+func TestRemoteWorkService_GetByID_ScopedToRequesterSupervisorAndHR(t *testing.T) {
+	supervisorUser, requesterUser, otherUser, hrUser := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	supervisor := entities.Employee{ID: uuid.New()}
+	requester := entities.Employee{ID: uuid.New(), SupervisorID: &supervisor.ID}
+	request := entities.RemoteWorkRequest{
+		ID:         uuid.New(),
+		EmployeeID: requester.ID,
+		Status:     constants.ENUM_REMOTE_WORK_STATUS_PENDING,
+		Employee:   requester,
+	}
+	remoteWorkRepo := &fakeRemoteWorkRepository{requests: []entities.RemoteWorkRequest{request}}
+	svc := service.NewRemoteWorkService(remoteWorkRepo, &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		supervisorUser: supervisor,
+		requesterUser:  requester,
+		otherUser:      {ID: uuid.New()},
+	}}, &fakeRbacService{granted: map[uuid.UUID]string{hrUser: constants.PERMISSION_VIEW_REMOTE_WORK}}, nil)
+
+	for _, userID := range []uuid.UUID{requesterUser, supervisorUser, hrUser} {
+		result, err := svc.GetByID(context.Background(), request.ID, userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, request.ID, result.ID)
+	}
+
+	_, err := svc.GetByID(context.Background(), request.ID, otherUser.String())
+	assert.ErrorIs(t, err, dto.ErrNotRequestViewer)
+}
+
+func TestAttendanceService_CheckIn_ConflictsWhenAlreadyCheckedIn(t *testing.T) {
+	location := entities.Location{ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	svc, attendanceRepo := newGeofenceService(location)
+	req := dto.CheckInDTO{EmployeeID: uuid.New(), LocationID: location.ID, Latitude: float(-6.2088), Longitude: float(106.8456)}
+
+	_, err := svc.CheckIn(req)
+	assert.NoError(t, err)
+
+	_, err = svc.CheckIn(req)
+	assert.ErrorIs(t, err, dto.ErrAlreadyCheckedIn)
+
+	// A tap racing the first one passes the lookup and loses on the unique index
+	attendanceRepo.today = nil
+	attendanceRepo.conflict = gorm.ErrDuplicatedKey
+	_, err = svc.CheckIn(req)
+	assert.ErrorIs(t, err, dto.ErrAlreadyCheckedIn)
+}
+
+func TestAttendanceService_CheckOut_ConflictsWhenRecordChanged(t *testing.T) {
+	location := entities.Location{ID: uuid.New(), Latitude: -6.2088, Longitude: 106.8456, RadiusMeters: 100, IsActive: true}
+	svc, attendanceRepo := newGeofenceService(location)
+	employeeID := uuid.New()
+
+	_, err := svc.CheckIn(dto.CheckInDTO{EmployeeID: employeeID, LocationID: location.ID, Latitude: float(-6.2088), Longitude: float(106.8456)})
+	assert.NoError(t, err)
+
+	attendanceRepo.conflict = repository.ErrAttendanceChanged
+	_, err = svc.CheckOut(dto.CheckOutDTO{EmployeeID: employeeID, Latitude: float(-6.2088), Longitude: float(106.8456)})
+	assert.ErrorIs(t, err, dto.ErrPunchConflict)
+}
\ No newline at end of file
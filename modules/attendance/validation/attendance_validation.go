@@ -24,10 +24,30 @@ func (v *AttendanceValidation) CheckOut(req dto.CheckOutDTO) error {
 	return v.validate.Struct(req)
 }
 
-func (v *AttendanceValidation) UpdateAttendance(req dto.UpdateAttendanceDTO) error {
+func (v *AttendanceValidation) Break(req dto.BreakDTO) error {
 	return v.validate.Struct(req)
 }
 
-func (v *AttendanceValidation) ApproveAttendance(req dto.ApproveAttendanceDTO) error {
+func (v *AttendanceValidation) Sync(req dto.SyncDTO) error {
 	return v.validate.Struct(req)
 }
+
+func (v *AttendanceValidation) CreateRemoteWork(req dto.RemoteWorkCreateDTO) error {
+	return v.validate.Struct(req)
+}
+
+func (v *AttendanceValidation) CreateCorrection(req dto.CorrectionCreateDTO) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	if req.CheckInTime == nil && req.CheckOutTime == nil {
+		return dto.ErrCorrectionEmpty
+	}
+	if req.AttendanceID == nil && (req.WorkDate == "" || req.LocationID == nil || req.CheckInTime == nil) {
+		return dto.ErrCorrectionTarget
+	}
+	if req.CheckInTime != nil && req.CheckOutTime != nil && !req.CheckOutTime.After(*req.CheckInTime) {
+		return dto.ErrCorrectionRange
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	DeviceController interface {
+		Register(ctx *gin.Context)
+		GetAll(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		Update(ctx *gin.Context)
+		Delete(ctx *gin.Context)
+
+		// Commands
+		QueueCommand(ctx *gin.Context)
+		GetCommands(ctx *gin.Context)
+	}
+
+	deviceController struct {
+		deviceService    service.DeviceService
+		deviceValidation *validation.DeviceValidation
+		db               *gorm.DB
+	}
+)
+
+func NewDeviceController(injector *do.Injector, s service.DeviceService) DeviceController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	deviceValidation := validation.NewDeviceValidation()
+	return &deviceController{
+		deviceService:    s,
+		deviceValidation: deviceValidation,
+		db:               db,
+	}
+}
+
+func (c *deviceController) Register(ctx *gin.Context) {
+	var req dto.DeviceCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.deviceValidation.ValidateDeviceCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.deviceService.Register(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed register device", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success register device", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *deviceController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.deviceService.FindAll(ctx.Request.Context(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get devices", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *deviceController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.deviceService.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get device", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *deviceController) Update(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.DeviceUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.deviceValidation.ValidateDeviceUpdateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.deviceService.Update(ctx.Request.Context(), id, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed update device", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success update device", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *deviceController) Delete(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.deviceService.Delete(ctx.Request.Context(), id); err != nil {
+		res := utils.BuildResponseFailed("failed delete device", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success delete device", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Commands
+func (c *deviceController) QueueCommand(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.DeviceCommandRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.deviceValidation.ValidateDeviceCommandRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.deviceService.QueueCommand(ctx.Request.Context(), id, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed queue device command", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success queue device command", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *deviceController) GetCommands(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.deviceService.FindCommands(ctx.Request.Context(), id, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get device commands", err.Error(), nil)
+		ctx.JSON(deviceErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func deviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrDeviceExists):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrDeviceUnknown), errors.Is(err, dto.ErrDeviceInactive):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
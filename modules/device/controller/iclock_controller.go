@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// IclockController speaks the ZKTeco ADMS push protocol. Terminals identify
+// themselves by the SN query parameter and expect plain text replies.
+type IclockController interface {
+	Handshake(ctx *gin.Context)
+	ReceiveData(ctx *gin.Context)
+	GetRequest(ctx *gin.Context)
+	DeviceCmd(ctx *gin.Context)
+}
+
+type iclockController struct {
+	deviceService service.DeviceService
+}
+
+func NewIclockController(s service.DeviceService) IclockController {
+	return &iclockController{
+		deviceService: s,
+	}
+}
+
+// Handshake answers GET /iclock/cdata with the options the terminal pushes
+// by: resume attendance uploads after the last stamp received, skip the
+// operation log, and upload scans as they happen.
+func (c *iclockController) Handshake(ctx *gin.Context) {
+	serialNumber := ctx.Query("SN")
+	if serialNumber == "" {
+		c.fail(ctx, dto.ErrDeviceNoSerial)
+		return
+	}
+
+	handshake, err := c.deviceService.Handshake(ctx.Request.Context(), serialNumber, ctx.Query("pushver"))
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+
+	stamp := handshake.AttlogStamp
+	if stamp == "" {
+		stamp = "None"
+	}
+	options := []string{
+		"GET OPTION FROM: " + handshake.SerialNumber,
+		"ATTLOGStamp=" + stamp,
+		"OPERLOGStamp=9999",
+		"ATTPHOTOStamp=None",
+		fmt.Sprintf("ErrorDelay=%d", constants.ADMS_ERROR_DELAY_SECONDS),
+		fmt.Sprintf("Delay=%d", constants.ADMS_POLL_DELAY_SECONDS),
+		"TransTimes=00:00;14:05",
+		"TransInterval=1",
+		"TransFlag=TransData AttLog",
+		fmt.Sprintf("TimeZone=%d", handshake.TimeZone),
+		"Realtime=1",
+		"Encrypt=None",
+	}
+	ctx.String(http.StatusOK, "%s\n", strings.Join(options, "\n"))
+}
+
+// ReceiveData takes POST /iclock/cdata uploads and acknowledges them with the
+// number of lines taken, which lets the terminal drop them from its queue.
+func (c *iclockController) ReceiveData(ctx *gin.Context) {
+	serialNumber := ctx.Query("SN")
+	if serialNumber == "" {
+		c.fail(ctx, dto.ErrDeviceNoSerial)
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+
+	lines, err := c.deviceService.ReceiveData(ctx.Request.Context(), serialNumber, ctx.Query("table"), ctx.Query("Stamp"), body)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	ctx.String(http.StatusOK, "OK: %d\n", lines)
+}
+
+// GetRequest answers the GET /iclock/getrequest poll with the queued commands,
+// one "C:<id>:<command>" line each, or OK when there are none.
+func (c *iclockController) GetRequest(ctx *gin.Context) {
+	serialNumber := ctx.Query("SN")
+	if serialNumber == "" {
+		c.fail(ctx, dto.ErrDeviceNoSerial)
+		return
+	}
+
+	commands, err := c.deviceService.PollCommands(ctx.Request.Context(), serialNumber)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	if len(commands) == 0 {
+		ctx.String(http.StatusOK, "OK\n")
+		return
+	}
+
+	var reply strings.Builder
+	for _, command := range commands {
+		fmt.Fprintf(&reply, "C:%d:%s\n", command.CommandNo, command.Command)
+	}
+	ctx.String(http.StatusOK, "%s", reply.String())
+}
+
+// DeviceCmd takes the POST /iclock/devicecmd results of executed commands.
+func (c *iclockController) DeviceCmd(ctx *gin.Context) {
+	serialNumber := ctx.Query("SN")
+	if serialNumber == "" {
+		c.fail(ctx, dto.ErrDeviceNoSerial)
+		return
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+
+	if err := c.deviceService.ReportResults(ctx.Request.Context(), serialNumber, body); err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	ctx.String(http.StatusOK, "OK\n")
+}
+
+func (c *iclockController) fail(ctx *gin.Context, err error) {
+	status := deviceErrorStatus(err)
+	if status == http.StatusNotFound {
+		status = http.StatusInternalServerError
+	}
+	ctx.String(status, "ERROR: %s\n", err.Error())
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrDeviceExists   = errors.New("a device with this serial number is already registered")
+	ErrDeviceLocation = errors.New("an active device needs a location")
+	ErrDeviceUnknown  = errors.New("device is not registered")
+	ErrDeviceInactive = errors.New("device is not active")
+	ErrCommandResult  = errors.New("command result needs a numeric ID and Return")
+	ErrDeviceNoSerial = errors.New("SN is required")
+	ErrNothingToSync  = errors.New("no active employees to sync")
+	ErrUnknownCommand = errors.New("unknown device command")
+)
+
+type (
+	// DeviceCreateRequest registers a terminal by the serial number it reports
+	// to the push server, at the location whose attendance it records.
+	DeviceCreateRequest struct {
+		SerialNumber string    `json:"serial_number" binding:"required,max=50"`
+		Name         string    `json:"name"`
+		LocationID   uuid.UUID `json:"location_id" binding:"required"`
+	}
+
+	DeviceUpdateRequest struct {
+		Name       *string    `json:"name"`
+		LocationID *uuid.UUID `json:"location_id"`
+		IsActive   *bool      `json:"is_active"`
+	}
+
+	// DeviceCommandRequest queues an action for a terminal. sync_users sends
+	// every active employee as a terminal user, keyed by employee code.
+	DeviceCommandRequest struct {
+		Action string `json:"action" binding:"required,oneof=sync_users reboot"`
+	}
+
+	// DeviceHandshake is what a terminal is told when it calls in: where its
+	// last upload ended and the UTC offset of its location.
+	DeviceHandshake struct {
+		SerialNumber string
+		AttlogStamp  string
+		TimeZone     int
+	}
+)
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Device], error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Device, error)
+	GetBySerialNumber(ctx context.Context, db *gorm.DB, serialNumber string) (entities.Device, error)
+	Update(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error)
+	Touch(ctx context.Context, tx *gorm.DB, id uuid.UUID, seenAt time.Time, pushVersion string) error
+	SetAttlogStamp(ctx context.Context, tx *gorm.DB, id uuid.UUID, stamp string) error
+	Delete(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Commands
+	CreateCommands(ctx context.Context, tx *gorm.DB, commands []entities.DeviceCommand) ([]entities.DeviceCommand, error)
+	FindCommands(ctx context.Context, db *gorm.DB, filter *pagination.Filter, deviceID uuid.UUID) (*pagination.Page[entities.DeviceCommand], error)
+	TakePendingCommands(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, limit int, sentAt time.Time) ([]entities.DeviceCommand, error)
+	CompleteCommand(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, commandNo int64, returnCode int, completedAt time.Time) error
+}
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepository{
+		db: db,
+	}
+}
+
+func (r *deviceRepository) Create(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&device).Error; err != nil {
+		return entities.Device{}, err
+	}
+	return device, nil
+}
+
+func (r *deviceRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Device], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.Device
+	var page pagination.Page[entities.Device]
+	paginator, err := pagination.NewPaginator(db.WithContext(ctx).Model(&entities.Device{}).Preload("Location"), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *deviceRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Device, error) {
+	if db == nil {
+		db = r.db
+	}
+	var device entities.Device
+	if err := db.WithContext(ctx).Preload("Location").Where("id = ?", id).First(&device).Error; err != nil {
+		return entities.Device{}, err
+	}
+	return device, nil
+}
+
+func (r *deviceRepository) GetBySerialNumber(ctx context.Context, db *gorm.DB, serialNumber string) (entities.Device, error) {
+	if db == nil {
+		db = r.db
+	}
+	var device entities.Device
+	if err := db.WithContext(ctx).Preload("Location").Where("serial_number = ?", serialNumber).First(&device).Error; err != nil {
+		return entities.Device{}, err
+	}
+	return device, nil
+}
+
+func (r *deviceRepository) Update(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Model(&device).Omit(clause.Associations).
+		Select("name", "location_id", "is_active").Updates(&device).Error; err != nil {
+		return entities.Device{}, err
+	}
+	return device, nil
+}
+
+// Touch records that the terminal called in, and its push protocol version
+// when it reported one.
+func (r *deviceRepository) Touch(ctx context.Context, tx *gorm.DB, id uuid.UUID, seenAt time.Time, pushVersion string) error {
+	if tx == nil {
+		tx = r.db
+	}
+	values := map[string]any{"last_seen_at": seenAt}
+	if pushVersion != "" {
+		values["push_version"] = pushVersion
+	}
+	return tx.WithContext(ctx).Model(&entities.Device{}).Where("id = ?", id).Updates(values).Error
+}
+
+func (r *deviceRepository) SetAttlogStamp(ctx context.Context, tx *gorm.DB, id uuid.UUID, stamp string) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.WithContext(ctx).Model(&entities.Device{}).Where("id = ?", id).Update("attlog_stamp", stamp).Error
+}
+
+func (r *deviceRepository) Delete(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.WithContext(ctx).Delete(&entities.Device{}, "id = ?", id).Error
+}
+
+// Commands
+func (r *deviceRepository) CreateCommands(ctx context.Context, tx *gorm.DB, commands []entities.DeviceCommand) ([]entities.DeviceCommand, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if len(commands) == 0 {
+		return commands, nil
+	}
+	if err := tx.WithContext(ctx).Create(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (r *deviceRepository) FindCommands(ctx context.Context, db *gorm.DB, filter *pagination.Filter, deviceID uuid.UUID) (*pagination.Page[entities.DeviceCommand], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.DeviceCommand
+	var page pagination.Page[entities.DeviceCommand]
+	query := db.WithContext(ctx).Model(&entities.DeviceCommand{}).Where("device_id = ?", deviceID).Order("command_no DESC")
+	paginator, err := pagination.NewPaginator(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+// TakePendingCommands hands out the oldest pending commands of a device and
+// marks them sent. Rows are locked with SKIP LOCKED so overlapping polls of
+// the same terminal never receive a command twice.
+func (r *deviceRepository) TakePendingCommands(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, limit int, sentAt time.Time) ([]entities.DeviceCommand, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var commands []entities.DeviceCommand
+	err := tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("device_id = ? AND status = ?", deviceID, constants.ENUM_DEVICE_COMMAND_STATUS_PENDING).
+			Order("command_no").Limit(limit).Find(&commands).Error; err != nil {
+			return err
+		}
+		if len(commands) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(commands))
+		for i := range commands {
+			ids = append(ids, commands[i].ID)
+			commands[i].Status = constants.ENUM_DEVICE_COMMAND_STATUS_SENT
+			commands[i].SentAt = &sentAt
+		}
+		return tx.Model(&entities.DeviceCommand{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":  constants.ENUM_DEVICE_COMMAND_STATUS_SENT,
+			"sent_at": sentAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// CompleteCommand stores the result a terminal reported for a command; a
+// return code of zero means it succeeded.
+func (r *deviceRepository) CompleteCommand(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, commandNo int64, returnCode int, completedAt time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	status := constants.ENUM_DEVICE_COMMAND_STATUS_DONE
+	if returnCode != 0 {
+		status = constants.ENUM_DEVICE_COMMAND_STATUS_FAILED
+	}
+	res := tx.WithContext(ctx).Model(&entities.DeviceCommand{}).
+		Where("device_id = ? AND command_no = ?", deviceID, commandNo).
+		Updates(map[string]any{
+			"status":       status,
+			"return_code":  returnCode,
+			"completed_at": completedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
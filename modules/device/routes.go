@@ -0,0 +1,41 @@
+package device
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/controller"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	deviceController := do.MustInvoke[controller.DeviceController](injector)
+	iclockController := do.MustInvoke[controller.IclockController](injector)
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
+
+	deviceRoutes := server.Group("/api/devices")
+	deviceRoutes.Use(middlewares.Authenticate(jwtService), middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_DEVICES))
+	{
+		deviceRoutes.GET("", deviceController.GetAll)
+		deviceRoutes.GET("/:id", deviceController.GetByID)
+		deviceRoutes.POST("", deviceController.Register)
+		deviceRoutes.PUT("/:id", deviceController.Update)
+		deviceRoutes.DELETE("/:id", deviceController.Delete)
+
+		// Commands
+		deviceRoutes.GET("/:id/commands", deviceController.GetCommands)
+		deviceRoutes.POST("/:id/commands", deviceController.QueueCommand)
+	}
+
+	// ADMS push protocol; terminals cannot log in and are known by serial number
+	iclockRoutes := server.Group("/iclock")
+	{
+		iclockRoutes.GET("/cdata", iclockController.Handshake)
+		iclockRoutes.POST("/cdata", iclockController.ReceiveData)
+		iclockRoutes.GET("/getrequest", iclockController.GetRequest)
+		iclockRoutes.POST("/devicecmd", iclockController.DeviceCmd)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Longest user name most terminals store
+const terminalNameLength = 24
+
+type DeviceService interface {
+	Register(ctx context.Context, req dto.DeviceCreateRequest) (entities.Device, error)
+	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Device], error)
+	GetByID(ctx context.Context, id uuid.UUID) (entities.Device, error)
+	Update(ctx context.Context, id uuid.UUID, req dto.DeviceUpdateRequest) (entities.Device, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	QueueCommand(ctx context.Context, id uuid.UUID, req dto.DeviceCommandRequest) ([]entities.DeviceCommand, error)
+	FindCommands(ctx context.Context, id uuid.UUID, filter *pagination.Filter) (*pagination.Page[entities.DeviceCommand], error)
+
+	// ADMS push protocol, called by the terminals themselves
+	Handshake(ctx context.Context, serialNumber, pushVersion string) (dto.DeviceHandshake, error)
+	ReceiveData(ctx context.Context, serialNumber, table, stamp string, body []byte) (int, error)
+	PollCommands(ctx context.Context, serialNumber string) ([]entities.DeviceCommand, error)
+	ReportResults(ctx context.Context, serialNumber string, body []byte) error
+}
+
+type deviceService struct {
+	deviceRepository   repository.DeviceRepository
+	employeeRepository employeeRepository.EmployeeRepository
+	masterRepository   masterRepository.MasterRepository
+	attendanceService  attendanceService.AttendanceService
+	db                 *gorm.DB
+}
+
+func NewDeviceService(
+	deviceRepo repository.DeviceRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	masterRepo masterRepository.MasterRepository,
+	attendanceSvc attendanceService.AttendanceService,
+	db *gorm.DB,
+) DeviceService {
+	return &deviceService{
+		deviceRepository:   deviceRepo,
+		employeeRepository: employeeRepo,
+		masterRepository:   masterRepo,
+		attendanceService:  attendanceSvc,
+		db:                 db,
+	}
+}
+
+// Register adds a terminal at a location, ready to push. A terminal that
+// already called in is pending under its serial number and is assigned with
+// Update instead.
+func (s *deviceService) Register(ctx context.Context, req dto.DeviceCreateRequest) (entities.Device, error) {
+	if _, err := s.deviceRepository.GetBySerialNumber(ctx, nil, req.SerialNumber); err == nil {
+		return entities.Device{}, dto.ErrDeviceExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Device{}, err
+	}
+
+	location, err := s.masterRepository.GetLocationByID(ctx, nil, req.LocationID)
+	if err != nil {
+		return entities.Device{}, err
+	}
+
+	device, err := s.deviceRepository.Create(ctx, nil, entities.Device{
+		SerialNumber: req.SerialNumber,
+		Name:         req.Name,
+		LocationID:   &location.ID,
+		IsActive:     true,
+	})
+	if err != nil {
+		return entities.Device{}, err
+	}
+	device.Location = &location
+	return device, nil
+}
+
+func (s *deviceService) FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Device], error) {
+	return s.deviceRepository.FindAll(ctx, nil, filter)
+}
+
+func (s *deviceService) GetByID(ctx context.Context, id uuid.UUID) (entities.Device, error) {
+	return s.deviceRepository.GetByID(ctx, nil, id)
+}
+
+// Update renames, moves or (de)activates a terminal. Only a terminal with a
+// location can be active, since its scans are recorded there.
+func (s *deviceService) Update(ctx context.Context, id uuid.UUID, req dto.DeviceUpdateRequest) (entities.Device, error) {
+	device, err := s.deviceRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.Device{}, err
+	}
+
+	if req.Name != nil {
+		device.Name = *req.Name
+	}
+	if req.LocationID != nil {
+		location, err := s.masterRepository.GetLocationByID(ctx, nil, *req.LocationID)
+		if err != nil {
+			return entities.Device{}, err
+		}
+		device.LocationID = &location.ID
+		device.Location = &location
+	}
+	if req.IsActive != nil {
+		device.IsActive = *req.IsActive
+	}
+	if device.IsActive && device.LocationID == nil {
+		return entities.Device{}, dto.ErrDeviceLocation
+	}
+
+	return s.deviceRepository.Update(ctx, nil, device)
+}
+
+func (s *deviceService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.deviceRepository.GetByID(ctx, nil, id); err != nil {
+		return err
+	}
+	return s.deviceRepository.Delete(ctx, nil, id)
+}
+
+// QueueCommand queues an action for the terminal's next poll. Syncing users
+// queues one command per active employee, so the terminal reports each one.
+func (s *deviceService) QueueCommand(ctx context.Context, id uuid.UUID, req dto.DeviceCommandRequest) ([]entities.DeviceCommand, error) {
+	device, err := s.deviceRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	switch req.Action {
+	case constants.ENUM_DEVICE_ACTION_SYNC_USERS:
+		employees, err := s.employeeRepository.FindActive(ctx, nil, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if len(employees) == 0 {
+			return nil, dto.ErrNothingToSync
+		}
+		for _, employee := range employees {
+			lines = append(lines, userInfoCommand(employee))
+		}
+	case constants.ENUM_DEVICE_ACTION_REBOOT:
+		lines = append(lines, "REBOOT")
+	default:
+		return nil, dto.ErrUnknownCommand
+	}
+
+	commands := make([]entities.DeviceCommand, 0, len(lines))
+	for _, line := range lines {
+		commands = append(commands, entities.DeviceCommand{
+			DeviceID: device.ID,
+			Command:  line,
+			Status:   constants.ENUM_DEVICE_COMMAND_STATUS_PENDING,
+		})
+	}
+	return s.deviceRepository.CreateCommands(ctx, nil, commands)
+}
+
+func (s *deviceService) FindCommands(ctx context.Context, id uuid.UUID, filter *pagination.Filter) (*pagination.Page[entities.DeviceCommand], error) {
+	if _, err := s.deviceRepository.GetByID(ctx, nil, id); err != nil {
+		return nil, err
+	}
+	return s.deviceRepository.FindCommands(ctx, nil, filter, id)
+}
+
+// userInfoCommand writes an employee as a terminal user whose PIN is the
+// employee code, the key scans are matched back on. Fields are tab separated,
+// so whitespace in the name is folded into single spaces.
+func userInfoCommand(employee entities.Employee) string {
+	name := []rune(strings.Join(strings.Fields(employee.User.Name), " "))
+	if len(name) > terminalNameLength {
+		name = name[:terminalNameLength]
+	}
+	return fmt.Sprintf("DATA UPDATE USERINFO PIN=%s\tName=%s\tPri=0", employee.EmployeeCode, string(name))
+}
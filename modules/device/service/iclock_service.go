@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+// The only upload table folded into attendance; operation logs, photos and
+// templates a terminal sends are acknowledged and dropped.
+const attlogTable = "ATTLOG"
+
+// Handshake answers the first call of a terminal after it starts. A serial
+// number seen for the first time is stored as a pending device for an admin
+// to assign, and is told nothing until then beyond the protocol options.
+func (s *deviceService) Handshake(ctx context.Context, serialNumber, pushVersion string) (dto.DeviceHandshake, error) {
+	device, err := s.deviceRepository.GetBySerialNumber(ctx, nil, serialNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		device, err = s.deviceRepository.Create(ctx, nil, entities.Device{
+			SerialNumber: serialNumber,
+			PushVersion:  pushVersion,
+		})
+	}
+	if err != nil {
+		return dto.DeviceHandshake{}, err
+	}
+	if err := s.deviceRepository.Touch(ctx, nil, device.ID, time.Now(), pushVersion); err != nil {
+		return dto.DeviceHandshake{}, err
+	}
+
+	timezone := ""
+	if device.Location != nil {
+		timezone = device.Location.Timezone
+	}
+	_, offset := time.Now().In(helpers.LoadTimezone(timezone)).Zone()
+
+	return dto.DeviceHandshake{
+		SerialNumber: device.SerialNumber,
+		AttlogStamp:  device.AttlogStamp,
+		TimeZone:     offset / 3600,
+	}, nil
+}
+
+// ReceiveData takes an upload of a terminal and returns how many lines it
+// held. Attendance scans are imported at the terminal's location and the
+// upload stamp is kept, so the terminal resumes after it on its next start.
+func (s *deviceService) ReceiveData(ctx context.Context, serialNumber, table, stamp string, body []byte) (int, error) {
+	device, err := s.activeDevice(ctx, serialNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	if table != attlogTable {
+		return countLines(body), nil
+	}
+
+	result, err := s.attendanceService.ImportPushedAttlog(ctx, *device.LocationID, device.SerialNumber, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for _, pin := range result.Unmatched {
+		log.Printf("device %s: %d scans of PIN %s match no employee", device.SerialNumber, pin.Punches, pin.PIN)
+	}
+
+	if stamp != "" {
+		if err := s.deviceRepository.SetAttlogStamp(ctx, nil, device.ID, stamp); err != nil {
+			return 0, err
+		}
+	}
+	return result.Lines, nil
+}
+
+// PollCommands hands a terminal the commands queued for it. Inactive
+// terminals still poll, but are given nothing.
+func (s *deviceService) PollCommands(ctx context.Context, serialNumber string) ([]entities.DeviceCommand, error) {
+	device, err := s.activeDevice(ctx, serialNumber)
+	if errors.Is(err, dto.ErrDeviceInactive) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.deviceRepository.TakePendingCommands(ctx, nil, device.ID, constants.ADMS_COMMANDS_PER_POLL, time.Now())
+}
+
+// ReportResults stores the command results a terminal posts, one
+// "ID=..&Return=..&CMD=.." line per command. Lines that cannot be read or
+// name a command of another terminal are skipped.
+func (s *deviceService) ReportResults(ctx context.Context, serialNumber string, body []byte) error {
+	device, err := s.deviceRepository.GetBySerialNumber(ctx, nil, serialNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.ErrDeviceUnknown
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		commandNo, returnCode, err := parseCommandResult(scanner.Text())
+		if err != nil {
+			continue
+		}
+		err = s.deviceRepository.CompleteCommand(ctx, nil, device.ID, commandNo, returnCode, now)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// activeDevice looks up a calling terminal and records that it was seen.
+func (s *deviceService) activeDevice(ctx context.Context, serialNumber string) (entities.Device, error) {
+	device, err := s.deviceRepository.GetBySerialNumber(ctx, nil, serialNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Device{}, dto.ErrDeviceUnknown
+	}
+	if err != nil {
+		return entities.Device{}, err
+	}
+	if err := s.deviceRepository.Touch(ctx, nil, device.ID, time.Now(), ""); err != nil {
+		return entities.Device{}, err
+	}
+	if !device.IsActive || device.LocationID == nil {
+		return entities.Device{}, dto.ErrDeviceInactive
+	}
+	return device, nil
+}
+
+func parseCommandResult(line string) (int64, int, error) {
+	values, err := url.ParseQuery(strings.TrimSpace(line))
+	if err != nil {
+		return 0, 0, err
+	}
+	commandNo, err := strconv.ParseInt(values.Get("ID"), 10, 64)
+	if err != nil {
+		return 0, 0, dto.ErrCommandResult
+	}
+	returnCode, err := strconv.Atoi(values.Get("Return"))
+	if err != nil {
+		return 0, 0, dto.ErrCommandResult
+	}
+	return commandNo, returnCode, nil
+}
+
+func countLines(body []byte) int {
+	lines := 0
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines++
+		}
+	}
+	return lines
+}
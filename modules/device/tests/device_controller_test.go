@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceController (t *testing.T) {
+	assert.True(t, true)
+}
+
+// simulatedDevice calls the push server the way a ZKTeco terminal does.
+type simulatedDevice struct {
+	t      *testing.T
+	server *gin.Engine
+	serial string
+}
+
+func iclockServer(svc service.DeviceService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	iclockController := controller.NewIclockController(svc)
+
+	server := gin.New()
+	server.GET("/iclock/cdata", iclockController.Handshake)
+	server.POST("/iclock/cdata", iclockController.ReceiveData)
+	server.GET("/iclock/getrequest", iclockController.GetRequest)
+	server.POST("/iclock/devicecmd", iclockController.DeviceCmd)
+	return server
+}
+
+func (d simulatedDevice) call(method, path, body string) (int, string) {
+	d.t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "text/plain")
+	d.server.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func (d simulatedDevice) handshake() (int, string) {
+	return d.call(http.MethodGet, "/iclock/cdata?SN="+d.serial+"&options=all&pushver=2.4.1&language=69", "")
+}
+
+func (d simulatedDevice) push(stamp string, lines ...string) (int, string) {
+	return d.call(http.MethodPost, "/iclock/cdata?SN="+d.serial+"&table=ATTLOG&Stamp="+stamp, strings.Join(lines, "\n")+"\n")
+}
+
+func (d simulatedDevice) poll() (int, string) {
+	return d.call(http.MethodGet, "/iclock/getrequest?SN="+d.serial, "")
+}
+
+func (d simulatedDevice) report(result string) (int, string) {
+	return d.call(http.MethodPost, "/iclock/devicecmd?SN="+d.serial, result)
+}
+
+func TestIclockController_SimulatedDevice(t *testing.T) {
+	location := jakarta()
+	svc, deviceRepo, attendanceSvc := newDeviceService(location, entities.Employee{EmployeeCode: "7", User: entities.User{Name: "Budi Santoso"}})
+	device := simulatedDevice{t: t, server: iclockServer(svc), serial: "CQZ7231560"}
+
+	// A terminal nobody registered yet calls in and waits for an admin
+	code, body := device.handshake()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "GET OPTION FROM: CQZ7231560\n")
+	assert.Contains(t, body, "ATTLOGStamp=None\n")
+
+	code, _ = device.push("100", "7\t2026-10-16 08:00:00\t0\t1\t0\t0\t0")
+	assert.Equal(t, http.StatusForbidden, code, "the terminal keeps its scans until it is assigned")
+	assert.Empty(t, attendanceSvc.pushed)
+
+	code, body = device.poll()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK\n", body)
+
+	// An admin assigns it to the office
+	active := true
+	pending := deviceRepo.devices["CQZ7231560"]
+	_, err := svc.Update(context.Background(), pending.ID, dto.DeviceUpdateRequest{LocationID: &location.ID, IsActive: &active})
+	assert.NoError(t, err)
+
+	code, body = device.push("100", "7\t2026-10-16 08:00:00\t0\t1\t0\t0\t0", "7\t2026-10-16 17:00:00\t1\t1\t0\t0\t0")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK: 2\n", body)
+	assert.Len(t, attendanceSvc.pushed, 1)
+	assert.Equal(t, location.ID, attendanceSvc.locationID)
+
+	code, body = device.handshake()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "ATTLOGStamp=100\n", "a restarted terminal resumes after the last upload")
+	assert.Contains(t, body, "TimeZone=7\n")
+
+	code, body = device.call(http.MethodPost, "/iclock/cdata?SN=CQZ7231560&table=OPERLOG&Stamp=5", "OPLOG 4\t0\t2026-10-16 08:00:00\t0\t0\t0\t0\n")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK: 1\n", body, "other tables are acknowledged")
+	assert.Len(t, attendanceSvc.pushed, 1)
+
+	// Commands queued for it go out on the next poll, once
+	_, err = svc.QueueCommand(context.Background(), pending.ID, dto.DeviceCommandRequest{Action: constants.ENUM_DEVICE_ACTION_SYNC_USERS})
+	assert.NoError(t, err)
+	_, err = svc.QueueCommand(context.Background(), pending.ID, dto.DeviceCommandRequest{Action: constants.ENUM_DEVICE_ACTION_REBOOT})
+	assert.NoError(t, err)
+
+	code, body = device.poll()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "C:1:DATA UPDATE USERINFO PIN=7\tName=Budi Santoso\tPri=0\nC:2:REBOOT\n", body)
+
+	_, body = device.poll()
+	assert.Equal(t, "OK\n", body)
+
+	code, body = device.report("ID=1&Return=0&CMD=DATA\nID=2&Return=-1002&CMD=REBOOT\nnot a result")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK\n", body)
+	assert.Equal(t, constants.ENUM_DEVICE_COMMAND_STATUS_DONE, deviceRepo.commands[0].Status)
+	assert.Equal(t, constants.ENUM_DEVICE_COMMAND_STATUS_FAILED, deviceRepo.commands[1].Status)
+	assert.Equal(t, -1002, *deviceRepo.commands[1].ReturnCode)
+}
+
+func TestIclockController_RequiresSerialNumber(t *testing.T) {
+	svc, _, _ := newDeviceService(jakarta())
+	device := simulatedDevice{t: t, server: iclockServer(svc)}
+
+	code, _ := device.call(http.MethodGet, "/iclock/cdata", "")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceDto "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/service"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestDeviceService (t *testing.T) {
+	assert.True(t, true)
+}
+
+type fakeDeviceRepository struct {
+	repository.DeviceRepository
+	devices  map[string]*entities.Device
+	commands []entities.DeviceCommand
+}
+
+func (r *fakeDeviceRepository) Create(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error) {
+	if r.devices == nil {
+		r.devices = map[string]*entities.Device{}
+	}
+	device.ID = uuid.New()
+	r.devices[device.SerialNumber] = &device
+	return device, nil
+}
+
+func (r *fakeDeviceRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Device, error) {
+	for _, device := range r.devices {
+		if device.ID == id {
+			return *device, nil
+		}
+	}
+	return entities.Device{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeDeviceRepository) GetBySerialNumber(ctx context.Context, db *gorm.DB, serialNumber string) (entities.Device, error) {
+	device, ok := r.devices[serialNumber]
+	if !ok {
+		return entities.Device{}, gorm.ErrRecordNotFound
+	}
+	return *device, nil
+}
+
+func (r *fakeDeviceRepository) Update(ctx context.Context, tx *gorm.DB, device entities.Device) (entities.Device, error) {
+	r.devices[device.SerialNumber] = &device
+	return device, nil
+}
+
+func (r *fakeDeviceRepository) Touch(ctx context.Context, tx *gorm.DB, id uuid.UUID, seenAt time.Time, pushVersion string) error {
+	for _, device := range r.devices {
+		if device.ID == id {
+			device.LastSeenAt = &seenAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeDeviceRepository) SetAttlogStamp(ctx context.Context, tx *gorm.DB, id uuid.UUID, stamp string) error {
+	for _, device := range r.devices {
+		if device.ID == id {
+			device.AttlogStamp = stamp
+		}
+	}
+	return nil
+}
+
+func (r *fakeDeviceRepository) CreateCommands(ctx context.Context, tx *gorm.DB, commands []entities.DeviceCommand) ([]entities.DeviceCommand, error) {
+	for i := range commands {
+		commands[i].ID = uuid.New()
+		commands[i].CommandNo = int64(len(r.commands) + 1)
+		r.commands = append(r.commands, commands[i])
+	}
+	return commands, nil
+}
+
+func (r *fakeDeviceRepository) TakePendingCommands(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, limit int, sentAt time.Time) ([]entities.DeviceCommand, error) {
+	var taken []entities.DeviceCommand
+	for i := range r.commands {
+		command := &r.commands[i]
+		if command.DeviceID != deviceID || command.Status != constants.ENUM_DEVICE_COMMAND_STATUS_PENDING || len(taken) == limit {
+			continue
+		}
+		command.Status = constants.ENUM_DEVICE_COMMAND_STATUS_SENT
+		command.SentAt = &sentAt
+		taken = append(taken, *command)
+	}
+	return taken, nil
+}
+
+func (r *fakeDeviceRepository) CompleteCommand(ctx context.Context, tx *gorm.DB, deviceID uuid.UUID, commandNo int64, returnCode int, completedAt time.Time) error {
+	for i := range r.commands {
+		command := &r.commands[i]
+		if command.DeviceID == deviceID && command.CommandNo == commandNo {
+			command.Status = constants.ENUM_DEVICE_COMMAND_STATUS_DONE
+			if returnCode != 0 {
+				command.Status = constants.ENUM_DEVICE_COMMAND_STATUS_FAILED
+			}
+			command.ReturnCode = &returnCode
+			command.CompletedAt = &completedAt
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type fakeEmployeeRepository struct {
+	employeeRepository.EmployeeRepository
+	active []entities.Employee
+}
+
+func (r *fakeEmployeeRepository) FindActive(ctx context.Context, db *gorm.DB, date time.Time) ([]entities.Employee, error) {
+	return r.active, nil
+}
+
+type fakeMasterRepository struct {
+	masterRepository.MasterRepository
+	location entities.Location
+}
+
+func (r *fakeMasterRepository) GetLocationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Location, error) {
+	if id != r.location.ID {
+		return entities.Location{}, gorm.ErrRecordNotFound
+	}
+	return r.location, nil
+}
+
+// fakeAttendanceService records the pushed logs instead of folding them.
+type fakeAttendanceService struct {
+	attendanceService.AttendanceService
+	pushed     []string
+	locationID uuid.UUID
+}
+
+func (s *fakeAttendanceService) ImportPushedAttlog(ctx context.Context, locationID uuid.UUID, deviceID string, body io.Reader) (attendanceDto.AttlogImportResult, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return attendanceDto.AttlogImportResult{}, err
+	}
+	s.pushed = append(s.pushed, string(raw))
+	s.locationID = locationID
+	return attendanceDto.AttlogImportResult{Lines: strings.Count(strings.TrimSpace(string(raw)), "\n") + 1}, nil
+}
+
+func newDeviceService(location entities.Location, employees ...entities.Employee) (service.DeviceService, *fakeDeviceRepository, *fakeAttendanceService) {
+	deviceRepo := &fakeDeviceRepository{}
+	attendanceSvc := &fakeAttendanceService{}
+	svc := service.NewDeviceService(deviceRepo, &fakeEmployeeRepository{active: employees}, &fakeMasterRepository{location: location}, attendanceSvc, nil)
+	return svc, deviceRepo, attendanceSvc
+}
+
+func jakarta() entities.Location {
+	return entities.Location{ID: uuid.New(), Name: "Head Office", Timezone: "Asia/Jakarta", IsActive: true}
+}
+
+func TestDeviceService_Handshake_KeepsUnknownDevicePending(t *testing.T) {
+	svc, deviceRepo, attendanceSvc := newDeviceService(jakarta())
+
+	handshake, err := svc.Handshake(context.Background(), "CQZ7231560", "2.4.1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CQZ7231560", handshake.SerialNumber)
+	device := deviceRepo.devices["CQZ7231560"]
+	assert.False(t, device.IsActive)
+	assert.Nil(t, device.LocationID)
+	assert.NotNil(t, device.LastSeenAt)
+
+	_, err = svc.ReceiveData(context.Background(), "CQZ7231560", "ATTLOG", "1", []byte("7\t2026-10-16 08:00:00\t0\t1\t0"))
+
+	assert.ErrorIs(t, err, dto.ErrDeviceInactive)
+	assert.Empty(t, attendanceSvc.pushed, "scans of a pending device are not imported")
+}
+
+func TestDeviceService_ReceiveData_RejectsUnknownDevice(t *testing.T) {
+	svc, _, _ := newDeviceService(jakarta())
+
+	_, err := svc.ReceiveData(context.Background(), "UNKNOWN", "ATTLOG", "1", []byte("7\t2026-10-16 08:00:00\t0\t1\t0"))
+
+	assert.ErrorIs(t, err, dto.ErrDeviceUnknown)
+}
+
+func TestDeviceService_Update_ActiveNeedsLocation(t *testing.T) {
+	location := jakarta()
+	svc, deviceRepo, _ := newDeviceService(location)
+	_, err := svc.Handshake(context.Background(), "CQZ7231560", "")
+	assert.NoError(t, err)
+	id := deviceRepo.devices["CQZ7231560"].ID
+
+	active := true
+	_, err = svc.Update(context.Background(), id, dto.DeviceUpdateRequest{IsActive: &active})
+	assert.ErrorIs(t, err, dto.ErrDeviceLocation)
+
+	device, err := svc.Update(context.Background(), id, dto.DeviceUpdateRequest{IsActive: &active, LocationID: &location.ID})
+	assert.NoError(t, err)
+	assert.True(t, device.IsActive)
+	assert.Equal(t, location.ID, *device.LocationID)
+
+	handshake, err := svc.Handshake(context.Background(), "CQZ7231560", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, handshake.TimeZone, "the terminal clock follows its location")
+}
+
+func TestDeviceService_Register_RejectsKnownSerialNumber(t *testing.T) {
+	location := jakarta()
+	svc, _, _ := newDeviceService(location)
+	_, err := svc.Handshake(context.Background(), "CQZ7231560", "")
+	assert.NoError(t, err)
+
+	_, err = svc.Register(context.Background(), dto.DeviceCreateRequest{SerialNumber: "CQZ7231560", LocationID: location.ID})
+
+	assert.ErrorIs(t, err, dto.ErrDeviceExists)
+}
+
+func TestDeviceService_QueueCommand_SyncUsers(t *testing.T) {
+	location := jakarta()
+	svc, _, _ := newDeviceService(location,
+		entities.Employee{EmployeeCode: "7", User: entities.User{Name: "Budi\tSantoso"}},
+		entities.Employee{EmployeeCode: "8", User: entities.User{Name: "Raden Ayu Kartika Sari Dewi Lestari"}},
+	)
+	device, err := svc.Register(context.Background(), dto.DeviceCreateRequest{SerialNumber: "CQZ7231560", LocationID: location.ID})
+	assert.NoError(t, err)
+
+	commands, err := svc.QueueCommand(context.Background(), device.ID, dto.DeviceCommandRequest{Action: constants.ENUM_DEVICE_ACTION_SYNC_USERS})
+
+	assert.NoError(t, err)
+	assert.Len(t, commands, 2)
+	assert.Equal(t, "DATA UPDATE USERINFO PIN=7\tName=Budi Santoso\tPri=0", commands[0].Command, "a tab in the name would split the field")
+	assert.Equal(t, "DATA UPDATE USERINFO PIN=8\tName=Raden Ayu Kartika Sari D\tPri=0", commands[1].Command)
+	assert.Equal(t, constants.ENUM_DEVICE_COMMAND_STATUS_PENDING, commands[0].Status)
+}
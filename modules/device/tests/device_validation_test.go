@@ -0,0 +1,10 @@
+package tests
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceValidation (t *testing.T) {
+	assert.True(t, true)
+}
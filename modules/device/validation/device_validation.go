@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/modules/device/dto"
+	"github.com/go-playground/validator/v10"
+)
+
+type DeviceValidation struct {
+	validate *validator.Validate
+}
+
+func NewDeviceValidation() *DeviceValidation {
+	validate := validator.New()
+	return &DeviceValidation{
+		validate: validate,
+	}
+}
+
+func (v *DeviceValidation) ValidateDeviceCreateRequest(req dto.DeviceCreateRequest) error {
+	return v.validate.Struct(req)
+}
+
+func (v *DeviceValidation) ValidateDeviceUpdateRequest(req dto.DeviceUpdateRequest) error {
+	return v.validate.Struct(req)
+}
+
+func (v *DeviceValidation) ValidateDeviceCommandRequest(req dto.DeviceCommandRequest) error {
+	return v.validate.Struct(req)
+}
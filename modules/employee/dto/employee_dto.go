@@ -35,6 +35,7 @@ type (
 		EmploymentType   string     `json:"employment_type" binding:"required"`
 		EmploymentStatus string     `json:"employment_status" binding:"required"`
 		ProbationEndDate time.Time  `json:"probation_end_date"`
+		LocationID       *uuid.UUID `json:"location_id"`
 
 		PersonalInfo EmployeePersonalInfoCreateRequest `json:"personal_info" binding:"required"`
 		Addresses    []EmployeeAddressCreateRequest    `json:"addresses" binding:"required"`
@@ -85,6 +86,7 @@ type (
 		EmploymentType   string     `json:"employment_type"`
 		EmploymentStatus string     `json:"employment_status"`
 		ProbationEndDate time.Time  `json:"probation_end_date"`
+		LocationID       *uuid.UUID `json:"location_id"`
 
 		PersonalInfo EmployeePersonalInfoUpdateRequest `json:"personal_info"`
 		Addresses    []EmployeeAddressUpdateRequest    `json:"addresses"`
@@ -144,6 +146,7 @@ type (
 		EmploymentType   string    `json:"employment_type"`
 		EmploymentStatus string    `json:"employment_status"`
 		ProbationEndDate time.Time `json:"probation_end_date"`
+		LocationID       *uuid.UUID `json:"location_id"`
 
 		User       UserResponse `json:"user"`
 		Department struct {
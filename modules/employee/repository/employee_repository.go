@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -13,6 +15,10 @@ type EmployeeRepository interface {
 	Create(ctx context.Context, tx *gorm.DB, employee entities.Employee, personalInfo entities.EmployeePersonalInfo, address []entities.EmployeeAddress, legalInfo entities.EmployeeLegalInfo, payrollInfo entities.EmployeePayrollProfile) (entities.Employee, error)
 	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Employee], error)
 	FindByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Employee, error)
+	FindByUserID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (entities.Employee, error)
+	FindByEmployeeCodes(ctx context.Context, db *gorm.DB, codes []string) ([]entities.Employee, error)
+	FindActive(ctx context.Context, db *gorm.DB, date time.Time) ([]entities.Employee, error)
+	FindForReport(ctx context.Context, db *gorm.DB, start, end time.Time, departmentID *uuid.UUID) ([]entities.Employee, error)
 	Update(ctx context.Context, tx *gorm.DB, employee entities.Employee) (entities.Employee, error)
 	Delete(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
 
@@ -117,6 +123,78 @@ func (r *employeeRepository) FindByID(ctx context.Context, db *gorm.DB, id uuid.
 	return employee, nil
 }
 
+func (r *employeeRepository) FindByUserID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (entities.Employee, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var employee entities.Employee
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).First(&employee).Error; err != nil {
+		return entities.Employee{}, err
+	}
+
+	return employee, nil
+}
+
+func (r *employeeRepository) FindByEmployeeCodes(ctx context.Context, db *gorm.DB, codes []string) ([]entities.Employee, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var employees []entities.Employee
+	if len(codes) == 0 {
+		return employees, nil
+	}
+	if err := db.WithContext(ctx).Where("employee_code IN ?", codes).Find(&employees).Error; err != nil {
+		return nil, err
+	}
+
+	return employees, nil
+}
+
+// FindActive lists the employees in active employment that had joined by date,
+// with their user and supervisor loaded for notifications.
+func (r *employeeRepository) FindActive(ctx context.Context, db *gorm.DB, date time.Time) ([]entities.Employee, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var employees []entities.Employee
+	if err := db.WithContext(ctx).Preload("User").Preload("Supervisor.User").
+		Where("LOWER(employment_status) = ?", constants.ENUM_EMPLOYMENT_STATUS_ACTIVE).
+		Where("join_date IS NULL OR join_date <= ?", date.Format("2006-01-02")).
+		Find(&employees).Error; err != nil {
+		return nil, err
+	}
+
+	return employees, nil
+}
+
+// FindForReport lists the employees an attendance report over [start, end]
+// covers: those in active employment who had joined by end, and anyone else
+// with attendance in the range, such as staff who left during it.
+func (r *employeeRepository) FindForReport(ctx context.Context, db *gorm.DB, start, end time.Time, departmentID *uuid.UUID) ([]entities.Employee, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	attended := db.Model(&entities.Attendance{}).Select("employee_id").
+		Where("work_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	query := db.WithContext(ctx).Preload("User").Preload("Department").
+		Where("(LOWER(employment_status) = ? AND (join_date IS NULL OR join_date <= ?)) OR id IN (?)",
+			constants.ENUM_EMPLOYMENT_STATUS_ACTIVE, end.Format("2006-01-02"), attended)
+	if departmentID != nil {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+
+	var employees []entities.Employee
+	if err := query.Order("employee_code").Find(&employees).Error; err != nil {
+		return nil, err
+	}
+
+	return employees, nil
+}
+
 func (r *employeeRepository) Update(ctx context.Context, tx *gorm.DB, employee entities.Employee) (entities.Employee, error) {
 	if tx == nil {
 		tx = r.db
@@ -70,6 +70,7 @@ func (s *employeeService) Create(ctx context.Context, req dto.EmployeeCreateRequ
 		EmploymentType:   req.EmploymentType,
 		EmploymentStatus: req.EmploymentStatus,
 		ProbationEndDate: req.ProbationEndDate,
+		LocationID:       req.LocationID,
 	}
 
 	personalInfo := entities.EmployeePersonalInfo{
@@ -142,6 +143,7 @@ func (s *employeeService) FindAll(ctx context.Context, filter *pagination.Filter
 			EmploymentType:   employee.EmploymentType,
 			EmploymentStatus: employee.EmploymentStatus,
 			ProbationEndDate: employee.ProbationEndDate,
+			LocationID:       employee.LocationID,
 			User: dto.UserResponse{
 				ID:         employee.User.ID,
 				Name:       employee.User.Name,
@@ -195,6 +197,7 @@ func (s *employeeService) FindByID(ctx context.Context, id uuid.UUID) (dto.Emplo
 		EmploymentType:   employee.EmploymentType,
 		EmploymentStatus: employee.EmploymentStatus,
 		ProbationEndDate: employee.ProbationEndDate,
+		LocationID:       employee.LocationID,
 		User: dto.UserResponse{
 			ID:         employee.User.ID,
 			Name:       employee.User.Name,
@@ -571,6 +574,9 @@ func (s *employeeService) Update(ctx context.Context, id uuid.UUID, req dto.Empl
 	if !req.ProbationEndDate.IsZero() {
 		employee.ProbationEndDate = req.ProbationEndDate
 	}
+	if req.LocationID != nil {
+		employee.LocationID = req.LocationID
+	}
 
 	updatedEmployee, err := s.employeeRepository.Update(ctx, tx, employee)
 	if err != nil {
@@ -1,15 +1,22 @@
 package controller
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/validation"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/samber/do"
 	"gorm.io/gorm"
 )
@@ -21,6 +28,21 @@ type (
 		Create(ctx *gin.Context)
 		Update(ctx *gin.Context)
 		Delete(ctx *gin.Context)
+		Approve(ctx *gin.Context)
+		Reject(ctx *gin.Context)
+		GetPendingApprovals(ctx *gin.Context)
+		Withdraw(ctx *gin.Context)
+		Cancel(ctx *gin.Context)
+		GetPendingCancellations(ctx *gin.Context)
+		ApproveCancellation(ctx *gin.Context)
+		RejectCancellation(ctx *gin.Context)
+		GetTypes(ctx *gin.Context)
+		CreateType(ctx *gin.Context)
+		UpdateType(ctx *gin.Context)
+		GetDuration(ctx *gin.Context)
+		GetMyBalances(ctx *gin.Context)
+		GetEmployeeBalances(ctx *gin.Context)
+		AdjustBalance(ctx *gin.Context)
 	}
 
 	leaveController struct {
@@ -84,8 +106,8 @@ func (c *leaveController) Create(ctx *gin.Context) {
 
 	result, err := c.leaveService.Create(req)
 	if err != nil {
-		res := utils.BuildResponseFailed("failed create leave", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		res := utils.BuildResponseFailed("failed create leave", err.Error(), leaveErrorData(err))
+		ctx.JSON(leaveErrorStatus(err), res)
 		return
 	}
 
@@ -108,10 +130,11 @@ func (c *leaveController) Update(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.leaveService.Update(id, req)
+	userID := ctx.MustGet("user_id").(string)
+	result, err := c.leaveService.Update(ctx.Request.Context(), id, userID, req)
 	if err != nil {
-		res := utils.BuildResponseFailed("failed update leave", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+		res := utils.BuildResponseFailed("failed update leave", err.Error(), leaveErrorData(err))
+		ctx.JSON(leaveErrorStatus(err), res)
 		return
 	}
 
@@ -123,10 +146,425 @@ func (c *leaveController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if err := c.leaveService.Delete(id); err != nil {
 		res := utils.BuildResponseFailed("failed delete leave", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+		ctx.JSON(leaveErrorStatus(err), res)
 		return
 	}
 
 	res := utils.BuildResponseSuccess("success delete leave", nil)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *leaveController) GetPendingApprovals(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.leaveService.FindPendingApprovals(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get pending leaves", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Approve decides the current approval step of a leave; the last step approves it.
+func (c *leaveController) Approve(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveApproveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.Approve(ctx.Request.Context(), id, userID, req.Comment)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed approve leave", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success approve leave", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) Reject(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveRejectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.Reject(ctx.Request.Context(), id, userID, req.Comment)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reject leave", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success reject leave", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Withdraw takes back a pending leave of the logged-in employee.
+func (c *leaveController) Withdraw(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.Withdraw(ctx.Request.Context(), id, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed withdraw leave", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success withdraw leave", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Cancel asks the approver of an approved leave of the logged-in employee to
+// cancel the days still ahead.
+func (c *leaveController) Cancel(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveCancelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.RequestCancellation(ctx.Request.Context(), id, userID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed cancel leave", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success request leave cancellation", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *leaveController) GetPendingCancellations(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.leaveService.FindPendingCancellations(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get pending leave cancellations", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) ApproveCancellation(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveApproveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.ApproveCancellation(ctx.Request.Context(), id, userID, req.Comment)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed approve leave cancellation", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success approve leave cancellation", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) RejectCancellation(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveRejectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.RejectCancellation(ctx.Request.Context(), id, userID, req.Comment)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reject leave cancellation", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success reject leave cancellation", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) GetTypes(ctx *gin.Context) {
+	result, err := c.leaveService.FindTypes(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get leave types", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) CreateType(ctx *gin.Context) {
+	var req dto.LeaveTypeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.CreateType(ctx.Request.Context(), req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed create leave type", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success create leave type", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *leaveController) UpdateType(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveTypeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.UpdateType(ctx.Request.Context(), id, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed update leave type", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success update leave type", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetMyBalances returns the yearly balances of the logged-in employee, for the
+// current year unless ?year= is given.
+func (c *leaveController) GetDuration(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var req dto.LeaveDurationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := utils.BuildResponseFailed("failed get data from query", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.GetDuration(ctx.Request.Context(), userID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed count leave days", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) GetMyBalances(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	year, err := balanceYear(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid year", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.GetMyBalances(ctx.Request.Context(), userID, year)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get leave balances", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) GetEmployeeBalances(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	year, err := balanceYear(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid year", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.GetEmployeeBalances(ctx.Request.Context(), employeeID, year)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get leave balances", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *leaveController) AdjustBalance(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.LeaveBalanceAdjustRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.leaveService.AdjustBalance(ctx.Request.Context(), employeeID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed adjust leave balance", err.Error(), nil)
+		ctx.JSON(leaveErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success adjust leave balance", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func balanceYear(ctx *gin.Context) (int, error) {
+	year := ctx.Query("year")
+	if year == "" {
+		return time.Now().In(helpers.LoadTimezone("")).Year(), nil
+	}
+	return strconv.Atoi(year)
+}
+
+// leaveErrorData carries the conflicts a refused leave request ran into.
+func leaveErrorData(err error) any {
+	var conflict *dto.LeaveConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Conflicts
+	}
+	return nil
+}
+
+func leaveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrNotCurrentApprover),
+		errors.Is(err, dto.ErrNotLeaveOwner),
+		errors.Is(err, dto.ErrNotLeaveEditor),
+		errors.Is(err, dto.ErrNotCancellationApprover):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrInsufficientBalance),
+		errors.Is(err, dto.ErrLeaveNotPending),
+		errors.Is(err, dto.ErrLeaveInReview),
+		errors.Is(err, dto.ErrLeaveConflict),
+		errors.Is(err, dto.ErrLeaveNotApproved),
+		errors.Is(err, dto.ErrLeaveDecided),
+		errors.Is(err, dto.ErrCancellationNotPending),
+		errors.Is(err, repository.ErrCancellationPending),
+		errors.Is(err, repository.ErrLeaveChanged),
+		errors.Is(err, repository.ErrBalanceExceeded),
+		errors.Is(err, repository.ErrStepDecided),
+		errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrLeaveRange),
+		errors.Is(err, dto.ErrLeavePartialRange),
+		errors.Is(err, dto.ErrLeaveSession),
+		errors.Is(err, dto.ErrLeaveHours),
+		errors.Is(err, dto.ErrCancelRange):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrEmployeeNotLinked),
+		errors.Is(err, dto.ErrLeaveTypeInactive),
+		errors.Is(err, dto.ErrLeaveNoDays),
+		errors.Is(err, dto.ErrLeaveExceedsLimit),
+		errors.Is(err, dto.ErrLeaveSpansYears),
+		errors.Is(err, dto.ErrLeaveTypeAccrual),
+		errors.Is(err, dto.ErrLeaveTypeNoBalance),
+		errors.Is(err, dto.ErrLeaveWithoutType),
+		errors.Is(err, dto.ErrLeaveUnitNotAllowed),
+		errors.Is(err, dto.ErrLeaveOutsideShift),
+		errors.Is(err, dto.ErrLeaveHoursTooLong),
+		errors.Is(err, dto.ErrCancelPast):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
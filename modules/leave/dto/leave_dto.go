@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,17 +12,182 @@ const (
 	MESSAGE_SUCCESS_GET_DATA         = "success get data"
 )
 
+var (
+	ErrEmployeeNotLinked       = errors.New("no employee record is linked to this user")
+	ErrLeaveRange              = errors.New("end_date must not be before start_date")
+	ErrLeaveTypeInactive       = errors.New("leave type is no longer offered")
+	ErrLeaveNoDays             = errors.New("leave covers no working day")
+	ErrLeaveExceedsLimit       = errors.New("leave is longer than this type allows per request")
+	ErrLeaveSpansYears         = errors.New("leave drawn from a yearly balance must start and end in the same year")
+	ErrInsufficientBalance     = errors.New("leave balance is not enough for this request")
+	ErrLeaveTypeAccrual        = errors.New("days_per_year is required for a leave type that accrues")
+	ErrLeaveTypeNoBalance      = errors.New("leave type keeps no yearly balance")
+	ErrLeaveNotPending         = errors.New("leave is no longer pending")
+	ErrNotCurrentApprover      = errors.New("only the approver of the current step can decide this leave")
+	ErrLeaveInReview           = errors.New("leave dates can no longer change once an approver has decided a step")
+	ErrLeaveWithoutType        = errors.New("leave has no type and can only have its reason edited")
+	ErrLeaveConflict           = errors.New("leave conflicts with other leave, attendance or department cover")
+	ErrLeaveUnitNotAllowed     = errors.New("leave type cannot be taken in this unit")
+	ErrLeavePartialRange       = errors.New("a half-day or hourly leave covers a single date")
+	ErrLeaveSession            = errors.New("session is required for a half-day leave")
+	ErrLeaveHours              = errors.New("start_time and end_time (HH:MM) are required for an hourly leave, with end_time after start_time")
+	ErrLeaveOutsideShift       = errors.New("hourly leave must fall within the scheduled shift")
+	ErrLeaveHoursTooLong       = errors.New("hourly leave must be shorter than the working day")
+	ErrNotLeaveOwner           = errors.New("only the employee who requested the leave can withdraw or cancel it")
+	ErrNotLeaveEditor          = errors.New("only the employee who requested the leave or HR can edit it")
+	ErrLeaveNotApproved        = errors.New("only an approved leave can be cancelled")
+	ErrLeaveDecided            = errors.New("only a pending leave no approver has decided can be deleted, withdraw or cancel it instead")
+	ErrCancelRange             = errors.New("from_date must fall within the leave")
+	ErrCancelPast              = errors.New("leave days already taken cannot be cancelled")
+	ErrNotCancellationApprover = errors.New("only the approver of the leave can decide its cancellation")
+	ErrCancellationNotPending  = errors.New("leave cancellation is no longer pending")
+)
+
+// LeaveConflictError lists everything a leave request runs into. It matches
+// ErrLeaveConflict with errors.Is.
+type LeaveConflictError struct {
+	Conflicts []LeaveConflict
+}
+
+func (e *LeaveConflictError) Error() string {
+	return ErrLeaveConflict.Error()
+}
+
+func (e *LeaveConflictError) Unwrap() error {
+	return ErrLeaveConflict
+}
+
 type (
+	// LeaveCreateRequest files a leave. Unit defaults to full_day; a half day
+	// names its session and an hourly leave its start and end time, both on a
+	// single date.
 	LeaveCreateRequest struct {
-		EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
-		StartDate  time.Time `json:"start_date" binding:"required"`
-		EndDate    time.Time `json:"end_date" binding:"required"`
-		Reason     string    `json:"reason" binding:"required"`
+		EmployeeID  uuid.UUID `json:"employee_id" binding:"required"`
+		LeaveTypeID uuid.UUID `json:"leave_type_id" binding:"required"`
+		StartDate   time.Time `json:"start_date" binding:"required"`
+		EndDate     time.Time `json:"end_date" binding:"required"`
+		Reason      string    `json:"reason" binding:"required"`
+		Unit        string    `json:"unit" binding:"omitempty,oneof=full_day half_day hourly"`
+		Session     *string   `json:"session" binding:"omitempty,oneof=morning afternoon"`
+		StartTime   *string   `json:"start_time" binding:"omitempty,len=5"`
+		EndTime     *string   `json:"end_time" binding:"omitempty,len=5"`
 	}
 
+	// LeaveUpdateRequest edits a pending leave; its status only moves through
+	// the approval chain. Dates and the part of the day may change until an
+	// approver has decided a step, and the leave is then routed afresh.
 	LeaveUpdateRequest struct {
-		Status string `json:"status" binding:"required,oneof=pending approved rejected"`
-		Reason string `json:"reason"`
+		StartDate *time.Time `json:"start_date"`
+		EndDate   *time.Time `json:"end_date"`
+		Reason    string     `json:"reason"`
+		Unit      *string    `json:"unit" binding:"omitempty,oneof=full_day half_day hourly"`
+		Session   *string    `json:"session" binding:"omitempty,oneof=morning afternoon"`
+		StartTime *string    `json:"start_time" binding:"omitempty,len=5"`
+		EndTime   *string    `json:"end_time" binding:"omitempty,len=5"`
+	}
+
+	LeaveApproveRequest struct {
+		Comment string `json:"comment"`
+	}
+
+	LeaveRejectRequest struct {
+		Comment string `json:"comment" binding:"required"`
+	}
+
+	// LeaveCancelRequest asks to cancel an approved leave from FromDate to its
+	// end, by default from today or from its first day when that is later.
+	LeaveCancelRequest struct {
+		FromDate *time.Time `json:"from_date"`
+		Reason   string     `json:"reason" binding:"required"`
+	}
+
+	// LeaveTypeRequest creates or updates a leave type. Paid defaults to true
+	// on create; IsActive only applies to updates.
+	LeaveTypeRequest struct {
+		Code              string   `json:"code" binding:"required,max=50"`
+		Name              string   `json:"name" binding:"required,max=100"`
+		Description       string   `json:"description"`
+		Paid              *bool    `json:"paid"`
+		CalendarDays      bool     `json:"calendar_days"`
+		AccrualPolicy     string   `json:"accrual_policy" binding:"required,oneof=yearly monthly none"`
+		DaysPerYear       float64  `json:"days_per_year" binding:"gte=0,lte=365"`
+		MinServiceMonths  int      `json:"min_service_months" binding:"gte=0"`
+		ProRate           bool     `json:"pro_rate"`
+		MaxDaysPerRequest *float64 `json:"max_days_per_request" binding:"omitempty,gt=0"`
+		AllowHalfDay      bool     `json:"allow_half_day"`
+		AllowHourly       bool     `json:"allow_hourly"`
+		IsActive          *bool    `json:"is_active"`
+
+		HeadApprovalFromDays *float64 `json:"head_approval_from_days" binding:"omitempty,gte=0"`
+		HRApprovalFromDays   *float64 `json:"hr_approval_from_days" binding:"omitempty,gte=0"`
+	}
+
+	// LeaveBalanceAdjustRequest sets the HR adjustment of a yearly balance,
+	// e.g. days carried over from the year before.
+	LeaveBalanceAdjustRequest struct {
+		LeaveTypeID uuid.UUID `json:"leave_type_id" binding:"required"`
+		Year        int       `json:"year" binding:"required,gte=2000,lte=2100"`
+		Adjustment  float64   `json:"adjustment"`
+	}
+
+	// LeaveBalanceResponse is the balance of one leave type in a year. Pending
+	// days are requested but not approved yet; Available leaves them out.
+	LeaveBalanceResponse struct {
+		ID            uuid.UUID `json:"id"`
+		LeaveTypeID   uuid.UUID `json:"leave_type_id"`
+		LeaveTypeCode string    `json:"leave_type_code"`
+		LeaveTypeName string    `json:"leave_type_name"`
+		Year          int       `json:"year"`
+		Entitled      float64   `json:"entitled"`
+		Adjustment    float64   `json:"adjustment"`
+		Used          float64   `json:"used"`
+		Pending       float64   `json:"pending"`
+		Available     float64   `json:"available"`
+	}
+
+	// LeaveDurationRequest previews how many days a leave of the signed in
+	// employee would take before it is filed.
+	LeaveDurationRequest struct {
+		LeaveTypeID string    `form:"leave_type_id" binding:"required,uuid"`
+		StartDate   time.Time `form:"start_date" time_format:"2006-01-02" binding:"required"`
+		EndDate     time.Time `form:"end_date" time_format:"2006-01-02" binding:"required"`
+		Unit        string    `form:"unit" binding:"omitempty,oneof=full_day half_day hourly"`
+		Session     string    `form:"session" binding:"omitempty,oneof=morning afternoon"`
+		StartTime   string    `form:"start_time" binding:"omitempty,len=5"`
+		EndTime     string    `form:"end_time" binding:"omitempty,len=5"`
+	}
+
+	// LeaveDurationResponse counts the days a leave takes and lists the
+	// holidays within it, which working day types leave out.
+	LeaveDurationResponse struct {
+		LeaveTypeID  uuid.UUID              `json:"leave_type_id"`
+		StartDate    string                 `json:"start_date"`
+		EndDate      string                 `json:"end_date"`
+		Unit         string                 `json:"unit"`
+		WorkWeekDays int                    `json:"work_week_days"`
+		Days         float64                `json:"days"`
+		Holidays     []LeaveHolidayResponse `json:"holidays"`
+	}
+
+	LeaveHolidayResponse struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+
+	// LeaveConflict is one thing a leave request runs into: an overlapping
+	// leave of the employee, a day they checked in, or a day their department
+	// already has Cap people off.
+	LeaveConflict struct {
+		Type         string     `json:"type"`
+		Date         string     `json:"date,omitempty"`
+		LeaveID      *uuid.UUID `json:"leave_id,omitempty"`
+		Status       string     `json:"status,omitempty"`
+		StartDate    string     `json:"start_date,omitempty"`
+		EndDate      string     `json:"end_date,omitempty"`
+		AttendanceID *uuid.UUID `json:"attendance_id,omitempty"`
+		OnLeave      int        `json:"on_leave,omitempty"`
+		Cap          int        `json:"cap,omitempty"`
 	}
 
 	LeaveResponse struct {
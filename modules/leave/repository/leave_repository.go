@@ -2,11 +2,33 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrBalanceExceeded is returned when a leave would use more days than its
+	// balance has left.
+	ErrBalanceExceeded = errors.New("leave balance exceeded")
+
+	// ErrStepDecided is returned when the approval step was decided by someone
+	// else since the leave was read.
+	ErrStepDecided = errors.New("leave approval step already decided")
+
+	// ErrCancellationPending is returned when a leave already has a
+	// cancellation waiting for a decision.
+	ErrCancellationPending = errors.New("leave already has a pending cancellation")
+
+	// ErrLeaveChanged is returned when a leave was cancelled or shortened since
+	// a cancellation of it was requested.
+	ErrLeaveChanged = errors.New("leave changed since the cancellation was requested")
 )
 
 type LeaveRepository interface {
@@ -15,6 +37,20 @@ type LeaveRepository interface {
 	Create(leave *entities.Leave) (*entities.Leave, error)
 	Update(leave *entities.Leave) (*entities.Leave, error)
 	Delete(id uuid.UUID) error
+	Reschedule(ctx context.Context, leave *entities.Leave) (*entities.Leave, error)
+	Decide(ctx context.Context, leave *entities.Leave, step entities.LeaveApproval, balanceID uuid.UUID, usedDelta float64) (*entities.Leave, error)
+	FindPendingForApprover(ctx context.Context, db *gorm.DB, filter *pagination.Filter, approverID uuid.UUID, includeHR bool) (*pagination.Page[entities.Leave], error)
+	Withdraw(ctx context.Context, leave *entities.Leave) (*entities.Leave, error)
+	CreateCancellation(ctx context.Context, db *gorm.DB, cancellation entities.LeaveCancellation) (entities.LeaveCancellation, error)
+	FindCancellationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveCancellation, error)
+	FindPendingCancellations(ctx context.Context, db *gorm.DB, filter *pagination.Filter, approverID uuid.UUID, includeHR bool) (*pagination.Page[entities.LeaveCancellation], error)
+	DecideCancellation(ctx context.Context, cancellation entities.LeaveCancellation, leave entities.Leave, balanceID uuid.UUID) (entities.LeaveCancellation, error)
+	SumDays(ctx context.Context, db *gorm.DB, employeeID, leaveTypeID uuid.UUID, status string, start, end time.Time) (float64, error)
+	FindEmployeeIDsOnLeave(ctx context.Context, db *gorm.DB, date time.Time) ([]uuid.UUID, error)
+	FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.Leave, error)
+	FindApprovedPartialOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) ([]entities.Leave, error)
+	FindOverlapping(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) ([]entities.Leave, error)
+	FindDepartmentLeavesInRange(ctx context.Context, db *gorm.DB, departmentID uuid.UUID, start, end time.Time, excludeEmployeeID uuid.UUID) ([]entities.Leave, error)
 }
 
 type leaveRepository struct {
@@ -50,24 +86,34 @@ func (r *leaveRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagi
 
 func (r *leaveRepository) FindByID(id uuid.UUID) (*entities.Leave, error) {
 	var leave entities.Leave
-	if err := r.db.Preload("Employee").Where("id = ?", id).First(&leave).Error; err != nil {
+	query := withApprovals(r.db).Preload("Employee.User").Preload("LeaveType").Preload("Cancellations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	})
+	if err := query.Where("id = ?", id).First(&leave).Error; err != nil {
 		return nil, err
 	}
 	return &leave, nil
 }
 
+// withApprovals preloads the approval chain of leaves in level order.
+func withApprovals(db *gorm.DB) *gorm.DB {
+	return db.Preload("Approvals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("level")
+	})
+}
+
 func (r *leaveRepository) Create(leave *entities.Leave) (*entities.Leave, error) {
 	if err := r.db.Create(leave).Error; err != nil {
 		return nil, err
 	}
-	if err := r.db.Preload("Employee").First(leave, "id = ?", leave.ID).Error; err != nil {
+	if err := withApprovals(r.db).Preload("Employee").First(leave, "id = ?", leave.ID).Error; err != nil {
 		return nil, err
 	}
 	return leave, nil
 }
 
 func (r *leaveRepository) Update(leave *entities.Leave) (*entities.Leave, error) {
-	if err := r.db.Save(leave).Error; err != nil {
+	if err := r.db.Omit(clause.Associations).Save(leave).Error; err != nil {
 		return nil, err
 	}
 	if err := r.db.Preload("Employee").First(leave, "id = ?", leave.ID).Error; err != nil {
@@ -76,9 +122,417 @@ func (r *leaveRepository) Update(leave *entities.Leave) (*entities.Leave, error)
 	return leave, nil
 }
 
+// Reschedule writes the new dates and days of a pending leave and replaces its
+// approval chain with leave.Approvals. A leave on which an approver decided a
+// step meanwhile returns ErrStepDecided.
+func (r *leaveRepository) Reschedule(ctx context.Context, leave *entities.Leave) (*entities.Leave, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		decided := tx.Model(&entities.LeaveApproval{}).Select("1").
+			Where("leave_id = leaves.id AND status NOT IN ?", []string{constants.ENUM_LEAVE_STEP_WAITING, constants.ENUM_LEAVE_STEP_PENDING})
+		result := tx.Model(&entities.Leave{}).
+			Where("id = ? AND status = ?", leave.ID, constants.ENUM_LEAVE_STATUS_PENDING).
+			Where("NOT EXISTS (?)", decided).
+			Updates(map[string]any{
+				"start_date": leave.StartDate,
+				"end_date":   leave.EndDate,
+				"days":       leave.Days,
+				"unit":       leave.Unit,
+				"session":    leave.Session,
+				"start_time": leave.StartTime,
+				"end_time":   leave.EndTime,
+				"reason":     leave.Reason,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStepDecided
+		}
+
+		if err := tx.Delete(&entities.LeaveApproval{}, "leave_id = ?", leave.ID).Error; err != nil {
+			return err
+		}
+		for i := range leave.Approvals {
+			leave.Approvals[i].ID = uuid.Nil
+			leave.Approvals[i].LeaveID = leave.ID
+		}
+		return tx.Create(&leave.Approvals).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := withApprovals(r.db.WithContext(ctx)).Preload("Employee").Preload("LeaveType").First(leave, "id = ?", leave.ID).Error; err != nil {
+		return nil, err
+	}
+	return leave, nil
+}
+
+// Decide records the decision on the current approval step along with the
+// leave status and the later steps it moves, in one transaction. A leave that
+// ends approved moves usedDelta days of its balance; a zero delta leaves the
+// balance alone. A step decided meanwhile returns ErrStepDecided.
+func (r *leaveRepository) Decide(ctx context.Context, leave *entities.Leave, step entities.LeaveApproval, balanceID uuid.UUID, usedDelta float64) (*entities.Leave, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.LeaveApproval{}).
+			Where("id = ? AND status = ?", step.ID, constants.ENUM_LEAVE_STEP_PENDING).
+			Updates(map[string]any{
+				"status":        step.Status,
+				"decided_by_id": step.DecidedByID,
+				"decided_at":    step.DecidedAt,
+				"comment":       step.Comment,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStepDecided
+		}
+
+		for _, approval := range leave.Approvals {
+			if approval.ID == step.ID {
+				continue
+			}
+			if err := tx.Model(&entities.LeaveApproval{}).Where("id = ?", approval.ID).
+				Update("status", approval.Status).Error; err != nil {
+				return err
+			}
+		}
+
+		if usedDelta != 0 {
+			if err := moveUsedDays(tx, balanceID, usedDelta); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entities.Leave{}).Where("id = ?", leave.ID).Update("status", leave.Status).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := withApprovals(r.db.WithContext(ctx)).Preload("Employee").Preload("LeaveType").First(leave, "id = ?", leave.ID).Error; err != nil {
+		return nil, err
+	}
+	return leave, nil
+}
+
+// FindPendingForApprover lists the pending leaves whose current step waits on
+// the approver, and the HR steps as well when includeHR is set. The approver's
+// own leave is left out.
+func (r *leaveRepository) FindPendingForApprover(ctx context.Context, db *gorm.DB, filter *pagination.Filter, approverID uuid.UUID, includeHR bool) (*pagination.Page[entities.Leave], error) {
+	if db == nil {
+		db = r.db
+	}
+
+	steps := db.Model(&entities.LeaveApproval{}).Select("leave_id").
+		Where("status = ?", constants.ENUM_LEAVE_STEP_PENDING)
+	if includeHR {
+		steps = steps.Where("approver_id = ? OR role = ?", approverID, constants.ENUM_LEAVE_APPROVER_HR)
+	} else {
+		steps = steps.Where("approver_id = ?", approverID)
+	}
+
+	var items []entities.Leave
+	var page pagination.Page[entities.Leave]
+
+	query := db.WithContext(ctx).Model(&entities.Leave{}).Preload("Employee").Preload("LeaveType").
+		Where("status = ? AND employee_id <> ?", constants.ENUM_LEAVE_STATUS_PENDING, approverID).
+		Where("id IN (?)", steps)
+	paginator, err := pagination.NewPaginator(withApprovals(query), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+// Withdraw takes back a pending leave and skips the approval steps still open,
+// keeping the leave and its chain as history. A leave decided meanwhile
+// returns ErrStepDecided.
+func (r *leaveRepository) Withdraw(ctx context.Context, leave *entities.Leave) (*entities.Leave, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Leave{}).
+			Where("id = ? AND status = ?", leave.ID, constants.ENUM_LEAVE_STATUS_PENDING).
+			Update("status", constants.ENUM_LEAVE_STATUS_WITHDRAWN)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStepDecided
+		}
+
+		return tx.Model(&entities.LeaveApproval{}).
+			Where("leave_id = ? AND status IN ?", leave.ID, []string{constants.ENUM_LEAVE_STEP_WAITING, constants.ENUM_LEAVE_STEP_PENDING}).
+			Update("status", constants.ENUM_LEAVE_STEP_SKIPPED).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := withApprovals(r.db.WithContext(ctx)).Preload("Employee").Preload("LeaveType").First(leave, "id = ?", leave.ID).Error; err != nil {
+		return nil, err
+	}
+	return leave, nil
+}
+
+// CreateCancellation files a cancellation of an approved leave. A leave that
+// already has one pending returns ErrCancellationPending.
+func (r *leaveRepository) CreateCancellation(ctx context.Context, db *gorm.DB, cancellation entities.LeaveCancellation) (entities.LeaveCancellation, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	if err := db.WithContext(ctx).Omit(clause.Associations).Create(&cancellation).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return entities.LeaveCancellation{}, ErrCancellationPending
+		}
+		return entities.LeaveCancellation{}, err
+	}
+	return cancellation, nil
+}
+
+func (r *leaveRepository) FindCancellationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveCancellation, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var cancellation entities.LeaveCancellation
+	if err := db.WithContext(ctx).Preload("Leave.Employee.User").Preload("Leave.LeaveType").Preload("DecidedBy").
+		Where("id = ?", id).First(&cancellation).Error; err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	return cancellation, nil
+}
+
+// FindPendingCancellations lists the cancellations waiting on the approver,
+// and those of leaves HR approved as well when includeHR is set. Cancellations
+// of the approver's own leave are left out.
+func (r *leaveRepository) FindPendingCancellations(ctx context.Context, db *gorm.DB, filter *pagination.Filter, approverID uuid.UUID, includeHR bool) (*pagination.Page[entities.LeaveCancellation], error) {
+	if db == nil {
+		db = r.db
+	}
+
+	others := db.Model(&entities.Leave{}).Select("id").Where("employee_id <> ?", approverID)
+	query := db.WithContext(ctx).Model(&entities.LeaveCancellation{}).Preload("Leave.Employee").Preload("Leave.LeaveType").
+		Where("status = ? AND leave_id IN (?)", constants.ENUM_LEAVE_CANCELLATION_PENDING, others)
+	if includeHR {
+		query = query.Where("approver_id = ? OR approver_id IS NULL", approverID)
+	} else {
+		query = query.Where("approver_id = ?", approverID)
+	}
+
+	var items []entities.LeaveCancellation
+	var page pagination.Page[entities.LeaveCancellation]
+
+	paginator, err := pagination.NewPaginator(query.Order("created_at"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+// DecideCancellation records the decision on a pending cancellation. An
+// approved one writes the status, end date and days of leave in the same
+// transaction and gives the cancelled days back to the balance, unless
+// balanceID is nil. A cancellation decided meanwhile returns ErrStepDecided,
+// and a leave cancelled or shortened meanwhile ErrLeaveChanged.
+func (r *leaveRepository) DecideCancellation(ctx context.Context, cancellation entities.LeaveCancellation, leave entities.Leave, balanceID uuid.UUID) (entities.LeaveCancellation, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.LeaveCancellation{}).
+			Where("id = ? AND status = ?", cancellation.ID, constants.ENUM_LEAVE_CANCELLATION_PENDING).
+			Updates(map[string]any{
+				"status":        cancellation.Status,
+				"decided_by_id": cancellation.DecidedByID,
+				"decided_at":    cancellation.DecidedAt,
+				"comment":       cancellation.Comment,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStepDecided
+		}
+		if cancellation.Status != constants.ENUM_LEAVE_CANCELLATION_APPROVED {
+			return nil
+		}
+
+		result = tx.Model(&entities.Leave{}).
+			Where("id = ? AND status = ? AND end_date = ?", leave.ID, constants.ENUM_LEAVE_STATUS_APPROVED, cancellation.ToDate.Format("2006-01-02")).
+			Updates(map[string]any{
+				"status":   leave.Status,
+				"end_date": leave.EndDate,
+				"days":     leave.Days,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLeaveChanged
+		}
+
+		if balanceID != uuid.Nil && cancellation.Days != 0 {
+			return moveUsedDays(tx, balanceID, -cancellation.Days)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	return r.FindCancellationByID(ctx, nil, cancellation.ID)
+}
+
+// moveUsedDays changes the used days of a balance. Deductions only apply while
+// the remaining days cover them, so concurrent approvals cannot overdraw it.
+func moveUsedDays(tx *gorm.DB, balanceID uuid.UUID, usedDelta float64) error {
+	query := tx.Model(&entities.LeaveBalance{}).Where("id = ?", balanceID)
+	if usedDelta > 0 {
+		query = query.Where("entitled + adjustment - used >= ?", usedDelta)
+	}
+	result := query.Update("used", gorm.Expr("used + ?", usedDelta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBalanceExceeded
+	}
+	return nil
+}
+
+// Delete removes a pending leave along with its approval chain. A leave that
+// was decided meanwhile returns ErrStepDecided, as its history must be kept.
 func (r *leaveRepository) Delete(id uuid.UUID) error {
-	if err := r.db.Delete(&entities.Leave{}, "id = ?", id).Error; err != nil {
-		return err
+	decided := r.db.Model(&entities.LeaveApproval{}).Select("1").
+		Where("leave_id = leaves.id AND status NOT IN ?", []string{constants.ENUM_LEAVE_STEP_WAITING, constants.ENUM_LEAVE_STEP_PENDING})
+	result := r.db.
+		Where("id = ? AND status = ?", id, constants.ENUM_LEAVE_STATUS_PENDING).
+		Where("NOT EXISTS (?)", decided).
+		Delete(&entities.Leave{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStepDecided
 	}
 	return nil
 }
+
+// FindEmployeeIDsOnLeave lists the employees whose approved full-day leave
+// covers date. Those on a half-day or hourly leave are still expected at work.
+func (r *leaveRepository) FindEmployeeIDsOnLeave(ctx context.Context, db *gorm.DB, date time.Time) ([]uuid.UUID, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var ids []uuid.UUID
+	day := date.Format("2006-01-02")
+	if err := db.WithContext(ctx).Model(&entities.Leave{}).
+		Where("status = ? AND start_date <= ? AND end_date >= ?", constants.ENUM_LEAVE_STATUS_APPROVED, day, day).
+		Where("unit = ?", constants.ENUM_LEAVE_UNIT_FULL_DAY).
+		Distinct().Pluck("employee_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FindApprovedInRange lists the approved leaves of the employees that overlap
+// [start, end].
+func (r *leaveRepository) FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.Leave, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaves []entities.Leave
+	if len(employeeIDs) == 0 {
+		return leaves, nil
+	}
+	if err := db.WithContext(ctx).
+		Where("employee_id IN ? AND status = ?", employeeIDs, constants.ENUM_LEAVE_STATUS_APPROVED).
+		Where("start_date <= ? AND end_date >= ?", end.Format("2006-01-02"), start.Format("2006-01-02")).
+		Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// FindApprovedPartialOn lists the approved half-day and hourly leaves of an
+// employee on date.
+func (r *leaveRepository) FindApprovedPartialOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) ([]entities.Leave, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaves []entities.Leave
+	if err := db.WithContext(ctx).
+		Where("employee_id = ? AND status = ? AND start_date = ?", employeeID, constants.ENUM_LEAVE_STATUS_APPROVED, date.Format("2006-01-02")).
+		Where("unit <> ?", constants.ENUM_LEAVE_UNIT_FULL_DAY).
+		Order("start_time").
+		Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// FindOverlapping lists the pending and approved leaves of an employee that
+// overlap [start, end], leaving out excludeID.
+func (r *leaveRepository) FindOverlapping(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) ([]entities.Leave, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaves []entities.Leave
+	query := db.WithContext(ctx).
+		Where("employee_id = ? AND status IN ?", employeeID, []string{constants.ENUM_LEAVE_STATUS_PENDING, constants.ENUM_LEAVE_STATUS_APPROVED}).
+		Where("start_date <= ? AND end_date >= ?", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+	if err := query.Order("start_date").Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// FindDepartmentLeavesInRange lists the pending and approved leaves of the
+// department's employees that overlap [start, end], leaving out those of
+// excludeEmployeeID.
+func (r *leaveRepository) FindDepartmentLeavesInRange(ctx context.Context, db *gorm.DB, departmentID uuid.UUID, start, end time.Time, excludeEmployeeID uuid.UUID) ([]entities.Leave, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaves []entities.Leave
+	if err := db.WithContext(ctx).
+		Joins("JOIN employees ON employees.id = leaves.employee_id").
+		Where("employees.department_id = ? AND leaves.employee_id <> ?", departmentID, excludeEmployeeID).
+		Where("leaves.status IN ?", []string{constants.ENUM_LEAVE_STATUS_PENDING, constants.ENUM_LEAVE_STATUS_APPROVED}).
+		Where("leaves.start_date <= ? AND leaves.end_date >= ?", end.Format("2006-01-02"), start.Format("2006-01-02")).
+		Find(&leaves).Error; err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+// SumDays totals the days of an employee's leaves of a type in a status that
+// start within [start, end].
+func (r *leaveRepository) SumDays(ctx context.Context, db *gorm.DB, employeeID, leaveTypeID uuid.UUID, status string, start, end time.Time) (float64, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var days float64
+	if err := db.WithContext(ctx).Model(&entities.Leave{}).
+		Where("employee_id = ? AND leave_type_id = ? AND status = ?", employeeID, leaveTypeID, status).
+		Where("start_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Select("COALESCE(SUM(days), 0)").Scan(&days).Error; err != nil {
+		return 0, err
+	}
+	return days, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LeaveTypeRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, leaveType entities.LeaveType) (entities.LeaveType, error)
+	FindAll(ctx context.Context, db *gorm.DB) ([]entities.LeaveType, error)
+	FindAccruing(ctx context.Context, db *gorm.DB) ([]entities.LeaveType, error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveType, error)
+	Update(ctx context.Context, tx *gorm.DB, leaveType entities.LeaveType) (entities.LeaveType, error)
+	EnsureBalance(ctx context.Context, tx *gorm.DB, employeeID, leaveTypeID uuid.UUID, year int) (entities.LeaveBalance, error)
+	FindBalances(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, year int) ([]entities.LeaveBalance, error)
+	SetEntitled(ctx context.Context, tx *gorm.DB, balanceID uuid.UUID, entitled float64) error
+	SetAdjustment(ctx context.Context, tx *gorm.DB, balanceID uuid.UUID, adjustment float64) error
+}
+
+type leaveTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaveTypeRepository(db *gorm.DB) LeaveTypeRepository {
+	return &leaveTypeRepository{
+		db: db,
+	}
+}
+
+func (r *leaveTypeRepository) Create(ctx context.Context, tx *gorm.DB, leaveType entities.LeaveType) (entities.LeaveType, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&leaveType).Error; err != nil {
+		return entities.LeaveType{}, err
+	}
+	return leaveType, nil
+}
+
+func (r *leaveTypeRepository) FindAll(ctx context.Context, db *gorm.DB) ([]entities.LeaveType, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaveTypes []entities.LeaveType
+	if err := db.WithContext(ctx).Order("name").Find(&leaveTypes).Error; err != nil {
+		return nil, err
+	}
+	return leaveTypes, nil
+}
+
+// FindAccruing lists the active types that keep a yearly balance.
+func (r *leaveTypeRepository) FindAccruing(ctx context.Context, db *gorm.DB) ([]entities.LeaveType, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaveTypes []entities.LeaveType
+	if err := db.WithContext(ctx).
+		Where("is_active AND accrual_policy <> ?", constants.ENUM_LEAVE_ACCRUAL_NONE).
+		Order("name").Find(&leaveTypes).Error; err != nil {
+		return nil, err
+	}
+	return leaveTypes, nil
+}
+
+func (r *leaveTypeRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveType, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var leaveType entities.LeaveType
+	if err := db.WithContext(ctx).Where("id = ?", id).Take(&leaveType).Error; err != nil {
+		return entities.LeaveType{}, err
+	}
+	return leaveType, nil
+}
+
+func (r *leaveTypeRepository) Update(ctx context.Context, tx *gorm.DB, leaveType entities.LeaveType) (entities.LeaveType, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Save(&leaveType).Error; err != nil {
+		return entities.LeaveType{}, err
+	}
+	return leaveType, nil
+}
+
+// EnsureBalance returns the balance of an employee for a type and year,
+// creating it empty on first use.
+func (r *leaveTypeRepository) EnsureBalance(ctx context.Context, tx *gorm.DB, employeeID, leaveTypeID uuid.UUID, year int) (entities.LeaveBalance, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	balance := entities.LeaveBalance{EmployeeID: employeeID, LeaveTypeID: leaveTypeID, Year: year}
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&balance).Error; err != nil {
+		return entities.LeaveBalance{}, err
+	}
+
+	if err := tx.WithContext(ctx).Preload("LeaveType").
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveTypeID, year).
+		Take(&balance).Error; err != nil {
+		return entities.LeaveBalance{}, err
+	}
+	return balance, nil
+}
+
+func (r *leaveTypeRepository) FindBalances(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, year int) ([]entities.LeaveBalance, error) {
+	if db == nil {
+		db = r.db
+	}
+
+	var balances []entities.LeaveBalance
+	if err := db.WithContext(ctx).Preload("LeaveType").
+		Where("employee_id = ? AND year = ?", employeeID, year).
+		Find(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// SetEntitled stores the accrued days of a balance, leaving the used days to
+// the leaves that move them.
+func (r *leaveTypeRepository) SetEntitled(ctx context.Context, tx *gorm.DB, balanceID uuid.UUID, entitled float64) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entities.LeaveBalance{}).Where("id = ?", balanceID).
+		Updates(map[string]any{"entitled": entitled, "updated_at": time.Now()}).Error
+}
+
+func (r *leaveTypeRepository) SetAdjustment(ctx context.Context, tx *gorm.DB, balanceID uuid.UUID, adjustment float64) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Model(&entities.LeaveBalance{}).Where("id = ?", balanceID).
+		Updates(map[string]any{"adjustment": adjustment, "updated_at": time.Now()}).Error
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/controller"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do"
@@ -13,14 +14,33 @@ func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 	leaveController := do.MustInvoke[controller.LeaveController](injector)
 
 	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
 
 	leaveRoutes := server.Group("/api/leaves")
 	leaveRoutes.Use(middlewares.Authenticate(jwtService))
 	{
+		leaveRoutes.GET("/types", leaveController.GetTypes)
+		leaveRoutes.POST("/types", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_LEAVE), leaveController.CreateType)
+		leaveRoutes.PUT("/types/:id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_LEAVE), leaveController.UpdateType)
+		leaveRoutes.GET("/duration", leaveController.GetDuration)
+		leaveRoutes.GET("/balances/me", leaveController.GetMyBalances)
+		leaveRoutes.GET("/balances/employees/:employee_id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_LEAVE), leaveController.GetEmployeeBalances)
+		leaveRoutes.PUT("/balances/employees/:employee_id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_LEAVE), leaveController.AdjustBalance)
+
+		leaveRoutes.GET("/approvals", leaveController.GetPendingApprovals)
+		leaveRoutes.POST("/:id/approve", leaveController.Approve)
+		leaveRoutes.POST("/:id/reject", leaveController.Reject)
+		leaveRoutes.POST("/:id/withdraw", leaveController.Withdraw)
+		leaveRoutes.POST("/:id/cancel", leaveController.Cancel)
+
+		leaveRoutes.GET("/cancellations", leaveController.GetPendingCancellations)
+		leaveRoutes.POST("/cancellations/:id/approve", leaveController.ApproveCancellation)
+		leaveRoutes.POST("/cancellations/:id/reject", leaveController.RejectCancellation)
+
 		leaveRoutes.GET("", leaveController.GetAll)
 		leaveRoutes.GET(":id", leaveController.GetByID)
 		leaveRoutes.POST("", leaveController.Create)
 		leaveRoutes.PUT(":id", leaveController.Update)
-		leaveRoutes.DELETE(":id", leaveController.Delete)
+		leaveRoutes.DELETE(":id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_LEAVE), leaveController.Delete)
 	}
 }
@@ -3,10 +3,23 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,22 +29,64 @@ type LeaveService interface {
 	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.Leave], error)
 	GetByID(id string) (*entities.Leave, error)
 	Create(req dto.LeaveCreateRequest) (*entities.Leave, error)
-	Update(id string, req dto.LeaveUpdateRequest) (*entities.Leave, error)
+	Update(ctx context.Context, id string, userID string, req dto.LeaveUpdateRequest) (*entities.Leave, error)
 	Delete(id string) error
+	Approve(ctx context.Context, id uuid.UUID, userID string, comment string) (*entities.Leave, error)
+	Reject(ctx context.Context, id uuid.UUID, userID string, comment string) (*entities.Leave, error)
+	FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.Leave], error)
+
+	Withdraw(ctx context.Context, id uuid.UUID, userID string) (*entities.Leave, error)
+	RequestCancellation(ctx context.Context, id uuid.UUID, userID string, req dto.LeaveCancelRequest) (entities.LeaveCancellation, error)
+	ApproveCancellation(ctx context.Context, id uuid.UUID, userID string, comment string) (entities.LeaveCancellation, error)
+	RejectCancellation(ctx context.Context, id uuid.UUID, userID string, comment string) (entities.LeaveCancellation, error)
+	FindPendingCancellations(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.LeaveCancellation], error)
+
+	GetDuration(ctx context.Context, userID string, req dto.LeaveDurationRequest) (dto.LeaveDurationResponse, error)
+
+	FindTypes(ctx context.Context) ([]entities.LeaveType, error)
+	CreateType(ctx context.Context, req dto.LeaveTypeRequest) (entities.LeaveType, error)
+	UpdateType(ctx context.Context, id uuid.UUID, req dto.LeaveTypeRequest) (entities.LeaveType, error)
+	GetMyBalances(ctx context.Context, userID string, year int) ([]dto.LeaveBalanceResponse, error)
+	GetEmployeeBalances(ctx context.Context, employeeID uuid.UUID, year int) ([]dto.LeaveBalanceResponse, error)
+	AdjustBalance(ctx context.Context, employeeID uuid.UUID, req dto.LeaveBalanceAdjustRequest) (dto.LeaveBalanceResponse, error)
 }
 
 type leaveService struct {
-	leaveRepository repository.LeaveRepository
-	db              *gorm.DB
+	leaveRepository      repository.LeaveRepository
+	leaveTypeRepository  repository.LeaveTypeRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	masterRepository     masterRepository.MasterRepository
+	attendanceRepository attendanceRepository.AttendanceRepository
+	attendanceService    attendanceService.AttendanceService
+	shiftService         shiftService.ShiftService
+	notificationService  notificationService.NotificationService
+	rbacService          rbacService.RbacService
+	db                   *gorm.DB
 }
 
 func NewLeaveService(
 	leaveRepo repository.LeaveRepository,
+	leaveTypeRepo repository.LeaveTypeRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	masterRepo masterRepository.MasterRepository,
+	attendanceRepo attendanceRepository.AttendanceRepository,
+	attendanceSvc attendanceService.AttendanceService,
+	shiftSvc shiftService.ShiftService,
+	notificationSvc notificationService.NotificationService,
+	rbacSvc rbacService.RbacService,
 	db *gorm.DB,
 ) LeaveService {
 	return &leaveService{
-		leaveRepository: leaveRepo,
-		db:              db,
+		leaveRepository:      leaveRepo,
+		leaveTypeRepository:  leaveTypeRepo,
+		employeeRepository:   employeeRepo,
+		masterRepository:     masterRepo,
+		attendanceRepository: attendanceRepo,
+		attendanceService:    attendanceSvc,
+		shiftService:         shiftSvc,
+		notificationService:  notificationSvc,
+		rbacService:          rbacSvc,
+		db:                   db,
 	}
 }
 
@@ -47,18 +102,68 @@ func (s *leaveService) GetByID(id string) (*entities.Leave, error) {
 	return s.leaveRepository.FindByID(uid)
 }
 
+// Create files a pending leave and routes it along its approval chain. Leave
+// drawn from a yearly balance is refused when the days left, less those already
+// requested and pending, do not cover it. A half-day or hourly leave takes its
+// share of a single working day.
 func (s *leaveService) Create(req dto.LeaveCreateRequest) (*entities.Leave, error) {
+	ctx := context.Background()
+
+	start, end := helpers.DateOf(req.StartDate), helpers.DateOf(req.EndDate)
+	if end.Before(start) {
+		return nil, dto.ErrLeaveRange
+	}
+
+	leaveType, err := s.leaveTypeRepository.GetByID(ctx, nil, req.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !leaveType.IsActive {
+		return nil, dto.ErrLeaveTypeInactive
+	}
+
+	employee, err := s.employeeRepository.FindByID(ctx, nil, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
 	leave := &entities.Leave{
-		EmployeeID: req.EmployeeID,
-		StartDate:  req.StartDate,
-		EndDate:    req.EndDate,
-		Reason:     req.Reason,
-		Status:     "pending",
+		EmployeeID:  req.EmployeeID,
+		LeaveTypeID: &leaveType.ID,
+		StartDate:   start,
+		EndDate:     end,
+		Unit:        req.Unit,
+		Session:     req.Session,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Reason:      req.Reason,
+		Status:      constants.ENUM_LEAVE_STATUS_PENDING,
+	}
+	if err := applyPart(leaveType, leave); err != nil {
+		return nil, err
+	}
+
+	days, err := s.check(ctx, employee, leaveType, *leave, nil)
+	if err != nil {
+		return nil, err
+	}
+	leave.Days = days
+	leave.Approvals = approvalChain(employee, leaveType, days)
+
+	created, err := s.leaveRepository.Create(leave)
+	if err != nil {
+		return nil, err
 	}
-	return s.leaveRepository.Create(leave)
+
+	s.notifyApprover(ctx, *created, created.Approvals[0], employee)
+	return created, nil
 }
 
-func (s *leaveService) Update(id string, req dto.LeaveUpdateRequest) (*entities.Leave, error) {
+// Update edits a pending leave for the employee who requested it, or for HR
+// signed in as userID. New dates or a new part of the day go through the same
+// checks as a new request and route the leave along a fresh approval chain, so
+// they are only taken until an approver has decided a step.
+func (s *leaveService) Update(ctx context.Context, id string, userID string, req dto.LeaveUpdateRequest) (*entities.Leave, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
 		return nil, errors.New("invalid id")
@@ -68,21 +173,1171 @@ func (s *leaveService) Update(id string, req dto.LeaveUpdateRequest) (*entities.
 	if err != nil {
 		return nil, err
 	}
-
+	mayEdit, err := s.mayEdit(ctx, leave, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mayEdit {
+		return nil, dto.ErrNotLeaveEditor
+	}
+	if leave.Status != constants.ENUM_LEAVE_STATUS_PENDING {
+		return nil, dto.ErrLeaveNotPending
+	}
 	if req.Reason != "" {
 		leave.Reason = req.Reason
 	}
-	if req.Status != "" {
-		leave.Status = req.Status
+	if req.StartDate == nil && req.EndDate == nil && req.Unit == nil &&
+		req.Session == nil && req.StartTime == nil && req.EndTime == nil {
+		return s.leaveRepository.Update(leave)
+	}
+
+	rescheduled := *leave
+	if req.StartDate != nil {
+		rescheduled.StartDate = helpers.DateOf(*req.StartDate)
+	}
+	if req.EndDate != nil {
+		rescheduled.EndDate = helpers.DateOf(*req.EndDate)
+	}
+	if req.Unit != nil {
+		rescheduled.Unit = *req.Unit
+	}
+	if req.Session != nil {
+		rescheduled.Session = req.Session
+	}
+	if req.StartTime != nil {
+		rescheduled.StartTime = req.StartTime
+	}
+	if req.EndTime != nil {
+		rescheduled.EndTime = req.EndTime
+	}
+	if rescheduled.EndDate.Before(rescheduled.StartDate) {
+		return nil, dto.ErrLeaveRange
+	}
+	if leave.LeaveType == nil {
+		return nil, dto.ErrLeaveWithoutType
+	}
+	for _, approval := range leave.Approvals {
+		if approval.Status != constants.ENUM_LEAVE_STEP_WAITING && approval.Status != constants.ENUM_LEAVE_STEP_PENDING {
+			return nil, dto.ErrLeaveInReview
+		}
+	}
+	if err := applyPart(*leave.LeaveType, &rescheduled); err != nil {
+		return nil, err
+	}
+
+	employee, err := s.employeeRepository.FindByID(ctx, nil, leave.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.check(ctx, employee, *leave.LeaveType, rescheduled, leave)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.leaveRepository.Update(leave)
+	rescheduled.Days = days
+	rescheduled.Approvals = approvalChain(employee, *leave.LeaveType, days)
+	updated, err := s.leaveRepository.Reschedule(ctx, &rescheduled)
+	if errors.Is(err, repository.ErrStepDecided) {
+		return nil, dto.ErrLeaveInReview
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyApprover(ctx, *updated, updated.Approvals[0], employee)
+	return updated, nil
 }
 
+// Delete removes a pending leave no approver has decided a step of yet, so no
+// decision is lost with it. Any other leave keeps its history and ends through
+// a withdrawal or a cancellation.
 func (s *leaveService) Delete(id string) error {
 	uid, err := uuid.Parse(id)
 	if err != nil {
 		return errors.New("invalid id")
 	}
-	return s.leaveRepository.Delete(uid)
+
+	leave, err := s.leaveRepository.FindByID(uid)
+	if err != nil {
+		return err
+	}
+	if leave.Status != constants.ENUM_LEAVE_STATUS_PENDING {
+		return dto.ErrLeaveDecided
+	}
+	for _, approval := range leave.Approvals {
+		if approval.Status != constants.ENUM_LEAVE_STEP_WAITING && approval.Status != constants.ENUM_LEAVE_STEP_PENDING {
+			return dto.ErrLeaveDecided
+		}
+	}
+
+	err = s.leaveRepository.Delete(uid)
+	if errors.Is(err, repository.ErrStepDecided) {
+		return dto.ErrLeaveDecided
+	}
+	return err
+}
+
+// Approve decides the current step of a pending leave. The leave moves on to
+// the next step, or ends approved at the last one and takes its days from the
+// balance. An approved half-day or hourly leave reclassifies the attendance
+// already recorded on its day.
+func (s *leaveService) Approve(ctx context.Context, id uuid.UUID, userID string, comment string) (*entities.Leave, error) {
+	return s.decide(ctx, id, userID, comment, true)
+}
+
+// Reject ends a pending leave at its current step and skips the rest of the chain.
+func (s *leaveService) Reject(ctx context.Context, id uuid.UUID, userID string, comment string) (*entities.Leave, error) {
+	return s.decide(ctx, id, userID, comment, false)
+}
+
+// FindPendingApprovals lists the leaves waiting on the logged-in employee, and
+// on HR when they may act for it.
+func (s *leaveService) FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.Leave], error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	actsForHR, err := s.actsForHR(ctx, reviewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leaveRepository.FindPendingForApprover(ctx, nil, filter, reviewer.ID, actsForHR)
+}
+
+// Withdraw takes back a pending leave of the employee signed in as userID. The
+// leave stays on record as withdrawn and the steps still open are skipped.
+func (s *leaveService) Withdraw(ctx context.Context, id uuid.UUID, userID string) (*entities.Leave, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leave, err := s.leaveRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if leave.EmployeeID != employee.ID {
+		return nil, dto.ErrNotLeaveOwner
+	}
+	if leave.Status != constants.ENUM_LEAVE_STATUS_PENDING {
+		return nil, dto.ErrLeaveNotPending
+	}
+
+	withdrawn, err := s.leaveRepository.Withdraw(ctx, leave)
+	if errors.Is(err, repository.ErrStepDecided) {
+		return nil, dto.ErrLeaveNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withdrawn, nil
+}
+
+// RequestCancellation asks the approver of an approved leave of the employee
+// signed in as userID to cancel it from req.FromDate to its end. Only days
+// still ahead are given back: cancelling from the first day cancels the whole
+// leave, a later date keeps the days already taken. The leave's last approver
+// decides, or HR when it approved the leave.
+func (s *leaveService) RequestCancellation(ctx context.Context, id uuid.UUID, userID string, req dto.LeaveCancelRequest) (entities.LeaveCancellation, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+
+	leave, err := s.leaveRepository.FindByID(id)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	if leave.EmployeeID != employee.ID {
+		return entities.LeaveCancellation{}, dto.ErrNotLeaveOwner
+	}
+	if leave.Status != constants.ENUM_LEAVE_STATUS_APPROVED {
+		return entities.LeaveCancellation{}, dto.ErrLeaveNotApproved
+	}
+
+	today := helpers.DateOf(time.Now().In(helpers.LoadTimezone("")))
+	start, end := helpers.DateOf(leave.StartDate), helpers.DateOf(leave.EndDate)
+	if end.Before(today) {
+		return entities.LeaveCancellation{}, dto.ErrCancelPast
+	}
+	from := start
+	if req.FromDate != nil {
+		from = helpers.DateOf(*req.FromDate)
+	} else if from.Before(today) {
+		from = today
+	}
+	if from.Before(start) || from.After(end) {
+		return entities.LeaveCancellation{}, dto.ErrCancelRange
+	}
+	if from.Before(today) {
+		return entities.LeaveCancellation{}, dto.ErrCancelPast
+	}
+
+	days, err := s.cancelledDays(ctx, leave, from)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	var approverID *uuid.UUID
+	if len(leave.Approvals) > 0 {
+		approverID = leave.Approvals[len(leave.Approvals)-1].ApproverID
+	}
+
+	cancellation, err := s.leaveRepository.CreateCancellation(ctx, nil, entities.LeaveCancellation{
+		LeaveID:    leave.ID,
+		FromDate:   from,
+		ToDate:     end,
+		Days:       days,
+		Reason:     req.Reason,
+		ApproverID: approverID,
+		Status:     constants.ENUM_LEAVE_CANCELLATION_PENDING,
+	})
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+
+	cancellation.Leave = leave
+	s.notifyCancellationApprover(ctx, cancellation, employee)
+	return cancellation, nil
+}
+
+// ApproveCancellation agrees to a pending cancellation. The leave is cancelled
+// or shortened, its days go back to the balance and the attendance of those
+// days is classified again without it.
+func (s *leaveService) ApproveCancellation(ctx context.Context, id uuid.UUID, userID string, comment string) (entities.LeaveCancellation, error) {
+	return s.decideCancellation(ctx, id, userID, comment, true)
+}
+
+// RejectCancellation refuses a pending cancellation; the leave stands as approved.
+func (s *leaveService) RejectCancellation(ctx context.Context, id uuid.UUID, userID string, comment string) (entities.LeaveCancellation, error) {
+	return s.decideCancellation(ctx, id, userID, comment, false)
+}
+
+// FindPendingCancellations lists the cancellations waiting on the logged-in
+// employee, and on HR when they may act for it.
+func (s *leaveService) FindPendingCancellations(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.LeaveCancellation], error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	actsForHR, err := s.actsForHR(ctx, reviewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leaveRepository.FindPendingCancellations(ctx, nil, filter, reviewer.ID, actsForHR)
+}
+
+// GetDuration counts the days a leave of the employee signed in as userID
+// would take, the same way Create does.
+func (s *leaveService) GetDuration(ctx context.Context, userID string, req dto.LeaveDurationRequest) (dto.LeaveDurationResponse, error) {
+	start, end := helpers.DateOf(req.StartDate), helpers.DateOf(req.EndDate)
+	if end.Before(start) {
+		return dto.LeaveDurationResponse{}, dto.ErrLeaveRange
+	}
+
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return dto.LeaveDurationResponse{}, err
+	}
+	leaveType, err := s.leaveTypeRepository.GetByID(ctx, nil, uuid.MustParse(req.LeaveTypeID))
+	if err != nil {
+		return dto.LeaveDurationResponse{}, err
+	}
+
+	leave := entities.Leave{
+		StartDate: start,
+		EndDate:   end,
+		Unit:      req.Unit,
+		Session:   optional(req.Session),
+		StartTime: optional(req.StartTime),
+		EndTime:   optional(req.EndTime),
+	}
+	if err := applyPart(leaveType, &leave); err != nil {
+		return dto.LeaveDurationResponse{}, err
+	}
+	_, holidays, days, err := s.takes(ctx, employee, leaveType, leave)
+	if err != nil {
+		return dto.LeaveDurationResponse{}, err
+	}
+
+	response := dto.LeaveDurationResponse{
+		LeaveTypeID:  leaveType.ID,
+		StartDate:    start.Format("2006-01-02"),
+		EndDate:      end.Format("2006-01-02"),
+		Unit:         leave.Unit,
+		WorkWeekDays: helpers.WorkWeekDays(),
+		Days:         days,
+		Holidays:     make([]dto.LeaveHolidayResponse, 0, len(holidays)),
+	}
+	for _, holiday := range holidays {
+		response.Holidays = append(response.Holidays, dto.LeaveHolidayResponse{
+			Date: holiday.Date.Format("2006-01-02"),
+			Name: holiday.Name,
+			Type: holiday.Type,
+		})
+	}
+	return response, nil
+}
+
+func (s *leaveService) FindTypes(ctx context.Context) ([]entities.LeaveType, error) {
+	return s.leaveTypeRepository.FindAll(ctx, nil)
+}
+
+func (s *leaveService) CreateType(ctx context.Context, req dto.LeaveTypeRequest) (entities.LeaveType, error) {
+	leaveType := entities.LeaveType{Paid: true, IsActive: true}
+	if err := applyLeaveType(&leaveType, req); err != nil {
+		return entities.LeaveType{}, err
+	}
+	return s.leaveTypeRepository.Create(ctx, nil, leaveType)
+}
+
+func (s *leaveService) UpdateType(ctx context.Context, id uuid.UUID, req dto.LeaveTypeRequest) (entities.LeaveType, error) {
+	leaveType, err := s.leaveTypeRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.LeaveType{}, err
+	}
+	if err := applyLeaveType(&leaveType, req); err != nil {
+		return entities.LeaveType{}, err
+	}
+	if req.IsActive != nil {
+		leaveType.IsActive = *req.IsActive
+	}
+	return s.leaveTypeRepository.Update(ctx, nil, leaveType)
+}
+
+func (s *leaveService) GetMyBalances(ctx context.Context, userID string, year int) ([]dto.LeaveBalanceResponse, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.balances(ctx, employee, year)
+}
+
+func (s *leaveService) GetEmployeeBalances(ctx context.Context, employeeID uuid.UUID, year int) ([]dto.LeaveBalanceResponse, error) {
+	employee, err := s.employeeRepository.FindByID(ctx, nil, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.balances(ctx, employee, year)
+}
+
+// AdjustBalance sets the HR adjustment of an employee's balance, replacing the
+// previous one.
+func (s *leaveService) AdjustBalance(ctx context.Context, employeeID uuid.UUID, req dto.LeaveBalanceAdjustRequest) (dto.LeaveBalanceResponse, error) {
+	employee, err := s.employeeRepository.FindByID(ctx, nil, employeeID)
+	if err != nil {
+		return dto.LeaveBalanceResponse{}, err
+	}
+	leaveType, err := s.leaveTypeRepository.GetByID(ctx, nil, req.LeaveTypeID)
+	if err != nil {
+		return dto.LeaveBalanceResponse{}, err
+	}
+	if !accrues(leaveType) {
+		return dto.LeaveBalanceResponse{}, dto.ErrLeaveTypeNoBalance
+	}
+
+	balance, err := s.balance(ctx, employee, leaveType, req.Year)
+	if err != nil {
+		return dto.LeaveBalanceResponse{}, err
+	}
+	if err := s.leaveTypeRepository.SetAdjustment(ctx, nil, balance.ID, req.Adjustment); err != nil {
+		return dto.LeaveBalanceResponse{}, err
+	}
+	balance.Adjustment = req.Adjustment
+	return s.balanceResponse(ctx, balance)
+}
+
+// Entitlement is the days of a leave type an employee who joined on joinDate
+// has accrued in year by asOf. Nothing accrues before MinServiceMonths of
+// service. A yearly type grants its days at once, pro-rated over the months
+// left in the year it becomes due when ProRate is set; a monthly type grants a
+// twelfth for every month begun. Days round down to the half day.
+func Entitlement(leaveType entities.LeaveType, joinDate time.Time, year int, asOf time.Time) float64 {
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	asOf = helpers.DateOf(asOf)
+	if asOf.After(yearEnd) {
+		asOf = yearEnd
+	}
+	start := helpers.DateOf(joinDate).AddDate(0, leaveType.MinServiceMonths, 0)
+	if start.Before(yearStart) {
+		start = yearStart
+	}
+	if start.After(asOf) {
+		return 0
+	}
+
+	var days float64
+	switch leaveType.AccrualPolicy {
+	case constants.ENUM_LEAVE_ACCRUAL_YEARLY:
+		days = leaveType.DaysPerYear
+		if leaveType.ProRate && start.After(yearStart) {
+			days = days * float64(13-int(start.Month())) / 12
+		}
+	case constants.ENUM_LEAVE_ACCRUAL_MONTHLY:
+		days = leaveType.DaysPerYear * float64(int(asOf.Month())-int(start.Month())+1) / 12
+	}
+	return math.Floor(days*2) / 2
+}
+
+// decide records the reviewer's decision on the current step of a leave. Only
+// the approver the step names may act, or for the HR step a holder of
+// manage_leave, and never on their own leave.
+func (s *leaveService) decide(ctx context.Context, id uuid.UUID, userID string, comment string, approve bool) (*entities.Leave, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leave, err := s.leaveRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	current := currentStep(leave)
+	if leave.Status != constants.ENUM_LEAVE_STATUS_PENDING || current < 0 {
+		return nil, dto.ErrLeaveNotPending
+	}
+
+	step := &leave.Approvals[current]
+	allowed, err := s.mayDecide(ctx, reviewer, leave, *step)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, dto.ErrNotCurrentApprover
+	}
+
+	now := time.Now()
+	step.DecidedByID = &reviewer.ID
+	step.DecidedAt = &now
+	step.Comment = comment
+
+	var next *entities.LeaveApproval
+	var balanceID uuid.UUID
+	var usedDelta float64
+	if approve {
+		step.Status = constants.ENUM_LEAVE_STEP_APPROVED
+		if current+1 < len(leave.Approvals) {
+			next = &leave.Approvals[current+1]
+			next.Status = constants.ENUM_LEAVE_STEP_PENDING
+		} else {
+			leave.Status = constants.ENUM_LEAVE_STATUS_APPROVED
+			if tracksBalance(leave) {
+				balance, err := s.balance(ctx, leave.Employee, *leave.LeaveType, leave.StartDate.Year())
+				if err != nil {
+					return nil, err
+				}
+				balanceID, usedDelta = balance.ID, leave.Days
+			}
+		}
+	} else {
+		step.Status = constants.ENUM_LEAVE_STEP_REJECTED
+		for i := current + 1; i < len(leave.Approvals); i++ {
+			leave.Approvals[i].Status = constants.ENUM_LEAVE_STEP_SKIPPED
+		}
+		leave.Status = constants.ENUM_LEAVE_STATUS_REJECTED
+	}
+
+	requester := leave.Employee
+	decided := *step
+	decided.DecidedBy = &reviewer
+	updated, err := s.leaveRepository.Decide(ctx, leave, decided, balanceID, usedDelta)
+	if errors.Is(err, repository.ErrBalanceExceeded) {
+		return nil, dto.ErrInsufficientBalance
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if updated.Status == constants.ENUM_LEAVE_STATUS_APPROVED && updated.Partial() {
+		// A check-in made before the approval was held to the whole shift
+		if err := s.attendanceService.Reclassify(ctx, updated.EmployeeID, updated.StartDate, updated.EndDate); err != nil {
+			log.Printf("leave %s: failed to reclassify attendance after approval: %v", updated.ID, err)
+		}
+	}
+
+	s.notifyRequester(ctx, *updated, decided, requester)
+	if next != nil {
+		s.notifyApprover(ctx, *updated, *next, requester)
+	}
+	return updated, nil
+}
+
+// decideCancellation records the reviewer's decision on a cancellation. Only
+// the approver it names may act, or a holder of manage_leave for leave HR
+// approved, and never on their own leave.
+func (s *leaveService) decideCancellation(ctx context.Context, id uuid.UUID, userID string, comment string, approve bool) (entities.LeaveCancellation, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+
+	cancellation, err := s.leaveRepository.FindCancellationByID(ctx, nil, id)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	if cancellation.Status != constants.ENUM_LEAVE_CANCELLATION_PENDING {
+		return entities.LeaveCancellation{}, dto.ErrCancellationNotPending
+	}
+
+	leave := *cancellation.Leave
+	allowed, err := s.mayDecideCancellation(ctx, reviewer, cancellation)
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+	if !allowed {
+		return entities.LeaveCancellation{}, dto.ErrNotCancellationApprover
+	}
+
+	now := time.Now()
+	cancellation.DecidedByID = &reviewer.ID
+	cancellation.DecidedAt = &now
+	cancellation.Comment = comment
+
+	remaining := leave
+	var balanceID uuid.UUID
+	if approve {
+		cancellation.Status = constants.ENUM_LEAVE_CANCELLATION_APPROVED
+		if cancellation.FromDate.After(helpers.DateOf(leave.StartDate)) {
+			remaining.EndDate = cancellation.FromDate.AddDate(0, 0, -1)
+			remaining.Days = leave.Days - cancellation.Days
+		} else {
+			remaining.Status = constants.ENUM_LEAVE_STATUS_CANCELLED
+		}
+		if tracksBalance(&leave) {
+			balance, err := s.balance(ctx, leave.Employee, *leave.LeaveType, leave.StartDate.Year())
+			if err != nil {
+				return entities.LeaveCancellation{}, err
+			}
+			balanceID = balance.ID
+		}
+	} else {
+		cancellation.Status = constants.ENUM_LEAVE_CANCELLATION_REJECTED
+	}
+
+	decided, err := s.leaveRepository.DecideCancellation(ctx, cancellation, remaining, balanceID)
+	if errors.Is(err, repository.ErrStepDecided) {
+		return entities.LeaveCancellation{}, dto.ErrCancellationNotPending
+	}
+	if err != nil {
+		return entities.LeaveCancellation{}, err
+	}
+
+	if approve {
+		// The days are given back already; records left as they were can be
+		// corrected like any other
+		if err := s.attendanceService.Reclassify(ctx, leave.EmployeeID, cancellation.FromDate, cancellation.ToDate); err != nil {
+			log.Printf("leave %s: failed to reclassify attendance after cancellation %s: %v", leave.ID, cancellation.ID, err)
+		}
+	}
+	decided.DecidedBy = &reviewer
+	s.notifyCancellationRequester(ctx, decided, leave.Employee)
+	return decided, nil
+}
+
+func (s *leaveService) mayDecide(ctx context.Context, reviewer entities.Employee, leave *entities.Leave, step entities.LeaveApproval) (bool, error) {
+	if reviewer.ID == leave.EmployeeID {
+		return false, nil
+	}
+	if step.Role == constants.ENUM_LEAVE_APPROVER_HR {
+		return s.actsForHR(ctx, reviewer.UserID)
+	}
+	return step.ApproverID != nil && *step.ApproverID == reviewer.ID, nil
+}
+
+func (s *leaveService) mayDecideCancellation(ctx context.Context, reviewer entities.Employee, cancellation entities.LeaveCancellation) (bool, error) {
+	if reviewer.ID == cancellation.Leave.EmployeeID {
+		return false, nil
+	}
+	if cancellation.ApproverID == nil {
+		return s.actsForHR(ctx, reviewer.UserID)
+	}
+	return *cancellation.ApproverID == reviewer.ID, nil
+}
+
+// mayEdit reports whether the user signed in as userID may edit the leave: the
+// employee who requested it, or a holder of manage_leave.
+func (s *leaveService) mayEdit(ctx context.Context, leave *entities.Leave, userID string) (bool, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return false, nil
+	}
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if err == nil && employee.ID == leave.EmployeeID {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	return s.actsForHR(ctx, uid)
+}
+
+// actsForHR reports whether the user holds manage_leave through a role.
+func (s *leaveService) actsForHR(ctx context.Context, userID uuid.UUID) (bool, error) {
+	roles, err := s.rbacService.GetRolesByUser(ctx, nil, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if permission.Name == constants.PERMISSION_MANAGE_LEAVE {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// notifyApprover tells the employee a step names that a leave waits on them.
+// The HR step names nobody and shows up in the approvals list instead.
+func (s *leaveService) notifyApprover(ctx context.Context, leave entities.Leave, step entities.LeaveApproval, requester entities.Employee) {
+	if step.ApproverID == nil {
+		return
+	}
+	approver, err := s.employeeRepository.FindByID(ctx, nil, *step.ApproverID)
+	if err != nil {
+		log.Printf("leave %s: failed to load approver %s: %v", leave.ID, *step.ApproverID, err)
+		return
+	}
+
+	title := "Leave awaiting your approval"
+	body := fmt.Sprintf("%s requested %s day(s) of leave from %s to %s.",
+		employeeName(requester), formatDays(leave.Days), leave.StartDate.Format(time.DateOnly), leave.EndDate.Format(time.DateOnly))
+	data := map[string]any{"leave_id": leave.ID, "step_id": step.ID, "level": step.Level}
+
+	if _, err := s.notificationService.Notify(ctx, approver.User, constants.ENUM_NOTIFICATION_TYPE_LEAVE, title, body, data); err != nil {
+		log.Printf("leave %s: failed to notify approver %s: %v", leave.ID, approver.ID, err)
+	}
+}
+
+// notifyRequester tells the employee who asked for a leave how a step was decided.
+func (s *leaveService) notifyRequester(ctx context.Context, leave entities.Leave, step entities.LeaveApproval, requester entities.Employee) {
+	var title string
+	switch {
+	case leave.Status == constants.ENUM_LEAVE_STATUS_APPROVED:
+		title = "Leave approved"
+	case leave.Status == constants.ENUM_LEAVE_STATUS_REJECTED:
+		title = "Leave rejected"
+	default:
+		title = "Leave approved at step " + fmt.Sprint(step.Level)
+	}
+	body := fmt.Sprintf("Your leave from %s to %s was %s by %s.",
+		leave.StartDate.Format(time.DateOnly), leave.EndDate.Format(time.DateOnly), step.Status, employeeName(*step.DecidedBy))
+	if step.Comment != "" {
+		body += " Comment: " + step.Comment
+	}
+	data := map[string]any{"leave_id": leave.ID, "step_id": step.ID, "level": step.Level, "status": leave.Status}
+
+	if _, err := s.notificationService.Notify(ctx, requester.User, constants.ENUM_NOTIFICATION_TYPE_LEAVE, title, body, data); err != nil {
+		log.Printf("leave %s: failed to notify employee %s: %v", leave.ID, requester.ID, err)
+	}
+}
+
+// notifyCancellationApprover tells the approver a cancellation names that it
+// waits on them. One for HR names nobody and shows up in the list instead.
+func (s *leaveService) notifyCancellationApprover(ctx context.Context, cancellation entities.LeaveCancellation, requester entities.Employee) {
+	if cancellation.ApproverID == nil {
+		return
+	}
+	approver, err := s.employeeRepository.FindByID(ctx, nil, *cancellation.ApproverID)
+	if err != nil {
+		log.Printf("leave %s: failed to load approver %s: %v", cancellation.LeaveID, *cancellation.ApproverID, err)
+		return
+	}
+
+	title := "Leave cancellation awaiting your approval"
+	body := fmt.Sprintf("%s asked to cancel %s day(s) of leave from %s to %s.",
+		employeeName(requester), formatDays(cancellation.Days), cancellation.FromDate.Format(time.DateOnly), cancellation.ToDate.Format(time.DateOnly))
+	data := map[string]any{"leave_id": cancellation.LeaveID, "cancellation_id": cancellation.ID}
+
+	if _, err := s.notificationService.Notify(ctx, approver.User, constants.ENUM_NOTIFICATION_TYPE_LEAVE, title, body, data); err != nil {
+		log.Printf("leave %s: failed to notify approver %s: %v", cancellation.LeaveID, approver.ID, err)
+	}
+}
+
+// notifyCancellationRequester tells the employee how their cancellation was decided.
+func (s *leaveService) notifyCancellationRequester(ctx context.Context, cancellation entities.LeaveCancellation, requester entities.Employee) {
+	title := "Leave cancellation " + cancellation.Status
+	body := fmt.Sprintf("Your request to cancel your leave from %s to %s was %s by %s.",
+		cancellation.FromDate.Format(time.DateOnly), cancellation.ToDate.Format(time.DateOnly), cancellation.Status, employeeName(*cancellation.DecidedBy))
+	if cancellation.Comment != "" {
+		body += " Comment: " + cancellation.Comment
+	}
+	data := map[string]any{"leave_id": cancellation.LeaveID, "cancellation_id": cancellation.ID, "status": cancellation.Status}
+
+	if _, err := s.notificationService.Notify(ctx, requester.User, constants.ENUM_NOTIFICATION_TYPE_LEAVE, title, body, data); err != nil {
+		log.Printf("leave %s: failed to notify employee %s: %v", cancellation.LeaveID, requester.ID, err)
+	}
+}
+
+func (s *leaveService) employeeByUserID(ctx context.Context, userID string) (entities.Employee, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+	return employee, err
+}
+
+// balance returns the employee's balance of a type for year, bringing its
+// entitlement up to date. Later years open with what is due on January 1.
+func (s *leaveService) balance(ctx context.Context, employee entities.Employee, leaveType entities.LeaveType, year int) (entities.LeaveBalance, error) {
+	balance, err := s.leaveTypeRepository.EnsureBalance(ctx, nil, employee.ID, leaveType.ID, year)
+	if err != nil {
+		return entities.LeaveBalance{}, err
+	}
+
+	asOf := time.Now().In(helpers.LoadTimezone(""))
+	if asOf.Year() < year {
+		asOf = time.Date(year, time.January, 1, 0, 0, 0, 0, asOf.Location())
+	}
+	entitled := Entitlement(leaveType, employee.JoinDate, year, asOf)
+	if entitled != balance.Entitled {
+		if err := s.leaveTypeRepository.SetEntitled(ctx, nil, balance.ID, entitled); err != nil {
+			return entities.LeaveBalance{}, err
+		}
+		balance.Entitled = entitled
+	}
+	balance.LeaveType = leaveType
+	return balance, nil
+}
+
+func (s *leaveService) balances(ctx context.Context, employee entities.Employee, year int) ([]dto.LeaveBalanceResponse, error) {
+	leaveTypes, err := s.leaveTypeRepository.FindAccruing(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.LeaveBalanceResponse, 0, len(leaveTypes))
+	for _, leaveType := range leaveTypes {
+		balance, err := s.balance(ctx, employee, leaveType, year)
+		if err != nil {
+			return nil, err
+		}
+		response, err := s.balanceResponse(ctx, balance)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *leaveService) balanceResponse(ctx context.Context, balance entities.LeaveBalance) (dto.LeaveBalanceResponse, error) {
+	pending, err := s.pendingDays(ctx, balance)
+	if err != nil {
+		return dto.LeaveBalanceResponse{}, err
+	}
+	return dto.LeaveBalanceResponse{
+		ID:            balance.ID,
+		LeaveTypeID:   balance.LeaveTypeID,
+		LeaveTypeCode: balance.LeaveType.Code,
+		LeaveTypeName: balance.LeaveType.Name,
+		Year:          balance.Year,
+		Entitled:      balance.Entitled,
+		Adjustment:    balance.Adjustment,
+		Used:          balance.Used,
+		Pending:       pending,
+		Available:     balance.Remaining() - pending,
+	}, nil
+}
+
+// pendingDays totals the requested but unapproved leave against a balance.
+func (s *leaveService) pendingDays(ctx context.Context, balance entities.LeaveBalance) (float64, error) {
+	return s.leaveRepository.SumDays(ctx, nil, balance.EmployeeID, balance.LeaveTypeID, constants.ENUM_LEAVE_STATUS_PENDING,
+		time.Date(balance.Year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(balance.Year, time.December, 31, 0, 0, 0, 0, time.UTC))
+}
+
+// check validates a leave of the employee and returns the days it takes.
+// current is the leave being rescheduled, which neither conflicts with itself
+// nor counts against its own balance.
+func (s *leaveService) check(ctx context.Context, employee entities.Employee, leaveType entities.LeaveType, leave entities.Leave, current *entities.Leave) (float64, error) {
+	dates, _, days, err := s.takes(ctx, employee, leaveType, leave)
+	if err != nil {
+		return 0, err
+	}
+	if days == 0 {
+		return 0, dto.ErrLeaveNoDays
+	}
+	if leaveType.MaxDaysPerRequest != nil && days > *leaveType.MaxDaysPerRequest {
+		return 0, dto.ErrLeaveExceedsLimit
+	}
+
+	var currentID *uuid.UUID
+	if current != nil {
+		currentID = &current.ID
+	}
+	conflicts, err := s.conflicts(ctx, employee, leave, dates, currentID)
+	if err != nil {
+		return 0, err
+	}
+	if len(conflicts) > 0 {
+		return 0, &dto.LeaveConflictError{Conflicts: conflicts}
+	}
+
+	if accrues(leaveType) {
+		if leave.StartDate.Year() != leave.EndDate.Year() {
+			return 0, dto.ErrLeaveSpansYears
+		}
+		balance, err := s.balance(ctx, employee, leaveType, leave.StartDate.Year())
+		if err != nil {
+			return 0, err
+		}
+		pending, err := s.pendingDays(ctx, balance)
+		if err != nil {
+			return 0, err
+		}
+		if current != nil && current.StartDate.Year() == balance.Year {
+			pending -= current.Days
+		}
+		if days > balance.Remaining()-pending {
+			return 0, dto.ErrInsufficientBalance
+		}
+	}
+	return days, nil
+}
+
+// conflicts lists what a leave of the employee runs into: their other pending
+// or approved leaves, the days they checked in, and the days taken on which
+// their department already has as many people off as it allows. Partial leaves
+// on the same date only overlap when their hours do, a half-day or hourly
+// leave is taken around the attendance of its day, and hours off do not count
+// against department cover.
+func (s *leaveService) conflicts(ctx context.Context, employee entities.Employee, leave entities.Leave, dates []time.Time, excludeID *uuid.UUID) ([]dto.LeaveConflict, error) {
+	var conflicts []dto.LeaveConflict
+	start, end := leave.StartDate, leave.EndDate
+	partial := leave.Partial()
+
+	overlapping, err := s.leaveRepository.FindOverlapping(ctx, nil, employee.ID, start, end, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range overlapping {
+		if partial && other.Partial() {
+			apart, err := s.apart(ctx, employee, leave, other)
+			if err != nil {
+				return nil, err
+			}
+			if apart {
+				continue
+			}
+		}
+		conflicts = append(conflicts, dto.LeaveConflict{
+			Type:      constants.ENUM_LEAVE_CONFLICT_OVERLAP,
+			LeaveID:   &other.ID,
+			Status:    other.Status,
+			StartDate: other.StartDate.Format(time.DateOnly),
+			EndDate:   other.EndDate.Format(time.DateOnly),
+		})
+	}
+
+	attendances, err := s.attendanceRepository.FindInRange(ctx, nil, []uuid.UUID{employee.ID}, start, end, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, attendance := range attendances {
+		if attendance.CheckInTime == nil || partial {
+			// Absences recorded for the day are what a leave may cover
+			continue
+		}
+		conflicts = append(conflicts, dto.LeaveConflict{
+			Type:         constants.ENUM_LEAVE_CONFLICT_ATTENDANCE,
+			Date:         attendance.WorkDate.Format(time.DateOnly),
+			AttendanceID: &attendance.ID,
+			Status:       attendance.Status,
+		})
+	}
+
+	if employee.Department.MaxOnLeave == nil || leave.Unit == constants.ENUM_LEAVE_UNIT_HOURLY {
+		return conflicts, nil
+	}
+	limit := *employee.Department.MaxOnLeave
+	colleagues, err := s.leaveRepository.FindDepartmentLeavesInRange(ctx, nil, employee.DepartmentID, start, end, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, date := range dates {
+		off := map[uuid.UUID]bool{}
+		for _, colleague := range colleagues {
+			if colleague.Unit == constants.ENUM_LEAVE_UNIT_HOURLY {
+				continue
+			}
+			if !date.Before(helpers.DateOf(colleague.StartDate)) && !date.After(helpers.DateOf(colleague.EndDate)) {
+				off[colleague.EmployeeID] = true
+			}
+		}
+		if len(off) >= limit {
+			conflicts = append(conflicts, dto.LeaveConflict{
+				Type:    constants.ENUM_LEAVE_CONFLICT_DEPARTMENT_CAP,
+				Date:    date.Format(time.DateOnly),
+				OnLeave: len(off),
+				Cap:     limit,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// apart reports whether two half-day or hourly leaves of the employee on the
+// same date take separate parts of the day, e.g. a morning and an afternoon.
+func (s *leaveService) apart(ctx context.Context, employee entities.Employee, a, b entities.Leave) (bool, error) {
+	_, shift, err := s.shiftService.ScheduleOn(ctx, employee.ID, a.StartDate)
+	if err != nil {
+		return false, err
+	}
+	aFrom, aTo, err := shiftService.LeaveWindow(a, shift, a.StartDate)
+	if err != nil {
+		return false, err
+	}
+	bFrom, bTo, err := shiftService.LeaveWindow(b, shift, a.StartDate)
+	if err != nil {
+		return false, err
+	}
+	return !aFrom.Before(bTo) || !bFrom.Before(aTo), nil
+}
+
+// takes counts the days a leave takes and lists its dates and the holidays
+// within its range. A half-day or hourly leave takes a share of its date.
+func (s *leaveService) takes(ctx context.Context, employee entities.Employee, leaveType entities.LeaveType, leave entities.Leave) ([]time.Time, []entities.Holiday, float64, error) {
+	dates, holidays, err := s.countDays(ctx, employee, leaveType, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	days := float64(len(dates))
+	if days > 0 && leave.Partial() {
+		if days, err = s.partialDays(ctx, employee, leave); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	return dates, holidays, days, nil
+}
+
+// partialDays is the share of its working day a half-day or hourly leave
+// takes. Hours must fall within the employee's shift and count against its
+// length less the break, or against the statutory working day of the company
+// work week without a shift. Days round to two decimals.
+func (s *leaveService) partialDays(ctx context.Context, employee entities.Employee, leave entities.Leave) (float64, error) {
+	if leave.Unit == constants.ENUM_LEAVE_UNIT_HALF_DAY {
+		return 0.5, nil
+	}
+
+	_, shift, err := s.shiftService.ScheduleOn(ctx, employee.ID, leave.StartDate)
+	if err != nil {
+		return 0, err
+	}
+	from, to, err := shiftService.LeaveWindow(leave, shift, leave.StartDate)
+	if err != nil || !to.After(from) {
+		return 0, dto.ErrLeaveHours
+	}
+	dayStart, dayEnd, err := shiftService.LeaveWindow(entities.Leave{Unit: constants.ENUM_LEAVE_UNIT_FULL_DAY}, shift, leave.StartDate)
+	if err != nil {
+		return 0, err
+	}
+	if from.Before(dayStart) || to.After(dayEnd) {
+		return 0, dto.ErrLeaveOutsideShift
+	}
+
+	dayHours := float64(constants.LEAVE_DAY_HOURS_FIVE_DAY_WEEK)
+	if helpers.WorkWeekDays() == 6 {
+		dayHours = constants.LEAVE_DAY_HOURS_SIX_DAY_WEEK
+	}
+	if shift != nil {
+		dayHours = dayEnd.Sub(dayStart).Hours() - float64(shift.BreakMinutes)/60
+	}
+	hours := to.Sub(from).Hours()
+	if hours >= dayHours {
+		return 0, dto.ErrLeaveHoursTooLong
+	}
+	return math.Round(hours/dayHours*100) / 100, nil
+}
+
+// cancelledDays is the share of a leave cancelled from from to its end: all of
+// it from the first day, otherwise the days it takes from then on, never more
+// than the leave has left. A leave without a type counts calendar days.
+func (s *leaveService) cancelledDays(ctx context.Context, leave *entities.Leave, from time.Time) (float64, error) {
+	if !from.After(helpers.DateOf(leave.StartDate)) {
+		return leave.Days, nil
+	}
+
+	leaveType := entities.LeaveType{CalendarDays: true}
+	if leave.LeaveType != nil {
+		leaveType = *leave.LeaveType
+	}
+	dates, _, err := s.countDays(ctx, leave.Employee, leaveType, from, helpers.DateOf(leave.EndDate))
+	if err != nil {
+		return 0, err
+	}
+	return math.Min(float64(len(dates)), leave.Days), nil
+}
+
+// countDays lists the days a leave takes: every day for calendar day types,
+// otherwise the scheduled working days of the employee, less the holidays that
+// apply to their location. It also returns those holidays.
+func (s *leaveService) countDays(ctx context.Context, employee entities.Employee, leaveType entities.LeaveType, start, end time.Time) ([]time.Time, []entities.Holiday, error) {
+	holidays, err := s.masterRepository.FindHolidaysBetween(ctx, nil, start, end, employee.LocationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	off := map[time.Time]bool{}
+	for _, holiday := range holidays {
+		off[helpers.DateOf(holiday.Date)] = true
+	}
+
+	var dates []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if leaveType.CalendarDays {
+			dates = append(dates, day)
+			continue
+		}
+		if off[day] {
+			continue
+		}
+		working, _, err := s.shiftService.ScheduleOn(ctx, employee.ID, day)
+		if err != nil {
+			return nil, nil, err
+		}
+		if working {
+			dates = append(dates, day)
+		}
+	}
+	return dates, holidays, nil
+}
+
+func applyLeaveType(leaveType *entities.LeaveType, req dto.LeaveTypeRequest) error {
+	if req.AccrualPolicy != constants.ENUM_LEAVE_ACCRUAL_NONE && req.DaysPerYear <= 0 {
+		return dto.ErrLeaveTypeAccrual
+	}
+
+	leaveType.Code = req.Code
+	leaveType.Name = req.Name
+	leaveType.Description = req.Description
+	if req.Paid != nil {
+		leaveType.Paid = *req.Paid
+	}
+	leaveType.CalendarDays = req.CalendarDays
+	leaveType.AccrualPolicy = req.AccrualPolicy
+	leaveType.DaysPerYear = req.DaysPerYear
+	leaveType.MinServiceMonths = req.MinServiceMonths
+	leaveType.ProRate = req.ProRate
+	leaveType.MaxDaysPerRequest = req.MaxDaysPerRequest
+	leaveType.AllowHalfDay = req.AllowHalfDay
+	leaveType.AllowHourly = req.AllowHourly
+	leaveType.HeadApprovalFromDays = req.HeadApprovalFromDays
+	leaveType.HRApprovalFromDays = req.HRApprovalFromDays
+	return nil
+}
+
+// applyPart checks the part of the day a leave takes against its type and
+// clears what does not apply to its unit. A leave without a unit takes whole
+// days; a half-day or hourly one covers a single date.
+func applyPart(leaveType entities.LeaveType, leave *entities.Leave) error {
+	switch leave.Unit {
+	case "", constants.ENUM_LEAVE_UNIT_FULL_DAY:
+		leave.Unit = constants.ENUM_LEAVE_UNIT_FULL_DAY
+		leave.Session, leave.StartTime, leave.EndTime = nil, nil, nil
+		return nil
+	case constants.ENUM_LEAVE_UNIT_HALF_DAY:
+		if !leaveType.AllowHalfDay {
+			return dto.ErrLeaveUnitNotAllowed
+		}
+		if leave.Session == nil {
+			return dto.ErrLeaveSession
+		}
+		leave.StartTime, leave.EndTime = nil, nil
+	case constants.ENUM_LEAVE_UNIT_HOURLY:
+		if !leaveType.AllowHourly {
+			return dto.ErrLeaveUnitNotAllowed
+		}
+		if leave.StartTime == nil || leave.EndTime == nil {
+			return dto.ErrLeaveHours
+		}
+		leave.Session = nil
+	default:
+		return dto.ErrLeaveUnitNotAllowed
+	}
+
+	if !leave.EndDate.Equal(leave.StartDate) {
+		return dto.ErrLeavePartialRange
+	}
+	return nil
+}
+
+// approvalChain routes a leave to the employee's supervisor, then to the
+// department head and HR when the type asks for them at its length. A leave
+// with nobody else to go to goes to HR.
+func approvalChain(employee entities.Employee, leaveType entities.LeaveType, days float64) []entities.LeaveApproval {
+	var chain []entities.LeaveApproval
+	add := func(role string, approverID *uuid.UUID) {
+		chain = append(chain, entities.LeaveApproval{
+			Level:      len(chain) + 1,
+			Role:       role,
+			ApproverID: approverID,
+			Status:     constants.ENUM_LEAVE_STEP_WAITING,
+		})
+	}
+
+	supervisorID := employee.SupervisorID
+	if supervisorID != nil {
+		add(constants.ENUM_LEAVE_APPROVER_SUPERVISOR, supervisorID)
+	}
+	headID := employee.Department.HeadID
+	if headID != nil && *headID != employee.ID && (supervisorID == nil || *supervisorID != *headID) &&
+		reaches(leaveType.HeadApprovalFromDays, days) {
+		add(constants.ENUM_LEAVE_APPROVER_DEPARTMENT_HEAD, headID)
+	}
+	if reaches(leaveType.HRApprovalFromDays, days) || len(chain) == 0 {
+		add(constants.ENUM_LEAVE_APPROVER_HR, nil)
+	}
+
+	chain[0].Status = constants.ENUM_LEAVE_STEP_PENDING
+	return chain
+}
+
+// reaches reports whether days meets a threshold that is set.
+func reaches(threshold *float64, days float64) bool {
+	return threshold != nil && days >= *threshold
+}
+
+// currentStep is the index of the pending approval step, -1 when none is.
+func currentStep(leave *entities.Leave) int {
+	for i, approval := range leave.Approvals {
+		if approval.Status == constants.ENUM_LEAVE_STEP_PENDING {
+			return i
+		}
+	}
+	return -1
+}
+
+// optional is nil for an empty query value.
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func formatDays(days float64) string {
+	return fmt.Sprintf("%g", days)
+}
+
+func employeeName(employee entities.Employee) string {
+	if employee.User.Name != "" {
+		return employee.User.Name
+	}
+	return employee.EmployeeCode
+}
+
+func accrues(leaveType entities.LeaveType) bool {
+	return leaveType.AccrualPolicy == constants.ENUM_LEAVE_ACCRUAL_YEARLY ||
+		leaveType.AccrualPolicy == constants.ENUM_LEAVE_ACCRUAL_MONTHLY
+}
+
+// tracksBalance reports whether a leave draws on a yearly balance.
+func tracksBalance(leave *entities.Leave) bool {
+	return leave.LeaveType != nil && accrues(*leave.LeaveType)
 }
@@ -1,10 +1,1019 @@
 package tests
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/leave/service"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestLeaveuservice (t *testing.T) {
 	assert.True(t, true)
 }
+
+// fakeLeaveRepository fills in the employee and type of the leaves it reads
+// the way the repository preloads them.
+type fakeLeaveRepository struct {
+	repository.LeaveRepository
+	leaves     []*entities.Leave
+	employees  []entities.Employee
+	leaveTypes []entities.LeaveType
+	usedDelta  float64
+	balanceErr error
+
+	cancellations []*entities.LeaveCancellation
+}
+
+func (r *fakeLeaveRepository) Create(leave *entities.Leave) (*entities.Leave, error) {
+	leave.ID = uuid.New()
+	for i := range leave.Approvals {
+		leave.Approvals[i].ID = uuid.New()
+		leave.Approvals[i].LeaveID = leave.ID
+	}
+	r.leaves = append(r.leaves, leave)
+	return leave, nil
+}
+
+func (r *fakeLeaveRepository) FindByID(id uuid.UUID) (*entities.Leave, error) {
+	for _, leave := range r.leaves {
+		if leave.ID != id {
+			continue
+		}
+		for _, employee := range r.employees {
+			if employee.ID == leave.EmployeeID {
+				leave.Employee = employee
+			}
+		}
+		for i, leaveType := range r.leaveTypes {
+			if leave.LeaveTypeID != nil && leaveType.ID == *leave.LeaveTypeID {
+				leave.LeaveType = &r.leaveTypes[i]
+			}
+		}
+		return leave, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLeaveRepository) Update(leave *entities.Leave) (*entities.Leave, error) {
+	return leave, nil
+}
+
+func (r *fakeLeaveRepository) Delete(id uuid.UUID) error {
+	for i, leave := range r.leaves {
+		if leave.ID == id {
+			r.leaves = append(r.leaves[:i], r.leaves[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeLeaveRepository) Reschedule(ctx context.Context, leave *entities.Leave) (*entities.Leave, error) {
+	for i := range leave.Approvals {
+		leave.Approvals[i].ID = uuid.New()
+		leave.Approvals[i].LeaveID = leave.ID
+	}
+	return leave, nil
+}
+
+func (r *fakeLeaveRepository) FindOverlapping(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time, excludeID *uuid.UUID) ([]entities.Leave, error) {
+	var leaves []entities.Leave
+	for _, leave := range r.leaves {
+		if leave.EmployeeID != employeeID || (excludeID != nil && leave.ID == *excludeID) {
+			continue
+		}
+		if overlaps(leave, start, end) {
+			leaves = append(leaves, *leave)
+		}
+	}
+	return leaves, nil
+}
+
+func (r *fakeLeaveRepository) FindDepartmentLeavesInRange(ctx context.Context, db *gorm.DB, departmentID uuid.UUID, start, end time.Time, excludeEmployeeID uuid.UUID) ([]entities.Leave, error) {
+	var leaves []entities.Leave
+	for _, leave := range r.leaves {
+		if leave.EmployeeID == excludeEmployeeID || !overlaps(leave, start, end) {
+			continue
+		}
+		for _, employee := range r.employees {
+			if employee.ID == leave.EmployeeID && employee.DepartmentID == departmentID {
+				leaves = append(leaves, *leave)
+			}
+		}
+	}
+	return leaves, nil
+}
+
+// overlaps reports whether a pending or approved leave covers part of [start, end].
+func overlaps(leave *entities.Leave, start, end time.Time) bool {
+	if leave.Status != constants.ENUM_LEAVE_STATUS_PENDING && leave.Status != constants.ENUM_LEAVE_STATUS_APPROVED {
+		return false
+	}
+	return !leave.StartDate.After(end) && !leave.EndDate.Before(start)
+}
+
+func (r *fakeLeaveRepository) Decide(ctx context.Context, leave *entities.Leave, step entities.LeaveApproval, balanceID uuid.UUID, usedDelta float64) (*entities.Leave, error) {
+	if r.balanceErr != nil && usedDelta != 0 {
+		return nil, r.balanceErr
+	}
+	r.usedDelta += usedDelta
+	return leave, nil
+}
+
+func (r *fakeLeaveRepository) Withdraw(ctx context.Context, leave *entities.Leave) (*entities.Leave, error) {
+	leave.Status = constants.ENUM_LEAVE_STATUS_WITHDRAWN
+	for i, approval := range leave.Approvals {
+		if approval.Status == constants.ENUM_LEAVE_STEP_WAITING || approval.Status == constants.ENUM_LEAVE_STEP_PENDING {
+			leave.Approvals[i].Status = constants.ENUM_LEAVE_STEP_SKIPPED
+		}
+	}
+	return leave, nil
+}
+
+func (r *fakeLeaveRepository) CreateCancellation(ctx context.Context, db *gorm.DB, cancellation entities.LeaveCancellation) (entities.LeaveCancellation, error) {
+	for _, other := range r.cancellations {
+		if other.LeaveID == cancellation.LeaveID && other.Status == constants.ENUM_LEAVE_CANCELLATION_PENDING {
+			return entities.LeaveCancellation{}, repository.ErrCancellationPending
+		}
+	}
+	cancellation.ID = uuid.New()
+	stored := cancellation
+	r.cancellations = append(r.cancellations, &stored)
+	return cancellation, nil
+}
+
+func (r *fakeLeaveRepository) FindCancellationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveCancellation, error) {
+	for _, cancellation := range r.cancellations {
+		if cancellation.ID == id {
+			leave, err := r.FindByID(cancellation.LeaveID)
+			if err != nil {
+				return entities.LeaveCancellation{}, err
+			}
+			found := *cancellation
+			found.Leave = leave
+			return found, nil
+		}
+	}
+	return entities.LeaveCancellation{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLeaveRepository) DecideCancellation(ctx context.Context, cancellation entities.LeaveCancellation, leave entities.Leave, balanceID uuid.UUID) (entities.LeaveCancellation, error) {
+	for _, stored := range r.cancellations {
+		if stored.ID == cancellation.ID {
+			stored.Status = cancellation.Status
+			stored.DecidedByID = cancellation.DecidedByID
+			stored.Comment = cancellation.Comment
+		}
+	}
+	if cancellation.Status == constants.ENUM_LEAVE_CANCELLATION_APPROVED {
+		current, err := r.FindByID(leave.ID)
+		if err != nil {
+			return entities.LeaveCancellation{}, err
+		}
+		current.Status, current.EndDate, current.Days = leave.Status, leave.EndDate, leave.Days
+		if balanceID != uuid.Nil {
+			r.usedDelta -= cancellation.Days
+		}
+	}
+	return r.FindCancellationByID(ctx, nil, cancellation.ID)
+}
+
+func (r *fakeLeaveRepository) SumDays(ctx context.Context, db *gorm.DB, employeeID, leaveTypeID uuid.UUID, status string, start, end time.Time) (float64, error) {
+	var days float64
+	for _, leave := range r.leaves {
+		if leave.EmployeeID == employeeID && leave.LeaveTypeID != nil && *leave.LeaveTypeID == leaveTypeID &&
+			leave.Status == status && !leave.StartDate.Before(start) && !leave.StartDate.After(end) {
+			days += leave.Days
+		}
+	}
+	return days, nil
+}
+
+type fakeLeaveTypeRepository struct {
+	repository.LeaveTypeRepository
+	leaveTypes []entities.LeaveType
+	balances   []*entities.LeaveBalance
+}
+
+func (r *fakeLeaveTypeRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.LeaveType, error) {
+	for _, leaveType := range r.leaveTypes {
+		if leaveType.ID == id {
+			return leaveType, nil
+		}
+	}
+	return entities.LeaveType{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLeaveTypeRepository) EnsureBalance(ctx context.Context, tx *gorm.DB, employeeID, leaveTypeID uuid.UUID, year int) (entities.LeaveBalance, error) {
+	for _, balance := range r.balances {
+		if balance.EmployeeID == employeeID && balance.LeaveTypeID == leaveTypeID && balance.Year == year {
+			return *balance, nil
+		}
+	}
+	balance := &entities.LeaveBalance{ID: uuid.New(), EmployeeID: employeeID, LeaveTypeID: leaveTypeID, Year: year}
+	r.balances = append(r.balances, balance)
+	return *balance, nil
+}
+
+func (r *fakeLeaveTypeRepository) SetEntitled(ctx context.Context, tx *gorm.DB, balanceID uuid.UUID, entitled float64) error {
+	for _, balance := range r.balances {
+		if balance.ID == balanceID {
+			balance.Entitled = entitled
+		}
+	}
+	return nil
+}
+
+type fakeEmployeeRepository struct {
+	employeeRepository.EmployeeRepository
+	employees []entities.Employee
+}
+
+func (r *fakeEmployeeRepository) FindByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Employee, error) {
+	for _, employee := range r.employees {
+		if employee.ID == id {
+			return employee, nil
+		}
+	}
+	return entities.Employee{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeEmployeeRepository) FindByUserID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (entities.Employee, error) {
+	for _, employee := range r.employees {
+		if employee.UserID == userID {
+			return employee, nil
+		}
+	}
+	return entities.Employee{}, gorm.ErrRecordNotFound
+}
+
+type fakeAttendanceRepository struct {
+	attendanceRepository.AttendanceRepository
+	attendances []entities.Attendance
+}
+
+func (r *fakeAttendanceRepository) FindInRange(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time, locationID *uuid.UUID) ([]entities.Attendance, error) {
+	var attendances []entities.Attendance
+	for _, attendance := range r.attendances {
+		for _, id := range employeeIDs {
+			if attendance.EmployeeID == id && !attendance.WorkDate.Before(start) && !attendance.WorkDate.After(end) {
+				attendances = append(attendances, attendance)
+			}
+		}
+	}
+	return attendances, nil
+}
+
+// fakeAttendanceService records the ranges whose attendance it reclassifies.
+type fakeAttendanceService struct {
+	attendanceService.AttendanceService
+	reclassified [][2]time.Time
+}
+
+func (s *fakeAttendanceService) Reclassify(ctx context.Context, employeeID uuid.UUID, start, end time.Time) error {
+	s.reclassified = append(s.reclassified, [2]time.Time{start, end})
+	return nil
+}
+
+type fakeMasterRepository struct {
+	masterRepository.MasterRepository
+	holidays []entities.Holiday
+}
+
+func (r *fakeMasterRepository) FindHolidaysBetween(ctx context.Context, db *gorm.DB, start, end time.Time, locationID *uuid.UUID) ([]entities.Holiday, error) {
+	var holidays []entities.Holiday
+	for _, holiday := range r.holidays {
+		if holiday.Date.Before(start) || holiday.Date.After(end) {
+			continue
+		}
+		if holiday.LocationID != nil && (locationID == nil || *holiday.LocationID != *locationID) {
+			continue
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, nil
+}
+
+// fakeShiftService schedules work from Monday to Friday, on shift when set.
+type fakeShiftService struct {
+	shiftService.ShiftService
+	shift *entities.Shift
+}
+
+func (s *fakeShiftService) ScheduleOn(ctx context.Context, employeeID uuid.UUID, date time.Time) (bool, *entities.Shift, error) {
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday, s.shift, nil
+}
+
+type fakeNotificationService struct {
+	notificationService.NotificationService
+	recipients []uuid.UUID
+	titles     []string
+}
+
+func (s *fakeNotificationService) Notify(ctx context.Context, recipient entities.User, kind, title, body string, data any) (entities.Notification, error) {
+	s.recipients = append(s.recipients, recipient.ID)
+	s.titles = append(s.titles, title)
+	return entities.Notification{UserID: recipient.ID, Type: kind, Title: title, Body: body}, nil
+}
+
+// fakeRbacService grants manage_leave to the users it lists.
+type fakeRbacService struct {
+	rbacService.RbacService
+	leaveManagers []uuid.UUID
+}
+
+func (s *fakeRbacService) GetRolesByUser(ctx context.Context, db *gorm.DB, userID uuid.UUID) ([]entities.Role, error) {
+	for _, manager := range s.leaveManagers {
+		if manager == userID {
+			return []entities.Role{{Name: "HR Manager", Permissions: []entities.Permission{{Name: constants.PERMISSION_MANAGE_LEAVE}}}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func annualLeave() entities.LeaveType {
+	return entities.LeaveType{
+		ID:               uuid.New(),
+		Code:             "annual",
+		Paid:             true,
+		AccrualPolicy:    constants.ENUM_LEAVE_ACCRUAL_YEARLY,
+		DaysPerYear:      12,
+		MinServiceMonths: 12,
+		ProRate:          true,
+		IsActive:         true,
+	}
+}
+
+// nextMonday is a Monday in the coming year, whose balance opens in full on
+// January 1 whatever the date the tests run.
+func nextMonday() time.Time {
+	day := time.Date(time.Now().Year()+1, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+type leaveFixture struct {
+	svc           service.LeaveService
+	leaves        *fakeLeaveRepository
+	leaveTypes    *fakeLeaveTypeRepository
+	notifications *fakeNotificationService
+	rbac          *fakeRbacService
+	master        *fakeMasterRepository
+	attendances   *fakeAttendanceRepository
+	attendanceSvc *fakeAttendanceService
+	shifts        *fakeShiftService
+}
+
+func newLeaveService(leaveType entities.LeaveType, employees ...entities.Employee) leaveFixture {
+	f := leaveFixture{
+		leaves:        &fakeLeaveRepository{employees: employees, leaveTypes: []entities.LeaveType{leaveType}},
+		leaveTypes:    &fakeLeaveTypeRepository{leaveTypes: []entities.LeaveType{leaveType}},
+		notifications: &fakeNotificationService{},
+		rbac:          &fakeRbacService{},
+		master:        &fakeMasterRepository{},
+		attendances:   &fakeAttendanceRepository{},
+		attendanceSvc: &fakeAttendanceService{},
+		shifts:        &fakeShiftService{},
+	}
+	f.svc = service.NewLeaveService(f.leaves, f.leaveTypes, &fakeEmployeeRepository{employees: employees}, f.master, f.attendances, f.attendanceSvc, f.shifts, f.notifications, f.rbac, nil)
+	return f
+}
+
+// employeeWithUser is an employee who can log in, as approvers do.
+func employeeWithUser(name string) entities.Employee {
+	userID := uuid.New()
+	return entities.Employee{
+		ID:       uuid.New(),
+		UserID:   userID,
+		JoinDate: time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC),
+		User:     entities.User{ID: userID, Name: name},
+	}
+}
+
+func TestEntitlement_AnnualAfterTwelveMonths(t *testing.T) {
+	annual := annualLeave()
+	joined := time.Date(2025, time.April, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0.0, service.Entitlement(annual, joined, 2025, time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)),
+		"nothing is due in the first year of service")
+	assert.Equal(t, 0.0, service.Entitlement(annual, joined, 2026, time.Date(2026, time.April, 9, 0, 0, 0, 0, time.UTC)),
+		"nothing is due before 12 months of service")
+	assert.Equal(t, 9.0, service.Entitlement(annual, joined, 2026, time.Date(2026, time.April, 10, 0, 0, 0, 0, time.UTC)),
+		"the first year is pro-rated from April to December")
+	assert.Equal(t, 12.0, service.Entitlement(annual, joined, 2027, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		"later years open in full")
+
+	annual.ProRate = false
+	assert.Equal(t, 12.0, service.Entitlement(annual, joined, 2026, time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestEntitlement_MonthlyAccrual(t *testing.T) {
+	monthly := entities.LeaveType{AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_MONTHLY, DaysPerYear: 12}
+	joined := time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3.0, service.Entitlement(monthly, joined, 2026, time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 12.0, service.Entitlement(monthly, joined, 2025, time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)),
+		"a past year has accrued in full")
+
+	newcomer := time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2.0, service.Entitlement(monthly, newcomer, 2026, time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)))
+
+	monthly.DaysPerYear = 14
+	assert.Equal(t, 3.5, service.Entitlement(monthly, joined, 2026, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)),
+		"days round down to the half day")
+}
+
+func TestLeaveService_Create_RejectsBeyondBalance(t *testing.T) {
+	annual := annualLeave()
+	employee := entities.Employee{ID: uuid.New(), JoinDate: time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)}
+	f := newLeaveService(annual, employee)
+
+	monday := nextMonday()
+	f.leaveTypes.balances = []*entities.LeaveBalance{{ID: uuid.New(), EmployeeID: employee.ID, LeaveTypeID: annual.ID, Year: monday.Year(), Used: 8}}
+	f.leaves.leaves = []*entities.Leave{{
+		ID: uuid.New(), EmployeeID: employee.ID, LeaveTypeID: &annual.ID,
+		StartDate: monday.AddDate(0, 1, 0), Days: 2, Status: constants.ENUM_LEAVE_STATUS_PENDING,
+	}}
+
+	request := dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, Reason: "holiday"}
+
+	// Monday to the next Monday is six working days, two more than the 12
+	// entitled less 8 used and 2 pending
+	request.EndDate = monday.AddDate(0, 0, 7)
+	_, err := f.svc.Create(request)
+	assert.ErrorIs(t, err, dto.ErrInsufficientBalance)
+
+	request.EndDate = monday.AddDate(0, 0, 1)
+	leave, err := f.svc.Create(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, leave.Days)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_PENDING, leave.Status)
+}
+
+func TestLeaveService_Create_CapsPerRequest(t *testing.T) {
+	marriage := entities.LeaveType{ID: uuid.New(), AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_NONE, IsActive: true}
+	limit := 3.0
+	marriage.MaxDaysPerRequest = &limit
+	employee := entities.Employee{ID: uuid.New()}
+	f := newLeaveService(marriage, employee)
+
+	monday := nextMonday()
+	_, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: marriage.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 3)})
+	assert.ErrorIs(t, err, dto.ErrLeaveExceedsLimit)
+
+	// Friday to Tuesday spans a weekend and takes three working days
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: marriage.ID, StartDate: monday.AddDate(0, 0, 4), EndDate: monday.AddDate(0, 0, 8)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, leave.Days)
+}
+
+func TestLeaveService_Create_SkipsHolidaysOfTheLocation(t *testing.T) {
+	marriage := entities.LeaveType{ID: uuid.New(), AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_NONE, IsActive: true}
+	jakarta, bali := uuid.New(), uuid.New()
+	office := entities.Employee{ID: uuid.New(), LocationID: &jakarta}
+	resort := entities.Employee{ID: uuid.New(), LocationID: &bali}
+	f := newLeaveService(marriage, office, resort)
+
+	monday := nextMonday()
+	f.master.holidays = []entities.Holiday{
+		{Date: monday.AddDate(0, 0, 1), Name: "Cuti Bersama", Type: constants.ENUM_HOLIDAY_TYPE_COLLECTIVE},
+		{Date: monday.AddDate(0, 0, 2), Name: "Nyepi", Type: constants.ENUM_HOLIDAY_TYPE_PUBLIC, LocationID: &bali},
+	}
+
+	// Monday to Friday less the company-wide collective leave day
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: office.ID, LeaveTypeID: marriage.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 4)})
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, leave.Days)
+
+	// The regional holiday is only a day off for employees based in Bali
+	leave, err = f.svc.Create(dto.LeaveCreateRequest{EmployeeID: resort.ID, LeaveTypeID: marriage.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 4)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, leave.Days)
+
+	_, err = f.svc.Create(dto.LeaveCreateRequest{EmployeeID: resort.ID, LeaveTypeID: marriage.ID, StartDate: monday.AddDate(0, 0, 1), EndDate: monday.AddDate(0, 0, 2)})
+	assert.ErrorIs(t, err, dto.ErrLeaveNoDays)
+}
+
+func TestLeaveService_GetDuration_ListsHolidays(t *testing.T) {
+	marriage := entities.LeaveType{ID: uuid.New(), AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_NONE, IsActive: true}
+	employee := employeeWithUser("Sari")
+	f := newLeaveService(marriage, employee)
+
+	monday := nextMonday()
+	f.master.holidays = []entities.Holiday{{Date: monday.AddDate(0, 0, 3), Name: "Independence Day", Type: constants.ENUM_HOLIDAY_TYPE_PUBLIC}}
+
+	duration, err := f.svc.GetDuration(context.Background(), employee.UserID.String(), dto.LeaveDurationRequest{
+		LeaveTypeID: marriage.ID.String(), StartDate: monday, EndDate: monday.AddDate(0, 0, 6),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, duration.Days, "the weekend and the holiday are left out")
+	assert.Len(t, duration.Holidays, 1)
+	assert.Equal(t, "Independence Day", duration.Holidays[0].Name)
+	assert.Equal(t, 0, len(f.leaves.leaves), "a preview files nothing")
+
+	_, err = f.svc.GetDuration(context.Background(), employee.UserID.String(), dto.LeaveDurationRequest{
+		LeaveTypeID: marriage.ID.String(), StartDate: monday, EndDate: monday.AddDate(0, 0, -1),
+	})
+	assert.ErrorIs(t, err, dto.ErrLeaveRange)
+}
+
+func TestLeaveService_Create_RoutesApprovalChain(t *testing.T) {
+	annual := annualLeave()
+	five := 5.0
+	annual.HeadApprovalFromDays = &five
+	supervisor := employeeWithUser("Sari")
+	head := employeeWithUser("Budi")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	employee.Department = entities.Department{HeadID: &head.ID}
+	f := newLeaveService(annual, employee, supervisor, head)
+
+	monday := nextMonday()
+	short, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 1)})
+	assert.NoError(t, err)
+	if assert.Len(t, short.Approvals, 1) {
+		assert.Equal(t, constants.ENUM_LEAVE_APPROVER_SUPERVISOR, short.Approvals[0].Role)
+		assert.Equal(t, constants.ENUM_LEAVE_STEP_PENDING, short.Approvals[0].Status)
+	}
+	assert.Equal(t, []uuid.UUID{supervisor.UserID}, f.notifications.recipients, "the supervisor hears of the request")
+
+	long, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday.AddDate(0, 0, 7), EndDate: monday.AddDate(0, 0, 11)})
+	assert.NoError(t, err)
+	if assert.Len(t, long.Approvals, 2, "five days also need the department head") {
+		assert.Equal(t, constants.ENUM_LEAVE_APPROVER_DEPARTMENT_HEAD, long.Approvals[1].Role)
+		assert.Equal(t, head.ID, *long.Approvals[1].ApproverID)
+		assert.Equal(t, constants.ENUM_LEAVE_STEP_WAITING, long.Approvals[1].Status)
+	}
+
+	loner := employeeWithUser("Eka")
+	f = newLeaveService(annual, loner)
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: loner.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday})
+	assert.NoError(t, err)
+	if assert.Len(t, leave.Approvals, 1) {
+		assert.Equal(t, constants.ENUM_LEAVE_APPROVER_HR, leave.Approvals[0].Role, "without a supervisor HR decides")
+		assert.Nil(t, leave.Approvals[0].ApproverID)
+	}
+}
+
+func TestLeaveService_Approve_WalksTheChain(t *testing.T) {
+	annual := annualLeave()
+	zero := 0.0
+	annual.HeadApprovalFromDays = &zero
+	supervisor := employeeWithUser("Sari")
+	head := employeeWithUser("Budi")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	employee.Department = entities.Department{HeadID: &head.ID}
+	f := newLeaveService(annual, employee, supervisor, head)
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 2)})
+	assert.NoError(t, err)
+
+	_, err = f.svc.Approve(context.Background(), leave.ID, head.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotCurrentApprover, "the head waits for the supervisor")
+	_, err = f.svc.Approve(context.Background(), leave.ID, employee.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotCurrentApprover)
+
+	leave, err = f.svc.Approve(context.Background(), leave.ID, supervisor.UserID.String(), "enjoy")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_PENDING, leave.Status)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_APPROVED, leave.Approvals[0].Status)
+	assert.Equal(t, supervisor.ID, *leave.Approvals[0].DecidedByID)
+	assert.Equal(t, "enjoy", leave.Approvals[0].Comment)
+	assert.NotNil(t, leave.Approvals[0].DecidedAt)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_PENDING, leave.Approvals[1].Status)
+	assert.Equal(t, 0.0, f.leaves.usedDelta, "nothing is deducted before the last step")
+
+	leave, err = f.svc.Approve(context.Background(), leave.ID, head.UserID.String(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_APPROVED, leave.Status)
+	assert.Equal(t, 3.0, f.leaves.usedDelta, "the last approval deducts the days")
+
+	_, err = f.svc.Approve(context.Background(), leave.ID, head.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrLeaveNotPending)
+
+	// Supervisor and head on create, then the requester after each decision
+	// with the head told in between
+	assert.Equal(t, []uuid.UUID{supervisor.UserID, employee.UserID, head.UserID, employee.UserID}, f.notifications.recipients)
+	assert.Equal(t, "Leave approved", f.notifications.titles[3])
+}
+
+func TestLeaveService_Reject_SkipsTheRest(t *testing.T) {
+	annual := annualLeave()
+	zero := 0.0
+	annual.HRApprovalFromDays = &zero
+	supervisor := employeeWithUser("Sari")
+	hr := employeeWithUser("Rina")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	f := newLeaveService(annual, employee, supervisor, hr)
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday})
+	assert.NoError(t, err)
+	leave, err = f.svc.Approve(context.Background(), leave.ID, supervisor.UserID.String(), "")
+	assert.NoError(t, err)
+
+	_, err = f.svc.Reject(context.Background(), leave.ID, hr.UserID.String(), "busy season")
+	assert.ErrorIs(t, err, dto.ErrNotCurrentApprover, "the HR step takes manage_leave")
+
+	f.rbac.leaveManagers = []uuid.UUID{hr.UserID}
+	leave, err = f.svc.Reject(context.Background(), leave.ID, hr.UserID.String(), "busy season")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_REJECTED, leave.Status)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_REJECTED, leave.Approvals[1].Status)
+	assert.Equal(t, "busy season", leave.Approvals[1].Comment)
+	assert.Equal(t, 0.0, f.leaves.usedDelta)
+	assert.Equal(t, "Leave rejected", f.notifications.titles[len(f.notifications.titles)-1])
+}
+
+func TestLeaveService_Approve_RefusesOverdrawnBalance(t *testing.T) {
+	annual := annualLeave()
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	f := newLeaveService(annual, employee, supervisor)
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday})
+	assert.NoError(t, err)
+
+	// Another leave was approved from the balance in the meantime
+	f.leaves.balanceErr = repository.ErrBalanceExceeded
+	_, err = f.svc.Approve(context.Background(), leave.ID, supervisor.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrInsufficientBalance)
+}
+
+func TestLeaveService_Update_OnlyEditsPending(t *testing.T) {
+	employee := employeeWithUser("Dewi")
+	f := newLeaveService(annualLeave(), employee)
+	leave := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, Reason: "trip", Status: constants.ENUM_LEAVE_STATUS_PENDING}
+	f.leaves.leaves = []*entities.Leave{leave}
+
+	updated, err := f.svc.Update(context.Background(), leave.ID.String(), employee.UserID.String(), dto.LeaveUpdateRequest{Reason: "family trip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "family trip", updated.Reason)
+
+	leave.Status = constants.ENUM_LEAVE_STATUS_APPROVED
+	_, err = f.svc.Update(context.Background(), leave.ID.String(), employee.UserID.String(), dto.LeaveUpdateRequest{Reason: "again"})
+	assert.ErrorIs(t, err, dto.ErrLeaveNotPending)
+}
+
+func TestLeaveService_Delete_KeepsDecidedHistory(t *testing.T) {
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	annual := annualLeave()
+	f := newLeaveService(annual, employee, supervisor)
+
+	monday := nextMonday()
+	rejected, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday})
+	assert.NoError(t, err)
+	_, err = f.svc.Reject(context.Background(), rejected.ID, supervisor.UserID.String(), "busy week")
+	assert.NoError(t, err)
+
+	inReview := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, Status: constants.ENUM_LEAVE_STATUS_PENDING, Approvals: []entities.LeaveApproval{
+		{Level: 1, Status: constants.ENUM_LEAVE_STEP_APPROVED},
+		{Level: 2, Status: constants.ENUM_LEAVE_STEP_PENDING},
+	}}
+	withdrawn := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, Status: constants.ENUM_LEAVE_STATUS_WITHDRAWN}
+	fresh := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, Status: constants.ENUM_LEAVE_STATUS_PENDING, Approvals: []entities.LeaveApproval{
+		{Level: 1, Status: constants.ENUM_LEAVE_STEP_PENDING},
+	}}
+	f.leaves.leaves = append(f.leaves.leaves, inReview, withdrawn, fresh)
+
+	for _, leave := range []*entities.Leave{rejected, inReview, withdrawn} {
+		assert.ErrorIs(t, f.svc.Delete(leave.ID.String()), dto.ErrLeaveDecided)
+	}
+	kept, err := f.leaves.FindByID(rejected.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_REJECTED, kept.Approvals[0].Status)
+	assert.Equal(t, "busy week", kept.Approvals[0].Comment)
+
+	assert.NoError(t, f.svc.Delete(fresh.ID.String()))
+	assert.Len(t, f.leaves.leaves, 3)
+}
+
+func TestLeaveService_Update_OnlyOwnerOrHR(t *testing.T) {
+	employee := employeeWithUser("Dewi")
+	colleague := employeeWithUser("Budi")
+	hr := employeeWithUser("Rina")
+	f := newLeaveService(annualLeave(), employee, colleague, hr)
+	f.rbac.leaveManagers = []uuid.UUID{hr.UserID}
+	leave := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, Reason: "trip", Status: constants.ENUM_LEAVE_STATUS_PENDING}
+	f.leaves.leaves = []*entities.Leave{leave}
+
+	_, err := f.svc.Update(context.Background(), leave.ID.String(), colleague.UserID.String(), dto.LeaveUpdateRequest{Reason: "moved"})
+	assert.ErrorIs(t, err, dto.ErrNotLeaveEditor)
+	assert.Equal(t, "trip", leave.Reason)
+
+	updated, err := f.svc.Update(context.Background(), leave.ID.String(), hr.UserID.String(), dto.LeaveUpdateRequest{Reason: "family trip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "family trip", updated.Reason)
+}
+func TestLeaveService_Create_ReportsConflicts(t *testing.T) {
+	marriage := entities.LeaveType{ID: uuid.New(), AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_NONE, IsActive: true}
+	cap := 1
+	department := entities.Department{ID: uuid.New(), MaxOnLeave: &cap}
+	employee := entities.Employee{ID: uuid.New(), DepartmentID: department.ID, Department: department}
+	colleague := entities.Employee{ID: uuid.New(), DepartmentID: department.ID, Department: department}
+	f := newLeaveService(marriage, employee, colleague)
+
+	monday := nextMonday()
+	earlier := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, StartDate: monday, EndDate: monday, Days: 1, Status: constants.ENUM_LEAVE_STATUS_APPROVED}
+	f.leaves.leaves = []*entities.Leave{
+		earlier,
+		{ID: uuid.New(), EmployeeID: colleague.ID, StartDate: monday.AddDate(0, 0, 2), EndDate: monday.AddDate(0, 0, 2), Days: 1, Status: constants.ENUM_LEAVE_STATUS_PENDING},
+		{ID: uuid.New(), EmployeeID: colleague.ID, StartDate: monday.AddDate(0, 0, 3), EndDate: monday.AddDate(0, 0, 3), Days: 1, Status: constants.ENUM_LEAVE_STATUS_REJECTED},
+	}
+	checkIn := monday.AddDate(0, 0, 1).Add(8 * time.Hour)
+	f.attendances.attendances = []entities.Attendance{
+		{ID: uuid.New(), EmployeeID: employee.ID, WorkDate: monday.AddDate(0, 0, 1), CheckInTime: &checkIn, Status: constants.ENUM_ATTENDANCE_STATUS_PRESENT},
+		{ID: uuid.New(), EmployeeID: employee.ID, WorkDate: monday.AddDate(0, 0, 4), Status: constants.ENUM_ATTENDANCE_STATUS_ABSENT},
+	}
+
+	_, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: marriage.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 4)})
+	assert.ErrorIs(t, err, dto.ErrLeaveConflict)
+
+	var conflict *dto.LeaveConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []dto.LeaveConflict{
+		{Type: constants.ENUM_LEAVE_CONFLICT_OVERLAP, LeaveID: &earlier.ID, Status: constants.ENUM_LEAVE_STATUS_APPROVED, StartDate: monday.Format(time.DateOnly), EndDate: monday.Format(time.DateOnly)},
+		{Type: constants.ENUM_LEAVE_CONFLICT_ATTENDANCE, Date: monday.AddDate(0, 0, 1).Format(time.DateOnly), AttendanceID: &f.attendances.attendances[0].ID, Status: constants.ENUM_ATTENDANCE_STATUS_PRESENT},
+		{Type: constants.ENUM_LEAVE_CONFLICT_DEPARTMENT_CAP, Date: monday.AddDate(0, 0, 2).Format(time.DateOnly), OnLeave: 1, Cap: 1},
+	}, conflict.Conflicts, "rejected leaves and recorded absences are no conflict")
+
+	// Thursday and Friday are clear
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: marriage.ID, StartDate: monday.AddDate(0, 0, 3), EndDate: monday.AddDate(0, 0, 4)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, leave.Days)
+}
+
+func TestLeaveService_Update_Reschedules(t *testing.T) {
+	annual := annualLeave()
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	f := newLeaveService(annual, employee, supervisor)
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 1), Reason: "trip"})
+	assert.NoError(t, err)
+
+	// Moving over its own dates is no overlap, and its own pending days are
+	// not held against it: ten of the twelve days are fine
+	end := monday.AddDate(0, 0, 11)
+	updated, err := f.svc.Update(context.Background(), leave.ID.String(), employee.UserID.String(), dto.LeaveUpdateRequest{EndDate: &end})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, updated.Days)
+	assert.Equal(t, "trip", updated.Reason)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_PENDING, updated.Approvals[0].Status)
+
+	before := monday.AddDate(0, 0, -1)
+	_, err = f.svc.Update(context.Background(), leave.ID.String(), employee.UserID.String(), dto.LeaveUpdateRequest{EndDate: &before})
+	assert.ErrorIs(t, err, dto.ErrLeaveRange)
+
+	// The supervisor approved and the leave moved on to a later step
+	leave.Approvals[0].Status = constants.ENUM_LEAVE_STEP_APPROVED
+	_, err = f.svc.Update(context.Background(), leave.ID.String(), employee.UserID.String(), dto.LeaveUpdateRequest{EndDate: &monday})
+	assert.ErrorIs(t, err, dto.ErrLeaveInReview)
+}
+
+func session(value string) *string {
+	return &value
+}
+
+func TestLeaveService_Approve_ReclassifiesAPartialLeave(t *testing.T) {
+	annual := annualLeave()
+	annual.AllowHalfDay = true
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	f := newLeaveService(annual, employee, supervisor)
+
+	// Dewi checked in late on Monday and asked for the morning off afterwards
+	monday := nextMonday()
+	morning, err := f.svc.Create(dto.LeaveCreateRequest{
+		EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday, Reason: "errand",
+		Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Session: session(constants.ENUM_LEAVE_SESSION_MORNING),
+	})
+	assert.NoError(t, err)
+	full, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday.AddDate(0, 0, 1), EndDate: monday.AddDate(0, 0, 1)})
+	assert.NoError(t, err)
+
+	_, err = f.svc.Approve(context.Background(), morning.ID, supervisor.UserID.String(), "")
+	assert.NoError(t, err)
+	_, err = f.svc.Approve(context.Background(), full.ID, supervisor.UserID.String(), "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, [][2]time.Time{{monday, monday}}, f.attendanceSvc.reclassified, "a full day needs no new classification")
+}
+
+func TestLeaveService_Create_HalfDays(t *testing.T) {
+	annual := annualLeave()
+	annual.AllowHalfDay = true
+	employee := entities.Employee{ID: uuid.New(), JoinDate: time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)}
+	f := newLeaveService(annual, employee)
+
+	monday := nextMonday()
+	f.leaveTypes.balances = []*entities.LeaveBalance{{ID: uuid.New(), EmployeeID: employee.ID, LeaveTypeID: annual.ID, Year: monday.Year(), Used: 11}}
+	request := dto.LeaveCreateRequest{
+		EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday, Reason: "errand",
+		Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Session: session(constants.ENUM_LEAVE_SESSION_MORNING),
+	}
+
+	morning, err := f.svc.Create(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, morning.Days)
+	assert.Equal(t, constants.ENUM_LEAVE_UNIT_HALF_DAY, morning.Unit)
+
+	// The afternoon of the same day is free and takes the last half day left
+	request.Session = session(constants.ENUM_LEAVE_SESSION_AFTERNOON)
+	afternoon, err := f.svc.Create(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, afternoon.Days)
+
+	_, err = f.svc.Create(request)
+	assert.ErrorIs(t, err, dto.ErrLeaveConflict, "the afternoon is taken")
+
+	request.EndDate = monday.AddDate(0, 0, 1)
+	_, err = f.svc.Create(request)
+	assert.ErrorIs(t, err, dto.ErrLeavePartialRange)
+
+	request.EndDate = monday
+	request.Session = nil
+	_, err = f.svc.Create(request)
+	assert.ErrorIs(t, err, dto.ErrLeaveSession)
+
+	request.Unit = constants.ENUM_LEAVE_UNIT_HOURLY
+	_, err = f.svc.Create(request)
+	assert.ErrorIs(t, err, dto.ErrLeaveUnitNotAllowed, "annual leave is not taken by the hour")
+}
+
+func TestLeaveService_Create_HourlyCountsAgainstTheShift(t *testing.T) {
+	permission := entities.LeaveType{ID: uuid.New(), AccrualPolicy: constants.ENUM_LEAVE_ACCRUAL_NONE, AllowHourly: true, IsActive: true}
+	employee := entities.Employee{ID: uuid.New()}
+	f := newLeaveService(permission, employee)
+
+	tuesday := nextMonday().AddDate(0, 0, 1)
+	hours := func(start, end string) dto.LeaveCreateRequest {
+		return dto.LeaveCreateRequest{
+			EmployeeID: employee.ID, LeaveTypeID: permission.ID, StartDate: tuesday, EndDate: tuesday, Reason: "doctor",
+			Unit: constants.ENUM_LEAVE_UNIT_HOURLY, StartTime: &start, EndTime: &end,
+		}
+	}
+
+	// Without a shift two hours are a quarter of the 8-hour statutory day
+	leave, err := f.svc.Create(hours("09:00", "11:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, leave.Days)
+	assert.Nil(t, leave.Session)
+
+	f.leaves.leaves = nil
+	f.shifts.shift = &entities.Shift{StartTime: "07:00", EndTime: "15:00", BreakMinutes: 60}
+
+	// Seven working hours a day, less the break
+	leave, err = f.svc.Create(hours("13:30", "15:00"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.21, leave.Days)
+
+	_, err = f.svc.Create(hours("14:00", "16:00"))
+	assert.ErrorIs(t, err, dto.ErrLeaveOutsideShift)
+
+	_, err = f.svc.Create(hours("07:00", "14:00"))
+	assert.ErrorIs(t, err, dto.ErrLeaveHoursTooLong)
+
+	_, err = f.svc.Create(hours("10:00", "10:00"))
+	assert.ErrorIs(t, err, dto.ErrLeaveHours)
+
+	_, err = f.svc.Create(hours("12:00", "14:00"))
+	assert.ErrorIs(t, err, dto.ErrLeaveConflict, "overlaps the hours taken in the afternoon")
+}
+
+func TestLeaveService_Withdraw_KeepsThePendingLeave(t *testing.T) {
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	annual := annualLeave()
+	f := newLeaveService(annual, employee, supervisor)
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday})
+	assert.NoError(t, err)
+
+	_, err = f.svc.Withdraw(context.Background(), leave.ID, supervisor.UserID.String())
+	assert.ErrorIs(t, err, dto.ErrNotLeaveOwner)
+
+	withdrawn, err := f.svc.Withdraw(context.Background(), leave.ID, employee.UserID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_WITHDRAWN, withdrawn.Status)
+	assert.Equal(t, constants.ENUM_LEAVE_STEP_SKIPPED, withdrawn.Approvals[0].Status)
+
+	_, err = f.svc.Withdraw(context.Background(), leave.ID, employee.UserID.String())
+	assert.ErrorIs(t, err, dto.ErrLeaveNotPending)
+	_, err = f.svc.Approve(context.Background(), leave.ID, supervisor.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrLeaveNotPending)
+}
+
+func TestLeaveService_Cancel_GivesBackTheDaysAhead(t *testing.T) {
+	supervisor := employeeWithUser("Sari")
+	employee := employeeWithUser("Dewi")
+	employee.SupervisorID = &supervisor.ID
+	annual := annualLeave()
+	f := newLeaveService(annual, employee, supervisor)
+
+	monday := nextMonday()
+	friday := monday.AddDate(0, 0, 4)
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: friday})
+	assert.NoError(t, err)
+
+	wednesday := monday.AddDate(0, 0, 2)
+	request := dto.LeaveCancelRequest{FromDate: &wednesday, Reason: "project moved up"}
+	_, err = f.svc.RequestCancellation(context.Background(), leave.ID, employee.UserID.String(), request)
+	assert.ErrorIs(t, err, dto.ErrLeaveNotApproved)
+
+	_, err = f.svc.Approve(context.Background(), leave.ID, supervisor.UserID.String(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, f.leaves.usedDelta)
+
+	_, err = f.svc.RequestCancellation(context.Background(), leave.ID, supervisor.UserID.String(), request)
+	assert.ErrorIs(t, err, dto.ErrNotLeaveOwner)
+	sunday := friday.AddDate(0, 0, 2)
+	_, err = f.svc.RequestCancellation(context.Background(), leave.ID, employee.UserID.String(), dto.LeaveCancelRequest{FromDate: &sunday, Reason: "x"})
+	assert.ErrorIs(t, err, dto.ErrCancelRange)
+
+	cancellation, err := f.svc.RequestCancellation(context.Background(), leave.ID, employee.UserID.String(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, cancellation.Days)
+	assert.Equal(t, supervisor.ID, *cancellation.ApproverID, "the last approver decides")
+	assert.Equal(t, "Leave cancellation awaiting your approval", f.notifications.titles[len(f.notifications.titles)-1])
+
+	_, err = f.svc.RequestCancellation(context.Background(), leave.ID, employee.UserID.String(), request)
+	assert.ErrorIs(t, err, repository.ErrCancellationPending)
+
+	_, err = f.svc.ApproveCancellation(context.Background(), cancellation.ID, employee.UserID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotCancellationApprover)
+
+	decided, err := f.svc.ApproveCancellation(context.Background(), cancellation.ID, supervisor.UserID.String(), "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_CANCELLATION_APPROVED, decided.Status)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_APPROVED, leave.Status, "the days already taken stay approved")
+	assert.Equal(t, monday.AddDate(0, 0, 1), leave.EndDate)
+	assert.Equal(t, 2.0, leave.Days)
+	assert.Equal(t, 2.0, f.leaves.usedDelta, "three days went back to the balance")
+	assert.Equal(t, [][2]time.Time{{wednesday, friday}}, f.attendanceSvc.reclassified)
+	assert.Equal(t, "Leave cancellation approved", f.notifications.titles[len(f.notifications.titles)-1])
+
+	_, err = f.svc.RejectCancellation(context.Background(), cancellation.ID, supervisor.UserID.String(), "too late")
+	assert.ErrorIs(t, err, dto.ErrCancellationNotPending)
+}
+
+func TestLeaveService_Cancel_WholeLeaveGoesToHR(t *testing.T) {
+	hr := employeeWithUser("Rina")
+	employee := employeeWithUser("Dewi")
+	annual := annualLeave()
+	f := newLeaveService(annual, employee, hr)
+	f.rbac.leaveManagers = []uuid.UUID{hr.UserID}
+
+	monday := nextMonday()
+	leave, err := f.svc.Create(dto.LeaveCreateRequest{EmployeeID: employee.ID, LeaveTypeID: annual.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 1)})
+	assert.NoError(t, err)
+	_, err = f.svc.Approve(context.Background(), leave.ID, hr.UserID.String(), "")
+	assert.NoError(t, err)
+
+	// Without a date the cancellation starts from the first day still ahead
+	cancellation, err := f.svc.RequestCancellation(context.Background(), leave.ID, employee.UserID.String(), dto.LeaveCancelRequest{Reason: "plans changed"})
+	assert.NoError(t, err)
+	assert.Equal(t, monday, cancellation.FromDate)
+	assert.Nil(t, cancellation.ApproverID)
+
+	_, err = f.svc.ApproveCancellation(context.Background(), cancellation.ID, hr.UserID.String(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_LEAVE_STATUS_CANCELLED, leave.Status)
+	assert.Equal(t, 0.0, f.leaves.usedDelta)
+
+	// Days already taken cannot be given back
+	past := time.Now().AddDate(0, 0, -7)
+	taken := &entities.Leave{ID: uuid.New(), EmployeeID: employee.ID, StartDate: past, EndDate: past.AddDate(0, 0, 1), Status: constants.ENUM_LEAVE_STATUS_APPROVED}
+	f.leaves.leaves = append(f.leaves.leaves, taken)
+	_, err = f.svc.RequestCancellation(context.Background(), taken.ID, employee.UserID.String(), dto.LeaveCancelRequest{Reason: "late"})
+	assert.ErrorIs(t, err, dto.ErrCancelPast)
+}
@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/master/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/master/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/master/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -28,6 +31,7 @@ type (
 		GetLocationByID(ctx *gin.Context)
 		UpdateLocation(ctx *gin.Context)
 		DeleteLocation(ctx *gin.Context)
+		GetKioskCode(ctx *gin.Context)
 
 		// Positions
 		CreatePosition(ctx *gin.Context)
@@ -35,6 +39,14 @@ type (
 		GetPositionByID(ctx *gin.Context)
 		UpdatePosition(ctx *gin.Context)
 		DeletePosition(ctx *gin.Context)
+
+		// Holidays
+		CreateHoliday(ctx *gin.Context)
+		GetHolidays(ctx *gin.Context)
+		GetHolidayByID(ctx *gin.Context)
+		UpdateHoliday(ctx *gin.Context)
+		DeleteHoliday(ctx *gin.Context)
+		ImportHolidays(ctx *gin.Context)
 	}
 
 	masterController struct {
@@ -69,6 +81,8 @@ func (c *masterController) CreateDepartment(ctx *gin.Context) {
 	deptModel := entities.Department{
 		Name:        req.Name,
 		Description: req.Description,
+		HeadID:      req.HeadID,
+		MaxOnLeave:  req.MaxOnLeave,
 	}
 
 	result, err := c.masterService.CreateDepartment(ctx.Request.Context(), nil, deptModel)
@@ -141,6 +155,8 @@ func (c *masterController) UpdateDepartment(ctx *gin.Context) {
 		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
+		HeadID:      req.HeadID,
+		MaxOnLeave:  req.MaxOnLeave,
 	}
 
 	result, err := c.masterService.UpdateDepartment(ctx.Request.Context(), nil, deptModel)
@@ -188,10 +204,15 @@ func (c *masterController) CreateLocation(ctx *gin.Context) {
 	}
 
 	locModel := entities.Location{
-		Name:         req.Name,
-		Latitude:     req.Latitude,
-		Longitude:    req.Longitude,
-		RadiusMeters: req.RadiusMeters,
+		Name:          req.Name,
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
+		RadiusMeters:  req.RadiusMeters,
+		Polygon:       req.Polygon,
+		Timezone:      req.Timezone,
+		IsActive:      true,
+		RequireSelfie: req.RequireSelfie,
+		KioskEnabled:  req.KioskEnabled,
 	}
 	result, err := c.masterService.CreateLocation(ctx.Request.Context(), nil, locModel)
 	if err != nil {
@@ -238,6 +259,32 @@ func (c *masterController) GetLocationByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// GetKioskCode returns the rotating check-in code the kiosk tablet of a
+// location renders as a QR code.
+func (c *masterController) GetKioskCode(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.masterService.KioskCode(ctx.Request.Context(), id, time.Now())
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrKioskDisabled) {
+			status = http.StatusConflict
+		}
+		res := utils.BuildResponseFailed("failed get kiosk code", err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
 func (c *masterController) UpdateLocation(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
@@ -265,6 +312,8 @@ func (c *masterController) UpdateLocation(ctx *gin.Context) {
 		Latitude:     req.Latitude,
 		Longitude:    req.Longitude,
 		RadiusMeters: req.RadiusMeters,
+		Polygon:      req.Polygon,
+		Timezone:     req.Timezone,
 	}
 	result, err := c.masterService.UpdateLocation(ctx.Request.Context(), nil, locModel)
 	if err != nil {
@@ -273,6 +322,33 @@ func (c *masterController) UpdateLocation(ctx *gin.Context) {
 		return
 	}
 
+	if req.IsActive != nil {
+		if err := c.masterService.SetLocationActive(ctx.Request.Context(), nil, id, *req.IsActive); err != nil {
+			res := utils.BuildResponseFailed("failed update location", err.Error(), nil)
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+		result.IsActive = *req.IsActive
+	}
+
+	if req.RequireSelfie != nil {
+		if err := c.masterService.SetLocationSelfieRequired(ctx.Request.Context(), nil, id, *req.RequireSelfie); err != nil {
+			res := utils.BuildResponseFailed("failed update location", err.Error(), nil)
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+		result.RequireSelfie = *req.RequireSelfie
+	}
+
+	if req.KioskEnabled != nil {
+		if err := c.masterService.SetLocationKiosk(ctx.Request.Context(), nil, id, *req.KioskEnabled); err != nil {
+			res := utils.BuildResponseFailed("failed update location", err.Error(), nil)
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+		result.KioskEnabled = *req.KioskEnabled
+	}
+
 	res := utils.BuildResponseSuccess("success update location", result)
 	ctx.JSON(http.StatusOK, res)
 }
@@ -417,3 +493,172 @@ func (c *masterController) DeletePosition(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess("success delete position", nil)
 	ctx.JSON(http.StatusOK, res)
 }
+
+// Holidays
+func (c *masterController) CreateHoliday(ctx *gin.Context) {
+	var req dto.HolidayCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.masterValidation.ValidateHolidayCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	date, _ := time.Parse("2006-01-02", req.Date)
+	holidayModel := entities.Holiday{
+		Date:        date,
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		LocationID:  req.LocationID,
+	}
+	if holidayModel.Type == "" {
+		holidayModel.Type = constants.ENUM_HOLIDAY_TYPE_PUBLIC
+	}
+	result, err := c.masterService.CreateHoliday(ctx.Request.Context(), nil, holidayModel)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed create holiday", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success create holiday", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *masterController) GetHolidays(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.masterService.FindHolidays(ctx.Request.Context(), nil, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get holidays", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *masterController) GetHolidayByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.masterService.GetHolidayByID(ctx.Request.Context(), nil, id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get holiday", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *masterController) UpdateHoliday(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.HolidayUpdateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.masterValidation.ValidateHolidayUpdateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	holidayModel := entities.Holiday{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+	}
+	if req.Date != "" {
+		holidayModel.Date, _ = time.Parse("2006-01-02", req.Date)
+	}
+	result, err := c.masterService.UpdateHoliday(ctx.Request.Context(), nil, holidayModel)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed update holiday", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success update holiday", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *masterController) DeleteHoliday(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.masterService.DeleteHoliday(ctx.Request.Context(), nil, id); err != nil {
+		res := utils.BuildResponseFailed("failed delete holiday", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success delete holiday", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *masterController) ImportHolidays(ctx *gin.Context) {
+	var req dto.HolidayImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, dto.ErrHolidayFile.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		res := utils.BuildResponseFailed("failed import holidays", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	defer file.Close()
+
+	var locationID *uuid.UUID
+	if req.LocationID != "" {
+		id := uuid.MustParse(req.LocationID)
+		locationID = &id
+	}
+
+	result, err := c.masterService.ImportHolidays(ctx.Request.Context(), file, locationID, req.Type)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed import holidays", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success import holidays", result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,8 +1,22 @@
 package dto
 
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
 const (
 	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
-	MESSAGE_SUCCESS_GET_DATA         = "success get data"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrKioskDisabled    = errors.New("kiosk mode is not enabled for this location")
+	ErrHolidayFile      = errors.New("an iCalendar file is required in the file field")
+	ErrHolidayFileEmpty = errors.New("the iCalendar file has no events")
 )
 
 type (
@@ -15,13 +29,17 @@ type (
 
 // Department DTOs
 type DepartmentCreateRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	HeadID      *uuid.UUID `json:"head_id"`
+	MaxOnLeave  *int       `json:"max_on_leave" binding:"omitempty,gte=1"`
 }
 
 type DepartmentUpdateRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	HeadID      *uuid.UUID `json:"head_id"`
+	MaxOnLeave  *int       `json:"max_on_leave" binding:"omitempty,gte=1"`
 }
 
 type DepartmentResponse struct {
@@ -32,17 +50,26 @@ type DepartmentResponse struct {
 
 // Location DTOs
 type LocationCreateRequest struct {
-	Name         string  `json:"name" binding:"required"`
-	Latitude     float64 `json:"latitude"`
-	Longitude    float64 `json:"longitude"`
-	RadiusMeters int     `json:"radius_meters"`
+	Name          string         `json:"name" binding:"required"`
+	Latitude      float64        `json:"latitude"`
+	Longitude     float64        `json:"longitude"`
+	RadiusMeters  int            `json:"radius_meters"`
+	Polygon       datatypes.JSON `json:"polygon"`
+	Timezone      string         `json:"timezone"`
+	RequireSelfie bool           `json:"require_selfie"`
+	KioskEnabled  bool           `json:"kiosk_enabled"`
 }
 
 type LocationUpdateRequest struct {
-	Name         string  `json:"name"`
-	Latitude     float64 `json:"latitude"`
-	Longitude    float64 `json:"longitude"`
-	RadiusMeters int     `json:"radius_meters"`
+	Name          string         `json:"name"`
+	Latitude      float64        `json:"latitude"`
+	Longitude     float64        `json:"longitude"`
+	RadiusMeters  int            `json:"radius_meters"`
+	Polygon       datatypes.JSON `json:"polygon"`
+	Timezone      string         `json:"timezone"`
+	IsActive      *bool          `json:"is_active"`
+	RequireSelfie *bool          `json:"require_selfie"`
+	KioskEnabled  *bool          `json:"kiosk_enabled"`
 }
 
 type LocationResponse struct {
@@ -53,6 +80,16 @@ type LocationResponse struct {
 	RadiusMeters int     `json:"radius_meters"`
 }
 
+// KioskCodeResponse is what the kiosk tablet of a location shows. Payload is
+// the QR content; the app sends its location_id and kiosk_code on check-in.
+type KioskCodeResponse struct {
+	LocationID    string    `json:"location_id"`
+	Code          string    `json:"code"`
+	Payload       string    `json:"payload"`
+	PeriodSeconds int       `json:"period_seconds"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
 // Position DTOs
 type PositionCreateRequest struct {
 	DepartmentID string `json:"department_id" binding:"required"`
@@ -72,3 +109,36 @@ type PositionResponse struct {
 	Name         string `json:"name"`
 	Level        string `json:"level"`
 }
+
+// Holiday DTOs
+type HolidayCreateRequest struct {
+	Date        string     `json:"date" binding:"required"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Type        string     `json:"type" binding:"omitempty,oneof=public collective_leave"`
+	LocationID  *uuid.UUID `json:"location_id"`
+}
+
+type HolidayUpdateRequest struct {
+	Date        string `json:"date"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type" binding:"omitempty,oneof=public collective_leave"`
+}
+
+// HolidayImportRequest scopes the events of an iCalendar file to a location,
+// or to the whole company without one. Without a type, events whose summary
+// names cuti bersama are taken as collective leave and the rest as public
+// holidays.
+type HolidayImportRequest struct {
+	LocationID string `form:"location_id" binding:"omitempty,uuid"`
+	Type       string `form:"type" binding:"omitempty,oneof=public collective_leave"`
+}
+
+// HolidayImportResult counts the calendar days an import wrote. An event
+// spanning several days counts once per day.
+type HolidayImportResult struct {
+	Events  int `json:"events"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
@@ -21,7 +23,11 @@ type MasterRepository interface {
 	CreateLocation(ctx context.Context, tx *gorm.DB, loc entities.Location) (entities.Location, error)
 	FindLocations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Location], error)
 	GetLocationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Location, error)
+	GetRemoteLocation(ctx context.Context, db *gorm.DB) (entities.Location, error)
 	UpdateLocation(ctx context.Context, tx *gorm.DB, loc entities.Location) (entities.Location, error)
+	SetLocationActive(ctx context.Context, tx *gorm.DB, id uuid.UUID, active bool) error
+	SetLocationSelfieRequired(ctx context.Context, tx *gorm.DB, id uuid.UUID, required bool) error
+	SetLocationKiosk(ctx context.Context, tx *gorm.DB, id uuid.UUID, enabled bool, secret string) error
 	DeleteLocation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
 
 	// Positions
@@ -30,6 +36,16 @@ type MasterRepository interface {
 	GetPositionByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Position, error)
 	UpdatePosition(ctx context.Context, tx *gorm.DB, p entities.Position) (entities.Position, error)
 	DeletePosition(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Holidays
+	CreateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error)
+	FindHolidays(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Holiday], error)
+	GetHolidayByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Holiday, error)
+	UpdateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error)
+	DeleteHoliday(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+	IsHoliday(ctx context.Context, db *gorm.DB, date time.Time, locationID *uuid.UUID) (bool, error)
+	FindHolidaysBetween(ctx context.Context, db *gorm.DB, start, end time.Time, locationID *uuid.UUID) ([]entities.Holiday, error)
+	UpsertHolidays(ctx context.Context, holidays []entities.Holiday) (created int, updated int, err error)
 }
 
 type masterRepository struct {
@@ -140,6 +156,19 @@ func (r *masterRepository) GetLocationByID(ctx context.Context, db *gorm.DB, id
 	return l, nil
 }
 
+// GetRemoteLocation returns the virtual location remote work days are
+// checked in at, seeded by the remote work migration.
+func (r *masterRepository) GetRemoteLocation(ctx context.Context, db *gorm.DB) (entities.Location, error) {
+	if db == nil {
+		db = r.db
+	}
+	var l entities.Location
+	if err := db.WithContext(ctx).Where("is_remote").First(&l).Error; err != nil {
+		return entities.Location{}, err
+	}
+	return l, nil
+}
+
 func (r *masterRepository) UpdateLocation(ctx context.Context, tx *gorm.DB, loc entities.Location) (entities.Location, error) {
 	if tx == nil {
 		tx = r.db
@@ -150,6 +179,43 @@ func (r *masterRepository) UpdateLocation(ctx context.Context, tx *gorm.DB, loc
 	return loc, nil
 }
 
+// SetLocationActive is separate from UpdateLocation because Updates skips false booleans.
+func (r *masterRepository) SetLocationActive(ctx context.Context, tx *gorm.DB, id uuid.UUID, active bool) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Model(&entities.Location{}).Where("id = ?", id).Update("is_active", active).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetLocationSelfieRequired is separate from UpdateLocation for the same reason as SetLocationActive.
+func (r *masterRepository) SetLocationSelfieRequired(ctx context.Context, tx *gorm.DB, id uuid.UUID, required bool) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Model(&entities.Location{}).Where("id = ?", id).Update("require_selfie", required).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetLocationKiosk switches kiosk mode, replacing the secret when one is given.
+func (r *masterRepository) SetLocationKiosk(ctx context.Context, tx *gorm.DB, id uuid.UUID, enabled bool, secret string) error {
+	if tx == nil {
+		tx = r.db
+	}
+	values := map[string]any{"kiosk_enabled": enabled}
+	if secret != "" {
+		values["kiosk_secret"] = secret
+	}
+	if err := tx.WithContext(ctx).Model(&entities.Location{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *masterRepository) DeleteLocation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
 	if tx == nil {
 		tx = r.db
@@ -218,3 +284,140 @@ func (r *masterRepository) DeletePosition(ctx context.Context, tx *gorm.DB, id u
 	}
 	return nil
 }
+
+// Holidays
+func (r *masterRepository) CreateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&h).Error; err != nil {
+		return entities.Holiday{}, err
+	}
+	return h, nil
+}
+
+func (r *masterRepository) FindHolidays(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Holiday], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.Holiday
+	var page pagination.Page[entities.Holiday]
+	paginator, err := pagination.NewPaginator(db.WithContext(ctx).Model(&entities.Holiday{}).Order("date DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *masterRepository) GetHolidayByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Holiday, error) {
+	if db == nil {
+		db = r.db
+	}
+	var h entities.Holiday
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&h).Error; err != nil {
+		return entities.Holiday{}, err
+	}
+	return h, nil
+}
+
+func (r *masterRepository) UpdateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Model(&entities.Holiday{}).Where("id = ?", h.ID).Updates(&h).Error; err != nil {
+		return entities.Holiday{}, err
+	}
+	return h, nil
+}
+
+func (r *masterRepository) DeleteHoliday(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.Holiday{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// holidaysFor narrows holidays to the days off of employees based at the
+// location: the company-wide ones and, with a location, its regional ones.
+func holidaysFor(db *gorm.DB, locationID *uuid.UUID) *gorm.DB {
+	if locationID == nil {
+		return db.Where("location_id IS NULL")
+	}
+	return db.Where("location_id IS NULL OR location_id = ?", *locationID)
+}
+
+// IsHoliday reports whether date is a day off on the calendar of the location,
+// or on the company-wide calendar when locationID is nil.
+func (r *masterRepository) IsHoliday(ctx context.Context, db *gorm.DB, date time.Time, locationID *uuid.UUID) (bool, error) {
+	if db == nil {
+		db = r.db
+	}
+	var count int64
+	query := db.WithContext(ctx).Model(&entities.Holiday{}).Where("date = ?", date.Format("2006-01-02"))
+	if err := holidaysFor(query, locationID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindHolidaysBetween lists the days off within [start, end] for employees
+// based at the location, ordered by date.
+func (r *masterRepository) FindHolidaysBetween(ctx context.Context, db *gorm.DB, start, end time.Time, locationID *uuid.UUID) ([]entities.Holiday, error) {
+	if db == nil {
+		db = r.db
+	}
+	var holidays []entities.Holiday
+	query := db.WithContext(ctx).
+		Where("date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err := holidaysFor(query, locationID).Order("date").Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// UpsertHolidays writes a batch of calendar entries at once. An entry for a
+// date already on the same calendar replaces its name, description and type.
+func (r *masterRepository) UpsertHolidays(ctx context.Context, holidays []entities.Holiday) (created int, updated int, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created, updated = 0, 0
+		for _, h := range holidays {
+			query := tx.Where("date = ?", h.Date.Format("2006-01-02"))
+			if h.LocationID == nil {
+				query = query.Where("location_id IS NULL")
+			} else {
+				query = query.Where("location_id = ?", *h.LocationID)
+			}
+
+			var existing entities.Holiday
+			err := query.Take(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Create(&h).Error; err != nil {
+					return err
+				}
+				created++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&existing).Updates(map[string]any{
+				"name":        h.Name,
+				"description": h.Description,
+				"type":        h.Type,
+			}).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	return created, updated, err
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/master/controller"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do"
@@ -13,6 +14,7 @@ func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 	masterController := do.MustInvoke[controller.MasterController](injector)
 
 	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
 
 	masterRoutes := server.Group("/api/master")
 	masterRoutes.Use(middlewares.Authenticate(jwtService))
@@ -30,6 +32,7 @@ func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 		masterRoutes.POST("/locations", masterController.CreateLocation)
 		masterRoutes.PUT("/locations/:id", masterController.UpdateLocation)
 		masterRoutes.DELETE("/locations/:id", masterController.DeleteLocation)
+		masterRoutes.GET("/locations/:id/kiosk-code", middlewares.Authorize(rbacService, constants.PERMISSION_OPERATE_KIOSK), masterController.GetKioskCode)
 
 		// Positions
 		masterRoutes.GET("/positions", masterController.GetPositions)
@@ -37,5 +40,13 @@ func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 		masterRoutes.POST("/positions", masterController.CreatePosition)
 		masterRoutes.PUT("/positions/:id", masterController.UpdatePosition)
 		masterRoutes.DELETE("/positions/:id", masterController.DeletePosition)
+
+		// Holidays
+		masterRoutes.GET("/holidays", masterController.GetHolidays)
+		masterRoutes.GET("/holidays/:id", masterController.GetHolidayByID)
+		masterRoutes.POST("/holidays", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_HOLIDAYS), masterController.CreateHoliday)
+		masterRoutes.POST("/holidays/import", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_HOLIDAYS), masterController.ImportHolidays)
+		masterRoutes.PUT("/holidays/:id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_HOLIDAYS), masterController.UpdateHoliday)
+		masterRoutes.DELETE("/holidays/:id", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_HOLIDAYS), masterController.DeleteHoliday)
 	}
 }
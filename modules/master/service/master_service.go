@@ -2,9 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -23,6 +30,10 @@ type MasterService interface {
 	FindLocations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Location], error)
 	GetLocationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Location, error)
 	UpdateLocation(ctx context.Context, tx *gorm.DB, loc entities.Location) (entities.Location, error)
+	SetLocationActive(ctx context.Context, tx *gorm.DB, id uuid.UUID, active bool) error
+	SetLocationSelfieRequired(ctx context.Context, tx *gorm.DB, id uuid.UUID, required bool) error
+	SetLocationKiosk(ctx context.Context, tx *gorm.DB, id uuid.UUID, enabled bool) error
+	KioskCode(ctx context.Context, id uuid.UUID, now time.Time) (dto.KioskCodeResponse, error)
 	DeleteLocation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
 
 	// Positions
@@ -31,6 +42,14 @@ type MasterService interface {
 	GetPositionByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Position, error)
 	UpdatePosition(ctx context.Context, tx *gorm.DB, pos entities.Position) (entities.Position, error)
 	DeletePosition(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Holidays
+	CreateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error)
+	FindHolidays(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Holiday], error)
+	GetHolidayByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Holiday, error)
+	UpdateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error)
+	DeleteHoliday(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+	ImportHolidays(ctx context.Context, file io.Reader, locationID *uuid.UUID, holidayType string) (dto.HolidayImportResult, error)
 }
 
 type masterService struct {
@@ -71,6 +90,13 @@ func (s *masterService) DeleteDepartment(ctx context.Context, tx *gorm.DB, id uu
 
 // Locations
 func (s *masterService) CreateLocation(ctx context.Context, tx *gorm.DB, loc entities.Location) (entities.Location, error) {
+	if loc.KioskEnabled {
+		secret, err := helpers.NewTOTPSecret()
+		if err != nil {
+			return entities.Location{}, err
+		}
+		loc.KioskSecret = secret
+	}
 	return s.masterRepository.CreateLocation(ctx, tx, loc)
 }
 
@@ -86,6 +112,60 @@ func (s *masterService) UpdateLocation(ctx context.Context, tx *gorm.DB, loc ent
 	return s.masterRepository.UpdateLocation(ctx, tx, loc)
 }
 
+func (s *masterService) SetLocationActive(ctx context.Context, tx *gorm.DB, id uuid.UUID, active bool) error {
+	return s.masterRepository.SetLocationActive(ctx, tx, id, active)
+}
+
+func (s *masterService) SetLocationSelfieRequired(ctx context.Context, tx *gorm.DB, id uuid.UUID, required bool) error {
+	return s.masterRepository.SetLocationSelfieRequired(ctx, tx, id, required)
+}
+
+// SetLocationKiosk switches kiosk mode. Enabling it always issues a new secret,
+// so doing it again rotates a secret that may have leaked.
+func (s *masterService) SetLocationKiosk(ctx context.Context, tx *gorm.DB, id uuid.UUID, enabled bool) error {
+	var secret string
+	if enabled {
+		var err error
+		if secret, err = helpers.NewTOTPSecret(); err != nil {
+			return err
+		}
+	}
+	return s.masterRepository.SetLocationKiosk(ctx, tx, id, enabled, secret)
+}
+
+// KioskCode returns the code a kiosk location shows at now.
+func (s *masterService) KioskCode(ctx context.Context, id uuid.UUID, now time.Time) (dto.KioskCodeResponse, error) {
+	location, err := s.masterRepository.GetLocationByID(ctx, nil, id)
+	if err != nil {
+		return dto.KioskCodeResponse{}, err
+	}
+	if !location.KioskEnabled || location.KioskSecret == "" {
+		return dto.KioskCodeResponse{}, dto.ErrKioskDisabled
+	}
+
+	period := constants.KIOSK_CODE_PERIOD_SECONDS * time.Second
+	code, err := helpers.TOTPCode(location.KioskSecret, now, period, constants.KIOSK_CODE_DIGITS)
+	if err != nil {
+		return dto.KioskCodeResponse{}, err
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"location_id": location.ID.String(),
+		"kiosk_code":  code,
+	})
+	if err != nil {
+		return dto.KioskCodeResponse{}, err
+	}
+
+	return dto.KioskCodeResponse{
+		LocationID:    location.ID.String(),
+		Code:          code,
+		Payload:       string(payload),
+		PeriodSeconds: constants.KIOSK_CODE_PERIOD_SECONDS,
+		ExpiresAt:     helpers.TOTPPeriodEnd(now, period),
+	}, nil
+}
+
 func (s *masterService) DeleteLocation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
 	return s.masterRepository.DeleteLocation(ctx, tx, id)
 }
@@ -110,3 +190,83 @@ func (s *masterService) UpdatePosition(ctx context.Context, tx *gorm.DB, pos ent
 func (s *masterService) DeletePosition(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
 	return s.masterRepository.DeletePosition(ctx, tx, id)
 }
+
+// Holidays
+func (s *masterService) CreateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error) {
+	return s.masterRepository.CreateHoliday(ctx, tx, h)
+}
+
+func (s *masterService) FindHolidays(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Holiday], error) {
+	return s.masterRepository.FindHolidays(ctx, db, filter)
+}
+
+func (s *masterService) GetHolidayByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Holiday, error) {
+	return s.masterRepository.GetHolidayByID(ctx, db, id)
+}
+
+func (s *masterService) UpdateHoliday(ctx context.Context, tx *gorm.DB, h entities.Holiday) (entities.Holiday, error) {
+	return s.masterRepository.UpdateHoliday(ctx, tx, h)
+}
+
+func (s *masterService) DeleteHoliday(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	return s.masterRepository.DeleteHoliday(ctx, tx, id)
+}
+
+// ImportHolidays puts the events of an iCalendar file on the calendar of the
+// location, or the company-wide one when locationID is nil. Days already on
+// that calendar take the name and type of the file.
+func (s *masterService) ImportHolidays(ctx context.Context, file io.Reader, locationID *uuid.UUID, holidayType string) (dto.HolidayImportResult, error) {
+	events, err := helpers.ParseICS(file)
+	if err != nil {
+		return dto.HolidayImportResult{}, err
+	}
+	if len(events) == 0 {
+		return dto.HolidayImportResult{}, dto.ErrHolidayFileEmpty
+	}
+
+	result := dto.HolidayImportResult{Events: len(events)}
+	var holidays []entities.Holiday
+	for _, holiday := range HolidaysFromEvents(events, holidayType) {
+		holiday.LocationID = locationID
+		holidays = append(holidays, holiday)
+	}
+
+	result.Created, result.Updated, err = s.masterRepository.UpsertHolidays(ctx, holidays)
+	if err != nil {
+		return dto.HolidayImportResult{}, err
+	}
+	return result, nil
+}
+
+// HolidaysFromEvents turns calendar events into one holiday per day they
+// cover. Without holidayType, an event is collective leave when its summary
+// names cuti bersama and a public holiday otherwise. Where events overlap, the
+// first one of a day wins.
+func HolidaysFromEvents(events []helpers.ICSEvent, holidayType string) []entities.Holiday {
+	seen := map[time.Time]bool{}
+	var holidays []entities.Holiday
+	for _, event := range events {
+		kind := holidayType
+		if kind == "" {
+			kind = constants.ENUM_HOLIDAY_TYPE_PUBLIC
+			summary := strings.ToLower(event.Summary)
+			if strings.Contains(summary, "cuti bersama") || strings.Contains(summary, "collective leave") {
+				kind = constants.ENUM_HOLIDAY_TYPE_COLLECTIVE
+			}
+		}
+
+		for _, date := range event.Dates() {
+			if seen[date] {
+				continue
+			}
+			seen[date] = true
+			holidays = append(holidays, entities.Holiday{
+				Date:        date,
+				Name:        event.Summary,
+				Description: event.Description,
+				Type:        kind,
+			})
+		}
+	}
+	return holidays
+}
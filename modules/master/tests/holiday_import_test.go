@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// indonesiaICS is shaped like the public holiday feeds of calendar providers,
+// with CRLF line ends and a folded description.
+const indonesiaICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Holidays//ID\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20270101\r\n" +
+	"DTEND;VALUE=DATE:20270102\r\n" +
+	"SUMMARY:Tahun Baru Masehi\r\n" +
+	"DESCRIPTION:Public holiday\\, national\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20270310\r\n" +
+	"DTEND;VALUE=DATE:20270312\r\n" +
+	"SUMMARY:Hari Raya Idul Fitri\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20270311\r\n" +
+	"DTEND;VALUE=DATE:20270314\r\n" +
+	"SUMMARY:Cuti Bersama Idul Fitri\r\n" +
+	"DESCRIPTION:Collective leave decreed \r\n" +
+	" with the holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20270417T000000Z\r\n" +
+	"SUMMARY:Moved holiday\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+type fakeMasterRepository struct {
+	repository.MasterRepository
+	upserted []entities.Holiday
+}
+
+func (r *fakeMasterRepository) UpsertHolidays(ctx context.Context, holidays []entities.Holiday) (int, int, error) {
+	r.upserted = append(r.upserted, holidays...)
+	return len(holidays), 0, nil
+}
+
+func TestParseICS(t *testing.T) {
+	events, err := helpers.ParseICS(strings.NewReader(indonesiaICS))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3, "cancelled events are left out")
+
+	assert.Equal(t, date(2027, time.January, 1), events[0].Start)
+	assert.Equal(t, []time.Time{date(2027, time.January, 1)}, events[0].Dates())
+	assert.Equal(t, "Public holiday, national", events[0].Description)
+
+	assert.Equal(t, []time.Time{date(2027, time.March, 10), date(2027, time.March, 11)}, events[1].Dates(),
+		"DTEND of an all-day event is exclusive")
+	assert.Equal(t, "Collective leave decreed with the holiday", events[2].Description)
+
+	_, err = helpers.ParseICS(strings.NewReader("BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:2027-01-01\r\nEND:VEVENT\r\n"))
+	assert.Error(t, err)
+}
+
+func TestHolidaysFromEvents_DetectsCollectiveLeave(t *testing.T) {
+	events, err := helpers.ParseICS(strings.NewReader(indonesiaICS))
+	assert.NoError(t, err)
+
+	holidays := service.HolidaysFromEvents(events, "")
+	assert.Len(t, holidays, 5, "overlapping days are taken once")
+
+	types := map[time.Time]string{}
+	for _, holiday := range holidays {
+		types[holiday.Date] = holiday.Type
+	}
+	assert.Equal(t, constants.ENUM_HOLIDAY_TYPE_PUBLIC, types[date(2027, time.March, 11)], "the first event of a day wins")
+	assert.Equal(t, constants.ENUM_HOLIDAY_TYPE_COLLECTIVE, types[date(2027, time.March, 12)])
+	assert.Equal(t, constants.ENUM_HOLIDAY_TYPE_COLLECTIVE, types[date(2027, time.March, 13)])
+
+	for _, holiday := range service.HolidaysFromEvents(events, constants.ENUM_HOLIDAY_TYPE_PUBLIC) {
+		assert.Equal(t, constants.ENUM_HOLIDAY_TYPE_PUBLIC, holiday.Type, "a given type applies to every event")
+	}
+}
+
+func TestMasterService_ImportHolidays(t *testing.T) {
+	repo := &fakeMasterRepository{}
+	svc := service.NewMasterService(repo, nil)
+	bali := uuid.New()
+
+	result, err := svc.ImportHolidays(context.Background(), strings.NewReader(indonesiaICS), &bali, "")
+	assert.NoError(t, err)
+	assert.Equal(t, dto.HolidayImportResult{Events: 3, Created: 5}, result)
+	for _, holiday := range repo.upserted {
+		assert.Equal(t, &bali, holiday.LocationID)
+	}
+
+	_, err = svc.ImportHolidays(context.Background(), strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil, "")
+	assert.ErrorIs(t, err, dto.ErrHolidayFileEmpty)
+}
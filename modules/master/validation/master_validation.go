@@ -1,6 +1,10 @@
 package validation
 
 import (
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/master/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -24,11 +28,23 @@ func (v *MasterValidation) ValidateDepartmentUpdateRequest(req interface{}) erro
 }
 
 func (v *MasterValidation) ValidateLocationCreateRequest(req interface{}) error {
-	return v.validate.Struct(req)
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	if r, ok := req.(dto.LocationCreateRequest); ok {
+		return validateLocationFields(r.Polygon, r.Timezone)
+	}
+	return nil
 }
 
 func (v *MasterValidation) ValidateLocationUpdateRequest(req interface{}) error {
-	return v.validate.Struct(req)
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	if r, ok := req.(dto.LocationUpdateRequest); ok {
+		return validateLocationFields(r.Polygon, r.Timezone)
+	}
+	return nil
 }
 
 func (v *MasterValidation) ValidatePositionCreateRequest(req interface{}) error {
@@ -38,3 +54,34 @@ func (v *MasterValidation) ValidatePositionCreateRequest(req interface{}) error
 func (v *MasterValidation) ValidatePositionUpdateRequest(req interface{}) error {
 	return v.validate.Struct(req)
 }
+
+func (v *MasterValidation) ValidateHolidayCreateRequest(req dto.HolidayCreateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	_, err := time.Parse("2006-01-02", req.Date)
+	return err
+}
+
+func (v *MasterValidation) ValidateHolidayUpdateRequest(req dto.HolidayUpdateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	if req.Date == "" {
+		return nil
+	}
+	_, err := time.Parse("2006-01-02", req.Date)
+	return err
+}
+
+func validateLocationFields(polygon []byte, timezone string) error {
+	if _, err := helpers.ParsePolygon(polygon); err != nil {
+		return err
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
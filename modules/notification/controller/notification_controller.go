@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	NotificationController interface {
+		GetMine(ctx *gin.Context)
+		MarkRead(ctx *gin.Context)
+		MarkAllRead(ctx *gin.Context)
+	}
+
+	notificationController struct {
+		notificationService    service.NotificationService
+		notificationValidation *validation.NotificationValidation
+		db                     *gorm.DB
+	}
+)
+
+func NewNotificationController(injector *do.Injector, s service.NotificationService) NotificationController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	notificationValidation := validation.NewNotificationValidation()
+	return &notificationController{
+		notificationService:    s,
+		notificationValidation: notificationValidation,
+		db:                     db,
+	}
+}
+
+func (c *notificationController) GetMine(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.notificationService.FindMine(ctx.Request.Context(), userID, &filter, ctx.Query("unread") == "true")
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get notifications", err.Error(), nil)
+		ctx.JSON(notificationErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *notificationController) MarkRead(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.notificationService.MarkRead(ctx.Request.Context(), userID, id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed mark notification as read", err.Error(), nil)
+		ctx.JSON(notificationErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success mark notification as read", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *notificationController) MarkAllRead(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	if err := c.notificationService.MarkAllRead(ctx.Request.Context(), userID); err != nil {
+		res := utils.BuildResponseFailed("failed mark notifications as read", err.Error(), nil)
+		ctx.JSON(notificationErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success mark notifications as read", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrInvalidUser):
+		return http.StatusUnauthorized
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
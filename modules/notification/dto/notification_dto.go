@@ -0,0 +1,12 @@
+package dto
+
+import "errors"
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrInvalidUser = errors.New("invalid user id")
+)
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, notification entities.Notification) (entities.Notification, error)
+	FindByUserID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, userID uuid.UUID, unreadOnly bool) (*pagination.Page[entities.Notification], error)
+	GetByIDForUser(ctx context.Context, db *gorm.DB, id, userID uuid.UUID) (entities.Notification, error)
+	MarkRead(ctx context.Context, tx *gorm.DB, id, userID uuid.UUID, readAt time.Time) error
+	MarkAllRead(ctx context.Context, tx *gorm.DB, userID uuid.UUID, readAt time.Time) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, tx *gorm.DB, notification entities.Notification) (entities.Notification, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&notification).Error; err != nil {
+		return entities.Notification{}, err
+	}
+	return notification, nil
+}
+
+func (r *notificationRepository) FindByUserID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, userID uuid.UUID, unreadOnly bool) (*pagination.Page[entities.Notification], error) {
+	if db == nil {
+		db = r.db
+	}
+
+	query := db.WithContext(ctx).Model(&entities.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var items []entities.Notification
+	var page pagination.Page[entities.Notification]
+	paginator, err := pagination.NewPaginator(query.Order("created_at DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *notificationRepository) GetByIDForUser(ctx context.Context, db *gorm.DB, id, userID uuid.UUID) (entities.Notification, error) {
+	if db == nil {
+		db = r.db
+	}
+	var notification entities.Notification
+	if err := db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return entities.Notification{}, err
+	}
+	return notification, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, tx *gorm.DB, id, userID uuid.UUID, readAt time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.WithContext(ctx).Model(&entities.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", readAt).Error
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, tx *gorm.DB, userID uuid.UUID, readAt time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.WithContext(ctx).Model(&entities.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt).Error
+}
@@ -0,0 +1,24 @@
+package notification
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	notificationController := do.MustInvoke[controller.NotificationController](injector)
+
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+
+	notificationRoutes := server.Group("/api/notifications")
+	notificationRoutes.Use(middlewares.Authenticate(jwtService))
+	{
+		notificationRoutes.GET("/me", notificationController.GetMine)
+		notificationRoutes.POST("/me/read", notificationController.MarkAllRead)
+		notificationRoutes.POST("/:id/read", notificationController.MarkRead)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/notification/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, recipient entities.User, kind, title, body string, data any) (entities.Notification, error)
+	FindMine(ctx context.Context, userID string, filter *pagination.Filter, unreadOnly bool) (*pagination.Page[entities.Notification], error)
+	MarkRead(ctx context.Context, userID string, id uuid.UUID) (entities.Notification, error)
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type notificationService struct {
+	notificationRepository repository.NotificationRepository
+	db                     *gorm.DB
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	db *gorm.DB,
+) NotificationService {
+	return &notificationService{
+		notificationRepository: notificationRepo,
+		db:                     db,
+	}
+}
+
+// Notify stores an in-app notification for recipient and mails a copy when the
+// user has an email address. A failed mail is logged, the stored copy stands.
+func (s *notificationService) Notify(ctx context.Context, recipient entities.User, kind, title, body string, data any) (entities.Notification, error) {
+	notification := entities.Notification{
+		UserID: recipient.ID,
+		Type:   kind,
+		Title:  title,
+		Body:   body,
+	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return entities.Notification{}, err
+		}
+		notification.Data = raw
+	}
+
+	notification, err := s.notificationRepository.Create(ctx, nil, notification)
+	if err != nil {
+		return entities.Notification{}, err
+	}
+
+	if recipient.Email != "" {
+		if err := utils.SendMail(recipient.Email, title, html.EscapeString(body)); err != nil {
+			log.Printf("notification %s: failed to mail %s: %v", notification.ID, recipient.Email, err)
+		}
+	}
+
+	return notification, nil
+}
+
+func (s *notificationService) FindMine(ctx context.Context, userID string, filter *pagination.Filter, unreadOnly bool) (*pagination.Page[entities.Notification], error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, dto.ErrInvalidUser
+	}
+	return s.notificationRepository.FindByUserID(ctx, nil, filter, uid, unreadOnly)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID string, id uuid.UUID) (entities.Notification, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Notification{}, dto.ErrInvalidUser
+	}
+
+	if err := s.notificationRepository.MarkRead(ctx, nil, id, uid, time.Now()); err != nil {
+		return entities.Notification{}, err
+	}
+	return s.notificationRepository.GetByIDForUser(ctx, nil, id, uid)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return dto.ErrInvalidUser
+	}
+	return s.notificationRepository.MarkAllRead(ctx, nil, uid, time.Now())
+}
@@ -0,0 +1,10 @@
+package tests
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationController (t *testing.T) {
+	assert.True(t, true)
+}
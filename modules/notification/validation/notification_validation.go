@@ -0,0 +1,16 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+type NotificationValidation struct {
+	validate *validator.Validate
+}
+
+func NewNotificationValidation() *NotificationValidation {
+	validate := validator.New()
+	return &NotificationValidation{
+		validate: validate,
+	}
+}
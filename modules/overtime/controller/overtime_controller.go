@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/validation"
+	shiftDto "github.com/Caknoooo/go-gin-clean-starter/modules/shift/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	OvertimeController interface {
+		Submit(ctx *gin.Context)
+		GetAll(ctx *gin.Context)
+		GetMine(ctx *gin.Context)
+		GetPendingApprovals(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		Approve(ctx *gin.Context)
+		Reject(ctx *gin.Context)
+		Reconcile(ctx *gin.Context)
+		GetPayrollSummary(ctx *gin.Context)
+		ReconcilePeriod(ctx *gin.Context)
+	}
+
+	overtimeController struct {
+		overtimeService    service.OvertimeService
+		overtimeValidation *validation.OvertimeValidation
+		db                 *gorm.DB
+	}
+)
+
+func NewOvertimeController(injector *do.Injector, s service.OvertimeService) OvertimeController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	overtimeValidation := validation.NewOvertimeValidation()
+	return &overtimeController{
+		overtimeService:    s,
+		overtimeValidation: overtimeValidation,
+		db:                 db,
+	}
+}
+
+func (c *overtimeController) Submit(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var req dto.OvertimeCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.overtimeValidation.ValidateOvertimeCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.Submit(ctx.Request.Context(), userID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed submit overtime request", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success submit overtime request", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *overtimeController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.overtimeService.FindAll(ctx.Request.Context(), &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get overtime requests", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) GetMine(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.overtimeService.FindMine(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get overtime requests", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) GetPendingApprovals(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.overtimeService.FindPendingApprovals(ctx.Request.Context(), userID, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get pending overtime requests", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(string)
+	result, err := c.overtimeService.GetByID(ctx.Request.Context(), id, userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get overtime request", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) Approve(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.OvertimeReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.Approve(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed approve overtime request", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success approve overtime request", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) Reject(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(string)
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.OvertimeRejectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.Reject(ctx.Request.Context(), id, userID, req.Note)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reject overtime request", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success reject overtime request", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *overtimeController) Reconcile(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.Reconcile(ctx.Request.Context(), id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reconcile overtime request", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success reconcile overtime request", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetPayrollSummary totals approved overtime for ?start=YYYY-MM-DD&end=YYYY-MM-DD.
+func (c *overtimeController) GetPayrollSummary(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", ctx.Query("start"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid start date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02", ctx.Query("end"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid end date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.GetPayrollSummary(ctx.Request.Context(), employeeID, start, end)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get overtime payroll summary", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ReconcilePeriod settles the approved overtime of an employee for
+// ?start=YYYY-MM-DD&end=YYYY-MM-DD that is not reconciled yet.
+func (c *overtimeController) ReconcilePeriod(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", ctx.Query("start"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid start date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02", ctx.Query("end"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid end date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.overtimeService.ReconcilePeriod(ctx.Request.Context(), employeeID, start, end)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed reconcile overtime requests", err.Error(), nil)
+		ctx.JSON(overtimeErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success reconcile overtime requests", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func overtimeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrNotSupervisor),
+		errors.Is(err, dto.ErrNotOvertimeViewer):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrOvertimeNotPending),
+		errors.Is(err, dto.ErrOvertimeNotApproved),
+		errors.Is(err, dto.ErrNoCheckOut):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrInvalidOvertimeWindow),
+		errors.Is(err, dto.ErrOvertimeTooLong),
+		errors.Is(err, dto.ErrEmployeeNotLinked),
+		errors.Is(err, dto.ErrSummaryRange),
+		errors.Is(err, shiftDto.ErrInvalidClock):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrInvalidOvertimeWindow = errors.New("overtime end must be after its start")
+	ErrOvertimeTooLong       = errors.New("overtime exceeds the daily limit for this day type")
+	ErrOvertimeNotPending    = errors.New("overtime request is no longer pending")
+	ErrOvertimeNotApproved   = errors.New("overtime request is not approved")
+	ErrNotSupervisor         = errors.New("only the employee's supervisor can review this request")
+	ErrNotOvertimeViewer     = errors.New("only the employee, their supervisor or HR can view this request")
+	ErrEmployeeNotLinked     = errors.New("no employee record is linked to this user")
+	ErrNoCheckOut            = errors.New("attendance for the work date has no check-out yet")
+	ErrSummaryRange          = errors.New("end must not be before start")
+)
+
+type (
+	OvertimeCreateRequest struct {
+		WorkDate  string `json:"work_date" binding:"required"`
+		StartTime string `json:"start_time" binding:"required"`
+		EndTime   string `json:"end_time" binding:"required"`
+		Reason    string `json:"reason" binding:"required"`
+	}
+
+	OvertimeReviewRequest struct {
+		Note string `json:"note"`
+	}
+
+	OvertimeRejectRequest struct {
+		Note string `json:"note" binding:"required"`
+	}
+
+	OvertimePayrollSummaryResponse struct {
+		EmployeeID   uuid.UUID                  `json:"employee_id"`
+		Start        string                     `json:"start"`
+		End          string                     `json:"end"`
+		TotalMinutes int                        `json:"total_minutes"`
+		PayableHours float64                    `json:"payable_hours"`
+		HourlyWage   float64                    `json:"hourly_wage"`
+		Amount       float64                    `json:"amount"`
+		Requests     []entities.OvertimeRequest `json:"requests"`
+	}
+)
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OvertimeRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error)
+	FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.OvertimeRequest], error)
+	FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.OvertimeRequest], error)
+	FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error)
+	FindApprovedForEmployees(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.OvertimeRequest, error)
+	Update(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error)
+}
+
+type overtimeRepository struct {
+	db *gorm.DB
+}
+
+func NewOvertimeRepository(db *gorm.DB) OvertimeRepository {
+	return &overtimeRepository{
+		db: db,
+	}
+}
+
+func (r *overtimeRepository) Create(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&overtime).Error; err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	return overtime, nil
+}
+
+func (r *overtimeRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.OvertimeRequest{}), filter)
+}
+
+func (r *overtimeRepository) FindByEmployeeID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID uuid.UUID) (*pagination.Page[entities.OvertimeRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	return r.paginate(db.WithContext(ctx).Model(&entities.OvertimeRequest{}).Where("employee_id = ?", employeeID), filter)
+}
+
+func (r *overtimeRepository) FindPendingBySupervisorID(ctx context.Context, db *gorm.DB, filter *pagination.Filter, supervisorID uuid.UUID) (*pagination.Page[entities.OvertimeRequest], error) {
+	if db == nil {
+		db = r.db
+	}
+	subordinates := db.Model(&entities.Employee{}).Select("id").Where("supervisor_id = ?", supervisorID)
+	query := db.WithContext(ctx).Model(&entities.OvertimeRequest{}).
+		Where("status = ?", constants.ENUM_OVERTIME_STATUS_PENDING).
+		Where("employee_id IN (?)", subordinates)
+	return r.paginate(query, filter)
+}
+
+// FindApprovedInRange returns approved requests whose work date falls within [start, end].
+func (r *overtimeRepository) FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error) {
+	if db == nil {
+		db = r.db
+	}
+	var overtimes []entities.OvertimeRequest
+	if err := db.WithContext(ctx).
+		Where("employee_id = ? AND status = ?", employeeID, constants.ENUM_OVERTIME_STATUS_APPROVED).
+		Where("work_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("work_date ASC").
+		Find(&overtimes).Error; err != nil {
+		return nil, err
+	}
+	return overtimes, nil
+}
+
+// FindApprovedForEmployees returns the approved requests of several employees
+// whose work date falls within [start, end].
+func (r *overtimeRepository) FindApprovedForEmployees(ctx context.Context, db *gorm.DB, employeeIDs []uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error) {
+	if db == nil {
+		db = r.db
+	}
+	var overtimes []entities.OvertimeRequest
+	if len(employeeIDs) == 0 {
+		return overtimes, nil
+	}
+	if err := db.WithContext(ctx).
+		Where("employee_id IN ? AND status = ?", employeeIDs, constants.ENUM_OVERTIME_STATUS_APPROVED).
+		Where("work_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Find(&overtimes).Error; err != nil {
+		return nil, err
+	}
+	return overtimes, nil
+}
+
+func (r *overtimeRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.OvertimeRequest, error) {
+	if db == nil {
+		db = r.db
+	}
+	var overtime entities.OvertimeRequest
+	if err := db.WithContext(ctx).Preload("Employee").Preload("Approver").Where("id = ?", id).First(&overtime).Error; err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	return overtime, nil
+}
+
+func (r *overtimeRepository) Update(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit("Employee", "Approver").Save(&overtime).Error; err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	return overtime, nil
+}
+
+func (r *overtimeRepository) paginate(query *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error) {
+	var overtimes []entities.OvertimeRequest
+	var page pagination.Page[entities.OvertimeRequest]
+
+	paginator, err := pagination.NewPaginator(query.Preload("Employee").Order("created_at DESC"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paginator.Find(&overtimes).Error; err != nil {
+		return nil, err
+	}
+
+	page.Set(overtimes, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
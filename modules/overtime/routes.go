@@ -0,0 +1,33 @@
+package overtime
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/controller"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	overtimeController := do.MustInvoke[controller.OvertimeController](injector)
+
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
+
+	overtimeRoutes := server.Group("/api/overtimes")
+	overtimeRoutes.Use(middlewares.Authenticate(jwtService))
+	{
+		overtimeRoutes.POST("", overtimeController.Submit)
+		overtimeRoutes.GET("", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_OVERTIME), overtimeController.GetAll)
+		overtimeRoutes.GET("/me", overtimeController.GetMine)
+		overtimeRoutes.GET("/approvals", overtimeController.GetPendingApprovals)
+		overtimeRoutes.GET("/employees/:employee_id/payroll-summary", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_OVERTIME), overtimeController.GetPayrollSummary)
+		overtimeRoutes.POST("/employees/:employee_id/reconcile", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_OVERTIME), overtimeController.ReconcilePeriod)
+		overtimeRoutes.GET("/:id", overtimeController.GetByID)
+		overtimeRoutes.POST("/:id/approve", overtimeController.Approve)
+		overtimeRoutes.POST("/:id/reject", overtimeController.Reject)
+		overtimeRoutes.POST("/:id/reconcile", middlewares.Authorize(rbacService, constants.PERMISSION_MANAGE_OVERTIME), overtimeController.Reconcile)
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftRepository "github.com/Caknoooo/go-gin-clean-starter/modules/shift/repository"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OvertimeService interface {
+	Submit(ctx context.Context, userID string, req dto.OvertimeCreateRequest) (entities.OvertimeRequest, error)
+	FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error)
+	FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error)
+	FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error)
+	GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.OvertimeRequest, error)
+	Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.OvertimeRequest, error)
+	Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.OvertimeRequest, error)
+	Reconcile(ctx context.Context, id uuid.UUID) (entities.OvertimeRequest, error)
+	ReconcilePeriod(ctx context.Context, employeeID uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error)
+	GetPayrollSummary(ctx context.Context, employeeID uuid.UUID, start, end time.Time) (dto.OvertimePayrollSummaryResponse, error)
+}
+
+type overtimeService struct {
+	overtimeRepository   repository.OvertimeRepository
+	employeeRepository   employeeRepository.EmployeeRepository
+	attendanceRepository attendanceRepository.AttendanceRepository
+	shiftRepository      shiftRepository.ShiftRepository
+	masterRepository     masterRepository.MasterRepository
+	rbacService          rbacService.RbacService
+	db                   *gorm.DB
+}
+
+func NewOvertimeService(
+	overtimeRepo repository.OvertimeRepository,
+	employeeRepo employeeRepository.EmployeeRepository,
+	attendanceRepo attendanceRepository.AttendanceRepository,
+	shiftRepo shiftRepository.ShiftRepository,
+	masterRepo masterRepository.MasterRepository,
+	rbacSvc rbacService.RbacService,
+	db *gorm.DB,
+) OvertimeService {
+	return &overtimeService{
+		overtimeRepository:   overtimeRepo,
+		employeeRepository:   employeeRepo,
+		attendanceRepository: attendanceRepo,
+		shiftRepository:      shiftRepo,
+		masterRepository:     masterRepo,
+		rbacService:          rbacSvc,
+		db:                   db,
+	}
+}
+
+// Submit files an overtime request for the logged-in employee. The day type
+// comes from the holiday calendar of the location they are based at and their
+// shift schedule, never from the client.
+func (s *overtimeService) Submit(ctx context.Context, userID string, req dto.OvertimeCreateRequest) (entities.OvertimeRequest, error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+
+	workDay, err := time.ParseInLocation("2006-01-02", req.WorkDate, helpers.LoadTimezone(""))
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	start, end, err := OvertimeWindow(workDay, req.StartTime, req.EndTime)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+
+	holiday, err := s.masterRepository.IsHoliday(ctx, nil, helpers.DateOf(workDay), employee.LocationID)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	dayType := constants.ENUM_OVERTIME_DAY_WORKDAY
+	if holiday {
+		dayType = constants.ENUM_OVERTIME_DAY_HOLIDAY
+	} else if s.isRestDay(ctx, employee.ID, workDay) {
+		dayType = constants.ENUM_OVERTIME_DAY_REST_DAY
+	}
+
+	minutes := int(end.Sub(start).Minutes())
+	if minutes > MaxOvertimeMinutes(dayType, helpers.WorkWeekDays()) {
+		return entities.OvertimeRequest{}, dto.ErrOvertimeTooLong
+	}
+
+	return s.overtimeRepository.Create(ctx, nil, entities.OvertimeRequest{
+		EmployeeID:   employee.ID,
+		WorkDate:     helpers.DateOf(workDay),
+		PlannedStart: start,
+		PlannedEnd:   end,
+		DayType:      dayType,
+		Reason:       req.Reason,
+		Status:       constants.ENUM_OVERTIME_STATUS_PENDING,
+	})
+}
+
+func (s *overtimeService) FindAll(ctx context.Context, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error) {
+	return s.overtimeRepository.FindAll(ctx, nil, filter)
+}
+
+func (s *overtimeService) FindMine(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error) {
+	employee, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.overtimeRepository.FindByEmployeeID(ctx, nil, filter, employee.ID)
+}
+
+func (s *overtimeService) FindPendingApprovals(ctx context.Context, userID string, filter *pagination.Filter) (*pagination.Page[entities.OvertimeRequest], error) {
+	supervisor, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.overtimeRepository.FindPendingBySupervisorID(ctx, nil, filter, supervisor.ID)
+}
+
+// GetByID shows a request to the employee who filed it, their supervisor, the
+// approver who decided it and holders of manage_overtime.
+func (s *overtimeService) GetByID(ctx context.Context, id uuid.UUID, userID string) (entities.OvertimeRequest, error) {
+	overtime, err := s.overtimeRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.OvertimeRequest{}, dto.ErrNotOvertimeViewer
+	}
+	viewer, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OvertimeRequest{}, err
+	}
+	if err == nil {
+		switch {
+		case viewer.ID == overtime.EmployeeID,
+			overtime.Employee.SupervisorID != nil && *overtime.Employee.SupervisorID == viewer.ID,
+			overtime.ApproverID != nil && *overtime.ApproverID == viewer.ID:
+			return overtime, nil
+		}
+	}
+
+	manager, err := s.rbacService.HasPermission(ctx, uid, constants.PERMISSION_MANAGE_OVERTIME)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	if !manager {
+		return entities.OvertimeRequest{}, dto.ErrNotOvertimeViewer
+	}
+	return overtime, nil
+}
+
+func (s *overtimeService) Approve(ctx context.Context, id uuid.UUID, userID string, note string) (entities.OvertimeRequest, error) {
+	overtime, err := s.review(ctx, id, userID, constants.ENUM_OVERTIME_STATUS_APPROVED, note)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+
+	// Overtime approved after the fact can be settled right away
+	if reconciled, err := s.Reconcile(ctx, overtime.ID); err == nil {
+		return reconciled, nil
+	}
+	return overtime, nil
+}
+
+func (s *overtimeService) Reject(ctx context.Context, id uuid.UUID, userID string, note string) (entities.OvertimeRequest, error) {
+	return s.review(ctx, id, userID, constants.ENUM_OVERTIME_STATUS_REJECTED, note)
+}
+
+// Reconcile settles an approved request against the attendance of its work
+// date. Only the part of the approved window the employee was actually on site
+// counts, so the approval acts as a cap.
+func (s *overtimeService) Reconcile(ctx context.Context, id uuid.UUID) (entities.OvertimeRequest, error) {
+	overtime, err := s.overtimeRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	if overtime.Status != constants.ENUM_OVERTIME_STATUS_APPROVED {
+		return entities.OvertimeRequest{}, dto.ErrOvertimeNotApproved
+	}
+
+	attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(overtime.EmployeeID, overtime.WorkDate)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	if attendance.CheckInTime == nil || attendance.CheckOutTime == nil {
+		return entities.OvertimeRequest{}, dto.ErrNoCheckOut
+	}
+
+	minutes := overlapMinutes(overtime.PlannedStart, overtime.PlannedEnd, *attendance.CheckInTime, *attendance.CheckOutTime)
+	now := time.Now()
+	overtime.AttendanceID = &attendance.ID
+	overtime.ActualMinutes = minutes
+	overtime.PayableHours = PayableHours(minutes, overtime.DayType, helpers.WorkWeekDays())
+	overtime.ReconciledAt = &now
+
+	return s.overtimeRepository.Update(ctx, nil, overtime)
+}
+
+// ReconcilePeriod settles the approved requests of an employee for the work
+// dates in [start, end] that are not reconciled yet, e.g. before payroll is
+// run. Requests still waiting for a check-out are left for later.
+func (s *overtimeService) ReconcilePeriod(ctx context.Context, employeeID uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error) {
+	if end.Before(start) {
+		return nil, dto.ErrSummaryRange
+	}
+
+	overtimes, err := s.overtimeRepository.FindApprovedInRange(ctx, nil, employeeID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciled := make([]entities.OvertimeRequest, 0, len(overtimes))
+	for _, overtime := range overtimes {
+		if overtime.ReconciledAt != nil {
+			continue
+		}
+		result, err := s.Reconcile(ctx, overtime.ID)
+		if errors.Is(err, dto.ErrNoCheckOut) || errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reconciled = append(reconciled, result)
+	}
+	return reconciled, nil
+}
+
+// GetPayrollSummary totals the approved overtime of an employee for the work
+// dates in [start, end]. It only reads: requests not reconciled yet count as
+// zero until ReconcilePeriod or Reconcile settles them.
+func (s *overtimeService) GetPayrollSummary(ctx context.Context, employeeID uuid.UUID, start, end time.Time) (dto.OvertimePayrollSummaryResponse, error) {
+	if end.Before(start) {
+		return dto.OvertimePayrollSummaryResponse{}, dto.ErrSummaryRange
+	}
+
+	overtimes, err := s.overtimeRepository.FindApprovedInRange(ctx, nil, employeeID, start, end)
+	if err != nil {
+		return dto.OvertimePayrollSummaryResponse{}, err
+	}
+
+	summary := dto.OvertimePayrollSummaryResponse{
+		EmployeeID: employeeID,
+		Start:      start.Format("2006-01-02"),
+		End:        end.Format("2006-01-02"),
+		Requests:   make([]entities.OvertimeRequest, 0, len(overtimes)),
+	}
+	for _, overtime := range overtimes {
+		summary.TotalMinutes += overtime.ActualMinutes
+		summary.PayableHours += overtime.PayableHours
+		summary.Requests = append(summary.Requests, overtime)
+	}
+
+	profile, err := s.employeeRepository.GetPayrollByEmployeeID(ctx, nil, employeeID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.OvertimePayrollSummaryResponse{}, err
+	}
+	summary.PayableHours = roundCents(summary.PayableHours)
+	summary.HourlyWage = roundCents(profile.BasicSalary / constants.OVERTIME_HOURLY_WAGE_DIVISOR)
+	summary.Amount = roundCents(summary.PayableHours * profile.BasicSalary / constants.OVERTIME_HOURLY_WAGE_DIVISOR)
+
+	return summary, nil
+}
+
+func (s *overtimeService) review(ctx context.Context, id uuid.UUID, userID string, status string, note string) (entities.OvertimeRequest, error) {
+	reviewer, err := s.employeeByUserID(ctx, userID)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+
+	overtime, err := s.overtimeRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.OvertimeRequest{}, err
+	}
+	if overtime.Status != constants.ENUM_OVERTIME_STATUS_PENDING {
+		return entities.OvertimeRequest{}, dto.ErrOvertimeNotPending
+	}
+	if overtime.Employee.SupervisorID == nil || *overtime.Employee.SupervisorID != reviewer.ID {
+		return entities.OvertimeRequest{}, dto.ErrNotSupervisor
+	}
+
+	now := time.Now()
+	overtime.Status = status
+	overtime.ApproverID = &reviewer.ID
+	overtime.ReviewedAt = &now
+	overtime.ReviewNote = note
+
+	return s.overtimeRepository.Update(ctx, nil, overtime)
+}
+
+func (s *overtimeService) employeeByUserID(ctx context.Context, userID string) (entities.Employee, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+
+	employee, err := s.employeeRepository.FindByUserID(ctx, nil, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Employee{}, dto.ErrEmployeeNotLinked
+	}
+	return employee, err
+}
+
+// isRestDay follows the employee's shift schedule when one is assigned and the
+// company work week otherwise.
+func (s *overtimeService) isRestDay(ctx context.Context, employeeID uuid.UUID, date time.Time) bool {
+	assignment, err := s.shiftRepository.GetAssignmentOn(ctx, nil, employeeID, date)
+	if err != nil {
+		return helpers.IsWeeklyRestDay(date)
+	}
+	return shiftService.ShiftForDate(assignment, date) == nil
+}
+
+// OvertimeWindow turns "HH:MM" clocks on workDay into a time range. An end at
+// or before the start runs into the next day.
+func OvertimeWindow(workDay time.Time, startClock, endClock string) (time.Time, time.Time, error) {
+	startHour, startMinute, err := shiftService.ParseClock(startClock)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endHour, endMinute, err := shiftService.ParseClock(endClock)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	y, m, d := workDay.Date()
+	start := time.Date(y, m, d, startHour, startMinute, 0, 0, workDay.Location())
+	end := time.Date(y, m, d, endHour, endMinute, 0, 0, workDay.Location())
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	if end.Sub(start) >= 24*time.Hour {
+		return time.Time{}, time.Time{}, dto.ErrInvalidOvertimeWindow
+	}
+	return start, end, nil
+}
+
+// overtimeMultipliers lists the wage multiplier of each successive overtime
+// hour under Kepmenakertrans 102/2004. Hours past the table use the last rate.
+func overtimeMultipliers(dayType string, workWeekDays int) []float64 {
+	if dayType == constants.ENUM_OVERTIME_DAY_WORKDAY {
+		return []float64{1.5, 2}
+	}
+	if workWeekDays == 6 {
+		return []float64{2, 2, 2, 2, 2, 2, 2, 3, 4, 4}
+	}
+	return []float64{2, 2, 2, 2, 2, 2, 2, 2, 3, 4, 4}
+}
+
+// MaxOvertimeMinutes is the longest overtime allowed on a day of dayType.
+func MaxOvertimeMinutes(dayType string, workWeekDays int) int {
+	if dayType == constants.ENUM_OVERTIME_DAY_WORKDAY {
+		return constants.OVERTIME_MAX_WORKDAY_MINUTES
+	}
+	return len(overtimeMultipliers(dayType, workWeekDays)) * 60
+}
+
+// PayableHours converts overtime minutes into wage hours by applying the
+// per-hour multipliers; a partial hour is paid pro rata.
+func PayableHours(minutes int, dayType string, workWeekDays int) float64 {
+	multipliers := overtimeMultipliers(dayType, workWeekDays)
+	hours := 0.0
+	for i := 0; minutes > 0; i++ {
+		chunk := minutes
+		if chunk > 60 {
+			chunk = 60
+		}
+		rate := multipliers[len(multipliers)-1]
+		if i < len(multipliers) {
+			rate = multipliers[i]
+		}
+		hours += float64(chunk) / 60 * rate
+		minutes -= chunk
+	}
+	return roundCents(hours)
+}
+
+func overlapMinutes(startA, endA, startB, endB time.Time) int {
+	start := startA
+	if startB.After(start) {
+		start = startB
+	}
+	end := endA
+	if endB.Before(end) {
+		end = endB
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start).Minutes())
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
+	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftRepository "github.com/Caknoooo/go-gin-clean-starter/modules/shift/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOvertimeService (t *testing.T) {
+	assert.True(t, true)
+}
+
+type fakeOvertimeRepository struct {
+	repository.OvertimeRepository
+	overtime entities.OvertimeRequest
+}
+
+func (r *fakeOvertimeRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.OvertimeRequest, error) {
+	return r.overtime, nil
+}
+
+func (r *fakeOvertimeRepository) Create(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error) {
+	r.overtime = overtime
+	return overtime, nil
+}
+
+func (r *fakeOvertimeRepository) FindApprovedInRange(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, start, end time.Time) ([]entities.OvertimeRequest, error) {
+	return []entities.OvertimeRequest{r.overtime}, nil
+}
+
+func (r *fakeOvertimeRepository) Update(ctx context.Context, tx *gorm.DB, overtime entities.OvertimeRequest) (entities.OvertimeRequest, error) {
+	r.overtime = overtime
+	return overtime, nil
+}
+
+type fakeRbacService struct {
+	rbacService.RbacService
+	granted map[uuid.UUID]string
+}
+
+func (f *fakeRbacService) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	return f.granted[userID] == permission, nil
+}
+
+type fakeEmployeeRepository struct {
+	employeeRepository.EmployeeRepository
+	byUserID map[uuid.UUID]entities.Employee
+}
+
+func (r *fakeEmployeeRepository) FindByUserID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (entities.Employee, error) {
+	employee, ok := r.byUserID[userID]
+	if !ok {
+		return entities.Employee{}, gorm.ErrRecordNotFound
+	}
+	return employee, nil
+}
+
+func (r *fakeEmployeeRepository) GetPayrollByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) (entities.EmployeePayrollProfile, error) {
+	return entities.EmployeePayrollProfile{BasicSalary: 3460000}, nil
+}
+
+type fakeAttendanceRepository struct {
+	attendanceRepository.AttendanceRepository
+	attendance *entities.Attendance
+}
+
+func (r *fakeAttendanceRepository) FindByEmployeeAndWorkDate(employeeID uuid.UUID, workDate time.Time) (*entities.Attendance, error) {
+	if r.attendance == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.attendance, nil
+}
+
+// fakeMasterRepository has a holiday on the dates it lists, at any location.
+type fakeMasterRepository struct {
+	masterRepository.MasterRepository
+	holidays []time.Time
+}
+
+func (r *fakeMasterRepository) IsHoliday(ctx context.Context, db *gorm.DB, date time.Time, locationID *uuid.UUID) (bool, error) {
+	for _, holiday := range r.holidays {
+		if holiday.Equal(date) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeShiftRepository assigns no shift, so the company work week applies.
+type fakeShiftRepository struct {
+	shiftRepository.ShiftRepository
+}
+
+func (r *fakeShiftRepository) GetAssignmentOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) (entities.EmployeeShift, error) {
+	return entities.EmployeeShift{}, gorm.ErrRecordNotFound
+}
+
+func TestPayableHours_Workday(t *testing.T) {
+	assert.Equal(t, 1.5, service.PayableHours(60, constants.ENUM_OVERTIME_DAY_WORKDAY, 5))
+	assert.Equal(t, 3.5, service.PayableHours(120, constants.ENUM_OVERTIME_DAY_WORKDAY, 5))
+	assert.Equal(t, 0.75, service.PayableHours(30, constants.ENUM_OVERTIME_DAY_WORKDAY, 5))
+}
+
+func TestPayableHours_RestDay(t *testing.T) {
+	// 8 hours at 2x, the 9th at 3x, the 10th at 4x
+	assert.Equal(t, 23.0, service.PayableHours(600, constants.ENUM_OVERTIME_DAY_REST_DAY, 5))
+	// 7 hours at 2x, the 8th at 3x on a 6-day work week
+	assert.Equal(t, 17.0, service.PayableHours(480, constants.ENUM_OVERTIME_DAY_HOLIDAY, 6))
+}
+
+func TestMaxOvertimeMinutes(t *testing.T) {
+	assert.Equal(t, 240, service.MaxOvertimeMinutes(constants.ENUM_OVERTIME_DAY_WORKDAY, 5))
+	assert.Equal(t, 660, service.MaxOvertimeMinutes(constants.ENUM_OVERTIME_DAY_HOLIDAY, 5))
+	assert.Equal(t, 600, service.MaxOvertimeMinutes(constants.ENUM_OVERTIME_DAY_REST_DAY, 6))
+}
+
+func TestOvertimeWindow_PastMidnight(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	start, end, err := service.OvertimeWindow(day, "22:00", "01:30")
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 3, 11, 1, 30, 0, 0, time.UTC), end)
+}
+
+func TestOvertimeService_ReconcileCapsToApprovedWindow(t *testing.T) {
+	plannedStart := time.Date(2026, 3, 10, 17, 0, 0, 0, time.UTC)
+	checkIn := plannedStart.Add(-9 * time.Hour)
+	checkOut := plannedStart.Add(3 * time.Hour)
+	overtimeRepo := &fakeOvertimeRepository{overtime: entities.OvertimeRequest{
+		ID:           uuid.New(),
+		PlannedStart: plannedStart,
+		PlannedEnd:   plannedStart.Add(2 * time.Hour),
+		DayType:      constants.ENUM_OVERTIME_DAY_WORKDAY,
+		Status:       constants.ENUM_OVERTIME_STATUS_APPROVED,
+	}}
+	attendanceRepo := &fakeAttendanceRepository{attendance: &entities.Attendance{
+		ID:           uuid.New(),
+		CheckInTime:  &checkIn,
+		CheckOutTime: &checkOut,
+	}}
+	svc := service.NewOvertimeService(overtimeRepo, &fakeEmployeeRepository{}, attendanceRepo, nil, nil, &fakeRbacService{}, nil)
+
+	result, err := svc.Reconcile(context.Background(), overtimeRepo.overtime.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 120, result.ActualMinutes)
+	assert.Equal(t, 3.5, result.PayableHours)
+	assert.Equal(t, attendanceRepo.attendance.ID, *result.AttendanceID)
+}
+
+func TestOvertimeService_ReconcileRequiresCheckOut(t *testing.T) {
+	overtimeRepo := &fakeOvertimeRepository{overtime: entities.OvertimeRequest{
+		Status: constants.ENUM_OVERTIME_STATUS_APPROVED,
+	}}
+	checkIn := time.Now()
+	attendanceRepo := &fakeAttendanceRepository{attendance: &entities.Attendance{CheckInTime: &checkIn}}
+	svc := service.NewOvertimeService(overtimeRepo, &fakeEmployeeRepository{}, attendanceRepo, nil, nil, &fakeRbacService{}, nil)
+
+	_, err := svc.Reconcile(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, dto.ErrNoCheckOut)
+}
+
+func TestOvertimeService_ApproveRequiresSupervisor(t *testing.T) {
+	supervisorID := uuid.New()
+	userID := uuid.New()
+	overtimeRepo := &fakeOvertimeRepository{overtime: entities.OvertimeRequest{
+		Status:   constants.ENUM_OVERTIME_STATUS_PENDING,
+		Employee: entities.Employee{SupervisorID: &supervisorID},
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		userID: {ID: uuid.New()},
+	}}
+	svc := service.NewOvertimeService(overtimeRepo, employeeRepo, &fakeAttendanceRepository{}, nil, nil, &fakeRbacService{}, nil)
+
+	_, err := svc.Approve(context.Background(), uuid.New(), userID.String(), "")
+	assert.ErrorIs(t, err, dto.ErrNotSupervisor)
+
+	employeeRepo.byUserID[userID] = entities.Employee{ID: supervisorID}
+	result, err := svc.Approve(context.Background(), uuid.New(), userID.String(), "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_OVERTIME_STATUS_APPROVED, result.Status)
+	assert.Equal(t, supervisorID, *result.ApproverID)
+}
+
+func TestOvertimeService_GetByID_ScopedToOwnerApproverAndManagers(t *testing.T) {
+	supervisorID := uuid.New()
+	owner, supervisor, approver, stranger, manager := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	ownerEmployee := entities.Employee{ID: uuid.New(), SupervisorID: &supervisorID}
+	approverEmployee := entities.Employee{ID: uuid.New()}
+	overtimeRepo := &fakeOvertimeRepository{overtime: entities.OvertimeRequest{
+		ID:         uuid.New(),
+		EmployeeID: ownerEmployee.ID,
+		Status:     constants.ENUM_OVERTIME_STATUS_APPROVED,
+		ApproverID: &approverEmployee.ID,
+		Employee:   ownerEmployee,
+	}}
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		owner:      ownerEmployee,
+		supervisor: {ID: supervisorID},
+		approver:   approverEmployee,
+		stranger:   {ID: uuid.New()},
+	}}
+	rbac := &fakeRbacService{granted: map[uuid.UUID]string{manager: constants.PERMISSION_MANAGE_OVERTIME}}
+	svc := service.NewOvertimeService(overtimeRepo, employeeRepo, &fakeAttendanceRepository{}, nil, nil, rbac, nil)
+
+	for _, userID := range []uuid.UUID{owner, supervisor, approver, manager} {
+		result, err := svc.GetByID(context.Background(), overtimeRepo.overtime.ID, userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, overtimeRepo.overtime.ID, result.ID)
+	}
+
+	_, err := svc.GetByID(context.Background(), overtimeRepo.overtime.ID, stranger.String())
+	assert.ErrorIs(t, err, dto.ErrNotOvertimeViewer)
+}
+
+func TestOvertimeService_SubmitTakesTheDayTypeFromTheCalendar(t *testing.T) {
+	userID := uuid.New()
+	employeeRepo := &fakeEmployeeRepository{byUserID: map[uuid.UUID]entities.Employee{
+		userID: {ID: uuid.New()},
+	}}
+	// A Tuesday, with Independence Day on the Monday before
+	tuesday := time.Date(2026, 8, 18, 0, 0, 0, 0, time.UTC)
+	master := &fakeMasterRepository{holidays: []time.Time{tuesday.AddDate(0, 0, -1)}}
+	overtimeRepo := &fakeOvertimeRepository{}
+	svc := service.NewOvertimeService(overtimeRepo, employeeRepo, &fakeAttendanceRepository{}, &fakeShiftRepository{}, master, &fakeRbacService{}, nil)
+	req := dto.OvertimeCreateRequest{WorkDate: "2026-08-18", StartTime: "17:00", EndTime: "19:00", Reason: "month-end closing"}
+
+	result, err := svc.Submit(context.Background(), userID.String(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_OVERTIME_DAY_WORKDAY, result.DayType)
+
+	req.WorkDate = "2026-08-17"
+	result, err = svc.Submit(context.Background(), userID.String(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ENUM_OVERTIME_DAY_HOLIDAY, result.DayType)
+}
+
+func TestOvertimeService_PayrollSummaryOnlyReads(t *testing.T) {
+	plannedStart := time.Date(2026, 3, 10, 17, 0, 0, 0, time.UTC)
+	checkIn := plannedStart.Add(-9 * time.Hour)
+	checkOut := plannedStart.Add(time.Hour)
+	overtimeRepo := &fakeOvertimeRepository{overtime: entities.OvertimeRequest{
+		ID:           uuid.New(),
+		PlannedStart: plannedStart,
+		PlannedEnd:   plannedStart.Add(2 * time.Hour),
+		DayType:      constants.ENUM_OVERTIME_DAY_WORKDAY,
+		Status:       constants.ENUM_OVERTIME_STATUS_APPROVED,
+	}}
+	attendanceRepo := &fakeAttendanceRepository{attendance: &entities.Attendance{ID: uuid.New(), CheckInTime: &checkIn, CheckOutTime: &checkOut}}
+	svc := service.NewOvertimeService(overtimeRepo, &fakeEmployeeRepository{}, attendanceRepo, nil, nil, &fakeRbacService{}, nil)
+	employeeID, start := uuid.New(), plannedStart.AddDate(0, 0, -9)
+	end := start.AddDate(0, 1, 0)
+
+	summary, err := svc.GetPayrollSummary(context.Background(), employeeID, start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, summary.PayableHours)
+	assert.Nil(t, overtimeRepo.overtime.ReconciledAt, "the summary settles nothing")
+
+	reconciled, err := svc.ReconcilePeriod(context.Background(), employeeID, start, end)
+	assert.NoError(t, err)
+	assert.Len(t, reconciled, 1)
+
+	summary, err = svc.GetPayrollSummary(context.Background(), employeeID, start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, summary.PayableHours)
+	assert.Equal(t, 20000.0, summary.HourlyWage)
+	assert.Equal(t, 30000.0, summary.Amount)
+}
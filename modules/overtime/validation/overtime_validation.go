@@ -0,0 +1,21 @@
+package validation
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/modules/overtime/dto"
+	"github.com/go-playground/validator/v10"
+)
+
+type OvertimeValidation struct {
+	validate *validator.Validate
+}
+
+func NewOvertimeValidation() *OvertimeValidation {
+	validate := validator.New()
+	return &OvertimeValidation{
+		validate: validate,
+	}
+}
+
+func (v *OvertimeValidation) ValidateOvertimeCreateRequest(req dto.OvertimeCreateRequest) error {
+	return v.validate.Struct(req)
+}
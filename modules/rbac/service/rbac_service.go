@@ -29,6 +29,7 @@ type RbacService interface {
 	AssignRoleToUser(ctx context.Context, tx *gorm.DB, userRole entities.UserRole) error
 	RemoveRoleFromUser(ctx context.Context, tx *gorm.DB, userID, roleID uuid.UUID) error
 	GetRolesByUser(ctx context.Context, db *gorm.DB, userID uuid.UUID) ([]entities.Role, error)
+	HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
 }
 
 type rbacService struct {
@@ -97,3 +98,20 @@ func (s *rbacService) RemoveRoleFromUser(ctx context.Context, tx *gorm.DB, userI
 func (s *rbacService) GetRolesByUser(ctx context.Context, db *gorm.DB, userID uuid.UUID) ([]entities.Role, error) {
 	return s.rbacRepository.GetRolesByUser(ctx, db, userID)
 }
+
+// HasPermission reports whether the user holds permission through any of
+// their roles, for checks that depend on the record as well as the user.
+func (s *rbacService) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	roles, err := s.rbacRepository.GetRolesByUser(ctx, nil, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			if p.Name == permission {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
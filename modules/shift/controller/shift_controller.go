@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	ShiftController interface {
+		// Shifts
+		CreateShift(ctx *gin.Context)
+		GetShifts(ctx *gin.Context)
+		GetShiftByID(ctx *gin.Context)
+		UpdateShift(ctx *gin.Context)
+		DeleteShift(ctx *gin.Context)
+
+		// Rotations
+		CreateRotation(ctx *gin.Context)
+		GetRotations(ctx *gin.Context)
+		GetRotationByID(ctx *gin.Context)
+		UpdateRotation(ctx *gin.Context)
+		DeleteRotation(ctx *gin.Context)
+
+		// Employee assignments
+		AssignEmployee(ctx *gin.Context)
+		GetEmployeeAssignments(ctx *gin.Context)
+		DeleteAssignment(ctx *gin.Context)
+		GetEmployeeSchedule(ctx *gin.Context)
+	}
+
+	shiftController struct {
+		shiftService    service.ShiftService
+		shiftValidation *validation.ShiftValidation
+		db              *gorm.DB
+	}
+)
+
+func NewShiftController(injector *do.Injector, s service.ShiftService) ShiftController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	shiftValidation := validation.NewShiftValidation()
+	return &shiftController{
+		shiftService:    s,
+		shiftValidation: shiftValidation,
+		db:              db,
+	}
+}
+
+// Shifts
+func (c *shiftController) CreateShift(ctx *gin.Context) {
+	var req dto.ShiftCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftValidation.ValidateShiftCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	shiftModel := entities.Shift{
+		Code:               req.Code,
+		Name:               req.Name,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		GracePeriodMinutes: req.GracePeriodMinutes,
+		BreakMinutes:       req.BreakMinutes,
+		IsActive:           true,
+	}
+	result, err := c.shiftService.CreateShift(ctx.Request.Context(), nil, shiftModel)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed create shift", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success create shift", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *shiftController) GetShifts(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.shiftService.FindShifts(ctx.Request.Context(), nil, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get shifts", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) GetShiftByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.shiftService.GetShiftByID(ctx.Request.Context(), nil, id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get shift", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) UpdateShift(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.ShiftUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftValidation.ValidateShiftUpdateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.shiftService.UpdateShift(ctx.Request.Context(), nil, id, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed update shift", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success update shift", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) DeleteShift(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftService.DeleteShift(ctx.Request.Context(), nil, id); err != nil {
+		res := utils.BuildResponseFailed("failed delete shift", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success delete shift", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Rotations
+func (c *shiftController) CreateRotation(ctx *gin.Context) {
+	var req dto.RotationCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftValidation.ValidateRotationCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	rotationModel := entities.ShiftRotation{
+		Name:        req.Name,
+		Description: req.Description,
+		CycleWeeks:  req.CycleWeeks,
+		Days:        rotationDays(req.Days),
+	}
+	result, err := c.shiftService.CreateRotation(ctx.Request.Context(), nil, rotationModel)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed create rotation", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success create rotation", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *shiftController) GetRotations(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.shiftService.FindRotations(ctx.Request.Context(), nil, &filter)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get rotations", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) GetRotationByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.shiftService.GetRotationByID(ctx.Request.Context(), nil, id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get rotation", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) UpdateRotation(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.RotationUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftValidation.ValidateRotationUpdateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	rotation, err := c.shiftService.GetRotationByID(ctx.Request.Context(), nil, id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get rotation", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	rotation.Name = req.Name
+	rotation.Description = req.Description
+	rotation.CycleWeeks = req.CycleWeeks
+	rotation.Days = rotationDays(req.Days)
+
+	result, err := c.shiftService.UpdateRotation(ctx.Request.Context(), nil, rotation)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed update rotation", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success update rotation", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) DeleteRotation(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftService.DeleteRotation(ctx.Request.Context(), nil, id); err != nil {
+		res := utils.BuildResponseFailed("failed delete rotation", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success delete rotation", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Employee assignments
+func (c *shiftController) AssignEmployee(ctx *gin.Context) {
+	var req dto.AssignmentCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftValidation.ValidateAssignmentCreateRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	assignmentModel := entities.EmployeeShift{
+		EmployeeID:    req.EmployeeID,
+		ShiftID:       req.ShiftID,
+		RotationID:    req.RotationID,
+		EffectiveFrom: req.EffectiveFrom,
+		EffectiveTo:   req.EffectiveTo,
+	}
+	result, err := c.shiftService.AssignEmployee(ctx.Request.Context(), assignmentModel)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed assign shift", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success assign shift", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *shiftController) GetEmployeeAssignments(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.shiftService.FindAssignmentsByEmployeeID(ctx.Request.Context(), nil, employeeID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get shift assignments", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *shiftController) DeleteAssignment(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.shiftService.DeleteAssignment(ctx.Request.Context(), nil, id); err != nil {
+		res := utils.BuildResponseFailed("failed delete shift assignment", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success delete shift assignment", nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetEmployeeSchedule resolves the shift for each day in ?start=YYYY-MM-DD&end=YYYY-MM-DD.
+func (c *shiftController) GetEmployeeSchedule(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", ctx.Query("start"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid start date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02", ctx.Query("end"), helpers.LoadTimezone(""))
+	if err != nil {
+		res := utils.BuildResponseFailed("invalid end date", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.shiftService.GetSchedule(ctx.Request.Context(), employeeID, start, end)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get schedule", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess("success", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func rotationDays(days []dto.RotationDayRequest) []entities.ShiftRotationDay {
+	result := make([]entities.ShiftRotationDay, 0, len(days))
+	for _, day := range days {
+		result = append(result, entities.ShiftRotationDay{
+			WeekIndex: day.WeekIndex,
+			DayOfWeek: day.DayOfWeek,
+			ShiftID:   day.ShiftID,
+		})
+	}
+	return result
+}
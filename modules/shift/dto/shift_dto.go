@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrInvalidClock           = errors.New("time must be in HH:MM format")
+	ErrAssignmentTarget       = errors.New("exactly one of shift_id or rotation_id is required")
+	ErrAssignmentRange        = errors.New("effective_to must not be before effective_from")
+	ErrRotationWeekOutOfRange = errors.New("rotation day week_index must be lower than cycle_weeks")
+	ErrScheduleRange          = errors.New("schedule range must be between 1 and 62 days")
+)
+
+type (
+	ShiftCreateRequest struct {
+		Code               string `json:"code" binding:"required"`
+		Name               string `json:"name" binding:"required"`
+		StartTime          string `json:"start_time" binding:"required"`
+		EndTime            string `json:"end_time" binding:"required"`
+		GracePeriodMinutes int    `json:"grace_period_minutes" binding:"gte=0"`
+		BreakMinutes       int    `json:"break_minutes" binding:"gte=0"`
+	}
+
+	ShiftUpdateRequest struct {
+		Code               string `json:"code"`
+		Name               string `json:"name"`
+		StartTime          string `json:"start_time"`
+		EndTime            string `json:"end_time"`
+		GracePeriodMinutes *int   `json:"grace_period_minutes" binding:"omitempty,gte=0"`
+		BreakMinutes       *int   `json:"break_minutes" binding:"omitempty,gte=0"`
+		IsActive           *bool  `json:"is_active"`
+	}
+
+	RotationDayRequest struct {
+		WeekIndex int        `json:"week_index" binding:"gte=0"`
+		DayOfWeek int        `json:"day_of_week" binding:"gte=0,lte=6"`
+		ShiftID   *uuid.UUID `json:"shift_id"`
+	}
+
+	RotationCreateRequest struct {
+		Name        string               `json:"name" binding:"required"`
+		Description string               `json:"description"`
+		CycleWeeks  int                  `json:"cycle_weeks" binding:"required,gte=1"`
+		Days        []RotationDayRequest `json:"days" binding:"required,dive"`
+	}
+
+	RotationUpdateRequest struct {
+		Name        string               `json:"name" binding:"required"`
+		Description string               `json:"description"`
+		CycleWeeks  int                  `json:"cycle_weeks" binding:"required,gte=1"`
+		Days        []RotationDayRequest `json:"days" binding:"required,dive"`
+	}
+
+	AssignmentCreateRequest struct {
+		EmployeeID    uuid.UUID  `json:"employee_id" binding:"required"`
+		ShiftID       *uuid.UUID `json:"shift_id"`
+		RotationID    *uuid.UUID `json:"rotation_id"`
+		EffectiveFrom time.Time  `json:"effective_from" binding:"required"`
+		EffectiveTo   *time.Time `json:"effective_to"`
+	}
+
+	ScheduleDayResponse struct {
+		Date           string          `json:"date"`
+		Shift          *entities.Shift `json:"shift"`
+		ScheduledStart *time.Time      `json:"scheduled_start"`
+		ScheduledEnd   *time.Time      `json:"scheduled_end"`
+	}
+)
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ShiftRepository interface {
+	// Shifts
+	CreateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error)
+	FindShifts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Shift], error)
+	GetShiftByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Shift, error)
+	UpdateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error)
+	DeleteShift(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Rotations
+	CreateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error)
+	FindRotations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.ShiftRotation], error)
+	GetRotationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.ShiftRotation, error)
+	UpdateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error)
+	DeleteRotation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Employee assignments
+	CreateAssignment(ctx context.Context, tx *gorm.DB, assignment entities.EmployeeShift) (entities.EmployeeShift, error)
+	FindAssignmentsByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) ([]entities.EmployeeShift, error)
+	GetAssignmentOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) (entities.EmployeeShift, error)
+	CloseOpenAssignments(ctx context.Context, tx *gorm.DB, employeeID uuid.UUID, from time.Time) error
+	DeleteAssignment(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+}
+
+type shiftRepository struct {
+	db *gorm.DB
+}
+
+func NewShiftRepository(db *gorm.DB) ShiftRepository {
+	return &shiftRepository{
+		db: db,
+	}
+}
+
+// Shifts
+func (r *shiftRepository) CreateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&shift).Error; err != nil {
+		return entities.Shift{}, err
+	}
+	return shift, nil
+}
+
+func (r *shiftRepository) FindShifts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Shift], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.Shift
+	var page pagination.Page[entities.Shift]
+	paginator, err := pagination.NewPaginator(db.WithContext(ctx).Model(&entities.Shift{}).Order("start_time asc"), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *shiftRepository) GetShiftByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Shift, error) {
+	if db == nil {
+		db = r.db
+	}
+	var shift entities.Shift
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&shift).Error; err != nil {
+		return entities.Shift{}, err
+	}
+	return shift, nil
+}
+
+func (r *shiftRepository) UpdateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Save(&shift).Error; err != nil {
+		return entities.Shift{}, err
+	}
+	return shift, nil
+}
+
+func (r *shiftRepository) DeleteShift(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.Shift{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rotations
+func (r *shiftRepository) CreateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&rotation).Error; err != nil {
+		return entities.ShiftRotation{}, err
+	}
+	return rotation, nil
+}
+
+func (r *shiftRepository) FindRotations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.ShiftRotation], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.ShiftRotation
+	var page pagination.Page[entities.ShiftRotation]
+	paginator, err := pagination.NewPaginator(db.WithContext(ctx).Model(&entities.ShiftRotation{}).Preload("Days.Shift"), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *shiftRepository) GetRotationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.ShiftRotation, error) {
+	if db == nil {
+		db = r.db
+	}
+	var rotation entities.ShiftRotation
+	if err := db.WithContext(ctx).Preload("Days.Shift").Where("id = ?", id).First(&rotation).Error; err != nil {
+		return entities.ShiftRotation{}, err
+	}
+	return rotation, nil
+}
+
+// UpdateRotation replaces the rotation days wholesale with the given set.
+func (r *shiftRepository) UpdateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	days := rotation.Days
+	rotation.Days = nil
+
+	if err := tx.WithContext(ctx).Save(&rotation).Error; err != nil {
+		return entities.ShiftRotation{}, err
+	}
+	if err := tx.WithContext(ctx).Where("rotation_id = ?", rotation.ID).Delete(&entities.ShiftRotationDay{}).Error; err != nil {
+		return entities.ShiftRotation{}, err
+	}
+	for i := range days {
+		days[i].ID = uuid.Nil
+		days[i].RotationID = rotation.ID
+	}
+	if len(days) > 0 {
+		if err := tx.WithContext(ctx).Create(&days).Error; err != nil {
+			return entities.ShiftRotation{}, err
+		}
+	}
+
+	rotation.Days = days
+	return rotation, nil
+}
+
+func (r *shiftRepository) DeleteRotation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.ShiftRotation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Employee assignments
+func (r *shiftRepository) CreateAssignment(ctx context.Context, tx *gorm.DB, assignment entities.EmployeeShift) (entities.EmployeeShift, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Create(&assignment).Error; err != nil {
+		return entities.EmployeeShift{}, err
+	}
+	return assignment, nil
+}
+
+func (r *shiftRepository) FindAssignmentsByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) ([]entities.EmployeeShift, error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.EmployeeShift
+	if err := db.WithContext(ctx).
+		Preload("Shift").
+		Preload("Rotation.Days.Shift").
+		Where("employee_id = ?", employeeID).
+		Order("effective_from desc").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetAssignmentOn returns the assignment in effect on the given date, preferring the most recent one.
+func (r *shiftRepository) GetAssignmentOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) (entities.EmployeeShift, error) {
+	if db == nil {
+		db = r.db
+	}
+	day := date.Format("2006-01-02")
+	var assignment entities.EmployeeShift
+	if err := db.WithContext(ctx).
+		Preload("Shift").
+		Preload("Rotation.Days.Shift").
+		Where("employee_id = ?", employeeID).
+		Where("effective_from <= ?", day).
+		Where("effective_to IS NULL OR effective_to >= ?", day).
+		Order("effective_from desc").
+		First(&assignment).Error; err != nil {
+		return entities.EmployeeShift{}, err
+	}
+	return assignment, nil
+}
+
+// CloseOpenAssignments ends every open-ended assignment starting before from on the day before it.
+func (r *shiftRepository) CloseOpenAssignments(ctx context.Context, tx *gorm.DB, employeeID uuid.UUID, from time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+	return tx.WithContext(ctx).
+		Model(&entities.EmployeeShift{}).
+		Where("employee_id = ? AND effective_to IS NULL AND effective_from < ?", employeeID, from.Format("2006-01-02")).
+		Update("effective_to", from.AddDate(0, 0, -1).Format("2006-01-02")).Error
+}
+
+func (r *shiftRepository) DeleteAssignment(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.EmployeeShift{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
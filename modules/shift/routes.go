@@ -0,0 +1,40 @@
+package shift
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	shiftController := do.MustInvoke[controller.ShiftController](injector)
+
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+
+	shiftRoutes := server.Group("/api/shifts")
+	shiftRoutes.Use(middlewares.Authenticate(jwtService))
+	{
+		// Rotations
+		shiftRoutes.GET("/rotations", shiftController.GetRotations)
+		shiftRoutes.GET("/rotations/:id", shiftController.GetRotationByID)
+		shiftRoutes.POST("/rotations", shiftController.CreateRotation)
+		shiftRoutes.PUT("/rotations/:id", shiftController.UpdateRotation)
+		shiftRoutes.DELETE("/rotations/:id", shiftController.DeleteRotation)
+
+		// Employee assignments
+		shiftRoutes.POST("/assignments", shiftController.AssignEmployee)
+		shiftRoutes.DELETE("/assignments/:id", shiftController.DeleteAssignment)
+		shiftRoutes.GET("/employees/:employee_id/assignments", shiftController.GetEmployeeAssignments)
+		shiftRoutes.GET("/employees/:employee_id/schedule", shiftController.GetEmployeeSchedule)
+
+		// Shifts
+		shiftRoutes.GET("", shiftController.GetShifts)
+		shiftRoutes.GET("/:id", shiftController.GetShiftByID)
+		shiftRoutes.POST("", shiftController.CreateShift)
+		shiftRoutes.PUT("/:id", shiftController.UpdateShift)
+		shiftRoutes.DELETE("/:id", shiftController.DeleteShift)
+	}
+}
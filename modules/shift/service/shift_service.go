@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ShiftService interface {
+	// Shifts
+	CreateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error)
+	FindShifts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Shift], error)
+	GetShiftByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Shift, error)
+	UpdateShift(ctx context.Context, tx *gorm.DB, id uuid.UUID, req dto.ShiftUpdateRequest) (entities.Shift, error)
+	DeleteShift(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Rotations
+	CreateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error)
+	FindRotations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.ShiftRotation], error)
+	GetRotationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.ShiftRotation, error)
+	UpdateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error)
+	DeleteRotation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Employee assignments
+	AssignEmployee(ctx context.Context, assignment entities.EmployeeShift) (entities.EmployeeShift, error)
+	FindAssignmentsByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) ([]entities.EmployeeShift, error)
+	DeleteAssignment(ctx context.Context, tx *gorm.DB, id uuid.UUID) error
+
+	// Schedule resolution
+	ResolveShift(ctx context.Context, employeeID uuid.UUID, date time.Time) (*entities.Shift, error)
+	ScheduleOn(ctx context.Context, employeeID uuid.UUID, date time.Time) (bool, *entities.Shift, error)
+	GetSchedule(ctx context.Context, employeeID uuid.UUID, start, end time.Time) ([]dto.ScheduleDayResponse, error)
+}
+
+type shiftService struct {
+	shiftRepository repository.ShiftRepository
+	db              *gorm.DB
+}
+
+func NewShiftService(
+	shiftRepo repository.ShiftRepository,
+	db *gorm.DB,
+) ShiftService {
+	return &shiftService{
+		shiftRepository: shiftRepo,
+		db:              db,
+	}
+}
+
+// Shifts
+func (s *shiftService) CreateShift(ctx context.Context, tx *gorm.DB, shift entities.Shift) (entities.Shift, error) {
+	return s.shiftRepository.CreateShift(ctx, tx, shift)
+}
+
+func (s *shiftService) FindShifts(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.Shift], error) {
+	return s.shiftRepository.FindShifts(ctx, db, filter)
+}
+
+func (s *shiftService) GetShiftByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Shift, error) {
+	return s.shiftRepository.GetShiftByID(ctx, db, id)
+}
+
+func (s *shiftService) UpdateShift(ctx context.Context, tx *gorm.DB, id uuid.UUID, req dto.ShiftUpdateRequest) (entities.Shift, error) {
+	shift, err := s.shiftRepository.GetShiftByID(ctx, tx, id)
+	if err != nil {
+		return entities.Shift{}, err
+	}
+
+	if req.Code != "" {
+		shift.Code = req.Code
+	}
+	if req.Name != "" {
+		shift.Name = req.Name
+	}
+	if req.StartTime != "" {
+		shift.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		shift.EndTime = req.EndTime
+	}
+	if req.GracePeriodMinutes != nil {
+		shift.GracePeriodMinutes = *req.GracePeriodMinutes
+	}
+	if req.BreakMinutes != nil {
+		shift.BreakMinutes = *req.BreakMinutes
+	}
+	if req.IsActive != nil {
+		shift.IsActive = *req.IsActive
+	}
+
+	return s.shiftRepository.UpdateShift(ctx, tx, shift)
+}
+
+func (s *shiftService) DeleteShift(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	return s.shiftRepository.DeleteShift(ctx, tx, id)
+}
+
+// Rotations
+func (s *shiftService) CreateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error) {
+	return s.shiftRepository.CreateRotation(ctx, tx, rotation)
+}
+
+func (s *shiftService) FindRotations(ctx context.Context, db *gorm.DB, filter *pagination.Filter) (*pagination.Page[entities.ShiftRotation], error) {
+	return s.shiftRepository.FindRotations(ctx, db, filter)
+}
+
+func (s *shiftService) GetRotationByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.ShiftRotation, error) {
+	return s.shiftRepository.GetRotationByID(ctx, db, id)
+}
+
+func (s *shiftService) UpdateRotation(ctx context.Context, tx *gorm.DB, rotation entities.ShiftRotation) (entities.ShiftRotation, error) {
+	if tx != nil {
+		return s.shiftRepository.UpdateRotation(ctx, tx, rotation)
+	}
+
+	var updated entities.ShiftRotation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		updated, err = s.shiftRepository.UpdateRotation(ctx, tx, rotation)
+		return err
+	})
+	return updated, err
+}
+
+func (s *shiftService) DeleteRotation(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	return s.shiftRepository.DeleteRotation(ctx, tx, id)
+}
+
+// AssignEmployee closes any open-ended assignment that started earlier so that
+// exactly one assignment applies from the new effective date onwards.
+func (s *shiftService) AssignEmployee(ctx context.Context, assignment entities.EmployeeShift) (entities.EmployeeShift, error) {
+	var created entities.EmployeeShift
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.shiftRepository.CloseOpenAssignments(ctx, tx, assignment.EmployeeID, assignment.EffectiveFrom); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = s.shiftRepository.CreateAssignment(ctx, tx, assignment)
+		return err
+	})
+	return created, err
+}
+
+func (s *shiftService) FindAssignmentsByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) ([]entities.EmployeeShift, error) {
+	return s.shiftRepository.FindAssignmentsByEmployeeID(ctx, db, employeeID)
+}
+
+func (s *shiftService) DeleteAssignment(ctx context.Context, tx *gorm.DB, id uuid.UUID) error {
+	return s.shiftRepository.DeleteAssignment(ctx, tx, id)
+}
+
+// ResolveShift returns the shift an employee is scheduled to work on date, or
+// nil when the employee has no assignment or the date is a day off.
+func (s *shiftService) ResolveShift(ctx context.Context, employeeID uuid.UUID, date time.Time) (*entities.Shift, error) {
+	assignment, err := s.shiftRepository.GetAssignmentOn(ctx, nil, employeeID, date)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ShiftForDate(assignment, date), nil
+}
+
+// ScheduleOn reports whether the employee is expected at work on date and on
+// which shift. Without an assignment the company work week decides.
+func (s *shiftService) ScheduleOn(ctx context.Context, employeeID uuid.UUID, date time.Time) (bool, *entities.Shift, error) {
+	assignment, err := s.shiftRepository.GetAssignmentOn(ctx, nil, employeeID, date)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return !helpers.IsWeeklyRestDay(date), nil, nil
+		}
+		return false, nil, err
+	}
+
+	shift := ShiftForDate(assignment, date)
+	return shift != nil, shift, nil
+}
+
+func (s *shiftService) GetSchedule(ctx context.Context, employeeID uuid.UUID, start, end time.Time) ([]dto.ScheduleDayResponse, error) {
+	days := int(dateOnly(end).Sub(dateOnly(start)).Hours()/24) + 1
+	if days < 1 || days > 62 {
+		return nil, dto.ErrScheduleRange
+	}
+
+	assignments, err := s.shiftRepository.FindAssignmentsByEmployeeID(ctx, nil, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := make([]dto.ScheduleDayResponse, 0, days)
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i)
+		day := dto.ScheduleDayResponse{Date: date.Format("2006-01-02")}
+
+		for _, assignment := range assignments {
+			if !assignmentCovers(assignment, date) {
+				continue
+			}
+			if shift := ShiftForDate(assignment, date); shift != nil {
+				shiftStart, shiftEnd, err := ShiftWindow(*shift, date)
+				if err != nil {
+					return nil, err
+				}
+				day.Shift = shift
+				day.ScheduledStart = &shiftStart
+				day.ScheduledEnd = &shiftEnd
+			}
+			break
+		}
+
+		schedule = append(schedule, day)
+	}
+
+	return schedule, nil
+}
+
+// ShiftForDate picks the shift of an assignment for the given date. Fixed
+// shifts are worked Monday to Friday; rotations follow their weekly cycle,
+// counted in whole weeks from the week the assignment became effective.
+func ShiftForDate(assignment entities.EmployeeShift, date time.Time) *entities.Shift {
+	weekday := date.Weekday()
+
+	if assignment.Shift != nil {
+		if weekday == time.Saturday || weekday == time.Sunday {
+			return nil
+		}
+		return assignment.Shift
+	}
+
+	if assignment.Rotation == nil {
+		return nil
+	}
+
+	cycle := assignment.Rotation.CycleWeeks
+	if cycle < 1 {
+		cycle = 1
+	}
+	anchor := dateOnly(assignment.EffectiveFrom)
+	anchor = anchor.AddDate(0, 0, -int(anchor.Weekday()))
+	weeks := int(dateOnly(date).Sub(anchor).Hours()/24) / 7
+	weekIndex := ((weeks % cycle) + cycle) % cycle
+
+	for _, day := range assignment.Rotation.Days {
+		if day.WeekIndex == weekIndex && day.DayOfWeek == int(weekday) {
+			return day.Shift
+		}
+	}
+	return nil
+}
+
+// ShiftWindow returns the scheduled start and end of shift when worked on
+// workDate, in workDate's location. Overnight shifts end on the following day.
+func ShiftWindow(shift entities.Shift, workDate time.Time) (time.Time, time.Time, error) {
+	startHour, startMinute, err := ParseClock(shift.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endHour, endMinute, err := ParseClock(shift.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	y, m, d := workDate.Date()
+	loc := workDate.Location()
+	start := time.Date(y, m, d, startHour, startMinute, 0, 0, loc)
+	end := time.Date(y, m, d, endHour, endMinute, 0, 0, loc)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, end, nil
+}
+
+// LeaveWindow returns the part of workDate a leave takes when the employee works
+// shift: the whole shift for a full day, its first or second half for a morning
+// or afternoon session, and the hours of an hourly leave. Without a shift the
+// calendar day stands in, split at noon. Hours before the start of an overnight
+// shift fall on the day after.
+func LeaveWindow(leave entities.Leave, shift *entities.Shift, workDate time.Time) (time.Time, time.Time, error) {
+	y, m, d := workDate.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, workDate.Location())
+	end := start.AddDate(0, 0, 1)
+	if shift != nil {
+		var err error
+		if start, end, err = ShiftWindow(*shift, workDate); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	middle := start.Add(end.Sub(start) / 2)
+
+	switch leave.Unit {
+	case constants.ENUM_LEAVE_UNIT_HALF_DAY:
+		if leave.Session != nil && *leave.Session == constants.ENUM_LEAVE_SESSION_AFTERNOON {
+			return middle, end, nil
+		}
+		return start, middle, nil
+	case constants.ENUM_LEAVE_UNIT_HOURLY:
+		if leave.StartTime == nil || leave.EndTime == nil {
+			return time.Time{}, time.Time{}, dto.ErrInvalidClock
+		}
+		from, err := clockFrom(*leave.StartTime, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to, err := clockFrom(*leave.EndTime, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return from, to, nil
+	}
+	return start, end, nil
+}
+
+// clockFrom places an "HH:MM" time at the first moment it reads on the clock
+// from since on.
+func clockFrom(clock string, since time.Time) (time.Time, error) {
+	hour, minute, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := since.Date()
+	at := time.Date(y, m, d, hour, minute, 0, 0, since.Location())
+	if at.Before(since) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
+
+// ParseClock parses an "HH:MM" wall-clock time.
+func ParseClock(clock string) (int, int, error) {
+	var hour, minute int
+	if len(clock) != 5 {
+		return 0, 0, dto.ErrInvalidClock
+	}
+	if _, err := fmt.Sscanf(clock, "%02d:%02d", &hour, &minute); err != nil {
+		return 0, 0, dto.ErrInvalidClock
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, dto.ErrInvalidClock
+	}
+	return hour, minute, nil
+}
+
+func assignmentCovers(assignment entities.EmployeeShift, date time.Time) bool {
+	day := dateOnly(date)
+	if day.Before(dateOnly(assignment.EffectiveFrom)) {
+		return false
+	}
+	return assignment.EffectiveTo == nil || !day.After(dateOnly(*assignment.EffectiveTo))
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestShiftService (t *testing.T) {
+	assert.True(t, true)
+}
+
+func TestShiftWindow_Overnight(t *testing.T) {
+	shift := entities.Shift{StartTime: "22:00", EndTime: "06:00"}
+	workDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	start, end, err := service.ShiftWindow(shift, workDate)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC), end)
+}
+
+func TestLeaveWindow(t *testing.T) {
+	shift := entities.Shift{StartTime: "08:00", EndTime: "17:00"}
+	workDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	clock := func(value string) *string {
+		return &value
+	}
+
+	afternoon := entities.Leave{Unit: constants.ENUM_LEAVE_UNIT_HALF_DAY, Session: clock(constants.ENUM_LEAVE_SESSION_AFTERNOON)}
+	from, to, err := service.LeaveWindow(afternoon, &shift, workDate)
+	assert.NoError(t, err)
+	assert.Equal(t, at(10, 12, 30), from, "a session is half the shift")
+	assert.Equal(t, at(10, 17, 0), to)
+
+	from, to, err = service.LeaveWindow(afternoon, nil, workDate)
+	assert.NoError(t, err)
+	assert.Equal(t, at(10, 12, 0), from, "without a shift the day splits at noon")
+	assert.Equal(t, at(11, 0, 0), to)
+
+	doctor := entities.Leave{Unit: constants.ENUM_LEAVE_UNIT_HOURLY, StartTime: clock("09:30"), EndTime: clock("11:00")}
+	from, to, err = service.LeaveWindow(doctor, &shift, workDate)
+	assert.NoError(t, err)
+	assert.Equal(t, at(10, 9, 30), from)
+	assert.Equal(t, at(10, 11, 0), to)
+
+	night := entities.Shift{StartTime: "22:00", EndTime: "06:00"}
+	doctor.StartTime, doctor.EndTime = clock("23:30"), clock("01:00")
+	from, to, err = service.LeaveWindow(doctor, &night, workDate)
+	assert.NoError(t, err)
+	assert.Equal(t, at(10, 23, 30), from)
+	assert.Equal(t, at(11, 1, 0), to, "hours run past midnight with an overnight shift")
+
+	doctor.EndTime = nil
+	_, _, err = service.LeaveWindow(doctor, &shift, workDate)
+	assert.Error(t, err)
+}
+
+func TestParseClock_Invalid(t *testing.T) {
+	for _, clock := range []string{"8:00", "24:00", "12:60", "ab:cd", ""} {
+		_, _, err := service.ParseClock(clock)
+		assert.Error(t, err, clock)
+	}
+}
+
+func TestShiftForDate_FixedShiftSkipsWeekend(t *testing.T) {
+	shift := &entities.Shift{ID: uuid.New(), StartTime: "08:00", EndTime: "17:00"}
+	assignment := entities.EmployeeShift{Shift: shift, EffectiveFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, shift, service.ShiftForDate(assignment, time.Date(2026, 3, 13, 9, 0, 0, 0, time.UTC)))
+	assert.Nil(t, service.ShiftForDate(assignment, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestShiftForDate_TwoWeekRotation(t *testing.T) {
+	morning := &entities.Shift{ID: uuid.New(), StartTime: "06:00", EndTime: "14:00"}
+	night := &entities.Shift{ID: uuid.New(), StartTime: "22:00", EndTime: "06:00"}
+	rotation := &entities.ShiftRotation{
+		CycleWeeks: 2,
+		Days: []entities.ShiftRotationDay{
+			{WeekIndex: 0, DayOfWeek: int(time.Monday), Shift: morning},
+			{WeekIndex: 1, DayOfWeek: int(time.Monday), Shift: night},
+		},
+	}
+	// Wednesday 2026-03-04; its week starts on Sunday 2026-03-01
+	assignment := entities.EmployeeShift{Rotation: rotation, EffectiveFrom: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, morning, service.ShiftForDate(assignment, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, night, service.ShiftForDate(assignment, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, morning, service.ShiftForDate(assignment, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, service.ShiftForDate(assignment, time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC)))
+}
+
+type fakeShiftRepository struct {
+	repository.ShiftRepository
+	assignment *entities.EmployeeShift
+}
+
+func (r *fakeShiftRepository) GetAssignmentOn(ctx context.Context, db *gorm.DB, employeeID uuid.UUID, date time.Time) (entities.EmployeeShift, error) {
+	if r.assignment == nil {
+		return entities.EmployeeShift{}, gorm.ErrRecordNotFound
+	}
+	return *r.assignment, nil
+}
+
+func TestShiftService_ScheduleOnWithoutAssignmentFollowsWorkWeek(t *testing.T) {
+	t.Setenv("APP_WORK_WEEK_DAYS", "5")
+	svc := service.NewShiftService(&fakeShiftRepository{}, nil)
+
+	working, shift, err := svc.ScheduleOn(context.Background(), uuid.New(), time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, working)
+	assert.Nil(t, shift)
+
+	working, _, err = svc.ScheduleOn(context.Background(), uuid.New(), time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.False(t, working)
+}
+
+func TestShiftService_ScheduleOnAssignedDayOff(t *testing.T) {
+	t.Setenv("APP_WORK_WEEK_DAYS", "6")
+	shift := &entities.Shift{ID: uuid.New(), StartTime: "08:00", EndTime: "17:00"}
+	svc := service.NewShiftService(&fakeShiftRepository{assignment: &entities.EmployeeShift{Shift: shift}}, nil)
+
+	working, _, err := svc.ScheduleOn(context.Background(), uuid.New(), time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.False(t, working)
+}
@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
+	"github.com/go-playground/validator/v10"
+)
+
+type ShiftValidation struct {
+	validate *validator.Validate
+}
+
+func NewShiftValidation() *ShiftValidation {
+	validate := validator.New()
+	return &ShiftValidation{
+		validate: validate,
+	}
+}
+
+func (v *ShiftValidation) ValidateShiftCreateRequest(req dto.ShiftCreateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	return validateClocks(req.StartTime, req.EndTime)
+}
+
+func (v *ShiftValidation) ValidateShiftUpdateRequest(req dto.ShiftUpdateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	return validateClocks(req.StartTime, req.EndTime)
+}
+
+func (v *ShiftValidation) ValidateRotationCreateRequest(req dto.RotationCreateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	return validateRotationDays(req.CycleWeeks, req.Days)
+}
+
+func (v *ShiftValidation) ValidateRotationUpdateRequest(req dto.RotationUpdateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	return validateRotationDays(req.CycleWeeks, req.Days)
+}
+
+func (v *ShiftValidation) ValidateAssignmentCreateRequest(req dto.AssignmentCreateRequest) error {
+	if err := v.validate.Struct(req); err != nil {
+		return err
+	}
+	if (req.ShiftID == nil) == (req.RotationID == nil) {
+		return dto.ErrAssignmentTarget
+	}
+	if req.EffectiveTo != nil && req.EffectiveTo.Before(req.EffectiveFrom) {
+		return dto.ErrAssignmentRange
+	}
+	return nil
+}
+
+func validateClocks(clocks ...string) error {
+	for _, clock := range clocks {
+		if clock == "" {
+			continue
+		}
+		if _, _, err := service.ParseClock(clock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRotationDays(cycleWeeks int, days []dto.RotationDayRequest) error {
+	for _, day := range days {
+		if day.WeekIndex >= cycleWeeks {
+			return dto.ErrRotationWeekOutOfRange
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	attendanceDto "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/validation"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	VisitController interface {
+		GetAll(ctx *gin.Context)
+		GetByID(ctx *gin.Context)
+		GetPhoto(ctx *gin.Context)
+		GetRoute(ctx *gin.Context)
+
+		// Self-service, the employee comes from the login
+		Start(ctx *gin.Context)
+		End(ctx *gin.Context)
+		Ping(ctx *gin.Context)
+		GetMine(ctx *gin.Context)
+		GetMyRoute(ctx *gin.Context)
+	}
+
+	visitController struct {
+		visitService    service.VisitService
+		visitValidation *validation.VisitValidation
+	}
+)
+
+func NewVisitController(s service.VisitService) VisitController {
+	return &visitController{
+		visitService:    s,
+		visitValidation: validation.NewVisitValidation(),
+	}
+}
+
+// GetAll godoc
+// @Summary Get all field visits
+// @Tags visits
+// @Produce json
+// @Param employee_id query string false "Employee ID"
+// @Success 200 {object} utils.Response
+// @Router /visits [get]
+func (c *visitController) GetAll(ctx *gin.Context) {
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	var employeeID *uuid.UUID
+	if raw := ctx.Query("employee_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			res := utils.BuildResponseFailed("Invalid employee ID", err.Error(), nil)
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+		employeeID = &id
+	}
+
+	page, err := c.visitService.FindAll(ctx.Request.Context(), &filter, employeeID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get visits", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DATA, page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *visitController) GetByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.visitService.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		res := utils.BuildResponseFailed("not found", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DATA, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *visitController) GetPhoto(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	file, err := c.visitService.PhotoFile(ctx.Request.Context(), id)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get photo", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	ctx.File(file)
+}
+
+// GetRoute godoc
+// @Summary Get the day route of a field worker
+// @Description Punches, location pings and visits of the employee's attendance on the date, in time order
+// @Tags visits
+// @Produce json
+// @Param employee_id path string true "Employee ID"
+// @Param date query string true "Work date, YYYY-MM-DD"
+// @Success 200 {object} utils.Response
+// @Router /visits/routes/{employee_id} [get]
+func (c *visitController) GetRoute(ctx *gin.Context) {
+	employeeID, err := uuid.Parse(ctx.Param("employee_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid employee ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	c.route(ctx, employeeID)
+}
+
+// Start godoc
+// @Summary Start a visit
+// @Description Start a customer visit on the open attendance of the logged-in employee
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param body body dto.VisitStartRequest true "Visit start request"
+// @Success 201 {object} utils.Response
+// @Router /visits [post]
+func (c *visitController) Start(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.VisitStartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.visitValidation.ValidateVisitStartRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.visitService.Start(ctx.Request.Context(), employeeID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed start visit", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("visit started", result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// End godoc
+// @Summary End a visit
+// @Description End a visit of the logged-in employee, optionally with a photo sent as a multipart form
+// @Tags visits
+// @Accept json,mpfd
+// @Produce json
+// @Param id path string true "Visit ID"
+// @Param body body dto.VisitEndRequest true "Visit end request"
+// @Success 200 {object} utils.Response
+// @Router /visits/{id}/end [put]
+func (c *visitController) End(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed("Invalid ID", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req dto.VisitEndRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.visitValidation.ValidateVisitEndRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.visitService.End(ctx.Request.Context(), employeeID, id, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed end visit", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("visit ended", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Ping godoc
+// @Summary Upload location pings
+// @Description Upload the positions the device recorded while the logged-in employee is on duty
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param body body dto.PingRequest true "Ping request"
+// @Success 200 {object} utils.Response
+// @Router /visits/pings [post]
+func (c *visitController) Ping(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.PingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.visitValidation.ValidatePingRequest(req); err != nil {
+		res := utils.BuildResponseFailed("validation failed", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.visitService.RecordPings(ctx.Request.Context(), employeeID, req)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed record pings", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess("pings recorded", result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetMine godoc
+// @Summary Get the visits of the logged-in employee
+// @Tags visits
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /visits/me [get]
+func (c *visitController) GetMine(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+
+	var filter = pagination.Filter{}
+	filter.Bind(ctx)
+
+	page, err := c.visitService.FindAll(ctx.Request.Context(), &filter, &employeeID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get visits", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DATA, page)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetMyRoute godoc
+// @Summary Get the day route of the logged-in employee
+// @Tags visits
+// @Produce json
+// @Param date query string true "Work date, YYYY-MM-DD"
+// @Success 200 {object} utils.Response
+// @Router /visits/me/route [get]
+func (c *visitController) GetMyRoute(ctx *gin.Context) {
+	employeeID, ok := c.currentEmployee(ctx)
+	if !ok {
+		return
+	}
+	c.route(ctx, employeeID)
+}
+
+func (c *visitController) route(ctx *gin.Context, employeeID uuid.UUID) {
+	result, err := c.visitService.DayRoute(ctx.Request.Context(), employeeID, ctx.Query("date"))
+	if err != nil {
+		res := utils.BuildResponseFailed("failed get route", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return
+	}
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DATA, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// currentEmployee resolves the employee linked to the logged-in user, writing
+// the error response when there is none.
+func (c *visitController) currentEmployee(ctx *gin.Context) (uuid.UUID, bool) {
+	userID := ctx.MustGet("user_id").(string)
+
+	employeeID, err := c.visitService.EmployeeIDByUserID(ctx.Request.Context(), userID)
+	if err != nil {
+		res := utils.BuildResponseFailed("failed resolve employee", err.Error(), nil)
+		ctx.JSON(visitErrorStatus(err), res)
+		return uuid.Nil, false
+	}
+	return employeeID, true
+}
+
+func visitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, attendanceDto.ErrEmployeeNotLinked),
+		errors.Is(err, dto.ErrNotVisitOwner):
+		return http.StatusForbidden
+	case errors.Is(err, dto.ErrNotOnDuty),
+		errors.Is(err, dto.ErrVisitInProgress),
+		errors.Is(err, dto.ErrVisitEnded):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrVisitPhotoInvalid):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, dto.ErrRouteDate):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
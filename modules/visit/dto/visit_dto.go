@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"errors"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+	MESSAGE_SUCCESS_GET_DATA          = "success get data"
+)
+
+var (
+	ErrNotOnDuty         = errors.New("check in before logging visits or location pings")
+	ErrVisitInProgress   = errors.New("another visit is still in progress")
+	ErrVisitEnded        = errors.New("visit has already ended")
+	ErrNotVisitOwner     = errors.New("visit belongs to another employee")
+	ErrVisitPhotoInvalid = errors.New("photo must be a jpg or png of at most 5 MB")
+	ErrRouteDate         = errors.New("date must be YYYY-MM-DD")
+)
+
+type (
+	// VisitStartRequest opens a visit at the customer, where the worker is now.
+	VisitStartRequest struct {
+		CustomerName string   `json:"customer_name" binding:"required,max=150"`
+		Latitude     *float64 `json:"latitude" binding:"required,latitude"`
+		Longitude    *float64 `json:"longitude" binding:"required,longitude"`
+		Accuracy     *float64 `json:"accuracy" binding:"omitempty,gte=0"`
+	}
+
+	// VisitEndRequest closes a visit, as JSON or as a multipart form when a
+	// photo is attached.
+	VisitEndRequest struct {
+		Latitude  *float64              `json:"latitude" form:"latitude" binding:"required,latitude"`
+		Longitude *float64              `json:"longitude" form:"longitude" binding:"required,longitude"`
+		Accuracy  *float64              `json:"accuracy" form:"accuracy" binding:"omitempty,gte=0"`
+		Notes     string                `json:"notes" form:"notes"`
+		Photo     *multipart.FileHeader `json:"-" form:"photo"`
+	}
+
+	// PingRequest uploads the fixes a device took since its last upload.
+	PingRequest struct {
+		Pings []PingItem `json:"pings" binding:"required,min=1,max=100,dive"`
+	}
+
+	PingItem struct {
+		Latitude   *float64  `json:"latitude" binding:"required,latitude"`
+		Longitude  *float64  `json:"longitude" binding:"required,longitude"`
+		Accuracy   *float64  `json:"accuracy" binding:"omitempty,gte=0"`
+		RecordedAt time.Time `json:"recorded_at" binding:"required"`
+	}
+
+	// PingResult counts the pings stored; skipped ones were taken outside the
+	// duty day or had been uploaded before.
+	PingResult struct {
+		Accepted int `json:"accepted"`
+		Skipped  int `json:"skipped"`
+	}
+
+	// DayRoute is the path a worker took on a work date: the punches, pings
+	// and visits of that day's attendance in time order.
+	DayRoute struct {
+		EmployeeID     uuid.UUID    `json:"employee_id"`
+		WorkDate       string       `json:"work_date"`
+		AttendanceID   *uuid.UUID   `json:"attendance_id"`
+		DistanceMeters float64      `json:"distance_meters"`
+		Points         []RoutePoint `json:"points"`
+	}
+
+	RoutePoint struct {
+		At           time.Time  `json:"at"`
+		Kind         string     `json:"kind"`
+		Type         string     `json:"type,omitempty"`
+		Latitude     float64    `json:"latitude"`
+		Longitude    float64    `json:"longitude"`
+		Accuracy     *float64   `json:"accuracy"`
+		VisitID      *uuid.UUID `json:"visit_id,omitempty"`
+		CustomerName string     `json:"customer_name,omitempty"`
+	}
+)
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VisitRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error)
+	FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID *uuid.UUID) (*pagination.Page[entities.Visit], error)
+	GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Visit, error)
+	FindOpenByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) (entities.Visit, error)
+	FindByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.Visit, error)
+	Update(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error)
+
+	// Pings
+	CreatePings(ctx context.Context, tx *gorm.DB, pings []entities.LocationPing) (int64, error)
+	FindPingsByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.LocationPing, error)
+}
+
+type visitRepository struct {
+	db *gorm.DB
+}
+
+func NewVisitRepository(db *gorm.DB) VisitRepository {
+	return &visitRepository{
+		db: db,
+	}
+}
+
+func (r *visitRepository) Create(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Omit(clause.Associations).Create(&visit).Error; err != nil {
+		return entities.Visit{}, err
+	}
+	return visit, nil
+}
+
+func (r *visitRepository) FindAll(ctx context.Context, db *gorm.DB, filter *pagination.Filter, employeeID *uuid.UUID) (*pagination.Page[entities.Visit], error) {
+	if db == nil {
+		db = r.db
+	}
+	var items []entities.Visit
+	var page pagination.Page[entities.Visit]
+	query := db.WithContext(ctx).Model(&entities.Visit{}).Preload("Employee").Order("started_at DESC")
+	if employeeID != nil {
+		query = query.Where("employee_id = ?", *employeeID)
+	}
+	paginator, err := pagination.NewPaginator(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := paginator.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	page.Set(items, paginator.Page, paginator.Limit, paginator.Total)
+	return &page, nil
+}
+
+func (r *visitRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Visit, error) {
+	if db == nil {
+		db = r.db
+	}
+	var visit entities.Visit
+	if err := db.WithContext(ctx).Preload("Employee").Where("id = ?", id).First(&visit).Error; err != nil {
+		return entities.Visit{}, err
+	}
+	return visit, nil
+}
+
+// FindOpenByEmployeeID returns the visit the employee has not ended yet; the
+// database allows one at a time.
+func (r *visitRepository) FindOpenByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) (entities.Visit, error) {
+	if db == nil {
+		db = r.db
+	}
+	var visit entities.Visit
+	if err := db.WithContext(ctx).Where("employee_id = ? AND ended_at IS NULL", employeeID).First(&visit).Error; err != nil {
+		return entities.Visit{}, err
+	}
+	return visit, nil
+}
+
+func (r *visitRepository) FindByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.Visit, error) {
+	if db == nil {
+		db = r.db
+	}
+	var visits []entities.Visit
+	if err := db.WithContext(ctx).Where("attendance_id = ?", attendanceID).Order("started_at").Find(&visits).Error; err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+func (r *visitRepository) Update(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if err := tx.WithContext(ctx).Model(&visit).Omit(clause.Associations).
+		Select("ended_at", "end_latitude", "end_longitude", "end_accuracy", "notes", "photo").Updates(&visit).Error; err != nil {
+		return entities.Visit{}, err
+	}
+	return visit, nil
+}
+
+// CreatePings stores a batch of pings and returns how many were new; a ping
+// already stored for the employee at the same instant is skipped.
+func (r *visitRepository) CreatePings(ctx context.Context, tx *gorm.DB, pings []entities.LocationPing) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	if len(pings) == 0 {
+		return 0, nil
+	}
+	result := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "employee_id"}, {Name: "recorded_at"}}, DoNothing: true}).
+		Create(&pings)
+	return result.RowsAffected, result.Error
+}
+
+func (r *visitRepository) FindPingsByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.LocationPing, error) {
+	if db == nil {
+		db = r.db
+	}
+	var pings []entities.LocationPing
+	if err := db.WithContext(ctx).Where("attendance_id = ?", attendanceID).Order("recorded_at").Find(&pings).Error; err != nil {
+		return nil, err
+	}
+	return pings, nil
+}
@@ -0,0 +1,33 @@
+package visit
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	visitController := do.MustInvoke[controller.VisitController](injector)
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	rbacService := do.MustInvoke[rbacService.RbacService](injector)
+
+	visitRoutes := server.Group("/api/visits")
+	visitRoutes.Use(middlewares.Authenticate(jwtService))
+	{
+		// Self-service, the employee comes from the login
+		visitRoutes.POST("", visitController.Start)
+		visitRoutes.PUT("/:id/end", visitController.End)
+		visitRoutes.POST("/pings", visitController.Ping)
+		visitRoutes.GET("/me", visitController.GetMine)
+		visitRoutes.GET("/me/route", visitController.GetMyRoute)
+
+		visitRoutes.GET("", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_FIELD_VISITS), visitController.GetAll)
+		visitRoutes.GET("/routes/:employee_id", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_FIELD_VISITS), visitController.GetRoute)
+		visitRoutes.GET("/:id", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_FIELD_VISITS), visitController.GetByID)
+		visitRoutes.GET("/:id/photo", middlewares.Authorize(rbacService, constants.PERMISSION_VIEW_FIELD_VISITS), visitController.GetPhoto)
+	}
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceDto "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/pagination"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VisitService interface {
+	EmployeeIDByUserID(ctx context.Context, userID string) (uuid.UUID, error)
+	Start(ctx context.Context, employeeID uuid.UUID, req dto.VisitStartRequest) (entities.Visit, error)
+	End(ctx context.Context, employeeID, id uuid.UUID, req dto.VisitEndRequest) (entities.Visit, error)
+	RecordPings(ctx context.Context, employeeID uuid.UUID, req dto.PingRequest) (dto.PingResult, error)
+	FindAll(ctx context.Context, filter *pagination.Filter, employeeID *uuid.UUID) (*pagination.Page[entities.Visit], error)
+	GetByID(ctx context.Context, id uuid.UUID) (entities.Visit, error)
+	PhotoFile(ctx context.Context, id uuid.UUID) (string, error)
+	DayRoute(ctx context.Context, employeeID uuid.UUID, date string) (dto.DayRoute, error)
+}
+
+type visitService struct {
+	visitRepository      repository.VisitRepository
+	attendanceRepository attendanceRepository.AttendanceRepository
+	attendanceService    attendanceService.AttendanceService
+	db                   *gorm.DB
+}
+
+func NewVisitService(
+	visitRepo repository.VisitRepository,
+	attendanceRepo attendanceRepository.AttendanceRepository,
+	attendanceSvc attendanceService.AttendanceService,
+	db *gorm.DB,
+) VisitService {
+	return &visitService{
+		visitRepository:      visitRepo,
+		attendanceRepository: attendanceRepo,
+		attendanceService:    attendanceSvc,
+		db:                   db,
+	}
+}
+
+func (s *visitService) EmployeeIDByUserID(ctx context.Context, userID string) (uuid.UUID, error) {
+	return s.attendanceService.EmployeeIDByUserID(ctx, userID)
+}
+
+// onDuty returns the attendance the employee is checked in on; visits and
+// pings belong to the duty day that check-in started.
+func (s *visitService) onDuty(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error) {
+	attendance, err := s.attendanceService.OpenAttendance(ctx, employeeID)
+	if errors.Is(err, attendanceDto.ErrNoOpenAttendance) {
+		return nil, dto.ErrNotOnDuty
+	}
+	return attendance, err
+}
+
+// Start opens a visit at the customer on the employee's open attendance. A
+// visit still in progress has to be ended first.
+func (s *visitService) Start(ctx context.Context, employeeID uuid.UUID, req dto.VisitStartRequest) (entities.Visit, error) {
+	attendance, err := s.onDuty(ctx, employeeID)
+	if err != nil {
+		return entities.Visit{}, err
+	}
+
+	if _, err := s.visitRepository.FindOpenByEmployeeID(ctx, nil, employeeID); err == nil {
+		return entities.Visit{}, dto.ErrVisitInProgress
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Visit{}, err
+	}
+
+	visit, err := s.visitRepository.Create(ctx, nil, entities.Visit{
+		EmployeeID:     employeeID,
+		AttendanceID:   attendance.ID,
+		CustomerName:   req.CustomerName,
+		StartedAt:      time.Now(),
+		StartLatitude:  *req.Latitude,
+		StartLongitude: *req.Longitude,
+		StartAccuracy:  req.Accuracy,
+	})
+	if err != nil {
+		// A concurrent start loses on the one open visit per employee index
+		if _, findErr := s.visitRepository.FindOpenByEmployeeID(ctx, nil, employeeID); findErr == nil {
+			return entities.Visit{}, dto.ErrVisitInProgress
+		}
+		return entities.Visit{}, err
+	}
+	return visit, nil
+}
+
+// End closes a visit of the employee with where it ended, the notes and an
+// optional photo.
+func (s *visitService) End(ctx context.Context, employeeID, id uuid.UUID, req dto.VisitEndRequest) (entities.Visit, error) {
+	visit, err := s.visitRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return entities.Visit{}, err
+	}
+	if visit.EmployeeID != employeeID {
+		return entities.Visit{}, dto.ErrNotVisitOwner
+	}
+	if visit.EndedAt != nil {
+		return entities.Visit{}, dto.ErrVisitEnded
+	}
+
+	photo, err := storeVisitPhoto(req.Photo)
+	if err != nil {
+		return entities.Visit{}, err
+	}
+
+	endedAt := time.Now()
+	visit.EndedAt = &endedAt
+	visit.EndLatitude = req.Latitude
+	visit.EndLongitude = req.Longitude
+	visit.EndAccuracy = req.Accuracy
+	visit.Notes = req.Notes
+	visit.Photo = photo
+
+	updated, err := s.visitRepository.Update(ctx, nil, visit)
+	if err != nil {
+		discardVisitPhoto(photo)
+		return entities.Visit{}, err
+	}
+	return updated, nil
+}
+
+// RecordPings stores the fixes a device took while the employee was on duty.
+// Devices upload in batches, so each ping is tied to the visit in progress
+// when it was taken rather than when it arrived. Pings from before the
+// check-in or ahead of the server clock are skipped, as are ones uploaded
+// before.
+func (s *visitService) RecordPings(ctx context.Context, employeeID uuid.UUID, req dto.PingRequest) (dto.PingResult, error) {
+	attendance, err := s.onDuty(ctx, employeeID)
+	if err != nil {
+		return dto.PingResult{}, err
+	}
+	visits, err := s.visitRepository.FindByAttendanceID(ctx, nil, attendance.ID)
+	if err != nil {
+		return dto.PingResult{}, err
+	}
+
+	latest := time.Now().Add(constants.ATTENDANCE_SYNC_MAX_AHEAD_MINUTES * time.Minute)
+	pings := make([]entities.LocationPing, 0, len(req.Pings))
+	for _, item := range req.Pings {
+		if attendance.CheckInTime != nil && item.RecordedAt.Before(*attendance.CheckInTime) || item.RecordedAt.After(latest) {
+			continue
+		}
+		pings = append(pings, entities.LocationPing{
+			EmployeeID:   employeeID,
+			AttendanceID: attendance.ID,
+			VisitID:      visitAt(visits, item.RecordedAt),
+			Latitude:     *item.Latitude,
+			Longitude:    *item.Longitude,
+			Accuracy:     item.Accuracy,
+			RecordedAt:   item.RecordedAt,
+		})
+	}
+
+	accepted, err := s.visitRepository.CreatePings(ctx, nil, pings)
+	if err != nil {
+		return dto.PingResult{}, err
+	}
+	return dto.PingResult{Accepted: int(accepted), Skipped: len(req.Pings) - int(accepted)}, nil
+}
+
+// visitAt returns the visit that was in progress at the given time, if any.
+func visitAt(visits []entities.Visit, at time.Time) *uuid.UUID {
+	for i := range visits {
+		visit := &visits[i]
+		if at.Before(visit.StartedAt) {
+			continue
+		}
+		if visit.EndedAt == nil || !at.After(*visit.EndedAt) {
+			return &visit.ID
+		}
+	}
+	return nil
+}
+
+func (s *visitService) FindAll(ctx context.Context, filter *pagination.Filter, employeeID *uuid.UUID) (*pagination.Page[entities.Visit], error) {
+	return s.visitRepository.FindAll(ctx, nil, filter, employeeID)
+}
+
+func (s *visitService) GetByID(ctx context.Context, id uuid.UUID) (entities.Visit, error) {
+	return s.visitRepository.GetByID(ctx, nil, id)
+}
+
+func (s *visitService) PhotoFile(ctx context.Context, id uuid.UUID) (string, error) {
+	visit, err := s.visitRepository.GetByID(ctx, nil, id)
+	if err != nil {
+		return "", err
+	}
+	if visit.Photo == "" {
+		return "", gorm.ErrRecordNotFound
+	}
+	return visitPhotoFile(visit.Photo), nil
+}
+
+// DayRoute lays out the punches, pings and visit starts and ends of the
+// employee's attendance on a work date in time order, with the distance
+// covered between them. A day without attendance has an empty route.
+func (s *visitService) DayRoute(ctx context.Context, employeeID uuid.UUID, date string) (dto.DayRoute, error) {
+	workDate, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return dto.DayRoute{}, dto.ErrRouteDate
+	}
+	route := dto.DayRoute{EmployeeID: employeeID, WorkDate: workDate.Format(time.DateOnly), Points: []dto.RoutePoint{}}
+
+	attendance, err := s.attendanceRepository.FindByEmployeeAndWorkDate(employeeID, workDate)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return route, nil
+	}
+	if err != nil {
+		return dto.DayRoute{}, err
+	}
+	route.AttendanceID = &attendance.ID
+
+	pings, err := s.visitRepository.FindPingsByAttendanceID(ctx, nil, attendance.ID)
+	if err != nil {
+		return dto.DayRoute{}, err
+	}
+	visits, err := s.visitRepository.FindByAttendanceID(ctx, nil, attendance.ID)
+	if err != nil {
+		return dto.DayRoute{}, err
+	}
+
+	route.Points = routePoints(attendance.Punches, pings, visits)
+	for i := 1; i < len(route.Points); i++ {
+		route.DistanceMeters += helpers.HaversineDistance(
+			helpers.GeoPoint{Latitude: route.Points[i-1].Latitude, Longitude: route.Points[i-1].Longitude},
+			helpers.GeoPoint{Latitude: route.Points[i].Latitude, Longitude: route.Points[i].Longitude},
+		)
+	}
+	return route, nil
+}
+
+// routePoints merges the positions of a duty day into one time ordered list.
+// Punches without coordinates, like terminal scans, are left out.
+func routePoints(punches []entities.AttendancePunch, pings []entities.LocationPing, visits []entities.Visit) []dto.RoutePoint {
+	points := []dto.RoutePoint{}
+	for _, punch := range punches {
+		if punch.Latitude == nil || punch.Longitude == nil {
+			continue
+		}
+		points = append(points, dto.RoutePoint{
+			At:        punch.PunchTime,
+			Kind:      constants.ENUM_ROUTE_POINT_PUNCH,
+			Type:      punch.Type,
+			Latitude:  *punch.Latitude,
+			Longitude: *punch.Longitude,
+			Accuracy:  punch.Accuracy,
+		})
+	}
+	for _, ping := range pings {
+		points = append(points, dto.RoutePoint{
+			At:        ping.RecordedAt,
+			Kind:      constants.ENUM_ROUTE_POINT_PING,
+			Latitude:  ping.Latitude,
+			Longitude: ping.Longitude,
+			Accuracy:  ping.Accuracy,
+			VisitID:   ping.VisitID,
+		})
+	}
+	for i := range visits {
+		visit := &visits[i]
+		points = append(points, dto.RoutePoint{
+			At:           visit.StartedAt,
+			Kind:         constants.ENUM_ROUTE_POINT_VISIT_START,
+			Latitude:     visit.StartLatitude,
+			Longitude:    visit.StartLongitude,
+			Accuracy:     visit.StartAccuracy,
+			VisitID:      &visit.ID,
+			CustomerName: visit.CustomerName,
+		})
+		if visit.EndedAt != nil && visit.EndLatitude != nil && visit.EndLongitude != nil {
+			points = append(points, dto.RoutePoint{
+				At:           *visit.EndedAt,
+				Kind:         constants.ENUM_ROUTE_POINT_VISIT_END,
+				Latitude:     *visit.EndLatitude,
+				Longitude:    *visit.EndLongitude,
+				Accuracy:     visit.EndAccuracy,
+				VisitID:      &visit.ID,
+				CustomerName: visit.CustomerName,
+			})
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].At.Before(points[j].At)
+	})
+	return points
+}
+
+// storeVisitPhoto writes a visit photo through utils.UploadFile under a random
+// name and returns its path relative to utils.PATH, empty without a photo.
+func storeVisitPhoto(photo *multipart.FileHeader) (string, error) {
+	if photo == nil {
+		return "", nil
+	}
+
+	ext, err := visitPhotoExtension(photo)
+	if err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/%s.%s", constants.VISIT_PHOTO_DIR, uuid.New(), ext)
+	if err := utils.UploadFile(photo, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// discardVisitPhoto removes a stored photo whose visit could not be saved.
+func discardVisitPhoto(path string) {
+	if path != "" {
+		_ = os.Remove(visitPhotoFile(path))
+	}
+}
+
+func visitPhotoFile(path string) string {
+	return filepath.Join(utils.PATH, filepath.FromSlash(path))
+}
+
+// visitPhotoExtension sniffs the photo content, the client file name is not trusted.
+func visitPhotoExtension(photo *multipart.FileHeader) (string, error) {
+	if photo.Size > constants.VISIT_PHOTO_MAX_BYTES {
+		return "", dto.ErrVisitPhotoInvalid
+	}
+
+	file, err := photo.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", dto.ErrVisitPhotoInvalid
+	}
+
+	switch http.DetectContentType(head[:n]) {
+	case "image/jpeg":
+		return "jpg", nil
+	case "image/png":
+		return "png", nil
+	default:
+		return "", dto.ErrVisitPhotoInvalid
+	}
+}
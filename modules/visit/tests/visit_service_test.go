@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	attendanceDto "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/dto"
+	attendanceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/repository"
+	attendanceService "github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestVisitService (t *testing.T) {
+	assert.True(t, true)
+}
+
+type fakeVisitRepository struct {
+	repository.VisitRepository
+	visits []entities.Visit
+	pings  []entities.LocationPing
+}
+
+func (r *fakeVisitRepository) Create(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error) {
+	visit.ID = uuid.New()
+	r.visits = append(r.visits, visit)
+	return visit, nil
+}
+
+func (r *fakeVisitRepository) GetByID(ctx context.Context, db *gorm.DB, id uuid.UUID) (entities.Visit, error) {
+	for _, visit := range r.visits {
+		if visit.ID == id {
+			return visit, nil
+		}
+	}
+	return entities.Visit{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeVisitRepository) FindOpenByEmployeeID(ctx context.Context, db *gorm.DB, employeeID uuid.UUID) (entities.Visit, error) {
+	for _, visit := range r.visits {
+		if visit.EmployeeID == employeeID && visit.EndedAt == nil {
+			return visit, nil
+		}
+	}
+	return entities.Visit{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeVisitRepository) FindByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.Visit, error) {
+	var visits []entities.Visit
+	for _, visit := range r.visits {
+		if visit.AttendanceID == attendanceID {
+			visits = append(visits, visit)
+		}
+	}
+	return visits, nil
+}
+
+func (r *fakeVisitRepository) Update(ctx context.Context, tx *gorm.DB, visit entities.Visit) (entities.Visit, error) {
+	for i := range r.visits {
+		if r.visits[i].ID == visit.ID {
+			r.visits[i] = visit
+		}
+	}
+	return visit, nil
+}
+
+func (r *fakeVisitRepository) CreatePings(ctx context.Context, tx *gorm.DB, pings []entities.LocationPing) (int64, error) {
+	var created int64
+	for _, ping := range pings {
+		stored := false
+		for _, existing := range r.pings {
+			if existing.EmployeeID == ping.EmployeeID && existing.RecordedAt.Equal(ping.RecordedAt) {
+				stored = true
+			}
+		}
+		if !stored {
+			r.pings = append(r.pings, ping)
+			created++
+		}
+	}
+	return created, nil
+}
+
+func (r *fakeVisitRepository) FindPingsByAttendanceID(ctx context.Context, db *gorm.DB, attendanceID uuid.UUID) ([]entities.LocationPing, error) {
+	var pings []entities.LocationPing
+	for _, ping := range r.pings {
+		if ping.AttendanceID == attendanceID {
+			pings = append(pings, ping)
+		}
+	}
+	return pings, nil
+}
+
+type fakeAttendanceService struct {
+	attendanceService.AttendanceService
+	open *entities.Attendance
+}
+
+func (s *fakeAttendanceService) OpenAttendance(ctx context.Context, employeeID uuid.UUID) (*entities.Attendance, error) {
+	if s.open == nil || s.open.EmployeeID != employeeID {
+		return nil, attendanceDto.ErrNoOpenAttendance
+	}
+	return s.open, nil
+}
+
+type fakeAttendanceRepository struct {
+	attendanceRepository.AttendanceRepository
+	attendance *entities.Attendance
+}
+
+func (r *fakeAttendanceRepository) FindByEmployeeAndWorkDate(employeeID uuid.UUID, workDate time.Time) (*entities.Attendance, error) {
+	if r.attendance == nil || r.attendance.EmployeeID != employeeID || !r.attendance.WorkDate.Equal(workDate) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.attendance, nil
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// onDuty returns a visit service for an employee checked in an hour ago.
+func onDuty(employeeID uuid.UUID) (service.VisitService, *fakeVisitRepository, *entities.Attendance) {
+	checkIn := time.Now().Add(-time.Hour)
+	attendance := &entities.Attendance{ID: uuid.New(), EmployeeID: employeeID, CheckInTime: &checkIn}
+	visits := &fakeVisitRepository{}
+	svc := service.NewVisitService(visits, &fakeAttendanceRepository{attendance: attendance}, &fakeAttendanceService{open: attendance}, nil)
+	return svc, visits, attendance
+}
+
+func startAt(name string, lat, lng float64) dto.VisitStartRequest {
+	return dto.VisitStartRequest{CustomerName: name, Latitude: ptr(lat), Longitude: ptr(lng)}
+}
+
+func TestVisitService_Start_RequiresCheckIn(t *testing.T) {
+	svc := service.NewVisitService(&fakeVisitRepository{}, &fakeAttendanceRepository{}, &fakeAttendanceService{}, nil)
+
+	_, err := svc.Start(context.Background(), uuid.New(), startAt("Toko Makmur", -6.2, 106.8))
+
+	assert.ErrorIs(t, err, dto.ErrNotOnDuty)
+}
+
+func TestVisitService_Start_OneVisitAtATime(t *testing.T) {
+	employeeID := uuid.New()
+	svc, _, attendance := onDuty(employeeID)
+
+	visit, err := svc.Start(context.Background(), employeeID, startAt("Toko Makmur", -6.2, 106.8))
+	assert.NoError(t, err)
+	assert.Equal(t, attendance.ID, visit.AttendanceID, "the visit belongs to the duty day of the check-in")
+
+	_, err = svc.Start(context.Background(), employeeID, startAt("Warung Sejahtera", -6.21, 106.81))
+	assert.ErrorIs(t, err, dto.ErrVisitInProgress)
+
+	_, err = svc.End(context.Background(), employeeID, visit.ID, dto.VisitEndRequest{Latitude: ptr(-6.2), Longitude: ptr(106.8), Notes: "ordered 20 boxes"})
+	assert.NoError(t, err)
+
+	_, err = svc.Start(context.Background(), employeeID, startAt("Warung Sejahtera", -6.21, 106.81))
+	assert.NoError(t, err)
+}
+
+func TestVisitService_End_OwnOpenVisitOnly(t *testing.T) {
+	employeeID := uuid.New()
+	svc, _, _ := onDuty(employeeID)
+	visit, _ := svc.Start(context.Background(), employeeID, startAt("Toko Makmur", -6.2, 106.8))
+	end := dto.VisitEndRequest{Latitude: ptr(-6.2), Longitude: ptr(106.8)}
+
+	_, err := svc.End(context.Background(), uuid.New(), visit.ID, end)
+	assert.ErrorIs(t, err, dto.ErrNotVisitOwner)
+
+	ended, err := svc.End(context.Background(), employeeID, visit.ID, end)
+	assert.NoError(t, err)
+	assert.NotNil(t, ended.EndedAt)
+
+	_, err = svc.End(context.Background(), employeeID, visit.ID, end)
+	assert.ErrorIs(t, err, dto.ErrVisitEnded)
+}
+
+func TestVisitService_RecordPings_TiesPingsToTheVisitAndSkipsRepeats(t *testing.T) {
+	employeeID := uuid.New()
+	svc, visits, attendance := onDuty(employeeID)
+	visit, _ := svc.Start(context.Background(), employeeID, startAt("Toko Makmur", -6.2, 106.8))
+
+	beforeVisit := visit.StartedAt.Add(-10 * time.Minute)
+	duringVisit := visit.StartedAt.Add(time.Second)
+	req := dto.PingRequest{Pings: []dto.PingItem{
+		{Latitude: ptr(-6.19), Longitude: ptr(106.79), RecordedAt: beforeVisit},
+		{Latitude: ptr(-6.2), Longitude: ptr(106.8), RecordedAt: duringVisit},
+		{Latitude: ptr(-6.1), Longitude: ptr(106.7), RecordedAt: attendance.CheckInTime.Add(-time.Hour)},
+		{Latitude: ptr(-6.1), Longitude: ptr(106.7), RecordedAt: time.Now().Add(time.Hour)},
+	}}
+
+	result, err := svc.RecordPings(context.Background(), employeeID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, dto.PingResult{Accepted: 2, Skipped: 2}, result, "pings before the check-in or from the future are skipped")
+	assert.Nil(t, visits.pings[0].VisitID)
+	assert.Equal(t, &visit.ID, visits.pings[1].VisitID)
+
+	result, err = svc.RecordPings(context.Background(), employeeID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Accepted, "a retried upload stores nothing twice")
+}
+
+func TestVisitService_DayRoute(t *testing.T) {
+	employeeID := uuid.New()
+	svc, visits, attendance := onDuty(employeeID)
+	attendance.WorkDate = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	checkIn := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	attendance.Punches = []entities.AttendancePunch{
+		{Type: constants.ENUM_PUNCH_TYPE_IN, PunchTime: checkIn, Latitude: ptr(-6.2), Longitude: ptr(106.8)},
+		{Type: constants.ENUM_PUNCH_TYPE_IN, PunchTime: checkIn.Add(time.Minute)},
+	}
+	visitEnd := checkIn.Add(90 * time.Minute)
+	visits.visits = []entities.Visit{{
+		ID: uuid.New(), EmployeeID: employeeID, AttendanceID: attendance.ID, CustomerName: "Toko Makmur",
+		StartedAt: checkIn.Add(time.Hour), StartLatitude: -6.21, StartLongitude: 106.8,
+		EndedAt: &visitEnd, EndLatitude: ptr(-6.21), EndLongitude: ptr(106.8),
+	}}
+	visits.pings = []entities.LocationPing{{AttendanceID: attendance.ID, Latitude: -6.205, Longitude: 106.8, RecordedAt: checkIn.Add(30 * time.Minute)}}
+
+	route, err := svc.DayRoute(context.Background(), employeeID, "2026-10-16")
+	assert.NoError(t, err)
+	assert.Equal(t, &attendance.ID, route.AttendanceID)
+
+	var kinds []string
+	for _, point := range route.Points {
+		kinds = append(kinds, point.Kind)
+	}
+	assert.Equal(t, []string{
+		constants.ENUM_ROUTE_POINT_PUNCH,
+		constants.ENUM_ROUTE_POINT_PING,
+		constants.ENUM_ROUTE_POINT_VISIT_START,
+		constants.ENUM_ROUTE_POINT_VISIT_END,
+	}, kinds, "punches without coordinates are left out")
+	assert.InDelta(t, 1112, route.DistanceMeters, 5, "0.01 degrees of latitude south")
+
+	empty, err := svc.DayRoute(context.Background(), employeeID, "2026-10-15")
+	assert.NoError(t, err)
+	assert.Nil(t, empty.AttendanceID)
+	assert.Empty(t, empty.Points)
+
+	_, err = svc.DayRoute(context.Background(), employeeID, "16/10/2026")
+	assert.ErrorIs(t, err, dto.ErrRouteDate)
+}
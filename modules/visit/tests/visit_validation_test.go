@@ -0,0 +1,10 @@
+package tests
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisitValidation (t *testing.T) {
+	assert.True(t, true)
+}
\ No newline at end of file
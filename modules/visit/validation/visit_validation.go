@@ -0,0 +1,29 @@
+package validation
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/modules/visit/dto"
+	"github.com/go-playground/validator/v10"
+)
+
+type VisitValidation struct {
+	validate *validator.Validate
+}
+
+func NewVisitValidation() *VisitValidation {
+	validate := validator.New()
+	return &VisitValidation{
+		validate: validate,
+	}
+}
+
+func (v *VisitValidation) ValidateVisitStartRequest(req dto.VisitStartRequest) error {
+	return v.validate.Struct(req)
+}
+
+func (v *VisitValidation) ValidateVisitEndRequest(req dto.VisitEndRequest) error {
+	return v.validate.Struct(req)
+}
+
+func (v *VisitValidation) ValidatePingRequest(req dto.PingRequest) error {
+	return v.validate.Struct(req)
+}
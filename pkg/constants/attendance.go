@@ -0,0 +1,135 @@
+package constants
+
+const (
+	ENUM_ATTENDANCE_STATUS_PRESENT     = "present"
+	ENUM_ATTENDANCE_STATUS_ON_TIME     = "on_time"
+	ENUM_ATTENDANCE_STATUS_LATE        = "late"
+	ENUM_ATTENDANCE_STATUS_EARLY_LEAVE = "early_leave"
+	ENUM_ATTENDANCE_STATUS_ABSENT      = "absent"
+
+	// Device fixes reported with a worse accuracy than this are rejected.
+	ATTENDANCE_MAX_GPS_ACCURACY_METERS = 100
+
+	// Hours after the scheduled shift end that an open record still accepts a check-out
+	ATTENDANCE_CHECK_OUT_GRACE_HOURS = 6
+
+	// Company clock time of the nightly absence detection when ABSENCE_DETECTION_AT is unset
+	DEFAULT_ABSENCE_DETECTION_AT = "01:00"
+
+	// Work dates each absence detection run covers, counted back from today. Two
+	// days lets an overnight shift finish before its day is judged.
+	ABSENCE_DETECTION_LOOKBACK_DAYS = 2
+
+	// Check-out clock written to a forgotten punch without a shift when
+	// ATTENDANCE_AUTO_CHECKOUT_AT is unset
+	DEFAULT_AUTO_CHECKOUT_AT = "18:00"
+
+	// How often open records are checked against their check-out deadline
+	AUTO_CHECKOUT_INTERVAL_MINUTES = 30
+
+	// Largest punch selfie accepted, in bytes
+	ATTENDANCE_SELFIE_MAX_BYTES = 5 << 20
+
+	// Directory under utils.PATH the punch selfies are written to
+	ATTENDANCE_SELFIE_DIR = "attendance"
+
+	// Kiosk codes rotate every period and are accepted one period either side
+	KIOSK_CODE_PERIOD_SECONDS = 30
+	KIOSK_CODE_DIGITS         = 8
+	KIOSK_CODE_SKEW_PERIODS   = 1
+
+	// Offline punches older than this when they reach the server are refused
+	ATTENDANCE_SYNC_MAX_AGE_HOURS = 72
+
+	// Device clocks may run this far ahead of the server before a synced punch is refused
+	ATTENDANCE_SYNC_MAX_AHEAD_MINUTES = 5
+
+	// Longest date range an attendance report covers, a quarter
+	ATTENDANCE_REPORT_MAX_DAYS = 92
+)
+
+const (
+	// State keys of ZKTeco terminals that carry meaning for a punch; the
+	// check-in/check-out keys are not trusted since most staff never press them.
+	ZKTECO_STATUS_BREAK_OUT = 2
+	ZKTECO_STATUS_BREAK_IN  = 3
+
+	// Scans of the same employee closer together than this are one punch
+	ATTLOG_DOUBLE_SCAN_SECONDS = 60
+)
+
+const (
+	ENUM_SYNC_STATUS_ACCEPTED  = "accepted"
+	ENUM_SYNC_STATUS_DUPLICATE = "duplicate"
+	ENUM_SYNC_STATUS_REJECTED  = "rejected"
+)
+
+const (
+	ENUM_PUNCH_TYPE_IN          = "in"
+	ENUM_PUNCH_TYPE_OUT         = "out"
+	ENUM_PUNCH_TYPE_BREAK_START = "break_start"
+	ENUM_PUNCH_TYPE_BREAK_END   = "break_end"
+)
+
+const (
+	ENUM_ATTENDANCE_CORRECTION_STATUS_PENDING  = "pending"
+	ENUM_ATTENDANCE_CORRECTION_STATUS_APPROVED = "approved"
+	ENUM_ATTENDANCE_CORRECTION_STATUS_REJECTED = "rejected"
+)
+
+const (
+	ENUM_WORK_MODE_ONSITE = "onsite"
+	ENUM_WORK_MODE_REMOTE = "remote"
+
+	ENUM_REMOTE_WORK_TYPE_WFH           = "wfh"
+	ENUM_REMOTE_WORK_TYPE_BUSINESS_TRIP = "business_trip"
+
+	ENUM_REMOTE_WORK_STATUS_PENDING  = "pending"
+	ENUM_REMOTE_WORK_STATUS_APPROVED = "approved"
+	ENUM_REMOTE_WORK_STATUS_REJECTED = "rejected"
+
+	// Longest stretch of days one remote work request covers
+	REMOTE_WORK_MAX_DAYS = 31
+)
+
+const (
+	ENUM_ANOMALY_RULE_IMPOSSIBLE_TRAVEL    = "impossible_travel"
+	ENUM_ANOMALY_RULE_REPEATED_COORDINATES = "repeated_coordinates"
+	ENUM_ANOMALY_RULE_UNUSUAL_HOURS        = "unusual_hours"
+	ENUM_ANOMALY_RULE_BUDDY_PUNCHING       = "buddy_punching"
+
+	ENUM_ANOMALY_STATUS_OPEN      = "open"
+	ENUM_ANOMALY_STATUS_CONFIRMED = "confirmed"
+	ENUM_ANOMALY_STATUS_DISMISSED = "dismissed"
+
+	// Company clock time of the nightly anomaly detection when ANOMALY_DETECTION_AT is unset
+	DEFAULT_ANOMALY_DETECTION_AT = "02:00"
+
+	// Work dates each detection run flags, counted back from today
+	ANOMALY_DETECTION_LOOKBACK_DAYS = 2
+
+	// Days of history before the flagged dates that patterns and typical hours are taken from
+	ANOMALY_HISTORY_DAYS = 30
+
+	// Longest date range a manual anomaly scan covers
+	ANOMALY_SCAN_MAX_DAYS = 31
+
+	// Faster than this between two punches is impossible travel; hops shorter
+	// than the minimum distance are GPS noise or neighbouring sites
+	ANOMALY_MAX_TRAVEL_SPEED_KMH = 200
+	ANOMALY_MIN_TRAVEL_METERS    = 2000
+
+	// Real fixes jitter; the very same coordinates on this many work dates look like a mocked position
+	ANOMALY_REPEATED_COORDINATE_DAYS = 3
+
+	// A check-in further than this from the shift start, or from the employee's
+	// usual check-in without a shift, is outside typical hours. The usual
+	// check-in needs enough past check-ins to go by.
+	ANOMALY_UNUSUAL_HOURS_MINUTES     = 180
+	ANOMALY_TYPICAL_HOURS_MIN_SAMPLES = 5
+
+	// Two employees punching on the same device this close together, on this
+	// many work dates, is a buddy punching pattern
+	ANOMALY_BUDDY_WINDOW_SECONDS = 120
+	ANOMALY_BUDDY_MIN_DAYS       = 3
+)
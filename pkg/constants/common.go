@@ -10,6 +10,12 @@ const (
 	ENUM_PAGINATION_PER_PAGE = 10
 	ENUM_PAGINATION_PAGE     = 1
 
+	// Fallback zone when neither the location nor APP_TIMEZONE sets one
+	DEFAULT_TIMEZONE = "Asia/Jakarta"
+
+	// Working days per week when APP_WORK_WEEK_DAYS is unset, either 5 or 6
+	DEFAULT_WORK_WEEK_DAYS = 5
+
 	DB         = "db"
 	JWTService = "JWTService"
 )
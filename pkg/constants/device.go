@@ -0,0 +1,23 @@
+package constants
+
+const (
+	ENUM_DEVICE_COMMAND_STATUS_PENDING = "pending"
+	ENUM_DEVICE_COMMAND_STATUS_SENT    = "sent"
+	ENUM_DEVICE_COMMAND_STATUS_DONE    = "done"
+	ENUM_DEVICE_COMMAND_STATUS_FAILED  = "failed"
+)
+
+const (
+	ENUM_DEVICE_ACTION_SYNC_USERS = "sync_users"
+	ENUM_DEVICE_ACTION_REBOOT     = "reboot"
+)
+
+const (
+	// Seconds a terminal waits between command polls, and before it retries a
+	// request the server failed
+	ADMS_POLL_DELAY_SECONDS  = 10
+	ADMS_ERROR_DELAY_SECONDS = 30
+
+	// Queued commands handed to a terminal in one poll
+	ADMS_COMMANDS_PER_POLL = 20
+)
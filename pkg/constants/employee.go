@@ -0,0 +1,6 @@
+package constants
+
+const (
+	// Compared case-insensitively, seeded employees use "Active"
+	ENUM_EMPLOYMENT_STATUS_ACTIVE = "active"
+)
@@ -0,0 +1,8 @@
+package constants
+
+// Kinds of day off on the holiday calendar. Public holidays are set by law,
+// collective leave (cuti bersama) is decreed alongside them each year.
+const (
+	ENUM_HOLIDAY_TYPE_PUBLIC     = "public"
+	ENUM_HOLIDAY_TYPE_COLLECTIVE = "collective_leave"
+)
@@ -0,0 +1,76 @@
+package constants
+
+// Status of a leave. The employee may withdraw a leave while it is pending;
+// an approved one is cancelled, or shortened to the days already taken, once
+// its approver agrees.
+const (
+	ENUM_LEAVE_STATUS_PENDING   = "pending"
+	ENUM_LEAVE_STATUS_APPROVED  = "approved"
+	ENUM_LEAVE_STATUS_REJECTED  = "rejected"
+	ENUM_LEAVE_STATUS_WITHDRAWN = "withdrawn"
+	ENUM_LEAVE_STATUS_CANCELLED = "cancelled"
+)
+
+const (
+	ENUM_LEAVE_CANCELLATION_PENDING  = "pending"
+	ENUM_LEAVE_CANCELLATION_APPROVED = "approved"
+	ENUM_LEAVE_CANCELLATION_REJECTED = "rejected"
+)
+
+// How a leave type builds up its yearly balance. Yearly grants the days at
+// once, monthly a twelfth at the start of every month; types without accrual
+// keep no balance and are only capped per request.
+const (
+	ENUM_LEAVE_ACCRUAL_YEARLY  = "yearly"
+	ENUM_LEAVE_ACCRUAL_MONTHLY = "monthly"
+	ENUM_LEAVE_ACCRUAL_NONE    = "none"
+)
+
+// Who approves a step of a leave's approval chain. The supervisor and the
+// department head are named employees; any holder of manage_leave acts for HR.
+const (
+	ENUM_LEAVE_APPROVER_SUPERVISOR      = "supervisor"
+	ENUM_LEAVE_APPROVER_DEPARTMENT_HEAD = "department_head"
+	ENUM_LEAVE_APPROVER_HR              = "hr"
+)
+
+// Status of an approval step. Steps after the current one wait for it, and a
+// rejection skips the rest of the chain.
+const (
+	ENUM_LEAVE_STEP_WAITING  = "waiting"
+	ENUM_LEAVE_STEP_PENDING  = "pending"
+	ENUM_LEAVE_STEP_APPROVED = "approved"
+	ENUM_LEAVE_STEP_REJECTED = "rejected"
+	ENUM_LEAVE_STEP_SKIPPED  = "skipped"
+)
+
+// What a leave request runs into: another leave of the employee, a day they
+// already checked in, or a day their department has as many people off as it
+// allows.
+const (
+	ENUM_LEAVE_CONFLICT_OVERLAP        = "leave_overlap"
+	ENUM_LEAVE_CONFLICT_ATTENDANCE     = "attendance"
+	ENUM_LEAVE_CONFLICT_DEPARTMENT_CAP = "department_cap"
+)
+
+// How much of a day a leave takes. A half day is the morning or afternoon
+// session of a single working day and an hourly leave (izin) a range of hours
+// within it, e.g. for a doctor's visit.
+const (
+	ENUM_LEAVE_UNIT_FULL_DAY = "full_day"
+	ENUM_LEAVE_UNIT_HALF_DAY = "half_day"
+	ENUM_LEAVE_UNIT_HOURLY   = "hourly"
+)
+
+const (
+	ENUM_LEAVE_SESSION_MORNING   = "morning"
+	ENUM_LEAVE_SESSION_AFTERNOON = "afternoon"
+)
+
+// Working hours of a day an hourly leave is measured against when the employee
+// has no shift: 7 hours on a six-day week and 8 on a five-day week (UU 13/2003
+// art. 77).
+const (
+	LEAVE_DAY_HOURS_SIX_DAY_WEEK  = 7
+	LEAVE_DAY_HOURS_FIVE_DAY_WEEK = 8
+)
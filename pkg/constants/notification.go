@@ -0,0 +1,7 @@
+package constants
+
+const (
+	ENUM_NOTIFICATION_TYPE_ABSENCE       = "absence"
+	ENUM_NOTIFICATION_TYPE_AUTO_CHECKOUT = "auto_checkout"
+	ENUM_NOTIFICATION_TYPE_LEAVE         = "leave"
+)
@@ -0,0 +1,17 @@
+package constants
+
+const (
+	ENUM_OVERTIME_STATUS_PENDING  = "pending"
+	ENUM_OVERTIME_STATUS_APPROVED = "approved"
+	ENUM_OVERTIME_STATUS_REJECTED = "rejected"
+
+	ENUM_OVERTIME_DAY_WORKDAY  = "workday"
+	ENUM_OVERTIME_DAY_REST_DAY = "rest_day"
+	ENUM_OVERTIME_DAY_HOLIDAY  = "holiday"
+
+	// Overtime on a working day is capped at 4 hours (PP 35/2021)
+	OVERTIME_MAX_WORKDAY_MINUTES = 240
+
+	// Hourly wage is 1/173 of the monthly wage (Kepmenakertrans 102/2004)
+	OVERTIME_HOURLY_WAGE_DIVISOR = 173
+)
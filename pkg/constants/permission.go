@@ -0,0 +1,19 @@
+package constants
+
+// Permission names checked by middlewares.Authorize, seeded in permissions.json
+const (
+	PERMISSION_VIEW_ATTENDANCE_PHOTOS      = "view_attendance_photos"
+	PERMISSION_OPERATE_KIOSK               = "operate_kiosk"
+	PERMISSION_PUNCH_FOR_OTHERS            = "punch_for_others"
+	PERMISSION_IMPORT_ATTENDANCE           = "import_attendance"
+	PERMISSION_MANAGE_DEVICES              = "manage_devices"
+	PERMISSION_VIEW_ATTENDANCE_REPORT      = "view_attendance_report"
+	PERMISSION_VIEW_ATTENDANCE_ANOMALIES   = "view_attendance_anomalies"
+	PERMISSION_SCAN_ATTENDANCE_ANOMALIES   = "scan_attendance_anomalies"
+	PERMISSION_VIEW_ATTENDANCE_CORRECTIONS = "view_attendance_corrections"
+	PERMISSION_VIEW_REMOTE_WORK            = "view_remote_work"
+	PERMISSION_VIEW_FIELD_VISITS           = "view_field_visits"
+	PERMISSION_MANAGE_LEAVE                = "manage_leave"
+	PERMISSION_MANAGE_HOLIDAYS             = "manage_holidays"
+	PERMISSION_MANAGE_OVERTIME             = "manage_overtime"
+)
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	// Directory under utils.PATH the visit photos are written to
+	VISIT_PHOTO_DIR = "visits"
+
+	// Largest visit photo accepted, in bytes
+	VISIT_PHOTO_MAX_BYTES = 5 << 20
+)
+
+const (
+	ENUM_ROUTE_POINT_PUNCH       = "punch"
+	ENUM_ROUTE_POINT_PING        = "ping"
+	ENUM_ROUTE_POINT_VISIT_START = "visit_start"
+	ENUM_ROUTE_POINT_VISIT_END   = "visit_end"
+)
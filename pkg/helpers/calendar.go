@@ -0,0 +1,29 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+)
+
+// WorkWeekDays returns the configured working days per week, 5 or 6.
+func WorkWeekDays() int {
+	if days, err := strconv.Atoi(os.Getenv("APP_WORK_WEEK_DAYS")); err == nil && (days == 5 || days == 6) {
+		return days
+	}
+	return constants.DEFAULT_WORK_WEEK_DAYS
+}
+
+// IsWeeklyRestDay reports whether date falls on a rest day of the company work
+// week: Saturday and Sunday for a 5-day week, Sunday only for a 6-day week.
+func IsWeeklyRestDay(date time.Time) bool {
+	switch date.Weekday() {
+	case time.Sunday:
+		return true
+	case time.Saturday:
+		return WorkWeekDays() == 5
+	}
+	return false
+}
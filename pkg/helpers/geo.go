@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+)
+
+const EarthRadiusMeters = 6371000.0
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// HaversineDistance returns the great-circle distance between two points in meters.
+func HaversineDistance(a, b GeoPoint) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PointInPolygon reports whether p lies inside the polygon using ray casting.
+// The polygon may be given open or closed (first vertex repeated at the end).
+func PointInPolygon(p GeoPoint, polygon []GeoPoint) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	inside := false
+	j := len(polygon) - 1
+	for i := 0; i < len(polygon); i++ {
+		vi, vj := polygon[i], polygon[j]
+		if (vi.Latitude > p.Latitude) != (vj.Latitude > p.Latitude) {
+			crossLng := (vj.Longitude-vi.Longitude)*(p.Latitude-vi.Latitude)/(vj.Latitude-vi.Latitude) + vi.Longitude
+			if p.Longitude < crossLng {
+				inside = !inside
+			}
+		}
+		j = i
+	}
+	return inside
+}
+
+// ParsePolygon decodes a polygon stored as a JSON array of
+// {"latitude": .., "longitude": ..} vertices. Empty input yields a nil polygon.
+func ParsePolygon(raw []byte) ([]GeoPoint, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var polygon []GeoPoint
+	if err := json.Unmarshal(raw, &polygon); err != nil {
+		return nil, err
+	}
+	if len(polygon) == 0 {
+		return nil, nil
+	}
+	if len(polygon) < 3 {
+		return nil, errors.New("polygon must have at least 3 vertices")
+	}
+	return polygon, nil
+}
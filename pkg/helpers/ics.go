@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ICSEvent is a VEVENT of an iCalendar file reduced to what a day-off calendar
+// needs. Start is the first day and End the day after the last, as DTEND of
+// all-day events is exclusive.
+type ICSEvent struct {
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+}
+
+// Dates lists every day the event covers.
+func (e ICSEvent) Dates() []time.Time {
+	var dates []time.Time
+	for day := e.Start; day.Before(e.End); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day)
+	}
+	return dates
+}
+
+// ParseICS reads the events of an iCalendar (RFC 5545) file as published by
+// government and calendar providers. Only the date of DTSTART and DTEND is
+// kept, so timed events cover the days they start through end on. Cancelled
+// events are left out.
+func ParseICS(r io.Reader) ([]ICSEvent, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ICSEvent
+	var event *ICSEvent
+	cancelled := false
+	for i, line := range lines {
+		name, value := splitICSLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &ICSEvent{}
+			cancelled = false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("event ending on line %d has no DTSTART", i+1)
+			}
+			if !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			if !cancelled {
+				events = append(events, *event)
+			}
+			event = nil
+		case name == "DTSTART", name == "DTEND":
+			date, err := parseICSDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %q", i+1, name, value)
+			}
+			if name == "DTSTART" {
+				event.Start = date
+			} else if len(value) == 8 {
+				event.End = date
+			} else {
+				// A timed event ends on the day of DTEND, inclusive
+				event.End = date.AddDate(0, 0, 1)
+			}
+		case name == "SUMMARY":
+			event.Summary = unescapeICSText(value)
+		case name == "DESCRIPTION":
+			event.Description = unescapeICSText(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		}
+	}
+	if event != nil {
+		return nil, errors.New("event is missing END:VEVENT")
+	}
+	return events, nil
+}
+
+// unfoldICS joins content lines folded over several physical lines, which
+// continue with a leading space or tab.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "\ufeff"))
+	}
+	return lines, scanner.Err()
+}
+
+// splitICSLine splits "NAME;PARAM=x:value" into its upper-cased name and its
+// value, dropping the parameters.
+func splitICSLine(line string) (name, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	name, _, _ = strings.Cut(head, ";")
+	return strings.ToUpper(name), value
+}
+
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("date is too short")
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"os"
+	"time"
+	// Embedded so zone lookups work on images without a system tz database
+	_ "time/tzdata"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+)
+
+// LoadTimezone resolves an IANA zone name. An empty or unknown name falls back to
+// APP_TIMEZONE and then to the company default.
+func LoadTimezone(name string) *time.Location {
+	for _, candidate := range []string{name, os.Getenv("APP_TIMEZONE"), constants.DEFAULT_TIMEZONE} {
+		if candidate == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(candidate); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// StartOfDay returns midnight of t's calendar day in t's own location.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// DateOf returns t's calendar day as midnight UTC, the form written to date columns
+// so the stored day does not shift with the database session zone.
+func DateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time-based one-time codes as in RFC 6238: HMAC-SHA1 over the number of
+// periods since the Unix epoch, with base32 secrets like authenticator apps use.
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a random 160-bit secret, base32 encoded.
+func NewTOTPSecret() (string, error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(key), nil
+}
+
+// TOTPCode returns the code of the period t falls in.
+func TOTPCode(secret string, t time.Time, period time.Duration, digits int) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, totpCounter(t, period), digits), nil
+}
+
+// VerifyTOTP reports whether code belongs to the period of t or to one of the
+// skew periods on either side, which absorbs scan latency and clock drift.
+func VerifyTOTP(secret, code string, t time.Time, period time.Duration, digits, skew int) bool {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != digits {
+		return false
+	}
+
+	counter := int64(totpCounter(t, period))
+	for step := -int64(skew); step <= int64(skew); step++ {
+		if counter+step < 0 {
+			continue
+		}
+		expected := hotp(key, uint64(counter+step), digits)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// TOTPPeriodEnd is the moment the code of the period t falls in expires.
+func TOTPPeriodEnd(t time.Time, period time.Duration) time.Time {
+	seconds := int64(period / time.Second)
+	return time.Unix(int64(totpCounter(t, period)+1)*seconds, 0).In(t.Location())
+}
+
+func totpCounter(t time.Time, period time.Duration) uint64 {
+	return uint64(t.Unix() / int64(period/time.Second))
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	return totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
+
+// hotp is the HMAC-based one-time password of RFC 4226.
+func hotp(key []byte, counter uint64, digits int) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Job runs once a day at At, an "HH:MM" clock in the scheduler location, or
+// every Every when that is set.
+type Job struct {
+	Name  string
+	At    string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	location *time.Location
+	jobs     []Job
+}
+
+func New(location *time.Location) *Scheduler {
+	return &Scheduler{
+		location: location,
+	}
+}
+
+// Enabled reports whether background jobs should run in this process. Set
+// SCHEDULER_ENABLED=false on all but one instance when scaling out.
+func Enabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("SCHEDULER_ENABLED"))
+	return err != nil || enabled
+}
+
+func (s *Scheduler) Register(job Job) error {
+	if job.Every < 0 {
+		return fmt.Errorf("job %s: negative interval", job.Name)
+	}
+	if _, err := s.next(job, time.Now()); err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start runs every registered job in its own goroutine until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		next, _ := s.next(job, time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		log.Printf("job %s failed: %v", job.Name, err)
+	}
+}
+
+func (s *Scheduler) next(job Job, now time.Time) (time.Time, error) {
+	if job.Every > 0 {
+		return now.Truncate(job.Every).Add(job.Every), nil
+	}
+	return NextRun(job.At, now.In(s.location))
+}
+
+// NextRun is the first occurrence of the "HH:MM" clock at strictly after now, in
+// now's location.
+func NextRun(at string, now time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid run time %q, expected HH:MM", at)
+	}
+
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	}
+	return next, nil
+}
@@ -8,18 +8,36 @@ import (
 	authController "github.com/Caknoooo/go-gin-clean-starter/modules/auth/controller"
 	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
 	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	deviceController "github.com/Caknoooo/go-gin-clean-starter/modules/device/controller"
+	deviceRepository "github.com/Caknoooo/go-gin-clean-starter/modules/device/repository"
+	deviceService "github.com/Caknoooo/go-gin-clean-starter/modules/device/service"
 	employeeController "github.com/Caknoooo/go-gin-clean-starter/modules/employee/controller"
 	employeeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/employee/repository"
 	employeeService "github.com/Caknoooo/go-gin-clean-starter/modules/employee/service"
+	leaveController "github.com/Caknoooo/go-gin-clean-starter/modules/leave/controller"
+	leaveRepository "github.com/Caknoooo/go-gin-clean-starter/modules/leave/repository"
+	leaveService "github.com/Caknoooo/go-gin-clean-starter/modules/leave/service"
 	masterController "github.com/Caknoooo/go-gin-clean-starter/modules/master/controller"
 	masterRepository "github.com/Caknoooo/go-gin-clean-starter/modules/master/repository"
 	masterService "github.com/Caknoooo/go-gin-clean-starter/modules/master/service"
+	notificationController "github.com/Caknoooo/go-gin-clean-starter/modules/notification/controller"
+	notificationRepository "github.com/Caknoooo/go-gin-clean-starter/modules/notification/repository"
+	notificationService "github.com/Caknoooo/go-gin-clean-starter/modules/notification/service"
+	overtimeController "github.com/Caknoooo/go-gin-clean-starter/modules/overtime/controller"
+	overtimeRepository "github.com/Caknoooo/go-gin-clean-starter/modules/overtime/repository"
+	overtimeService "github.com/Caknoooo/go-gin-clean-starter/modules/overtime/service"
 	rbacController "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/controller"
 	rbacRepositoryPkg "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/repository"
 	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	shiftController "github.com/Caknoooo/go-gin-clean-starter/modules/shift/controller"
+	shiftRepository "github.com/Caknoooo/go-gin-clean-starter/modules/shift/repository"
+	shiftService "github.com/Caknoooo/go-gin-clean-starter/modules/shift/service"
 	userController "github.com/Caknoooo/go-gin-clean-starter/modules/user/controller"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
 	userService "github.com/Caknoooo/go-gin-clean-starter/modules/user/service"
+	visitController "github.com/Caknoooo/go-gin-clean-starter/modules/visit/controller"
+	visitRepository "github.com/Caknoooo/go-gin-clean-starter/modules/visit/repository"
+	visitService "github.com/Caknoooo/go-gin-clean-starter/modules/visit/service"
 
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/samber/do"
@@ -45,17 +63,61 @@ func RegisterDependencies(injector *do.Injector) {
 	userRepository := repository.NewUserRepository(db)
 	refreshTokenRepository := authRepo.NewRefreshTokenRepository(db)
 	employeeRepository := employeeRepository.NewEmployeeRepository(db)
+	attendanceCorrectionRepository := attendanceRepository.NewAttendanceCorrectionRepository(db)
+	attendanceAnomalyRepository := attendanceRepository.NewAttendanceAnomalyRepository(db)
+	remoteWorkRepository := attendanceRepository.NewRemoteWorkRepository(db)
 	attendanceRepository := attendanceRepository.NewAttendanceRepository(db)
 	masterRepository := masterRepository.NewMasterRepository(db)
+	shiftRepository := shiftRepository.NewShiftRepository(db)
+	overtimeRepository := overtimeRepository.NewOvertimeRepository(db)
+	leaveTypeRepository := leaveRepository.NewLeaveTypeRepository(db)
+	leaveRepository := leaveRepository.NewLeaveRepository(db)
+	notificationRepository := notificationRepository.NewNotificationRepository(db)
+	deviceRepository := deviceRepository.NewDeviceRepository(db)
+	visitRepository := visitRepository.NewVisitRepository(db)
 
 	rbacRepository := rbacRepositoryPkg.NewRbacRepository(db)
+	rbacService := rbacService.NewRbacService(rbacRepository, db)
 
 	userService := userService.NewUserService(userRepository, db)
 	authService := authService.NewAuthService(userRepository, refreshTokenRepository, jwtService, db)
 	employeeService := employeeService.NewEmployeeService(employeeRepository, db)
-	attendanceService := attendanceService.NewAttendanceService(attendanceRepository, db)
 	masterService := masterService.NewMasterService(masterRepository, db)
-	rbacService := rbacService.NewRbacService(rbacRepository, db)
+	shiftService := shiftService.NewShiftService(shiftRepository, db)
+	notificationService := notificationService.NewNotificationService(notificationRepository, db)
+	absenceService := attendanceService.NewAbsenceService(attendanceRepository, employeeRepository, leaveRepository, masterRepository, shiftService, notificationService, db)
+	autoCheckoutService := attendanceService.NewAutoCheckoutService(attendanceRepository, notificationService, db)
+	attendanceAnomalyService := attendanceService.NewAttendanceAnomalyService(attendanceAnomalyRepository, attendanceRepository, employeeRepository, masterRepository, db)
+
+	// Provided before attendanceService shadows the package name; jobs and scripts invoke them
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceService.AbsenceService, error) {
+			return absenceService, nil
+		},
+	)
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceService.AutoCheckoutService, error) {
+			return autoCheckoutService, nil
+		},
+	)
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceService.AttendanceAnomalyService, error) {
+			return attendanceAnomalyService, nil
+		},
+	)
+
+	attendanceReportService := attendanceService.NewAttendanceReportService(attendanceRepository, employeeRepository, leaveRepository, overtimeRepository, masterRepository, shiftService, db)
+	attendanceCorrectionService := attendanceService.NewAttendanceCorrectionService(attendanceCorrectionRepository, attendanceRepository, employeeRepository, masterRepository, leaveRepository, shiftService, rbacService, db)
+	remoteWorkService := attendanceService.NewRemoteWorkService(remoteWorkRepository, employeeRepository, rbacService, db)
+	attendanceService := attendanceService.NewAttendanceService(attendanceRepository, employeeRepository, masterRepository, remoteWorkRepository, leaveRepository, shiftService, db)
+	do.ProvideValue(injector, attendanceService)
+	overtimeService := overtimeService.NewOvertimeService(overtimeRepository, employeeRepository, attendanceRepository, shiftRepository, masterRepository, rbacService, db)
+	deviceService := deviceService.NewDeviceService(deviceRepository, employeeRepository, masterRepository, attendanceService, db)
+	visitService := visitService.NewVisitService(visitRepository, attendanceRepository, attendanceService, db)
+	leaveService := leaveService.NewLeaveService(leaveRepository, leaveTypeRepository, employeeRepository, masterRepository, attendanceRepository, attendanceService, shiftService, notificationService, rbacService, db)
+
+	// Route guards invoke it through middlewares.Authorize
+	do.ProvideValue(injector, rbacService)
 
 	do.Provide(
 		injector, func(i *do.Injector) (userController.UserController, error) {
@@ -81,12 +143,78 @@ func RegisterDependencies(injector *do.Injector) {
 		},
 	)
 
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceController.AttendanceCorrectionController, error) {
+			return attendanceController.NewAttendanceCorrectionController(attendanceCorrectionService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceController.AttendanceReportController, error) {
+			return attendanceController.NewAttendanceReportController(attendanceReportService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceController.AttendanceAnomalyController, error) {
+			return attendanceController.NewAttendanceAnomalyController(attendanceAnomalyService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (attendanceController.RemoteWorkController, error) {
+			return attendanceController.NewRemoteWorkController(remoteWorkService), nil
+		},
+	)
+
 	do.Provide(
 		injector, func(i *do.Injector) (masterController.MasterController, error) {
 			return masterController.NewMasterController(masterService), nil
 		},
 	)
 
+	do.Provide(
+		injector, func(i *do.Injector) (leaveController.LeaveController, error) {
+			return leaveController.NewLeaveController(i, leaveService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (shiftController.ShiftController, error) {
+			return shiftController.NewShiftController(i, shiftService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (overtimeController.OvertimeController, error) {
+			return overtimeController.NewOvertimeController(i, overtimeService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (notificationController.NotificationController, error) {
+			return notificationController.NewNotificationController(i, notificationService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (deviceController.DeviceController, error) {
+			return deviceController.NewDeviceController(i, deviceService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (deviceController.IclockController, error) {
+			return deviceController.NewIclockController(deviceService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (visitController.VisitController, error) {
+			return visitController.NewVisitController(visitService), nil
+		},
+	)
+
 	do.Provide(
 		injector, func(i *do.Injector) (rbacController.RbacController, error) {
 			return rbacController.NewRbacController(i, rbacService), nil
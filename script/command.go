@@ -119,7 +119,7 @@ func Commands(injector *do.Injector) bool {
 	}
 
 	if scriptFlag {
-		if err := Script(scriptName, db); err != nil {
+		if err := Script(scriptName, injector); err != nil {
 			log.Fatalf("error script: %v", err)
 		}
 		log.Println("script run successfully")
@@ -0,0 +1,48 @@
+package script
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/samber/do"
+)
+
+type (
+	DetectAbsenceScript struct {
+		absenceService service.AbsenceService
+	}
+)
+
+func NewDetectAbsenceScript(injector *do.Injector) *DetectAbsenceScript {
+	return &DetectAbsenceScript{
+		absenceService: do.MustInvoke[service.AbsenceService](injector),
+	}
+}
+
+// Run checks the work date given as --date=YYYY-MM-DD, yesterday by default.
+func (s *DetectAbsenceScript) Run() error {
+	loc := helpers.LoadTimezone("")
+	workDate := time.Now().In(loc).AddDate(0, 0, -1)
+
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "--date=") {
+			date, err := time.ParseInLocation("2006-01-02", strings.TrimPrefix(arg, "--date="), loc)
+			if err != nil {
+				return err
+			}
+			workDate = date
+		}
+	}
+
+	result, err := s.absenceService.DetectAbsences(context.Background(), workDate)
+	if err != nil {
+		return err
+	}
+	log.Printf("absence detection %s: %d marked absent, %d pending, holiday=%t", result.WorkDate, result.Marked, result.Pending, result.Holiday)
+	return nil
+}
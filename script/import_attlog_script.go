@@ -0,0 +1,68 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/attendance/service"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+type (
+	ImportAttlogScript struct {
+		attendanceService service.AttendanceService
+	}
+)
+
+func NewImportAttlogScript(injector *do.Injector) *ImportAttlogScript {
+	return &ImportAttlogScript{
+		attendanceService: do.MustInvoke[service.AttendanceService](injector),
+	}
+}
+
+// Run imports the terminal log given as --file=PATH for the location given as
+// --location=UUID, optionally naming the terminal with --device=SERIAL.
+func (s *ImportAttlogScript) Run() error {
+	var path, location, device string
+	for _, arg := range os.Args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "--file="):
+			path = strings.TrimPrefix(arg, "--file=")
+		case strings.HasPrefix(arg, "--location="):
+			location = strings.TrimPrefix(arg, "--location=")
+		case strings.HasPrefix(arg, "--device="):
+			device = strings.TrimPrefix(arg, "--device=")
+		}
+	}
+	if path == "" || location == "" {
+		return errors.New("--file and --location are required")
+	}
+
+	locationID, err := uuid.Parse(location)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := s.attendanceService.ImportAttlog(context.Background(), locationID, device, file)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("attlog import %s: %d lines, %d punches imported into %d attendances, %d duplicates", path, result.Lines, result.Imported, result.Attendances, result.Duplicates)
+	for _, pin := range result.Unmatched {
+		log.Printf("unmatched PIN %s: %d punches", pin.PIN, pin.Punches)
+	}
+	for _, line := range result.Invalid {
+		log.Printf("line %d skipped: %s", line.Line, line.Error)
+	}
+	return nil
+}
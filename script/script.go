@@ -3,15 +3,25 @@ package script
 import (
 	"errors"
 
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/samber/do"
 	"gorm.io/gorm"
 )
 
-func Script(scriptName string, db *gorm.DB) error {
+func Script(scriptName string, injector *do.Injector) error {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+
 	switch scriptName {
 	case "example_script":
 		exampleScript := NewExampleScript(db)
 		return exampleScript.Run()
+	case "detect_absence":
+		detectAbsenceScript := NewDetectAbsenceScript(injector)
+		return detectAbsenceScript.Run()
+	case "import_attlog":
+		importAttlogScript := NewImportAttlogScript(injector)
+		return importAttlogScript.Run()
 	default:
 		return errors.New("script not found")
 	}
-}
\ No newline at end of file
+}